@@ -18,8 +18,11 @@ import (
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/routes"
 	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/cloudinary"
 	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/email"
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/memory"
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/payment"
 	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql"
 	"github.com/ingwrok/hotelBooking/internal/common/logger"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
 	"github.com/ingwrok/hotelBooking/internal/core/services"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
@@ -34,6 +37,7 @@ func main() {
 
 	initTimeZone()
 	initConfig()
+	checkSecrets()
 
 	db := initDatabase()
 	defer db.Close()
@@ -46,6 +50,19 @@ func main() {
 	rateplanRepo := postgresql.NewRatePlanRepository(db)
 	bookingRepo := postgresql.NewBookingRepository(db)
 	userRepo := postgresql.NewUserRepository(db)
+	roleRepo := postgresql.NewRoleRepository(db)
+	refreshTokenRepo := postgresql.NewRefreshTokenRepository(db)
+	accountTokenRepo := postgresql.NewAccountTokenRepository(db)
+	auditRepo := postgresql.NewAuditRepository(db)
+	twoFactorRepo := postgresql.NewTwoFactorRepository(db)
+	restrictionRepo := postgresql.NewRestrictionRepository(db)
+	promotionRepo := postgresql.NewPromotionRepository(db)
+	taxRuleRepo := postgresql.NewTaxRuleRepository(db)
+	paymentRepo := postgresql.NewPaymentRepository(db)
+	folioRepo := postgresql.NewFolioRepository(db)
+	allotmentRepo := postgresql.NewAllotmentRepository(db)
+	waitlistRepo := postgresql.NewWaitlistRepository(db)
+	idempotencyRepo := postgresql.NewIdempotencyRepository(db)
 
 	// Adapters
 	cldCloudName := os.Getenv("CLOUDINARY_CLOUD_NAME")
@@ -55,19 +72,37 @@ func main() {
 	if err != nil {
 		logger.ErrorErr(err, "Failed to init Cloudinary")
 	}
-	userSvc := services.NewUserService(userRepo)
 
 	// Email Service
 	// emailAdapter := email.NewGomailAdapter()
 	emailAdapter := email.NewResendAdapter()
 
+	// Failed logins live in Postgres so every instance sees them; "memory"
+	// suits a single instance.
+	var loginAttemptStore ports.LoginAttemptStore = postgresql.NewLoginAttemptRepository(db)
+	if viper.GetString("auth.login_attempt_store") == "memory" {
+		loginAttemptStore = memory.NewLoginAttemptStore()
+	}
+
+	userSvc := services.NewUserService(userRepo, roleRepo, refreshTokenRepo, accountTokenRepo, emailAdapter, loginAttemptStore, auditRepo, twoFactorRepo)
+
+	// Payment Gateway
+	paymentGateway := initPaymentGateway()
+
 	// Services
-	roomSvc := services.NewRoomService(roomRepo)
+	roomSvc := services.NewRoomService(roomRepo, restrictionRepo)
 	amenitySvc := services.NewAmenityService(amenityRepo)
 	roomTypeSvc := services.NewRoomTypeService(roomTypeRepo, imgUploader)
 	addonSvc := services.NewAddonService(addonRepo, imgUploader)
 	rateplanSvc := services.NewRatePlanService(rateplanRepo)
-	bookingSvc := services.NewBookingService(bookingRepo, roomRepo, rateplanRepo, addonRepo, emailAdapter)
+	bookingSvc := services.NewBookingService(bookingRepo, roomRepo, roomTypeRepo, rateplanRepo, addonRepo, restrictionRepo, promotionRepo, taxRuleRepo, allotmentRepo, waitlistRepo, emailAdapter)
+	restrictionSvc := services.NewRestrictionService(restrictionRepo)
+	promotionSvc := services.NewPromotionService(promotionRepo)
+	taxRuleSvc := services.NewTaxRuleService(taxRuleRepo)
+	paymentSvc := services.NewPaymentService(paymentRepo, bookingRepo, rateplanRepo, paymentGateway, emailAdapter)
+	folioSvc := services.NewFolioService(folioRepo, bookingRepo)
+	allotmentSvc := services.NewAllotmentService(allotmentRepo)
+	idempotencySvc := services.NewIdempotencyService(idempotencyRepo)
 
 	// Handlers
 	roomHandler := handlers.NewRoomHandler(roomSvc)
@@ -77,11 +112,26 @@ func main() {
 	rateplanHandler := handlers.NewRatePlanHandler(rateplanSvc)
 	bookingHandler := handlers.NewBookingHandler(bookingSvc)
 	userHandler := handlers.NewUserHandler(userSvc)
+	restrictionHandler := handlers.NewRestrictionHandler(restrictionSvc)
+	promotionHandler := handlers.NewPromotionHandler(promotionSvc)
+	taxRuleHandler := handlers.NewTaxRuleHandler(taxRuleSvc)
+	paymentHandler := handlers.NewPaymentHandler(paymentSvc)
+	folioHandler := handlers.NewFolioHandler(folioSvc)
+	allotmentHandler := handlers.NewAllotmentHandler(allotmentSvc)
 
 	go startBookingCleanupWorker(ctx, bookingSvc)
+	go startIdempotencyCleanupWorker(ctx, idempotencySvc)
+	go startTokenCleanupWorker(ctx, userSvc)
 
 	// Server
-	app := fiber.New()
+	// nginx passes the client's address in X-Real-IP. It is only believed from
+	// the proxies in app.trusted_proxies, so c.IP() is the real client and
+	// cannot be spoofed by callers reaching the backend directly.
+	app := fiber.New(fiber.Config{
+		ProxyHeader:             "X-Real-IP",
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          splitList(viper.GetString("app.trusted_proxies")),
+	})
 	app.Use(recover.New(), fiberlogger.New())
 
 	// Rate Limiting
@@ -100,8 +150,8 @@ func main() {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     allowOrigins,
 		AllowMethods:     "GET,POST,PUT,DELETE,PATCH,OPTIONS",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
-		ExposeHeaders:    "Content-Length",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, Idempotency-Key",
+		ExposeHeaders:    "Content-Length, Idempotent-Replayed",
 		AllowCredentials: true,
 	}))
 
@@ -111,8 +161,14 @@ func main() {
 	routes.RoomTypeRoutes(app, roomTypeHandler, userSvc)
 	routes.AddonRoutes(app, addonHandler, userSvc)
 	routes.RatePlanRoutes(app, rateplanHandler, userSvc)
-	routes.BookingRoutes(app, bookingHandler, userSvc, bookingSvc)
+	routes.BookingRoutes(app, bookingHandler, paymentHandler, folioHandler, userSvc, bookingSvc, idempotencySvc)
+	routes.ReservationRoutes(app, bookingHandler, paymentHandler, userSvc, bookingSvc, idempotencySvc)
+	routes.WaitlistRoutes(app, bookingHandler, userSvc)
 	routes.UserRoutes(app, userHandler, userSvc)
+	routes.RestrictionRoutes(app, restrictionHandler, userSvc)
+	routes.PromotionRoutes(app, promotionHandler, userSvc)
+	routes.TaxRuleRoutes(app, taxRuleHandler, userSvc)
+	routes.AllotmentRoutes(app, allotmentHandler, userSvc)
 
 	go func() {
 		addr := fmt.Sprintf(":%d", viper.GetInt("app.port"))
@@ -143,6 +199,7 @@ func initConfig() {
 
 	viper.AddConfigPath(".")
 
+	viper.BindEnv("app.env", "APP_ENV")
 	viper.BindEnv("db.driver", "DB_DRIVER")
 	viper.BindEnv("db.host", "DB_HOST")
 	viper.BindEnv("db.port", "DB_PORT")
@@ -151,15 +208,52 @@ func initConfig() {
 	viper.BindEnv("db.password", "DB_PASSWORD")
 	viper.BindEnv("db.sslmode", "DB_SSLMODE")
 	viper.BindEnv("secret", "APP_SECRET")
+	viper.BindEnv("auth.totp_encryption_key", "TOTP_ENCRYPTION_KEY")
 	viper.BindEnv("cors.allow_origins", "CORS_ALLOW_ORIGINS")
+	viper.BindEnv("app.trusted_proxies", "TRUSTED_PROXIES")
+	viper.BindEnv("payment.provider", "PAYMENT_PROVIDER")
+	viper.BindEnv("payment.webhook_secret", "PAYMENT_WEBHOOK_SECRET")
 
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
+	viper.SetDefault("app.env", "dev")
 	viper.SetDefault("app.port", 8000)
+	viper.SetDefault("app.frontend_url", "http://localhost:5173")
+	viper.SetDefault("app.trusted_proxies", "127.0.0.1, ::1")
 	viper.SetDefault("db.driver", "pgx")
 	viper.SetDefault("db.host", "localhost")
 	viper.SetDefault("db.port", 5432)
+	viper.SetDefault("booking.check_in_hour", 14)
+	viper.SetDefault("booking.cancellation_deadline_hours", 24)
+	viper.SetDefault("booking.late_cancellation_penalty_nights", 1)
+	viper.SetDefault("waitlist.offer_hold_minutes", 120)
+	viper.SetDefault("idempotency.ttl_hours", 24)
+	viper.SetDefault("idempotency.processing_lease_seconds", 120)
+	viper.SetDefault("auth.access_token_minutes", 15)
+	viper.SetDefault("auth.refresh_token_days", 30)
+	viper.SetDefault("auth.verify_email_hours", 48)
+	viper.SetDefault("auth.reset_password_minutes", 30)
+	viper.SetDefault("auth.require_verified_email", true)
+	viper.SetDefault("auth.login_attempt_store", "postgres")
+	viper.SetDefault("auth.login_free_attempts", 3)
+	viper.SetDefault("auth.login_delay_seconds", 1)
+	viper.SetDefault("auth.login_max_delay_seconds", 30)
+	viper.SetDefault("auth.lockout_threshold", 10)
+	viper.SetDefault("auth.lockout_window_minutes", 15)
+	viper.SetDefault("auth.lockout_minutes", 30)
+	viper.SetDefault("auth.ip_block_threshold", 50)
+	viper.SetDefault("auth.unlock_account_hours", 24)
+	viper.SetDefault("auth.totp_issuer", "Hotel Booking")
+	viper.SetDefault("auth.login_challenge_minutes", 5)
+	viper.SetDefault("auth.login_challenge_max_attempts", 5)
+	viper.SetDefault("auth.recovery_code_count", 10)
+	viper.SetDefault("lookup.max_failed_attempts", 10)
+	viper.SetDefault("lookup.window_minutes", 15)
+	viper.SetDefault("pricing.tax_rounding", "line")
+	viper.SetDefault("payment.provider", "mock")
+	viper.SetDefault("payment.mock.webhook_url", "http://localhost:8000/api/payments/webhook")
+	viper.SetDefault("payment.mock.delay_seconds", 10)
 
 	if err := viper.ReadInConfig(); err != nil {
 		// ไม่มีไฟล์ config ก็ยังรันได้ด้วยค่า env/default
@@ -167,6 +261,17 @@ func initConfig() {
 	}
 }
 
+// splitList splits a comma separated config value, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func startBookingCleanupWorker(ctx context.Context, svc *services.BookingService) {
 	ticker := time.NewTicker(1 * time.Minute)
 	defer ticker.Stop()
@@ -187,6 +292,60 @@ func startBookingCleanupWorker(ctx context.Context, svc *services.BookingService
 	}
 }
 
+func startIdempotencyCleanupWorker(ctx context.Context, svc *services.IdempotencyService) {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			rows, err := svc.CleanupExpiredKeys(ctx)
+			if err != nil {
+				logger.ErrorErr(err, "Worker idempotency cleanup failed")
+			} else if rows > 0 {
+				logger.Info(fmt.Sprintf("Worker: Deleted %d expired idempotency keys", rows))
+			}
+		case <-ctx.Done():
+			logger.Info("Idempotency cleanup worker stopping...")
+			return
+		}
+	}
+}
+
+func startTokenCleanupWorker(ctx context.Context, svc *services.UserService) {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			rows, err := svc.CleanupExpiredTokens(ctx)
+			if err != nil {
+				logger.ErrorErr(err, "Worker token cleanup failed")
+			} else if rows > 0 {
+				logger.Info(fmt.Sprintf("Worker: Deleted %d expired refresh tokens", rows))
+			}
+
+			rows, err = svc.CleanupLoginAttempts(ctx)
+			if err != nil {
+				logger.ErrorErr(err, "Worker login attempt cleanup failed")
+			} else if rows > 0 {
+				logger.Info(fmt.Sprintf("Worker: Deleted %d stale login attempts", rows))
+			}
+
+			rows, err = svc.CleanupLoginChallenges(ctx)
+			if err != nil {
+				logger.ErrorErr(err, "Worker login challenge cleanup failed")
+			} else if rows > 0 {
+				logger.Info(fmt.Sprintf("Worker: Deleted %d expired login challenges", rows))
+			}
+		case <-ctx.Done():
+			logger.Info("Token cleanup worker stopping...")
+			return
+		}
+	}
+}
+
 func initTimeZone() {
 	loc, err := time.LoadLocation("Asia/Bangkok")
 	if err != nil {
@@ -195,6 +354,38 @@ func initTimeZone() {
 	time.Local = loc
 }
 
+// initPaymentGateway picks the provider from payment.provider. The mock takes
+// every payment without charging anyone, so it only runs with app.env=dev.
+// checkSecrets stops startup when the TOTP encryption key is missing or
+// reuses the JWT secret.
+func checkSecrets() {
+	key := viper.GetString("auth.totp_encryption_key")
+	if key == "" {
+		panic("auth.totp_encryption_key (TOTP_ENCRYPTION_KEY) is not set")
+	}
+	if key == viper.GetString("secret") {
+		panic("auth.totp_encryption_key must differ from the JWT secret")
+	}
+}
+
+func initPaymentGateway() ports.PaymentGateway {
+	provider := viper.GetString("payment.provider")
+	switch provider {
+	case "mock":
+		if env := viper.GetString("app.env"); env != "dev" {
+			panic(fmt.Sprintf("payment provider %q is only allowed with app.env=dev, got %q", provider, env))
+		}
+		logger.Warn("Using the mock payment gateway, no real payments will be taken")
+		return payment.NewMockGateway(
+			viper.GetString("payment.webhook_secret"),
+			viper.GetString("payment.mock.webhook_url"),
+			time.Duration(viper.GetInt("payment.mock.delay_seconds"))*time.Second,
+		)
+	default:
+		panic(fmt.Sprintf("unknown payment provider %q", provider))
+	}
+}
+
 func initDatabase() *sqlx.DB {
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=Asia/Bangkok",
 		viper.GetString("db.host"),
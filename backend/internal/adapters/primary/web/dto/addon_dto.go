@@ -1,5 +1,11 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
 type AddonCategoryRequest struct {
 	Name string `json:"name"`
 }
@@ -10,20 +16,102 @@ type AddonCategoryResponse struct {
 }
 
 type AddonRequest struct {
-	CategoryID  int     `json:"categoryId"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	UnitName    string  `json:"unitName"`
-	PictureURL  string  `json:"pictureUrl"`
+	CategoryID  int          `json:"categoryId"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Price       domain.Money `json:"price"`
+	UnitName    string       `json:"unitName"`
+	PictureURL  string       `json:"pictureUrl"`
+	IsExtraBed  bool         `json:"isExtraBed"`
+
+	ChargeUnit          string `json:"chargeUnit"`
+	RequiresServiceDate bool   `json:"requiresServiceDate"`
+	DailyCapacity       int    `json:"dailyCapacity"` // 0 is unlimited
 }
 
 type AddonResponse struct {
-	AddonID     int     `json:"addonId"`
-	CategoryID  int     `json:"categoryId"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	UnitName    string  `json:"unitName"`
-	PictureURL  string  `json:"pictureUrl"`
+	AddonID     int          `json:"addonId"`
+	CategoryID  int          `json:"categoryId"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Price       domain.Money `json:"price"`
+	UnitName    string       `json:"unitName"`
+	PictureURL  string       `json:"pictureUrl"`
+	IsExtraBed  bool         `json:"isExtraBed"`
+
+	ChargeUnit          string `json:"chargeUnit"`
+	RequiresServiceDate bool   `json:"requiresServiceDate"`
+	DailyCapacity       int    `json:"dailyCapacity"`
+
+	Slots []AddonSlotResponse `json:"slots,omitempty"`
+}
+
+type AddonSlotRequest struct {
+	Name     string `json:"name"`
+	Capacity int    `json:"capacity"`
+}
+
+type AddonSlotResponse struct {
+	SlotID   int    `json:"slotId"`
+	AddonID  int    `json:"addonId"`
+	Name     string `json:"name"`
+	Capacity int    `json:"capacity"`
+}
+
+func ToAddonSlotResponse(slot *domain.AddonSlot) AddonSlotResponse {
+	return AddonSlotResponse{
+		SlotID:   slot.SlotID,
+		AddonID:  slot.AddonID,
+		Name:     slot.Name,
+		Capacity: slot.Capacity,
+	}
+}
+
+func ToAddonSlotResponses(slots []*domain.AddonSlot) []AddonSlotResponse {
+	res := make([]AddonSlotResponse, len(slots))
+	for i, slot := range slots {
+		res[i] = ToAddonSlotResponse(slot)
+	}
+	return res
+}
+
+// AddonAvailabilityResponse is one day of an addon's capacity. Remaining is
+// left out when the addon has no daily limit.
+type AddonAvailabilityResponse struct {
+	Date      time.Time                       `json:"date"`
+	Booked    int                             `json:"booked"`
+	Remaining *int                            `json:"remaining,omitempty"`
+	Slots     []AddonSlotAvailabilityResponse `json:"slots,omitempty"`
+}
+
+type AddonSlotAvailabilityResponse struct {
+	SlotID    int    `json:"slotId"`
+	Name      string `json:"name"`
+	Capacity  int    `json:"capacity"`
+	Booked    int    `json:"booked"`
+	Remaining int    `json:"remaining"`
+}
+
+func ToAddonAvailabilityResponses(days []*domain.AddonAvailability) []AddonAvailabilityResponse {
+	res := make([]AddonAvailabilityResponse, len(days))
+	for i, d := range days {
+		res[i] = AddonAvailabilityResponse{
+			Date:   d.Date,
+			Booked: d.Booked,
+		}
+		if d.Remaining >= 0 {
+			remaining := d.Remaining
+			res[i].Remaining = &remaining
+		}
+		for _, slot := range d.Slots {
+			res[i].Slots = append(res[i].Slots, AddonSlotAvailabilityResponse{
+				SlotID:    slot.SlotID,
+				Name:      slot.Name,
+				Capacity:  slot.Capacity,
+				Booked:    slot.Booked,
+				Remaining: slot.Remaining,
+			})
+		}
+	}
+	return res
 }
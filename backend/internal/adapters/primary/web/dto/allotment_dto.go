@@ -0,0 +1,93 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type AllotmentRequest struct {
+	GroupCode     string `json:"groupCode"`
+	Name          string `json:"name"`
+	RoomTypeID    int    `json:"roomTypeId"`
+	StartDate     string `json:"startDate"`
+	EndDate       string `json:"endDate"`
+	RoomsAllotted int    `json:"roomsAllotted"`
+	CutoffDate    string `json:"cutoffDate"`
+	IsActive      *bool  `json:"isActive"`
+}
+
+type AllotmentResponse struct {
+	AllotmentID   int       `json:"allotmentId"`
+	GroupCode     string    `json:"groupCode"`
+	Name          string    `json:"name"`
+	RoomTypeID    int       `json:"roomTypeId"`
+	StartDate     string    `json:"startDate"`
+	EndDate       string    `json:"endDate"`
+	RoomsAllotted int       `json:"roomsAllotted"`
+	CutoffDate    string    `json:"cutoffDate"`
+	IsActive      bool      `json:"isActive"`
+	Released      bool      `json:"released"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+func ToAllotmentResponse(a *domain.GroupAllotment) AllotmentResponse {
+	return AllotmentResponse{
+		AllotmentID:   a.AllotmentID,
+		GroupCode:     a.GroupCode,
+		Name:          a.Name,
+		RoomTypeID:    a.RoomTypeID,
+		StartDate:     optionalDate(a.StartDate),
+		EndDate:       optionalDate(a.EndDate),
+		RoomsAllotted: a.RoomsAllotted,
+		CutoffDate:    optionalDate(a.CutoffDate),
+		IsActive:      a.IsActive,
+		Released:      a.Released(time.Now()),
+		CreatedAt:     a.CreatedAt,
+		UpdatedAt:     a.UpdatedAt,
+	}
+}
+
+func ToAllotmentResponses(allotments []*domain.GroupAllotment) []AllotmentResponse {
+	res := make([]AllotmentResponse, len(allotments))
+	for i, a := range allotments {
+		res[i] = ToAllotmentResponse(a)
+	}
+	return res
+}
+
+type AllotmentNightResponse struct {
+	StayDate  string `json:"stayDate"`
+	Allotted  int    `json:"allotted"`
+	PickedUp  int    `json:"pickedUp"`
+	Remaining int    `json:"remaining"`
+}
+
+type PickupReportResponse struct {
+	Allotment          AllotmentResponse        `json:"allotment"`
+	Nights             []AllotmentNightResponse `json:"nights"`
+	Bookings           int                      `json:"bookings"`
+	RoomNightsAllotted int                      `json:"roomNightsAllotted"`
+	RoomNightsPickedUp int                      `json:"roomNightsPickedUp"`
+}
+
+func ToPickupReportResponse(r *domain.PickupReport) PickupReportResponse {
+	nights := make([]AllotmentNightResponse, len(r.Nights))
+	for i, n := range r.Nights {
+		nights[i] = AllotmentNightResponse{
+			StayDate:  optionalDate(n.StayDate),
+			Allotted:  n.Allotted,
+			PickedUp:  n.PickedUp,
+			Remaining: n.Remaining(),
+		}
+	}
+
+	return PickupReportResponse{
+		Allotment:          ToAllotmentResponse(r.Allotment),
+		Nights:             nights,
+		Bookings:           r.Bookings,
+		RoomNightsAllotted: r.RoomNightsAllotted(),
+		RoomNightsPickedUp: r.RoomNightsPickedUp(),
+	}
+}
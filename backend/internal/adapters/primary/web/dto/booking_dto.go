@@ -13,23 +13,28 @@ type BookingRequest struct {
 	CheckInDate  string                `json:"checkInDate"`
 	CheckOutDate string                `json:"checkOutDate"`
 	NumAdults    int                   `json:"numAdults"`
+	ChildAges    []int                 `json:"childAges"`
 	Email        string                `json:"email"`
 	BookingAddon []BookingAddonRequest `json:"bookingAddon"`
+	PromoCode    string                `json:"promoCode"`
+	GroupCode    string                `json:"groupCode"`
 }
 
 type BookingResponse struct {
 	BookingID     int                    `json:"bookingId"`
+	ReservationID int                    `json:"reservationId"`
 	UserID        int                    `json:"userId"`
 	RatePlanID    int                    `json:"ratePlanId"`
 	RoomID        int                    `json:"roomId"`
 	CheckInDate   time.Time              `json:"checkInDate"`
 	CheckOutDate  time.Time              `json:"checkOutDate"`
 	NumAdults     int                    `json:"numAdults"`
+	ChildAges     []int                  `json:"childAges"`
 	Status        string                 `json:"status"`
-	RoomSubTotal  float64                `json:"roomSubTotal"`
-	AddonSubTotal float64                `json:"addonSubTotal"`
-	TaxesAmount   float64                `json:"taxesAmount"`
-	TotalPrice    float64                `json:"totalPrice"`
+	RoomSubTotal  domain.Money           `json:"roomSubTotal"`
+	AddonSubTotal domain.Money           `json:"addonSubTotal"`
+	TaxesAmount   domain.Money           `json:"taxesAmount"`
+	TotalPrice    domain.Money           `json:"totalPrice"`
 	CreatedAt     time.Time              `json:"createdAt"`
 	UpdatedAt     time.Time              `json:"updatedAt"`
 	ExpiredAt     time.Time              `json:"expiredAt"`
@@ -38,6 +43,84 @@ type BookingResponse struct {
 	RoomTypeName  string                 `json:"roomTypeName"`
 	GuestDetails  *GuestInfoResponse     `json:"guestDetails"`
 	BookingAddon  []BookingAddonResponse `json:"bookingAddon"`
+	Nights        []NightlyRateResponse  `json:"nights"`
+	Taxes         []BookingTaxResponse   `json:"taxes"`
+
+	CancellationReason string       `json:"cancellationReason,omitempty"`
+	CancellationFee    domain.Money `json:"cancellationFee"`
+	CancelledAt        *time.Time   `json:"cancelledAt,omitempty"`
+
+	PromoCode      string       `json:"promoCode,omitempty"`
+	DiscountAmount domain.Money `json:"discountAmount"`
+
+	AllotmentID int `json:"allotmentId,omitempty"`
+
+	ConfirmationCode string `json:"confirmationCode"`
+
+	AmountPaid domain.Money `json:"amountPaid"`
+	Balance    domain.Money `json:"balance"` // negative when the guest is owed a refund
+	BalanceDue domain.Money `json:"balanceDue"`
+}
+
+// ModifyStayRequest moves a booking. Empty fields keep the current value.
+// ExpectedTotalPrice is the total from the preview; the change is refused if
+// the price has moved since.
+type ModifyStayRequest struct {
+	CheckInDate        string        `json:"checkInDate"`
+	CheckOutDate       string        `json:"checkOutDate"`
+	RoomTypeID         int           `json:"roomTypeId"`
+	ExpectedTotalPrice *domain.Money `json:"expectedTotalPrice"`
+}
+
+type StayQuoteResponse struct {
+	BookingID      int                   `json:"bookingId"`
+	RoomTypeID     int                   `json:"roomTypeId"`
+	RoomID         int                   `json:"roomId"`
+	CheckInDate    time.Time             `json:"checkInDate"`
+	CheckOutDate   time.Time             `json:"checkOutDate"`
+	Nights         []NightlyRateResponse `json:"nights"`
+	RoomSubTotal   domain.Money          `json:"roomSubTotal"`
+	AddonSubTotal  domain.Money          `json:"addonSubTotal"`
+	DiscountAmount domain.Money          `json:"discountAmount"`
+	TaxesAmount    domain.Money          `json:"taxesAmount"`
+	Taxes          []BookingTaxResponse  `json:"taxes"`
+	TotalPrice     domain.Money          `json:"totalPrice"`
+	OldTotalPrice  domain.Money          `json:"oldTotalPrice"`
+	Difference     domain.Money          `json:"difference"`
+	PromoDropped   bool                  `json:"promoDropped"`
+	Applied        bool                  `json:"applied"`
+	Booking        *BookingResponse      `json:"booking,omitempty"`
+}
+
+func ToStayQuoteResponse(q *domain.StayQuote) *StayQuoteResponse {
+	return &StayQuoteResponse{
+		BookingID:      q.BookingID,
+		RoomTypeID:     q.RoomTypeID,
+		RoomID:         q.RoomID,
+		CheckInDate:    q.CheckInDate,
+		CheckOutDate:   q.CheckOutDate,
+		Nights:         ToNightlyRateResponses(q.Nights),
+		RoomSubTotal:   q.RoomSubTotal,
+		AddonSubTotal:  q.AddonSubTotal,
+		DiscountAmount: q.DiscountAmount,
+		TaxesAmount:    q.TaxesAmount,
+		Taxes:          ToBookingTaxResponses(q.Taxes),
+		TotalPrice:     q.TotalPrice,
+		OldTotalPrice:  q.OldTotalPrice,
+		Difference:     q.Difference,
+		PromoDropped:   q.PromoDropped,
+		Applied:        q.Applied,
+	}
+}
+
+type CancelBookingRequest struct {
+	Reason string `json:"reason"`
+}
+
+type CancelByCodeRequest struct {
+	Code   string `json:"code"`
+	Email  string `json:"email"`
+	Reason string `json:"reason"`
 }
 
 type GuestInfoResponse struct {
@@ -58,12 +141,14 @@ func ToBookingResponse(b *domain.BookingDetail) *BookingResponse {
 
 	return &BookingResponse{
 		BookingID:     b.BookingID,
+		ReservationID: b.ReservationID,
 		UserID:        b.UserID,
 		RatePlanID:    b.RatePlanID,
 		RoomID:        b.RoomID,
 		CheckInDate:   b.CheckInDate,
 		CheckOutDate:  b.CheckOutDate,
 		NumAdults:     b.NumAdults,
+		ChildAges:     b.ChildAges,
 		Status:        b.Status,
 		RoomSubTotal:  b.RoomSubTotal,
 		AddonSubTotal: b.AddonSubTotal,
@@ -76,12 +161,35 @@ func ToBookingResponse(b *domain.BookingDetail) *BookingResponse {
 		RoomNumber:    b.RoomNumber,
 		RoomTypeName:  b.RoomTypeName,
 		BookingAddon:  (ToBookingAddonResponses(b.BookingAddon)),
+		Nights:        ToNightlyRateResponses(b.Nights),
+		Taxes:         ToBookingTaxResponses(b.Taxes),
 		GuestDetails: &GuestInfoResponse{
 			FirstName: firstName,
 			LastName:  lastName,
 			Email:     b.Email,
 		},
+		CancellationReason: b.CancellationReason,
+		CancellationFee:    b.CancellationFee,
+		CancelledAt:        optionalTime(b.CancelledAt),
+
+		PromoCode:      b.PromoCode,
+		DiscountAmount: b.DiscountAmount,
+
+		AllotmentID: b.AllotmentID,
+
+		ConfirmationCode: b.ConfirmationCode,
+
+		AmountPaid: b.AmountPaid,
+		Balance:    b.Balance,
+		BalanceDue: b.BalanceDue(),
+	}
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
 	}
+	return &t
 }
 
 func ToDomainBookingAddons(addons []BookingAddonRequest) []*domain.BookingAddon {
@@ -94,23 +202,37 @@ func ToDomainBookingAddons(addons []BookingAddonRequest) []*domain.BookingAddon
 		res[i] = &domain.BookingAddon{
 			AddonID:  a.AddonID,
 			Quantity: a.Quantity,
+			SlotID:   a.SlotID,
 		}
 	}
 	return res
 }
 
+// BookingAddonRequest asks for an addon. ServiceDate (YYYY-MM-DD) is only
+// read for addons that are delivered on a given day of the stay; SlotID picks
+// one of the addon's time slots on that day.
 type BookingAddonRequest struct {
-	AddonID  int `json:"addonId"`
-	Quantity int `json:"quantity"`
+	AddonID     int    `json:"addonId"`
+	Quantity    int    `json:"quantity"`
+	ServiceDate string `json:"serviceDate"`
+	SlotID      int    `json:"slotId"`
 }
 
 type BookingAddonResponse struct {
-	BookingAddonID int     `json:"bookingAddonId"`
-	BookingID      int     `json:"bookingId"`
-	AddonID        int     `json:"addonId"`
-	AddonName      string  `json:"addonName"`
-	Quantity       int     `json:"quantity"`
-	PriceAtBooking float64 `json:"priceAtBooking"`
+	BookingAddonID int          `json:"bookingAddonId"`
+	BookingID      int          `json:"bookingId"`
+	AddonID        int          `json:"addonId"`
+	AddonName      string       `json:"addonName"`
+	ChargeUnit     string       `json:"chargeUnit"`
+	Quantity       int          `json:"quantity"`
+	Nights         int          `json:"nights"`
+	Guests         int          `json:"guests"`
+	ServiceDate    *time.Time   `json:"serviceDate,omitempty"`
+	SlotID         int          `json:"slotId,omitempty"`
+	SlotName       string       `json:"slotName,omitempty"`
+	PriceAtBooking domain.Money `json:"priceAtBooking"`
+	Total          domain.Money `json:"total"`
+	Breakdown      string       `json:"breakdown"`
 }
 
 func ToBookingAddonResponses(addons []*domain.BookingAddon) []BookingAddonResponse {
@@ -125,8 +247,42 @@ func ToBookingAddonResponses(addons []*domain.BookingAddon) []BookingAddonRespon
 			BookingID:      a.BookingID,
 			AddonID:        a.AddonID,
 			AddonName:      a.AddonName,
+			ChargeUnit:     a.ChargeUnit,
 			Quantity:       a.Quantity,
+			Nights:         a.Nights,
+			Guests:         a.Guests,
+			ServiceDate:    optionalTime(a.ServiceDate),
+			SlotID:         a.SlotID,
+			SlotName:       a.SlotName,
 			PriceAtBooking: a.PriceAtBooking,
+			Total:          a.Total(),
+			Breakdown:      a.Breakdown(),
+		}
+	}
+	return res
+}
+
+type BookingStatusHistoryResponse struct {
+	HistoryID   int       `json:"historyId"`
+	BookingID   int       `json:"bookingId"`
+	ActorUserID int       `json:"actorUserId"`
+	OldStatus   string    `json:"oldStatus"`
+	NewStatus   string    `json:"newStatus"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func ToBookingStatusHistoryResponses(history []*domain.BookingStatusHistory) []BookingStatusHistoryResponse {
+	res := make([]BookingStatusHistoryResponse, len(history))
+	for i, h := range history {
+		res[i] = BookingStatusHistoryResponse{
+			HistoryID:   h.HistoryID,
+			BookingID:   h.BookingID,
+			ActorUserID: h.ActorUserID,
+			OldStatus:   h.OldStatus,
+			NewStatus:   h.NewStatus,
+			Reason:      h.Reason,
+			CreatedAt:   h.CreatedAt,
 		}
 	}
 	return res
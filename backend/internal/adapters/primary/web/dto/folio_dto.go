@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+// FolioAdjustmentRequest posts a manual correction. A negative amount credits the guest.
+type FolioAdjustmentRequest struct {
+	Amount      domain.Money `json:"amount"`
+	Description string       `json:"description"`
+}
+
+type RefundRequest struct {
+	PaymentID int          `json:"paymentId"`
+	Amount    domain.Money `json:"amount"`
+	Reason    string       `json:"reason"`
+}
+
+type FolioEntryResponse struct {
+	EntryID     int          `json:"entryId"`
+	BookingID   int          `json:"bookingId"`
+	EntryType   string       `json:"entryType"`
+	Category    string       `json:"category"`
+	Description string       `json:"description"`
+	Amount      domain.Money `json:"amount"`
+	Currency    string       `json:"currency"`
+	PaymentID   int          `json:"paymentId,omitempty"`
+	ActorUserID int          `json:"actorUserId,omitempty"`
+	CreatedAt   time.Time    `json:"createdAt"`
+}
+
+type FolioResponse struct {
+	BookingID int                  `json:"bookingId"`
+	Entries   []FolioEntryResponse `json:"entries"`
+	Balance   domain.Money         `json:"balance"`
+}
+
+func ToFolioEntryResponse(e *domain.FolioEntry) FolioEntryResponse {
+	return FolioEntryResponse{
+		EntryID:     e.EntryID,
+		BookingID:   e.BookingID,
+		EntryType:   e.EntryType,
+		Category:    e.Category,
+		Description: e.Description,
+		Amount:      e.Amount,
+		Currency:    e.Amount.Currency,
+		PaymentID:   e.PaymentID,
+		ActorUserID: e.ActorUserID,
+		CreatedAt:   e.CreatedAt,
+	}
+}
+
+func ToFolioResponse(f *domain.Folio) FolioResponse {
+	entries := make([]FolioEntryResponse, len(f.Entries))
+	for i, e := range f.Entries {
+		entries[i] = ToFolioEntryResponse(e)
+	}
+	return FolioResponse{
+		BookingID: f.BookingID,
+		Entries:   entries,
+		Balance:   f.Balance,
+	}
+}
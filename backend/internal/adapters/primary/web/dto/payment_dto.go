@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type PayBookingRequest struct {
+	PaymentMethod string `json:"paymentMethod"`
+}
+
+type PartialPaymentRequest struct {
+	Amount        domain.Money `json:"amount"`
+	PaymentMethod string       `json:"paymentMethod"`
+}
+
+// RecordPaymentRequest is a payment taken at the front desk, e.g. cash or a
+// card terminal. Reference is the receipt or terminal slip number.
+type RecordPaymentRequest struct {
+	Amount    domain.Money `json:"amount"`
+	Method    string       `json:"method"`
+	Reference string       `json:"reference"`
+}
+
+type PaymentResponse struct {
+	PaymentID     int          `json:"paymentId"`
+	BookingID     int          `json:"bookingId,omitempty"`
+	ReservationID int          `json:"reservationId,omitempty"`
+	Provider      string       `json:"provider"`
+	ProviderRef   string       `json:"providerRef"`
+	Amount        domain.Money `json:"amount"`
+	Currency      string       `json:"currency"`
+	Status        string       `json:"status"`
+	FailureReason string       `json:"failureReason,omitempty"`
+	CreatedAt     time.Time    `json:"createdAt"`
+	UpdatedAt     time.Time    `json:"updatedAt"`
+}
+
+type PaymentIntentResponse struct {
+	Payment      PaymentResponse `json:"payment"`
+	ClientSecret string          `json:"clientSecret,omitempty"`
+}
+
+func ToPaymentResponse(p *domain.Payment) PaymentResponse {
+	return PaymentResponse{
+		PaymentID:     p.PaymentID,
+		BookingID:     p.BookingID,
+		ReservationID: p.ReservationID,
+		Provider:      p.Provider,
+		ProviderRef:   p.ProviderRef,
+		Amount:        p.Amount,
+		Currency:      p.Amount.Currency,
+		Status:        p.Status,
+		FailureReason: p.FailureReason,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+	}
+}
+
+func ToPaymentResponses(payments []*domain.Payment) []PaymentResponse {
+	res := make([]PaymentResponse, len(payments))
+	for i, p := range payments {
+		res[i] = ToPaymentResponse(p)
+	}
+	return res
+}
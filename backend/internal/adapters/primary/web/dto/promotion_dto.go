@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type PromotionRequest struct {
+	Code           string       `json:"code"`
+	Description    string       `json:"description"`
+	DiscountType   string       `json:"discountType"`
+	DiscountRate   float64      `json:"discountRate"`
+	DiscountAmount domain.Money `json:"discountAmount"`
+	MinSpend       domain.Money `json:"minSpend"`
+	BookingStart   *time.Time   `json:"bookingStart"`
+	BookingEnd     *time.Time   `json:"bookingEnd"`
+	StayStart      string       `json:"stayStart"`
+	StayEnd        string       `json:"stayEnd"`
+	RoomTypeIDs    []int        `json:"roomTypeIds"`
+	RatePlanIDs    []int        `json:"ratePlanIds"`
+	MaxRedemptions int          `json:"maxRedemptions"`
+	MaxPerUser     int          `json:"maxPerUser"`
+	IsActive       *bool        `json:"isActive"`
+}
+
+type PromotionResponse struct {
+	PromotionID    int          `json:"promotionId"`
+	Code           string       `json:"code"`
+	Description    string       `json:"description"`
+	DiscountType   string       `json:"discountType"`
+	DiscountRate   float64      `json:"discountRate"`
+	DiscountAmount domain.Money `json:"discountAmount"`
+	MinSpend       domain.Money `json:"minSpend"`
+	BookingStart   *time.Time   `json:"bookingStart,omitempty"`
+	BookingEnd     *time.Time   `json:"bookingEnd,omitempty"`
+	StayStart      string       `json:"stayStart,omitempty"`
+	StayEnd        string       `json:"stayEnd,omitempty"`
+	RoomTypeIDs    []int        `json:"roomTypeIds"`
+	RatePlanIDs    []int        `json:"ratePlanIds"`
+	MaxRedemptions int          `json:"maxRedemptions"`
+	MaxPerUser     int          `json:"maxPerUser"`
+	IsActive       bool         `json:"isActive"`
+	Redemptions    int          `json:"redemptions"`
+	CreatedAt      time.Time    `json:"createdAt"`
+	UpdatedAt      time.Time    `json:"updatedAt"`
+}
+
+func ToPromotionResponse(p *domain.Promotion) PromotionResponse {
+	return PromotionResponse{
+		PromotionID:    p.PromotionID,
+		Code:           p.Code,
+		Description:    p.Description,
+		DiscountType:   p.DiscountType,
+		DiscountRate:   p.DiscountRate,
+		DiscountAmount: p.DiscountAmount,
+		MinSpend:       p.MinSpend,
+		BookingStart:   optionalTime(p.BookingStart),
+		BookingEnd:     optionalTime(p.BookingEnd),
+		StayStart:      optionalDate(p.StayStart),
+		StayEnd:        optionalDate(p.StayEnd),
+		RoomTypeIDs:    p.RoomTypeIDs,
+		RatePlanIDs:    p.RatePlanIDs,
+		MaxRedemptions: p.MaxRedemptions,
+		MaxPerUser:     p.MaxPerUser,
+		IsActive:       p.IsActive,
+		Redemptions:    p.Redemptions,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+	}
+}
+
+func ToPromotionResponses(promos []*domain.Promotion) []PromotionResponse {
+	res := make([]PromotionResponse, len(promos))
+	for i, p := range promos {
+		res[i] = ToPromotionResponse(p)
+	}
+	return res
+}
+
+func optionalDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
@@ -1,40 +1,130 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
 
 type RatePlanRequest struct {
-	Name             string `json:"name"`
-	Description      string `json:"description"`
-	IsSpecialPackage bool   `json:"isSpecialPackage"`
-	AllowFreeCancel  bool   `json:"allowFreeCancel"`
-	AllowPayLater    bool   `json:"allowPayLater"`
+	Name              string  `json:"name"`
+	Description       string  `json:"description"`
+	IsSpecialPackage  bool    `json:"isSpecialPackage"`
+	AllowFreeCancel   bool    `json:"allowFreeCancel"`
+	AllowPayLater     bool    `json:"allowPayLater"`
+	DepositPercent    float64 `json:"depositPercent"`
+	RequiresGuarantee bool    `json:"requiresGuarantee"`
 }
 
 type RatePlanResponse struct {
-	RatePlanID       int       `json:"ratePlanId"`
-	Name             string    `json:"name"`
-	Description      string    `json:"description"`
-	IsSpecialPackage bool      `json:"isSpecialPackage"`
-	AllowFreeCancel  bool      `json:"allowFreeCancel"`
-	AllowPayLater    bool      `json:"allowPayLater"`
-	CreatedAt        time.Time `json:"createdAt"`
-	UpdatedAt        time.Time `json:"updatedAt"`
+	RatePlanID        int       `json:"ratePlanId"`
+	Name              string    `json:"name"`
+	Description       string    `json:"description"`
+	IsSpecialPackage  bool      `json:"isSpecialPackage"`
+	AllowFreeCancel   bool      `json:"allowFreeCancel"`
+	AllowPayLater     bool      `json:"allowPayLater"`
+	DepositPercent    float64   `json:"depositPercent"`
+	RequiresGuarantee bool      `json:"requiresGuarantee"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
 }
 
 type RatePlanFullResponse struct {
-	RatePlanID       int       `json:"ratePlanId"`
-	Name             string    `json:"name"`
-	Description      string    `json:"description"`
-	IsSpecialPackage bool      `json:"isSpecialPackage"`
-	AllowFreeCancel  bool      `json:"allowFreeCancel"`
-	AllowPayLater    bool      `json:"allowPayLater"`
-	Price            float64   `json:"price"`
-	CreatedAt        time.Time `json:"createdAt"`
-	UpdatedAt        time.Time `json:"updatedAt"`
+	RatePlanID        int          `json:"ratePlanId"`
+	Name              string       `json:"name"`
+	Description       string       `json:"description"`
+	IsSpecialPackage  bool         `json:"isSpecialPackage"`
+	AllowFreeCancel   bool         `json:"allowFreeCancel"`
+	AllowPayLater     bool         `json:"allowPayLater"`
+	DepositPercent    float64      `json:"depositPercent"`
+	RequiresGuarantee bool         `json:"requiresGuarantee"`
+	Price             domain.Money `json:"price"`
+	CreatedAt         time.Time    `json:"createdAt"`
+	UpdatedAt         time.Time    `json:"updatedAt"`
 }
 
 type RoomTypeRatePrice struct {
-	RoomTypeID int     `json:"roomTypeId"`
-	RatePlanID int     `json:"ratePlanId"`
-	Price      float64 `json:"price"`
+	RoomTypeID int          `json:"roomTypeId"`
+	RatePlanID int          `json:"ratePlanId"`
+	Price      domain.Money `json:"price"`
+}
+
+type RateCalendarRequest struct {
+	StartDate string       `json:"startDate"`
+	EndDate   string       `json:"endDate"`
+	Weekdays  []int        `json:"weekdays"`
+	Price     domain.Money `json:"price"`
+}
+
+type NightlyRateResponse struct {
+	Date             string       `json:"date"`
+	Price            domain.Money `json:"price"`
+	ExtraGuestCharge domain.Money `json:"extraGuestCharge"` // included in price
+}
+
+func ToNightlyRateResponses(rates []*domain.NightlyRate) []NightlyRateResponse {
+	res := make([]NightlyRateResponse, len(rates))
+	for i, r := range rates {
+		res[i] = NightlyRateResponse{
+			Date:             r.StayDate.Format("2006-01-02"),
+			Price:            r.Price,
+			ExtraGuestCharge: r.ExtraGuestCharge,
+		}
+	}
+	return res
+}
+
+type ChildAgeBandDTO struct {
+	MinAge int          `json:"minAge"`
+	MaxAge int          `json:"maxAge"`
+	Fee    domain.Money `json:"fee"`
+}
+
+type OccupancyPricingRequest struct {
+	BaseOccupancy int               `json:"baseOccupancy"`
+	ExtraAdultFee domain.Money      `json:"extraAdultFee"`
+	ChildFees     []ChildAgeBandDTO `json:"childFees"`
+}
+
+type OccupancyPricingResponse struct {
+	RoomTypeID    int               `json:"roomTypeId"`
+	RatePlanID    int               `json:"ratePlanId"`
+	BaseOccupancy int               `json:"baseOccupancy"`
+	ExtraAdultFee domain.Money      `json:"extraAdultFee"`
+	ChildFees     []ChildAgeBandDTO `json:"childFees"`
+}
+
+func ToDomainOccupancyPricing(roomTypeID, ratePlanID int, req *OccupancyPricingRequest) *domain.OccupancyPricing {
+	p := &domain.OccupancyPricing{
+		RoomTypeID:    roomTypeID,
+		RatePlanID:    ratePlanID,
+		BaseOccupancy: req.BaseOccupancy,
+		ExtraAdultFee: req.ExtraAdultFee,
+	}
+	for _, band := range req.ChildFees {
+		p.ChildFees = append(p.ChildFees, &domain.ChildAgeBand{
+			MinAge: band.MinAge,
+			MaxAge: band.MaxAge,
+			Fee:    band.Fee,
+		})
+	}
+	return p
+}
+
+func ToOccupancyPricingResponse(p *domain.OccupancyPricing) OccupancyPricingResponse {
+	bands := make([]ChildAgeBandDTO, len(p.ChildFees))
+	for i, band := range p.ChildFees {
+		bands[i] = ChildAgeBandDTO{
+			MinAge: band.MinAge,
+			MaxAge: band.MaxAge,
+			Fee:    band.Fee,
+		}
+	}
+	return OccupancyPricingResponse{
+		RoomTypeID:    p.RoomTypeID,
+		RatePlanID:    p.RatePlanID,
+		BaseOccupancy: p.BaseOccupancy,
+		ExtraAdultFee: p.ExtraAdultFee,
+		ChildFees:     bands,
+	}
 }
@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+// ReservationRequest books several rooms for the same dates in one checkout.
+type ReservationRequest struct {
+	CheckInDate  string                   `json:"checkInDate"`
+	CheckOutDate string                   `json:"checkOutDate"`
+	Email        string                   `json:"email"`
+	PromoCode    string                   `json:"promoCode"`
+	GroupCode    string                   `json:"groupCode"`
+	Rooms        []ReservationRoomRequest `json:"rooms"`
+}
+
+type ReservationRoomRequest struct {
+	RoomTypeID   int                   `json:"roomTypeId"`
+	RatePlanID   int                   `json:"ratePlanId"`
+	NumAdults    int                   `json:"numAdults"`
+	ChildAges    []int                 `json:"childAges"`
+	BookingAddon []BookingAddonRequest `json:"bookingAddon"`
+}
+
+type ReservationResponse struct {
+	ReservationID int                `json:"reservationId"`
+	UserID        int                `json:"userId"`
+	Email         string             `json:"email"`
+	Bookings      []*BookingResponse `json:"bookings"`
+	TotalPrice    domain.Money       `json:"totalPrice"`
+	AmountPaid    domain.Money       `json:"amountPaid"`
+	BalanceDue    domain.Money       `json:"balanceDue"`
+	CreatedAt     time.Time          `json:"createdAt"`
+}
+
+func ToReservationResponse(r *domain.ReservationDetail) (*ReservationResponse, error) {
+	bookings := make([]*BookingResponse, len(r.Bookings))
+	for i, b := range r.Bookings {
+		bookings[i] = ToBookingResponse(b)
+	}
+
+	total, paid, due, err := r.Totals()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReservationResponse{
+		ReservationID: r.ReservationID,
+		UserID:        r.UserID,
+		Email:         r.Email,
+		Bookings:      bookings,
+		TotalPrice:    total,
+		AmountPaid:    paid,
+		BalanceDue:    due,
+		CreatedAt:     r.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package dto
+
+import "github.com/ingwrok/hotelBooking/internal/core/domain"
+
+type StayRestrictionRequest struct {
+	RoomTypeID        int    `json:"roomTypeId"`
+	RatePlanID        int    `json:"ratePlanId"`
+	StartDate         string `json:"startDate"`
+	EndDate           string `json:"endDate"`
+	MinLOS            int    `json:"minLos"`
+	MaxLOS            int    `json:"maxLos"`
+	ClosedToArrival   bool   `json:"closedToArrival"`
+	ClosedToDeparture bool   `json:"closedToDeparture"`
+	StopSell          bool   `json:"stopSell"`
+}
+
+type StayRestrictionResponse struct {
+	RestrictionID     int    `json:"restrictionId"`
+	RoomTypeID        int    `json:"roomTypeId"`
+	RatePlanID        int    `json:"ratePlanId"`
+	StartDate         string `json:"startDate"`
+	EndDate           string `json:"endDate"`
+	MinLOS            int    `json:"minLos"`
+	MaxLOS            int    `json:"maxLos"`
+	ClosedToArrival   bool   `json:"closedToArrival"`
+	ClosedToDeparture bool   `json:"closedToDeparture"`
+	StopSell          bool   `json:"stopSell"`
+}
+
+func ToStayRestrictionResponse(r *domain.StayRestriction) StayRestrictionResponse {
+	return StayRestrictionResponse{
+		RestrictionID:     r.RestrictionID,
+		RoomTypeID:        r.RoomTypeID,
+		RatePlanID:        r.RatePlanID,
+		StartDate:         r.StartDate.Format("2006-01-02"),
+		EndDate:           r.EndDate.Format("2006-01-02"),
+		MinLOS:            r.MinLOS,
+		MaxLOS:            r.MaxLOS,
+		ClosedToArrival:   r.ClosedToArrival,
+		ClosedToDeparture: r.ClosedToDeparture,
+		StopSell:          r.StopSell,
+	}
+}
+
+func ToStayRestrictionResponses(restrictions []*domain.StayRestriction) []StayRestrictionResponse {
+	res := make([]StayRestrictionResponse, len(restrictions))
+	for i, r := range restrictions {
+		res[i] = ToStayRestrictionResponse(r)
+	}
+	return res
+}
@@ -65,12 +65,14 @@ type RoomBlockResponse struct {
 }
 
 type AvailabilityRequest struct {
-	CheckIn  string `json:"checkIn"`
-	CheckOut string `json:"checkOut"`
+	CheckIn    string `json:"checkIn"`
+	CheckOut   string `json:"checkOut"`
+	RatePlanID int    `json:"ratePlanId"`
 }
 
 type FindRoomRequest struct {
 	RoomTypeID int    `json:"roomTypeId"`
 	CheckIn    string `json:"checkIn"`
 	CheckOut   string `json:"checkOut"`
+	RatePlanID int    `json:"ratePlanId"`
 }
@@ -0,0 +1,83 @@
+package dto
+
+import "github.com/ingwrok/hotelBooking/internal/core/domain"
+
+type TaxRuleRequest struct {
+	Name       string       `json:"name"`
+	CalcType   string       `json:"calcType"`
+	Rate       float64      `json:"rate"`
+	Amount     domain.Money `json:"amount"`
+	Basis      string       `json:"basis"`
+	AppliesTo  string       `json:"appliesTo"`
+	IsCompound bool         `json:"isCompound"`
+	Priority   int          `json:"priority"`
+	IsActive   *bool        `json:"isActive"`
+}
+
+type TaxRuleResponse struct {
+	TaxRuleID  int          `json:"taxRuleId"`
+	Name       string       `json:"name"`
+	CalcType   string       `json:"calcType"`
+	Rate       float64      `json:"rate"`
+	Amount     domain.Money `json:"amount"`
+	Basis      string       `json:"basis"`
+	AppliesTo  string       `json:"appliesTo"`
+	IsCompound bool         `json:"isCompound"`
+	Priority   int          `json:"priority"`
+	IsActive   bool         `json:"isActive"`
+}
+
+type BookingTaxResponse struct {
+	TaxRuleID int          `json:"taxRuleId,omitempty"`
+	Name      string       `json:"name"`
+	Amount    domain.Money `json:"amount"`
+}
+
+func ToDomainTaxRule(req *TaxRuleRequest) *domain.TaxRule {
+	return &domain.TaxRule{
+		Name:       req.Name,
+		CalcType:   req.CalcType,
+		Rate:       req.Rate,
+		Amount:     req.Amount,
+		Basis:      req.Basis,
+		AppliesTo:  req.AppliesTo,
+		IsCompound: req.IsCompound,
+		Priority:   req.Priority,
+		IsActive:   req.IsActive == nil || *req.IsActive,
+	}
+}
+
+func ToTaxRuleResponse(r *domain.TaxRule) TaxRuleResponse {
+	return TaxRuleResponse{
+		TaxRuleID:  r.TaxRuleID,
+		Name:       r.Name,
+		CalcType:   r.CalcType,
+		Rate:       r.Rate,
+		Amount:     r.Amount,
+		Basis:      r.Basis,
+		AppliesTo:  r.AppliesTo,
+		IsCompound: r.IsCompound,
+		Priority:   r.Priority,
+		IsActive:   r.IsActive,
+	}
+}
+
+func ToTaxRuleResponses(rules []*domain.TaxRule) []TaxRuleResponse {
+	res := make([]TaxRuleResponse, len(rules))
+	for i, r := range rules {
+		res[i] = ToTaxRuleResponse(r)
+	}
+	return res
+}
+
+func ToBookingTaxResponses(taxes []*domain.BookingTax) []BookingTaxResponse {
+	res := make([]BookingTaxResponse, len(taxes))
+	for i, t := range taxes {
+		res[i] = BookingTaxResponse{
+			TaxRuleID: t.TaxRuleID,
+			Name:      t.Name,
+			Amount:    t.Amount,
+		}
+	}
+	return res
+}
@@ -1,5 +1,11 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
 type RegisterRequest struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
@@ -16,9 +22,166 @@ type UserResponse struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	IsAdmin  bool   `json:"is_admin"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+
+	EmailVerified    bool `json:"email_verified"`
+	TwoFactorEnabled bool `json:"two_factor_enabled"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LoginResponse is returned by login and refresh. Token is the short-lived
+// access token; RefreshToken is single use and replaced on every refresh.
 type LoginResponse struct {
-	Token string       `json:"token"`
-	User  UserResponse `json:"user"`
+	Token            string       `json:"token"`
+	ExpiresAt        time.Time    `json:"expires_at"`
+	RefreshToken     string       `json:"refresh_token"`
+	RefreshExpiresAt time.Time    `json:"refresh_expires_at"`
+	User             UserResponse `json:"user"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type UnlockAccountRequest struct {
+	Token string `json:"token"`
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type RoleResponse struct {
+	RoleID      int      `json:"role_id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+
+	RequireTwoFactor bool `json:"require_two_factor"`
+}
+
+type RoleTwoFactorRequest struct {
+	Required bool `json:"required"`
+}
+
+// ToUserResponse keeps is_admin for clients that predate roles.
+func ToUserResponse(u *domain.User) UserResponse {
+	return UserResponse{
+		UserID:      u.UserID,
+		Username:    u.Username,
+		Email:       u.Email,
+		IsAdmin:     u.HasRole(domain.RoleAdmin),
+		Roles:       u.Roles,
+		Permissions: u.Permissions,
+
+		EmailVerified:    u.EmailVerified(),
+		TwoFactorEnabled: u.TwoFactorEnabled,
+	}
+}
+
+func ToLoginResponse(pair *domain.TokenPair, u *domain.User) LoginResponse {
+	return LoginResponse{
+		Token:            pair.AccessToken,
+		ExpiresAt:        pair.AccessExpiresAt,
+		RefreshToken:     pair.RefreshToken,
+		RefreshExpiresAt: pair.RefreshExpiresAt,
+		User:             ToUserResponse(u),
+	}
+}
+
+func ToRoleResponses(roles []*domain.Role) []RoleResponse {
+	res := make([]RoleResponse, len(roles))
+	for i, r := range roles {
+		res[i] = RoleResponse{
+			RoleID:      r.RoleID,
+			Name:        r.Name,
+			Description: r.Description,
+			Permissions: r.Permissions,
+
+			RequireTwoFactor: r.RequireTwoFactor,
+		}
+	}
+	return res
+}
+
+// LoginChallengeResponse is returned by login instead of LoginResponse when
+// the user must also send a two-factor code to /api/auth/login/2fa.
+type LoginChallengeResponse struct {
+	TwoFactorRequired  bool      `json:"two_factor_required"`
+	ChallengeToken     string    `json:"challenge_token"`
+	ChallengeExpiresAt time.Time `json:"challenge_expires_at"`
+}
+
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+// TwoFactorCodeRequest takes an authenticator code or a recovery code.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled           bool `json:"enabled"`
+	Required          bool `json:"required"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+func ToLoginChallengeResponse(res *domain.LoginResult) LoginChallengeResponse {
+	return LoginChallengeResponse{
+		TwoFactorRequired:  true,
+		ChallengeToken:     res.ChallengeToken,
+		ChallengeExpiresAt: res.ChallengeExpiresAt,
+	}
+}
+
+type AuditEntryResponse struct {
+	AuditID   int64     `json:"audit_id"`
+	UserID    int       `json:"user_id,omitempty"`
+	Event     string    `json:"event"`
+	Username  string    `json:"username"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	Details   string    `json:"details"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func ToAuditEntryResponses(entries []*domain.AuditEntry) []AuditEntryResponse {
+	res := make([]AuditEntryResponse, len(entries))
+	for i, e := range entries {
+		res[i] = AuditEntryResponse{
+			AuditID:   e.AuditID,
+			UserID:    e.UserID,
+			Event:     e.Event,
+			Username:  e.Username,
+			IPAddress: e.IPAddress,
+			UserAgent: e.UserAgent,
+			Details:   e.Details,
+			CreatedAt: e.CreatedAt,
+		}
+	}
+	return res
 }
@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type WaitlistRequest struct {
+	RoomTypeID   int    `json:"roomTypeId"`
+	RatePlanID   int    `json:"ratePlanId"`
+	CheckInDate  string `json:"checkInDate"`
+	CheckOutDate string `json:"checkOutDate"`
+	NumAdults    int    `json:"numAdults"`
+	ChildAges    []int  `json:"childAges"`
+	Email        string `json:"email"`
+}
+
+type WaitlistEntryResponse struct {
+	EntryID        int        `json:"entryId"`
+	UserID         int        `json:"userId"`
+	RoomTypeID     int        `json:"roomTypeId"`
+	RatePlanID     int        `json:"ratePlanId"`
+	CheckInDate    time.Time  `json:"checkInDate"`
+	CheckOutDate   time.Time  `json:"checkOutDate"`
+	NumAdults      int        `json:"numAdults"`
+	ChildAges      []int      `json:"childAges"`
+	Email          string     `json:"email"`
+	Status         string     `json:"status"`
+	BookingID      int        `json:"bookingId,omitempty"`
+	OfferedAt      *time.Time `json:"offeredAt,omitempty"`
+	OfferExpiresAt *time.Time `json:"offerExpiresAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+func ToWaitlistEntryResponse(e *domain.WaitlistEntry) WaitlistEntryResponse {
+	return WaitlistEntryResponse{
+		EntryID:        e.EntryID,
+		UserID:         e.UserID,
+		RoomTypeID:     e.RoomTypeID,
+		RatePlanID:     e.RatePlanID,
+		CheckInDate:    e.CheckInDate,
+		CheckOutDate:   e.CheckOutDate,
+		NumAdults:      e.NumAdults,
+		ChildAges:      e.ChildAges,
+		Email:          e.Email,
+		Status:         e.Status,
+		BookingID:      e.BookingID,
+		OfferedAt:      optionalTime(e.OfferedAt),
+		OfferExpiresAt: optionalTime(e.OfferExpiresAt),
+		CreatedAt:      e.CreatedAt,
+		UpdatedAt:      e.UpdatedAt,
+	}
+}
+
+func ToWaitlistEntryResponses(entries []*domain.WaitlistEntry) []WaitlistEntryResponse {
+	res := make([]WaitlistEntryResponse, len(entries))
+	for i, e := range entries {
+		res[i] = ToWaitlistEntryResponse(e)
+	}
+	return res
+}
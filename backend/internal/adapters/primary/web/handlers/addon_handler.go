@@ -5,6 +5,7 @@ import (
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/dto"
 	"github.com/ingwrok/hotelBooking/internal/core/domain"
 	"github.com/ingwrok/hotelBooking/internal/core/services"
+	"github.com/ingwrok/hotelBooking/internal/core/utils"
 )
 
 type AddonHandler struct {
@@ -132,11 +133,15 @@ func (h *AddonHandler) CreateAddon(c *fiber.Ctx) error {
 	}
 
 	addon, err := h.svc.AddAddon(ctx, &domain.Addon{
-		Name:        req.Name,
-		Description: req.Description,
-		Price:       req.Price,
-		CategoryID:  req.CategoryID,
-		UnitName:    req.UnitName,
+		Name:                req.Name,
+		Description:         req.Description,
+		Price:               req.Price,
+		CategoryID:          req.CategoryID,
+		UnitName:            req.UnitName,
+		IsExtraBed:          req.IsExtraBed,
+		ChargeUnit:          req.ChargeUnit,
+		RequiresServiceDate: req.RequiresServiceDate,
+		DailyCapacity:       req.DailyCapacity,
 	})
 
 	if err != nil {
@@ -144,13 +149,17 @@ func (h *AddonHandler) CreateAddon(c *fiber.Ctx) error {
 	}
 
 	return c.Status(201).JSON(dto.AddonResponse{
-		AddonID:     addon.AddonID,
-		Name:        addon.Name,
-		Description: addon.Description,
-		Price:       addon.Price,
-		CategoryID:  addon.CategoryID,
-		UnitName:    addon.UnitName,
-		PictureURL:  addon.PictureURL,
+		AddonID:             addon.AddonID,
+		Name:                addon.Name,
+		Description:         addon.Description,
+		Price:               addon.Price,
+		CategoryID:          addon.CategoryID,
+		UnitName:            addon.UnitName,
+		PictureURL:          addon.PictureURL,
+		IsExtraBed:          addon.IsExtraBed,
+		ChargeUnit:          addon.ChargeUnit,
+		RequiresServiceDate: addon.RequiresServiceDate,
+		DailyCapacity:       addon.DailyCapacity,
 	})
 }
 
@@ -169,13 +178,18 @@ func (h *AddonHandler) GetAddon(c *fiber.Ctx) error {
 	}
 
 	return c.Status(200).JSON(dto.AddonResponse{
-		AddonID:     addon.AddonID,
-		Name:        addon.Name,
-		Description: addon.Description,
-		Price:       addon.Price,
-		CategoryID:  addon.CategoryID,
-		UnitName:    addon.UnitName,
-		PictureURL:  addon.PictureURL,
+		AddonID:             addon.AddonID,
+		Name:                addon.Name,
+		Description:         addon.Description,
+		Price:               addon.Price,
+		CategoryID:          addon.CategoryID,
+		UnitName:            addon.UnitName,
+		PictureURL:          addon.PictureURL,
+		IsExtraBed:          addon.IsExtraBed,
+		ChargeUnit:          addon.ChargeUnit,
+		RequiresServiceDate: addon.RequiresServiceDate,
+		DailyCapacity:       addon.DailyCapacity,
+		Slots:               dto.ToAddonSlotResponses(addon.Slots),
 	})
 }
 
@@ -191,13 +205,17 @@ func (h *AddonHandler) ListAddons(c *fiber.Ctx) error {
 	resAddons := make([]dto.AddonResponse, len(addons))
 	for i, addon := range addons {
 		resAddons[i] = dto.AddonResponse{
-			AddonID:     addon.AddonID,
-			Name:        addon.Name,
-			Description: addon.Description,
-			Price:       addon.Price,
-			CategoryID:  addon.CategoryID,
-			UnitName:    addon.UnitName,
-			PictureURL:  addon.PictureURL,
+			AddonID:             addon.AddonID,
+			Name:                addon.Name,
+			Description:         addon.Description,
+			Price:               addon.Price,
+			CategoryID:          addon.CategoryID,
+			UnitName:            addon.UnitName,
+			PictureURL:          addon.PictureURL,
+			IsExtraBed:          addon.IsExtraBed,
+			ChargeUnit:          addon.ChargeUnit,
+			RequiresServiceDate: addon.RequiresServiceDate,
+			DailyCapacity:       addon.DailyCapacity,
 		}
 	}
 
@@ -221,13 +239,17 @@ func (h *AddonHandler) ListAddonsByCategory(c *fiber.Ctx) error {
 	resAddons := make([]dto.AddonResponse, len(addons))
 	for i, addon := range addons {
 		resAddons[i] = dto.AddonResponse{
-			AddonID:     addon.AddonID,
-			Name:        addon.Name,
-			Description: addon.Description,
-			Price:       addon.Price,
-			CategoryID:  addon.CategoryID,
-			UnitName:    addon.UnitName,
-			PictureURL:  addon.PictureURL,
+			AddonID:             addon.AddonID,
+			Name:                addon.Name,
+			Description:         addon.Description,
+			Price:               addon.Price,
+			CategoryID:          addon.CategoryID,
+			UnitName:            addon.UnitName,
+			PictureURL:          addon.PictureURL,
+			IsExtraBed:          addon.IsExtraBed,
+			ChargeUnit:          addon.ChargeUnit,
+			RequiresServiceDate: addon.RequiresServiceDate,
+			DailyCapacity:       addon.DailyCapacity,
 		}
 	}
 
@@ -249,13 +271,17 @@ func (h *AddonHandler) UpdateAddon(c *fiber.Ctx) error {
 	}
 
 	err = h.svc.ChangeAddon(ctx, &domain.Addon{
-		AddonID:     id,
-		Name:        req.Name,
-		Description: req.Description,
-		Price:       req.Price,
-		CategoryID:  req.CategoryID,
-		UnitName:    req.UnitName,
-		PictureURL:  req.PictureURL,
+		AddonID:             id,
+		Name:                req.Name,
+		Description:         req.Description,
+		Price:               req.Price,
+		CategoryID:          req.CategoryID,
+		UnitName:            req.UnitName,
+		IsExtraBed:          req.IsExtraBed,
+		ChargeUnit:          req.ChargeUnit,
+		RequiresServiceDate: req.RequiresServiceDate,
+		DailyCapacity:       req.DailyCapacity,
+		PictureURL:          req.PictureURL,
 	})
 	if err != nil {
 		return handleError(c, err)
@@ -305,3 +331,109 @@ func (h *AddonHandler) UploadImage(c *fiber.Ctx) error {
 		"url": url,
 	})
 }
+
+// --- Addon Time Slots ---
+func (h *AddonHandler) CreateAddonSlot(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	addonID, err := c.ParamsInt("addon_id")
+	if err != nil || addonID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid addon ID"})
+	}
+
+	var req dto.AddonSlotRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	slot, err := h.svc.AddAddonSlot(ctx, &domain.AddonSlot{
+		AddonID:  addonID,
+		Name:     req.Name,
+		Capacity: req.Capacity,
+	})
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(201).JSON(dto.ToAddonSlotResponse(slot))
+}
+
+func (h *AddonHandler) UpdateAddonSlot(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	addonID, err := c.ParamsInt("addon_id")
+	if err != nil || addonID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid addon ID"})
+	}
+	slotID, err := c.ParamsInt("slot_id")
+	if err != nil || slotID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid slot ID"})
+	}
+
+	var req dto.AddonSlotRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	err = h.svc.ChangeAddonSlot(ctx, &domain.AddonSlot{
+		SlotID:   slotID,
+		AddonID:  addonID,
+		Name:     req.Name,
+		Capacity: req.Capacity,
+	})
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"message": "addon slot updated successfully"})
+}
+
+func (h *AddonHandler) DeleteAddonSlot(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	addonID, err := c.ParamsInt("addon_id")
+	if err != nil || addonID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid addon ID"})
+	}
+	slotID, err := c.ParamsInt("slot_id")
+	if err != nil || slotID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid slot ID"})
+	}
+
+	if err := h.svc.RemoveAddonSlot(ctx, addonID, slotID); err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"message": "addon slot deleted successfully"})
+}
+
+// GetAvailability returns the capacity left per day between the startDate and
+// endDate query parameters, inclusive.
+func (h *AddonHandler) GetAvailability(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("addon_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid addon ID"})
+	}
+
+	startDate, err := utils.ParseDate(c.Query("startDate"), "start date")
+	if err != nil {
+		return handleError(c, err)
+	}
+	endDate, err := utils.ParseDate(c.Query("endDate"), "end date")
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	days, err := h.svc.GetAddonAvailability(ctx, id, startDate, endDate)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToAddonAvailabilityResponses(days))
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/dto"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/services"
+	"github.com/ingwrok/hotelBooking/internal/core/utils"
+)
+
+type AllotmentHandler struct {
+	svc *services.AllotmentService
+}
+
+func NewAllotmentHandler(s *services.AllotmentService) *AllotmentHandler {
+	return &AllotmentHandler{svc: s}
+}
+
+func (h *AllotmentHandler) CreateAllotment(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	var req dto.AllotmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	allotment, err := allotmentFromRequest(&req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	allotment, err = h.svc.AddAllotment(ctx, allotment)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(201).JSON(dto.ToAllotmentResponse(allotment))
+}
+
+func (h *AllotmentHandler) UpdateAllotment(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("allotment_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid allotment ID"})
+	}
+
+	var req dto.AllotmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	allotment, err := allotmentFromRequest(&req)
+	if err != nil {
+		return handleError(c, err)
+	}
+	allotment.AllotmentID = id
+
+	err = h.svc.ChangeAllotment(ctx, allotment)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"message": "allotment updated successfully"})
+}
+
+func (h *AllotmentHandler) RemoveAllotment(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("allotment_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid allotment ID"})
+	}
+
+	err = h.svc.RemoveAllotment(ctx, id)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"message": "allotment deleted successfully"})
+}
+
+func (h *AllotmentHandler) GetAllotment(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("allotment_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid allotment ID"})
+	}
+
+	allotment, err := h.svc.GetAllotment(ctx, id)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToAllotmentResponse(allotment))
+}
+
+func (h *AllotmentHandler) ListAllotments(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	allotments, err := h.svc.ListAllotments(ctx)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToAllotmentResponses(allotments))
+}
+
+func (h *AllotmentHandler) GetPickupReport(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("allotment_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid allotment ID"})
+	}
+
+	report, err := h.svc.GetPickupReport(ctx, id)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToPickupReportResponse(report))
+}
+
+func allotmentFromRequest(req *dto.AllotmentRequest) (*domain.GroupAllotment, error) {
+	allotment := &domain.GroupAllotment{
+		GroupCode:     req.GroupCode,
+		Name:          req.Name,
+		RoomTypeID:    req.RoomTypeID,
+		RoomsAllotted: req.RoomsAllotted,
+		IsActive:      req.IsActive == nil || *req.IsActive,
+	}
+
+	var err error
+	if allotment.StartDate, err = utils.ParseDate(req.StartDate, "start date"); err != nil {
+		return nil, err
+	}
+	if allotment.EndDate, err = utils.ParseDate(req.EndDate, "end date"); err != nil {
+		return nil, err
+	}
+	if allotment.CutoffDate, err = utils.ParseDate(req.CutoffDate, "cutoff date"); err != nil {
+		return nil, err
+	}
+
+	return allotment, nil
+}
@@ -33,7 +33,10 @@ func (h *BookingHandler) CreateBooking(c *fiber.Ctx) error {
 		return c.Status(401).JSON(fiber.Map{"message": "unauthorized"})
 	}
 
-	domainAddons := dto.ToDomainBookingAddons(req.BookingAddon)
+	domainAddons, err := bookingAddonsFromRequest(req.BookingAddon)
+	if err != nil {
+		return handleError(c, err)
+	}
 
 	checkin, err := utils.ParseDate(req.CheckInDate, "check in date")
 	if err != nil {
@@ -51,8 +54,11 @@ func (h *BookingHandler) CreateBooking(c *fiber.Ctx) error {
 		CheckInDate:  checkin,
 		CheckOutDate: checkout,
 		NumAdults:    req.NumAdults,
+		ChildAges:    req.ChildAges,
 		Email:        req.Email,
 		BookingAddon: domainAddons,
+		PromoCode:    req.PromoCode,
+		GroupCode:    req.GroupCode,
 	}, req.RoomTypeID)
 	if err != nil {
 		return handleError(c, err)
@@ -78,6 +84,7 @@ func (h *BookingHandler) UpdateStatus(c *fiber.Ctx) error {
 
 	type update struct {
 		Status string `json:"status"`
+		Reason string `json:"reason"`
 	}
 
 	var req update
@@ -85,7 +92,12 @@ func (h *BookingHandler) UpdateStatus(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
 	}
 
-	err = h.svc.ChangeStatus(ctx, id, req.Status)
+	authUser := middleware.GetAuthUser(c)
+	if authUser == nil {
+		return c.Status(401).JSON(fiber.Map{"message": "unauthorized"})
+	}
+
+	err = h.svc.ChangeStatus(ctx, id, authUser.ID, req.Status, req.Reason)
 	if err != nil {
 		return handleError(c, err)
 	}
@@ -93,6 +105,52 @@ func (h *BookingHandler) UpdateStatus(c *fiber.Ctx) error {
 	return c.Status(200).JSON(fiber.Map{"message": "status updated successfully"})
 }
 
+func (h *BookingHandler) CancelBooking(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("booking_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid booking ID"})
+	}
+
+	var req dto.CancelBookingRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+		}
+	}
+
+	authUser := middleware.GetAuthUser(c)
+	if authUser == nil {
+		return c.Status(401).JSON(fiber.Map{"message": "unauthorized"})
+	}
+
+	booking, err := h.svc.CancelBooking(ctx, id, authUser.ID, req.Reason)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToBookingResponse(booking))
+}
+
+func (h *BookingHandler) GetStatusHistory(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("booking_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid booking ID"})
+	}
+
+	history, err := h.svc.GetStatusHistory(ctx, id)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToBookingStatusHistoryResponses(history))
+}
+
 func (h *BookingHandler) GetAddons(c *fiber.Ctx) error {
 	ctx, cancel := buildCtx(c)
 	defer cancel()
@@ -141,9 +199,17 @@ func (h *BookingHandler) UpdateAddons(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
 	}
 
-	domainAddons := dto.ToDomainBookingAddons(req)
+	authUser := middleware.GetAuthUser(c)
+	if authUser == nil {
+		return c.Status(401).JSON(fiber.Map{"message": "unauthorized"})
+	}
+
+	domainAddons, err := bookingAddonsFromRequest(req)
+	if err != nil {
+		return handleError(c, err)
+	}
 
-	err = h.svc.ModifyBookingAddons(ctx, id, domainAddons)
+	err = h.svc.ModifyBookingAddons(ctx, id, authUser.ID, domainAddons)
 	if err != nil {
 		return handleError(c, err)
 	}
@@ -151,6 +217,61 @@ func (h *BookingHandler) UpdateAddons(c *fiber.Ctx) error {
 	return c.Status(200).JSON(fiber.Map{"message": "booking addons updated successfully"})
 }
 
+// ModifyStay changes a booking's dates or room type. With ?preview=true it
+// only returns the price of the change.
+func (h *BookingHandler) ModifyStay(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	authUser := middleware.GetAuthUser(c)
+	if authUser == nil {
+		return c.Status(401).JSON(fiber.Map{"message": "unauthorized"})
+	}
+
+	id, err := c.ParamsInt("booking_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid booking ID"})
+	}
+
+	var req dto.ModifyStayRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	change := &domain.StayChange{
+		RoomTypeID:    req.RoomTypeID,
+		ExpectedTotal: req.ExpectedTotalPrice,
+	}
+	if req.CheckInDate != "" {
+		change.CheckInDate, err = utils.ParseDate(req.CheckInDate, "check in date")
+		if err != nil {
+			return handleError(c, err)
+		}
+	}
+	if req.CheckOutDate != "" {
+		change.CheckOutDate, err = utils.ParseDate(req.CheckOutDate, "check out date")
+		if err != nil {
+			return handleError(c, err)
+		}
+	}
+
+	quote, err := h.svc.ModifyStay(ctx, id, authUser.ID, change, c.QueryBool("preview"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	res := dto.ToStayQuoteResponse(quote)
+	if quote.Applied {
+		fullBooking, err := h.svc.GetFullDetails(ctx, id)
+		if err != nil {
+			return handleError(c, err)
+		}
+		res.Booking = dto.ToBookingResponse(fullBooking)
+	}
+
+	return c.Status(200).JSON(res)
+}
+
 func (h *BookingHandler) GetBookings(c *fiber.Ctx) error {
 	ctx, cancel := buildCtx(c)
 	defer cancel()
@@ -173,38 +294,118 @@ func (h *BookingHandler) GetBookings(c *fiber.Ctx) error {
 	return c.Status(200).JSON(resBookings)
 }
 
-func (h *BookingHandler) SimulatePayment(c *fiber.Ctx) error {
+func (h *BookingHandler) GetAllBookings(c *fiber.Ctx) error {
 	ctx, cancel := buildCtx(c)
 	defer cancel()
 
-	bookingID, err := c.ParamsInt("booking_id")
-	if err != nil || bookingID <= 0 {
-		return c.Status(400).JSON(fiber.Map{"message": "invalid booking ID"})
+	bookings, err := h.svc.GetAllBookings(ctx)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	// Map to DTO
+	var res []*dto.BookingResponse
+	for _, b := range bookings {
+		res = append(res, dto.ToBookingResponse(b))
+	}
+
+	return c.Status(200).JSON(res)
+}
+
+func (h *BookingHandler) CreateReservation(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	var req dto.ReservationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	authUser := middleware.GetAuthUser(c)
+	if authUser == nil {
+		return c.Status(401).JSON(fiber.Map{"message": "unauthorized"})
+	}
+
+	checkin, err := utils.ParseDate(req.CheckInDate, "check in date")
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	checkout, err := utils.ParseDate(req.CheckOutDate, "check out date")
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	bookings := make([]*domain.Booking, len(req.Rooms))
+	for i, room := range req.Rooms {
+		addons, err := bookingAddonsFromRequest(room.BookingAddon)
+		if err != nil {
+			return handleError(c, err)
+		}
+		bookings[i] = &domain.Booking{
+			RatePlanID:   room.RatePlanID,
+			RoomTypeID:   room.RoomTypeID,
+			NumAdults:    room.NumAdults,
+			ChildAges:    room.ChildAges,
+			Email:        req.Email,
+			BookingAddon: addons,
+		}
 	}
 
-	// Just update status to confirmed for mock
-	err = h.svc.ChangeStatus(ctx, bookingID, "confirmed")
+	res, err := h.svc.AddReservation(ctx, &domain.Reservation{
+		UserID:       authUser.ID,
+		Email:        req.Email,
+		CheckInDate:  checkin,
+		CheckOutDate: checkout,
+		PromoCode:    req.PromoCode,
+		GroupCode:    req.GroupCode,
+		Bookings:     bookings,
+	})
 	if err != nil {
 		return handleError(c, err)
 	}
 
-	return c.Status(200).JSON(fiber.Map{"message": "payment successful"})
+	resp, err := dto.ToReservationResponse(res)
+	if err != nil {
+		return handleError(c, err)
+	}
+	return c.Status(201).JSON(resp)
 }
 
-func (h *BookingHandler) GetAllBookings(c *fiber.Ctx) error {
+func (h *BookingHandler) GetReservation(c *fiber.Ctx) error {
 	ctx, cancel := buildCtx(c)
 	defer cancel()
 
-	bookings, err := h.svc.GetAllBookings(ctx)
+	id, err := c.ParamsInt("reservation_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid reservation ID"})
+	}
+
+	res, err := h.svc.GetReservation(ctx, id)
 	if err != nil {
 		return handleError(c, err)
 	}
 
-	// Map to DTO
-	var res []*dto.BookingResponse
-	for _, b := range bookings {
-		res = append(res, dto.ToBookingResponse(b))
+	resp, err := dto.ToReservationResponse(res)
+	if err != nil {
+		return handleError(c, err)
 	}
+	return c.JSON(resp)
+}
 
-	return c.Status(200).JSON(res)
+// bookingAddonsFromRequest maps the requested addons and parses their
+// optional service dates.
+func bookingAddonsFromRequest(req []dto.BookingAddonRequest) ([]*domain.BookingAddon, error) {
+	addons := dto.ToDomainBookingAddons(req)
+	for i, a := range req {
+		if a.ServiceDate == "" {
+			continue
+		}
+		date, err := utils.ParseDate(a.ServiceDate, "service date")
+		if err != nil {
+			return nil, err
+		}
+		addons[i].ServiceDate = date
+	}
+	return addons, nil
 }
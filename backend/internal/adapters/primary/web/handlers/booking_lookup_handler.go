@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/dto"
+)
+
+// LookupBooking serves GET /api/bookings/lookup?code=&email= without login.
+func (h *BookingHandler) LookupBooking(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	booking, err := h.svc.LookupBooking(ctx, c.Query("code"), c.Query("email"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToBookingResponse(booking))
+}
+
+func (h *BookingHandler) CancelBookingByCode(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	var req dto.CancelByCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	booking, err := h.svc.CancelBookingByCode(ctx, req.Code, req.Email, req.Reason)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToBookingResponse(booking))
+}
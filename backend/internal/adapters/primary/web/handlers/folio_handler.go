@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/dto"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
+	"github.com/ingwrok/hotelBooking/internal/core/services"
+)
+
+type FolioHandler struct {
+	svc *services.FolioService
+}
+
+func NewFolioHandler(s *services.FolioService) *FolioHandler {
+	return &FolioHandler{svc: s}
+}
+
+func (h *FolioHandler) GetFolio(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	bookingID, err := c.ParamsInt("booking_id")
+	if err != nil || bookingID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid booking ID"})
+	}
+
+	folio, err := h.svc.GetFolio(ctx, bookingID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToFolioResponse(folio))
+}
+
+func (h *FolioHandler) PostAdjustment(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	authUser := middleware.GetAuthUser(c)
+	if authUser == nil {
+		return c.Status(401).JSON(fiber.Map{"message": "unauthorized"})
+	}
+
+	bookingID, err := c.ParamsInt("booking_id")
+	if err != nil || bookingID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid booking ID"})
+	}
+
+	var req dto.FolioAdjustmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	entry, err := h.svc.PostAdjustment(ctx, bookingID, authUser.ID, req.Amount, req.Description)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(201).JSON(dto.ToFolioEntryResponse(entry))
+}
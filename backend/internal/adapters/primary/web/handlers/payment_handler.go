@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/dto"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
+	"github.com/ingwrok/hotelBooking/internal/core/services"
+)
+
+// webhookSignatureHeader is where payment providers put the webhook signature.
+const webhookSignatureHeader = "X-Webhook-Signature"
+
+type PaymentHandler struct {
+	svc *services.PaymentService
+}
+
+func NewPaymentHandler(s *services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{svc: s}
+}
+
+func (h *PaymentHandler) PayBooking(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	bookingID, err := c.ParamsInt("booking_id")
+	if err != nil || bookingID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid booking ID"})
+	}
+
+	var req dto.PayBookingRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+		}
+	}
+
+	payment, intent, err := h.svc.PayBooking(ctx, bookingID, req.PaymentMethod)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(202).JSON(dto.PaymentIntentResponse{
+		Payment:      dto.ToPaymentResponse(payment),
+		ClientSecret: intent.ClientSecret,
+	})
+}
+
+func (h *PaymentHandler) PayReservation(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	reservationID, err := c.ParamsInt("reservation_id")
+	if err != nil || reservationID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid reservation ID"})
+	}
+
+	var req dto.PayBookingRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+		}
+	}
+
+	payment, intent, err := h.svc.PayReservation(ctx, reservationID, req.PaymentMethod)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(202).JSON(dto.PaymentIntentResponse{
+		Payment:      dto.ToPaymentResponse(payment),
+		ClientSecret: intent.ClientSecret,
+	})
+}
+
+func (h *PaymentHandler) PayBalance(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	bookingID, err := c.ParamsInt("booking_id")
+	if err != nil || bookingID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid booking ID"})
+	}
+
+	var req dto.PartialPaymentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	payment, intent, err := h.svc.PayBalance(ctx, bookingID, req.Amount, req.PaymentMethod)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(202).JSON(dto.PaymentIntentResponse{
+		Payment:      dto.ToPaymentResponse(payment),
+		ClientSecret: intent.ClientSecret,
+	})
+}
+
+func (h *PaymentHandler) RecordPayment(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	authUser := middleware.GetAuthUser(c)
+	if authUser == nil {
+		return c.Status(401).JSON(fiber.Map{"message": "unauthorized"})
+	}
+
+	bookingID, err := c.ParamsInt("booking_id")
+	if err != nil || bookingID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid booking ID"})
+	}
+
+	var req dto.RecordPaymentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	payment, err := h.svc.RecordPayment(ctx, bookingID, authUser.ID, req.Amount, req.Method, req.Reference)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(201).JSON(dto.ToPaymentResponse(payment))
+}
+
+func (h *PaymentHandler) RefundPayment(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	authUser := middleware.GetAuthUser(c)
+	if authUser == nil {
+		return c.Status(401).JSON(fiber.Map{"message": "unauthorized"})
+	}
+
+	bookingID, err := c.ParamsInt("booking_id")
+	if err != nil || bookingID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid booking ID"})
+	}
+
+	var req dto.RefundRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+	if req.PaymentID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid payment ID"})
+	}
+
+	entry, err := h.svc.RefundPayment(ctx, bookingID, authUser.ID, req.PaymentID, req.Amount, req.Reason)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(201).JSON(dto.ToFolioEntryResponse(entry))
+}
+
+func (h *PaymentHandler) GetBookingPayments(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	bookingID, err := c.ParamsInt("booking_id")
+	if err != nil || bookingID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid booking ID"})
+	}
+
+	payments, err := h.svc.GetBookingPayments(ctx, bookingID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToPaymentResponses(payments))
+}
+
+func (h *PaymentHandler) Webhook(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	err := h.svc.HandleWebhook(ctx, c.Body(), c.Get(webhookSignatureHeader))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"message": "webhook processed"})
+}
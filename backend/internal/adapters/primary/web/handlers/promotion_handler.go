@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/dto"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/services"
+	"github.com/ingwrok/hotelBooking/internal/core/utils"
+)
+
+type PromotionHandler struct {
+	svc *services.PromotionService
+}
+
+func NewPromotionHandler(s *services.PromotionService) *PromotionHandler {
+	return &PromotionHandler{svc: s}
+}
+
+func (h *PromotionHandler) CreatePromotion(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	var req dto.PromotionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	promo, err := promotionFromRequest(&req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	promo, err = h.svc.AddPromotion(ctx, promo)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(201).JSON(dto.ToPromotionResponse(promo))
+}
+
+func (h *PromotionHandler) UpdatePromotion(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("promotion_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid promotion ID"})
+	}
+
+	var req dto.PromotionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	promo, err := promotionFromRequest(&req)
+	if err != nil {
+		return handleError(c, err)
+	}
+	promo.PromotionID = id
+
+	err = h.svc.ChangePromotion(ctx, promo)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"message": "promotion updated successfully"})
+}
+
+func (h *PromotionHandler) RemovePromotion(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("promotion_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid promotion ID"})
+	}
+
+	err = h.svc.RemovePromotion(ctx, id)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"message": "promotion deleted successfully"})
+}
+
+func (h *PromotionHandler) GetPromotion(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("promotion_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid promotion ID"})
+	}
+
+	promo, err := h.svc.GetPromotion(ctx, id)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToPromotionResponse(promo))
+}
+
+func (h *PromotionHandler) ListPromotions(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	promos, err := h.svc.ListPromotions(ctx)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToPromotionResponses(promos))
+}
+
+func promotionFromRequest(req *dto.PromotionRequest) (*domain.Promotion, error) {
+	promo := &domain.Promotion{
+		Code:           req.Code,
+		Description:    req.Description,
+		DiscountType:   req.DiscountType,
+		DiscountRate:   req.DiscountRate,
+		DiscountAmount: req.DiscountAmount,
+		MinSpend:       req.MinSpend,
+		RoomTypeIDs:    req.RoomTypeIDs,
+		RatePlanIDs:    req.RatePlanIDs,
+		MaxRedemptions: req.MaxRedemptions,
+		MaxPerUser:     req.MaxPerUser,
+		IsActive:       req.IsActive == nil || *req.IsActive,
+	}
+	if req.BookingStart != nil {
+		promo.BookingStart = *req.BookingStart
+	}
+	if req.BookingEnd != nil {
+		promo.BookingEnd = *req.BookingEnd
+	}
+
+	var err error
+	if req.StayStart != "" {
+		if promo.StayStart, err = utils.ParseDate(req.StayStart, "stay start date"); err != nil {
+			return nil, err
+		}
+	}
+	if req.StayEnd != "" {
+		if promo.StayEnd, err = utils.ParseDate(req.StayEnd, "stay end date"); err != nil {
+			return nil, err
+		}
+	}
+
+	return promo, nil
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/dto"
 	"github.com/ingwrok/hotelBooking/internal/core/domain"
@@ -26,25 +28,29 @@ func (h *RatePlanHandler) CreateRatePlan(c *fiber.Ctx) error {
 	}
 
 	ratePlan, err := h.svc.AddRatePlan(ctx, &domain.RatePlan{
-		Name:             req.Name,
-		Description:      req.Description,
-		IsSpecialPackage: req.IsSpecialPackage,
-		AllowFreeCancel:  req.AllowFreeCancel,
-		AllowPayLater:    req.AllowPayLater,
+		Name:              req.Name,
+		Description:       req.Description,
+		IsSpecialPackage:  req.IsSpecialPackage,
+		AllowFreeCancel:   req.AllowFreeCancel,
+		AllowPayLater:     req.AllowPayLater,
+		DepositPercent:    req.DepositPercent,
+		RequiresGuarantee: req.RequiresGuarantee,
 	})
 	if err != nil {
 		return handleError(c, err)
 	}
 
 	return c.Status(201).JSON(dto.RatePlanResponse{
-		RatePlanID:       ratePlan.RatePlanID,
-		Name:             ratePlan.Name,
-		Description:      ratePlan.Description,
-		IsSpecialPackage: ratePlan.IsSpecialPackage,
-		AllowFreeCancel:  ratePlan.AllowFreeCancel,
-		AllowPayLater:    ratePlan.AllowPayLater,
-		CreatedAt:        utils.ToThaiTime(ratePlan.CreatedAt),
-		UpdatedAt:        utils.ToThaiTime(ratePlan.UpdatedAt),
+		RatePlanID:        ratePlan.RatePlanID,
+		Name:              ratePlan.Name,
+		Description:       ratePlan.Description,
+		IsSpecialPackage:  ratePlan.IsSpecialPackage,
+		AllowFreeCancel:   ratePlan.AllowFreeCancel,
+		AllowPayLater:     ratePlan.AllowPayLater,
+		DepositPercent:    ratePlan.DepositPercent,
+		RequiresGuarantee: ratePlan.RequiresGuarantee,
+		CreatedAt:         utils.ToThaiTime(ratePlan.CreatedAt),
+		UpdatedAt:         utils.ToThaiTime(ratePlan.UpdatedAt),
 	})
 }
 
@@ -63,12 +69,14 @@ func (h *RatePlanHandler) UpdateRatePlan(c *fiber.Ctx) error {
 	}
 
 	err = h.svc.ChangeRatePlan(ctx, &domain.RatePlan{
-		RatePlanID:       id,
-		Name:             req.Name,
-		Description:      req.Description,
-		IsSpecialPackage: req.IsSpecialPackage,
-		AllowFreeCancel:  req.AllowFreeCancel,
-		AllowPayLater:    req.AllowPayLater,
+		RatePlanID:        id,
+		Name:              req.Name,
+		Description:       req.Description,
+		IsSpecialPackage:  req.IsSpecialPackage,
+		AllowFreeCancel:   req.AllowFreeCancel,
+		AllowPayLater:     req.AllowPayLater,
+		DepositPercent:    req.DepositPercent,
+		RequiresGuarantee: req.RequiresGuarantee,
 	})
 	if err != nil {
 		return handleError(c, err)
@@ -109,14 +117,16 @@ func (h *RatePlanHandler) GetRatePlan(c *fiber.Ctx) error {
 	}
 
 	return c.Status(200).JSON(dto.RatePlanResponse{
-		RatePlanID:       ratePlan.RatePlanID,
-		Name:             ratePlan.Name,
-		Description:      ratePlan.Description,
-		IsSpecialPackage: ratePlan.IsSpecialPackage,
-		AllowFreeCancel:  ratePlan.AllowFreeCancel,
-		AllowPayLater:    ratePlan.AllowPayLater,
-		CreatedAt:        utils.ToThaiTime(ratePlan.CreatedAt),
-		UpdatedAt:        utils.ToThaiTime(ratePlan.UpdatedAt),
+		RatePlanID:        ratePlan.RatePlanID,
+		Name:              ratePlan.Name,
+		Description:       ratePlan.Description,
+		IsSpecialPackage:  ratePlan.IsSpecialPackage,
+		AllowFreeCancel:   ratePlan.AllowFreeCancel,
+		AllowPayLater:     ratePlan.AllowPayLater,
+		DepositPercent:    ratePlan.DepositPercent,
+		RequiresGuarantee: ratePlan.RequiresGuarantee,
+		CreatedAt:         utils.ToThaiTime(ratePlan.CreatedAt),
+		UpdatedAt:         utils.ToThaiTime(ratePlan.UpdatedAt),
 	})
 }
 
@@ -132,14 +142,16 @@ func (h *RatePlanHandler) ListRatePlans(c *fiber.Ctx) error {
 	resRatePlans := make([]dto.RatePlanResponse, len(ratePlans))
 	for i, rp := range ratePlans {
 		resRatePlans[i] = dto.RatePlanResponse{
-			RatePlanID:       rp.RatePlanID,
-			Name:             rp.Name,
-			Description:      rp.Description,
-			IsSpecialPackage: rp.IsSpecialPackage,
-			AllowFreeCancel:  rp.AllowFreeCancel,
-			AllowPayLater:    rp.AllowPayLater,
-			CreatedAt:        utils.ToThaiTime(rp.CreatedAt),
-			UpdatedAt:        utils.ToThaiTime(rp.UpdatedAt),
+			RatePlanID:        rp.RatePlanID,
+			Name:              rp.Name,
+			Description:       rp.Description,
+			IsSpecialPackage:  rp.IsSpecialPackage,
+			AllowFreeCancel:   rp.AllowFreeCancel,
+			AllowPayLater:     rp.AllowPayLater,
+			DepositPercent:    rp.DepositPercent,
+			RequiresGuarantee: rp.RequiresGuarantee,
+			CreatedAt:         utils.ToThaiTime(rp.CreatedAt),
+			UpdatedAt:         utils.ToThaiTime(rp.UpdatedAt),
 		}
 	}
 
@@ -181,7 +193,7 @@ func (h *RatePlanHandler) UpdateRoomTypePrice(c *fiber.Ctx) error {
 	}
 
 	type priceRequest struct {
-		Price float64 `json:"price"`
+		Price domain.Money `json:"price"`
 	}
 
 	var req priceRequest
@@ -235,17 +247,187 @@ func (h *RatePlanHandler) ListRatePlansByRoomType(c *fiber.Ctx) error {
 	resRatePlans := make([]dto.RatePlanFullResponse, len(ratePlans))
 	for i, rp := range ratePlans {
 		resRatePlans[i] = dto.RatePlanFullResponse{
-			RatePlanID:       rp.RatePlanID,
-			Name:             rp.Name,
-			Description:      rp.Description,
-			IsSpecialPackage: rp.IsSpecialPackage,
-			AllowFreeCancel:  rp.AllowFreeCancel,
-			AllowPayLater:    rp.AllowPayLater,
-			Price:           rp.Price,
-			CreatedAt:        utils.ToThaiTime(rp.CreatedAt),
-			UpdatedAt:        utils.ToThaiTime(rp.UpdatedAt),
+			RatePlanID:        rp.RatePlanID,
+			Name:              rp.Name,
+			Description:       rp.Description,
+			IsSpecialPackage:  rp.IsSpecialPackage,
+			AllowFreeCancel:   rp.AllowFreeCancel,
+			AllowPayLater:     rp.AllowPayLater,
+			DepositPercent:    rp.DepositPercent,
+			RequiresGuarantee: rp.RequiresGuarantee,
+			Price:             rp.Price,
+			CreatedAt:         utils.ToThaiTime(rp.CreatedAt),
+			UpdatedAt:         utils.ToThaiTime(rp.UpdatedAt),
 		}
 	}
 
 	return c.Status(200).JSON(resRatePlans)
 }
+
+func (h *RatePlanHandler) SetCalendarPrice(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	rateplanID, err := c.ParamsInt("rate_plan_id")
+	if err != nil || rateplanID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid rate plan ID"})
+	}
+	roomTypeID, err := c.ParamsInt("room_type_id")
+	if err != nil || roomTypeID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid room type ID"})
+	}
+
+	var req dto.RateCalendarRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	startDate, err := utils.ParseDate(req.StartDate, "start date")
+	if err != nil {
+		return handleError(c, err)
+	}
+	endDate, err := utils.ParseDate(req.EndDate, "end date")
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	weekdays := make([]time.Weekday, len(req.Weekdays))
+	for i, wd := range req.Weekdays {
+		weekdays[i] = time.Weekday(wd)
+	}
+
+	count, err := h.svc.SetCalendarPrice(ctx, roomTypeID, rateplanID, startDate, endDate, weekdays, req.Price)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"message": "rate calendar updated successfully", "dates": count})
+}
+
+func (h *RatePlanHandler) ClearCalendarPrice(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	rateplanID, err := c.ParamsInt("rate_plan_id")
+	if err != nil || rateplanID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid rate plan ID"})
+	}
+	roomTypeID, err := c.ParamsInt("room_type_id")
+	if err != nil || roomTypeID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid room type ID"})
+	}
+
+	startDate, err := utils.ParseDate(c.Query("startDate"), "start date")
+	if err != nil {
+		return handleError(c, err)
+	}
+	endDate, err := utils.ParseDate(c.Query("endDate"), "end date")
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	count, err := h.svc.ClearCalendarPrice(ctx, roomTypeID, rateplanID, startDate, endDate)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"message": "rate calendar cleared successfully", "dates": count})
+}
+
+func (h *RatePlanHandler) GetCalendar(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	rateplanID, err := c.ParamsInt("rate_plan_id")
+	if err != nil || rateplanID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid rate plan ID"})
+	}
+	roomTypeID, err := c.ParamsInt("room_type_id")
+	if err != nil || roomTypeID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid room type ID"})
+	}
+
+	startDate, err := utils.ParseDate(c.Query("startDate"), "start date")
+	if err != nil {
+		return handleError(c, err)
+	}
+	endDate, err := utils.ParseDate(c.Query("endDate"), "end date")
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	rates, err := h.svc.GetCalendar(ctx, roomTypeID, rateplanID, startDate, endDate)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToNightlyRateResponses(rates))
+}
+
+func (h *RatePlanHandler) GetOccupancyPricing(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	rateplanID, err := c.ParamsInt("rate_plan_id")
+	if err != nil || rateplanID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid rate plan ID"})
+	}
+	roomTypeID, err := c.ParamsInt("room_type_id")
+	if err != nil || roomTypeID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid room type ID"})
+	}
+
+	pricing, err := h.svc.GetOccupancyPricing(ctx, roomTypeID, rateplanID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToOccupancyPricingResponse(pricing))
+}
+
+func (h *RatePlanHandler) SetOccupancyPricing(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	rateplanID, err := c.ParamsInt("rate_plan_id")
+	if err != nil || rateplanID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid rate plan ID"})
+	}
+	roomTypeID, err := c.ParamsInt("room_type_id")
+	if err != nil || roomTypeID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid room type ID"})
+	}
+
+	var req dto.OccupancyPricingRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	err = h.svc.ChangeOccupancyPricing(ctx, dto.ToDomainOccupancyPricing(roomTypeID, rateplanID, &req))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"message": "occupancy pricing updated successfully"})
+}
+
+func (h *RatePlanHandler) RemoveOccupancyPricing(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	rateplanID, err := c.ParamsInt("rate_plan_id")
+	if err != nil || rateplanID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid rate plan ID"})
+	}
+	roomTypeID, err := c.ParamsInt("room_type_id")
+	if err != nil || roomTypeID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid room type ID"})
+	}
+
+	err = h.svc.RemoveOccupancyPricing(ctx, roomTypeID, rateplanID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"message": "occupancy pricing deleted successfully"})
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/dto"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/services"
+	"github.com/ingwrok/hotelBooking/internal/core/utils"
+)
+
+type RestrictionHandler struct {
+	svc *services.RestrictionService
+}
+
+func NewRestrictionHandler(s *services.RestrictionService) *RestrictionHandler {
+	return &RestrictionHandler{svc: s}
+}
+
+func (h *RestrictionHandler) CreateRestriction(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	var req dto.StayRestrictionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	restriction, err := restrictionFromRequest(&req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	restriction, err = h.svc.AddRestriction(ctx, restriction)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(201).JSON(dto.ToStayRestrictionResponse(restriction))
+}
+
+func (h *RestrictionHandler) UpdateRestriction(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("restriction_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid restriction ID"})
+	}
+
+	var req dto.StayRestrictionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	restriction, err := restrictionFromRequest(&req)
+	if err != nil {
+		return handleError(c, err)
+	}
+	restriction.RestrictionID = id
+
+	err = h.svc.ChangeRestriction(ctx, restriction)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToStayRestrictionResponse(restriction))
+}
+
+func (h *RestrictionHandler) RemoveRestriction(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("restriction_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid restriction ID"})
+	}
+
+	err = h.svc.RemoveRestriction(ctx, id)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"message": "restriction deleted successfully"})
+}
+
+func (h *RestrictionHandler) GetRestriction(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("restriction_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid restriction ID"})
+	}
+
+	restriction, err := h.svc.GetRestriction(ctx, id)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToStayRestrictionResponse(restriction))
+}
+
+func (h *RestrictionHandler) ListRestrictionsByRoomType(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	roomTypeID, err := c.ParamsInt("room_type_id")
+	if err != nil || roomTypeID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid room type ID"})
+	}
+
+	restrictions, err := h.svc.ListRestrictionsByRoomType(ctx, roomTypeID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToStayRestrictionResponses(restrictions))
+}
+
+func restrictionFromRequest(req *dto.StayRestrictionRequest) (*domain.StayRestriction, error) {
+	startDate, err := utils.ParseDate(req.StartDate, "start date")
+	if err != nil {
+		return nil, err
+	}
+	endDate, err := utils.ParseDate(req.EndDate, "end date")
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.StayRestriction{
+		RoomTypeID:        req.RoomTypeID,
+		RatePlanID:        req.RatePlanID,
+		StartDate:         startDate,
+		EndDate:           endDate,
+		MinLOS:            req.MinLOS,
+		MaxLOS:            req.MaxLOS,
+		ClosedToArrival:   req.ClosedToArrival,
+		ClosedToDeparture: req.ClosedToDeparture,
+		StopSell:          req.StopSell,
+	}, nil
+}
@@ -225,7 +225,7 @@ func (h *RoomHandler) CountAvailableRooms(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
 	}
 
-	result, err := h.svc.CountAvailableRooms(ctx, req.CheckIn, req.CheckOut)
+	result, err := h.svc.CountAvailableRooms(ctx, req.CheckIn, req.CheckOut, req.RatePlanID)
 	if err != nil {
 		return handleError(c, err)
 	}
@@ -242,7 +242,7 @@ func (h *RoomHandler) FindAvailableRoom(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
 	}
 
-	roomID, err := h.svc.FindAvailableRoom(ctx, req.RoomTypeID, req.CheckIn, req.CheckOut)
+	roomID, err := h.svc.FindAvailableRoom(ctx, req.RoomTypeID, req.CheckIn, req.CheckOut, req.RatePlanID)
 	if err != nil {
 		return handleError(c, err)
 	}
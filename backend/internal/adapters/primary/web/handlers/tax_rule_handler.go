@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/dto"
+	"github.com/ingwrok/hotelBooking/internal/core/services"
+)
+
+type TaxRuleHandler struct {
+	svc *services.TaxRuleService
+}
+
+func NewTaxRuleHandler(s *services.TaxRuleService) *TaxRuleHandler {
+	return &TaxRuleHandler{svc: s}
+}
+
+func (h *TaxRuleHandler) CreateTaxRule(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	var req dto.TaxRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	rule, err := h.svc.AddTaxRule(ctx, dto.ToDomainTaxRule(&req))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(201).JSON(dto.ToTaxRuleResponse(rule))
+}
+
+func (h *TaxRuleHandler) UpdateTaxRule(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("tax_rule_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid tax rule ID"})
+	}
+
+	var req dto.TaxRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	rule := dto.ToDomainTaxRule(&req)
+	rule.TaxRuleID = id
+
+	err = h.svc.ChangeTaxRule(ctx, rule)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToTaxRuleResponse(rule))
+}
+
+func (h *TaxRuleHandler) RemoveTaxRule(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("tax_rule_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid tax rule ID"})
+	}
+
+	err = h.svc.RemoveTaxRule(ctx, id)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"message": "tax rule deleted successfully"})
+}
+
+func (h *TaxRuleHandler) GetTaxRule(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("tax_rule_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid tax rule ID"})
+	}
+
+	rule, err := h.svc.GetTaxRule(ctx, id)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToTaxRuleResponse(rule))
+}
+
+func (h *TaxRuleHandler) ListTaxRules(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	rules, err := h.svc.ListTaxRules(ctx)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToTaxRuleResponses(rules))
+}
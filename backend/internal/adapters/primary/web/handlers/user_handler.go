@@ -3,7 +3,9 @@ package handlers
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/dto"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
 	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
 	"github.com/ingwrok/hotelBooking/internal/core/services"
 )
 
@@ -29,12 +31,7 @@ func (h *UserHandler) Register(c *fiber.Ctx) error {
 		return handleError(c, err)
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(dto.UserResponse{
-		UserID:   created.UserID,
-		Username: created.Username,
-		Email:    created.Email,
-		IsAdmin:  created.IsAdmin,
-	})
+	return c.Status(fiber.StatusCreated).JSON(dto.ToUserResponse(created))
 }
 
 func (h *UserHandler) Login(c *fiber.Ctx) error {
@@ -46,28 +43,177 @@ func (h *UserHandler) Login(c *fiber.Ctx) error {
 		return handleError(c, errs.NewValidationError("request body incorrect format"))
 	}
 
-	token, user, err := h.svc.Login(ctx, req.Username, req.Password)
+	res, err := h.svc.Login(ctx, req.Username, req.Password, device(c))
 	if err != nil {
 		return handleError(c, err)
 	}
 
-	return c.Status(fiber.StatusOK).JSON(dto.LoginResponse{
-		Token: token,
-		User: dto.UserResponse{
-			UserID:   user.UserID,
-			Username: user.Username,
-			Email:    user.Email,
-			IsAdmin:  user.IsAdmin,
-		},
-	})
+	if res.ChallengeToken != "" {
+		return c.Status(fiber.StatusOK).JSON(dto.ToLoginChallengeResponse(res))
+	}
+	return c.Status(fiber.StatusOK).JSON(dto.ToLoginResponse(res.Tokens, res.User))
+}
+
+func (h *UserHandler) LoginTwoFactor(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	var req dto.LoginTwoFactorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return handleError(c, errs.NewValidationError("request body incorrect format"))
+	}
+
+	pair, user, err := h.svc.CompleteLogin(ctx, req.ChallengeToken, req.Code, device(c))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ToLoginResponse(pair, user))
+}
+
+func (h *UserHandler) Refresh(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	var req dto.RefreshRequest
+	if err := c.BodyParser(&req); err != nil {
+		return handleError(c, errs.NewValidationError("request body incorrect format"))
+	}
+
+	pair, user, err := h.svc.Refresh(ctx, req.RefreshToken, device(c))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ToLoginResponse(pair, user))
 }
 
+// Logout ends the session of the given refresh token. The access token stays
+// valid until it expires; LogoutAll revokes those too.
 func (h *UserHandler) Logout(c *fiber.Ctx) error {
-	// Cookie removed for Bearer Token strategy
-	// c.Cookie(&fiber.Cookie{...})
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	var req dto.RefreshRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return handleError(c, errs.NewValidationError("request body incorrect format"))
+		}
+	}
+
+	if err := h.svc.Logout(ctx, req.RefreshToken); err != nil {
+		return handleError(c, err)
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "logged out successfully"})
 }
 
+func (h *UserHandler) LogoutAll(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	au := middleware.GetAuthUser(c)
+	if au == nil {
+		return handleError(c, errs.NewUnauthorizedError("unauthorized"))
+	}
+
+	if err := h.svc.LogoutAll(ctx, au.ID); err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "logged out from all devices"})
+}
+
+func (h *UserHandler) VerifyEmail(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	var req dto.VerifyEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return handleError(c, errs.NewValidationError("request body incorrect format"))
+	}
+
+	if err := h.svc.VerifyEmail(ctx, req.Token); err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "email verified"})
+}
+
+func (h *UserHandler) ResendVerification(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	au := middleware.GetAuthUser(c)
+	if au == nil {
+		return handleError(c, errs.NewUnauthorizedError("unauthorized"))
+	}
+
+	if err := h.svc.RequestEmailVerification(ctx, au.ID); err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "verification email sent"})
+}
+
+// ForgotPassword answers the same way whether or not the email has an account.
+func (h *UserHandler) ForgotPassword(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	var req dto.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return handleError(c, errs.NewValidationError("request body incorrect format"))
+	}
+
+	if err := h.svc.RequestPasswordReset(ctx, req.Email); err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "if an account uses this email, a reset link has been sent"})
+}
+
+func (h *UserHandler) ResetPassword(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	var req dto.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return handleError(c, errs.NewValidationError("request body incorrect format"))
+	}
+
+	if err := h.svc.ResetPassword(ctx, req.Token, req.Password, device(c)); err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "password has been reset, please log in again"})
+}
+
+func (h *UserHandler) UnlockAccount(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	var req dto.UnlockAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return handleError(c, errs.NewValidationError("request body incorrect format"))
+	}
+
+	if err := h.svc.UnlockAccount(ctx, req.Token, device(c)); err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "account unlocked"})
+}
+
+// device describes the caller. c.IP() is the client's address, taken from the
+// proxy's X-Real-IP header when the request came through a trusted proxy.
+func device(c *fiber.Ctx) domain.Device {
+	return domain.Device{
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IPAddress: c.IP(),
+	}
+}
+
 func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 	ctx, cancel := buildCtx(c)
 	defer cancel()
@@ -119,12 +265,7 @@ func (h *UserHandler) GetUser(c *fiber.Ctx) error {
 		return handleError(c, err)
 	}
 
-	return c.JSON(dto.UserResponse{
-		UserID:   u.UserID,
-		Username: u.Username,
-		Email:    u.Email,
-		IsAdmin:  u.IsAdmin,
-	})
+	return c.JSON(dto.ToUserResponse(u))
 }
 
 func (h *UserHandler) GetUsers(c *fiber.Ctx) error {
@@ -138,12 +279,240 @@ func (h *UserHandler) GetUsers(c *fiber.Ctx) error {
 
 	out := make([]dto.UserResponse, 0, len(users))
 	for _, u := range users {
-		out = append(out, dto.UserResponse{
-			UserID:   u.UserID,
-			Username: u.Username,
-			Email:    u.Email,
-			IsAdmin:  u.IsAdmin,
-		})
+		out = append(out, dto.ToUserResponse(u))
 	}
 	return c.JSON(out)
 }
+
+func (h *UserHandler) GetRoles(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	roles, err := h.svc.GetRoles(ctx)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(dto.ToRoleResponses(roles))
+}
+
+func (h *UserHandler) AssignRole(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return handleError(c, errs.NewValidationError("invalid user id"))
+	}
+
+	var req dto.AssignRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return handleError(c, errs.NewValidationError("request body incorrect format"))
+	}
+
+	u, err := h.svc.AssignRole(ctx, id, req.Role)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(dto.ToUserResponse(u))
+}
+
+func (h *UserHandler) RevokeRole(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return handleError(c, errs.NewValidationError("invalid user id"))
+	}
+
+	u, err := h.svc.RevokeRole(ctx, id, c.Params("role"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(dto.ToUserResponse(u))
+}
+
+func (h *UserHandler) UnlockUser(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return handleError(c, errs.NewValidationError("invalid user id"))
+	}
+
+	au := middleware.GetAuthUser(c)
+	if au == nil {
+		return handleError(c, errs.NewUnauthorizedError("unauthorized"))
+	}
+
+	if err := h.svc.UnlockUser(ctx, id, au.ID, device(c)); err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "account unlocked"})
+}
+
+func (h *UserHandler) GetAuditLog(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	filter := domain.AuditFilter{
+		UserID: c.QueryInt("user_id"),
+		Event:  c.Query("event"),
+		Limit:  c.QueryInt("limit"),
+	}
+
+	entries, err := h.svc.GetAuditLog(ctx, filter)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(dto.ToAuditEntryResponses(entries))
+}
+
+func (h *UserHandler) GetTwoFactorStatus(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	au := middleware.GetAuthUser(c)
+	if au == nil {
+		return handleError(c, errs.NewUnauthorizedError("unauthorized"))
+	}
+
+	status, err := h.svc.GetTwoFactorStatus(ctx, au.ID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(dto.TwoFactorStatusResponse{
+		Enabled:           status.Enabled,
+		Required:          status.Required,
+		RecoveryCodesLeft: status.RecoveryCodesLeft,
+	})
+}
+
+func (h *UserHandler) SetupTwoFactor(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	au := middleware.GetAuthUser(c)
+	if au == nil {
+		return handleError(c, errs.NewUnauthorizedError("unauthorized"))
+	}
+
+	setup, err := h.svc.SetupTwoFactor(ctx, au.ID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(dto.TwoFactorSetupResponse{
+		Secret:          setup.Secret,
+		ProvisioningURI: setup.ProvisioningURI,
+	})
+}
+
+func (h *UserHandler) ConfirmTwoFactor(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	au := middleware.GetAuthUser(c)
+	if au == nil {
+		return handleError(c, errs.NewUnauthorizedError("unauthorized"))
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return handleError(c, errs.NewValidationError("request body incorrect format"))
+	}
+
+	codes, err := h.svc.ConfirmTwoFactor(ctx, au.ID, req.Code, device(c))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *UserHandler) DisableTwoFactor(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	au := middleware.GetAuthUser(c)
+	if au == nil {
+		return handleError(c, errs.NewUnauthorizedError("unauthorized"))
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return handleError(c, errs.NewValidationError("request body incorrect format"))
+	}
+
+	if err := h.svc.DisableTwoFactor(ctx, au.ID, req.Code, device(c)); err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "two-factor authentication disabled"})
+}
+
+func (h *UserHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	au := middleware.GetAuthUser(c)
+	if au == nil {
+		return handleError(c, errs.NewUnauthorizedError("unauthorized"))
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return handleError(c, errs.NewValidationError("request body incorrect format"))
+	}
+
+	codes, err := h.svc.RegenerateRecoveryCodes(ctx, au.ID, req.Code, device(c))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *UserHandler) ResetTwoFactor(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return handleError(c, errs.NewValidationError("invalid user id"))
+	}
+
+	au := middleware.GetAuthUser(c)
+	if au == nil {
+		return handleError(c, errs.NewUnauthorizedError("unauthorized"))
+	}
+
+	if err := h.svc.ResetTwoFactor(ctx, id, au.ID, device(c)); err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "two-factor authentication reset"})
+}
+
+func (h *UserHandler) SetRoleTwoFactor(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	var req dto.RoleTwoFactorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return handleError(c, errs.NewValidationError("request body incorrect format"))
+	}
+
+	if err := h.svc.SetRoleTwoFactor(ctx, c.Params("role"), req.Required); err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "role updated"})
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/dto"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/utils"
+)
+
+func (h *BookingHandler) JoinWaitlist(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	var req dto.WaitlistRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	authUser := middleware.GetAuthUser(c)
+	if authUser == nil {
+		return c.Status(401).JSON(fiber.Map{"message": "unauthorized"})
+	}
+
+	checkin, err := utils.ParseDate(req.CheckInDate, "check in date")
+	if err != nil {
+		return handleError(c, err)
+	}
+	checkout, err := utils.ParseDate(req.CheckOutDate, "check out date")
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	entry, err := h.svc.JoinWaitlist(ctx, &domain.WaitlistEntry{
+		UserID:       authUser.ID,
+		RoomTypeID:   req.RoomTypeID,
+		RatePlanID:   req.RatePlanID,
+		CheckInDate:  checkin,
+		CheckOutDate: checkout,
+		NumAdults:    req.NumAdults,
+		ChildAges:    req.ChildAges,
+		Email:        req.Email,
+	})
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(201).JSON(dto.ToWaitlistEntryResponse(entry))
+}
+
+func (h *BookingHandler) GetMyWaitlist(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	authUser := middleware.GetAuthUser(c)
+	if authUser == nil {
+		return c.Status(401).JSON(fiber.Map{"message": "unauthorized"})
+	}
+
+	entries, err := h.svc.GetMyWaitlist(ctx, authUser.ID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToWaitlistEntryResponses(entries))
+}
+
+// ListWaitlist returns the queue, filtered to one room type with ?roomTypeId=.
+func (h *BookingHandler) ListWaitlist(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	roomTypeID := c.QueryInt("roomTypeId", 0)
+	if roomTypeID < 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid room type ID"})
+	}
+
+	entries, err := h.svc.ListWaitlist(ctx, roomTypeID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToWaitlistEntryResponses(entries))
+}
+
+func (h *BookingHandler) LeaveWaitlist(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("entry_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid waitlist entry ID"})
+	}
+
+	authUser := middleware.GetAuthUser(c)
+	if authUser == nil {
+		return c.Status(401).JSON(fiber.Map{"message": "unauthorized"})
+	}
+
+	if err := h.svc.LeaveWaitlist(ctx, id, authUser.ID); err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"message": "left the waitlist successfully"})
+}
+
+func (h *BookingHandler) AcceptWaitlistOffer(c *fiber.Ctx) error {
+	ctx, cancel := buildCtx(c)
+	defer cancel()
+
+	id, err := c.ParamsInt("entry_id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid waitlist entry ID"})
+	}
+
+	authUser := middleware.GetAuthUser(c)
+	if authUser == nil {
+		return c.Status(401).JSON(fiber.Map{"message": "unauthorized"})
+	}
+
+	booking, err := h.svc.AcceptWaitlistOffer(ctx, id, authUser.ID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.Status(200).JSON(dto.ToBookingResponse(booking))
+}
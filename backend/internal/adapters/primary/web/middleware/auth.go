@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -20,14 +21,34 @@ type bookingGetter interface {
 	GetFullDetails(ctx context.Context, bookingID int) (*domain.BookingDetail, error)
 }
 
+type reservationGetter interface {
+	GetReservation(ctx context.Context, reservationID int) (*domain.ReservationDetail, error)
+}
+
 type AuthUser struct {
-	ID      int
-	IsAdmin bool
+	ID          int
+	Roles       []string
+	Permissions []string
+
+	EmailVerified bool
+	// TwoFactorSetupRequired is set for users whose role demands two-factor
+	// but who have not enrolled; they hold no permissions until they do.
+	TwoFactorSetupRequired bool
+}
+
+func (au *AuthUser) HasPermission(permission string) bool {
+	return slices.Contains(au.Permissions, permission)
 }
 
+// MyCustomClaims carries the user's roles and permissions as of login so
+// clients can shape their UI. AuthMiddleware reloads them from the database
+// on every request, so a revoked role takes effect immediately. A token whose
+// TokenVersion is behind the user's has been revoked.
 type MyCustomClaims struct {
-	UserID  int  `json:"uid"`
-	IsAdmin bool `json:"is_admin"`
+	UserID       int      `json:"uid"`
+	Roles        []string `json:"roles"`
+	Permissions  []string `json:"permissions"`
+	TokenVersion int      `json:"tv"`
 	jwt.RegisteredClaims
 }
 
@@ -67,14 +88,22 @@ func AuthMiddleware(ug userGetter) fiber.Handler {
 			logger.ErrorErr(err, "GetUser failed in AuthMiddleware", zap.Int("UserID", claims.UserID))
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found or inactive"})
 		}
+		if claims.TokenVersion != u.TokenVersion {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "token has been revoked"})
+		}
 
-		c.Locals("authUser", &AuthUser{
-			ID:      int(u.UserID),
-			IsAdmin: u.IsAdmin,
-		})
+		au := &AuthUser{
+			ID:          int(u.UserID),
+			Roles:       u.Roles,
+			Permissions: u.Permissions,
 
-		// DEBUG
-		fmt.Printf(">>> GLOBAL AUTH DEBUG: UserID=%d IsAdmin=%v <<<\n", u.UserID, u.IsAdmin)
+			EmailVerified: u.EmailVerified(),
+		}
+		if u.TwoFactorRequired && !u.TwoFactorEnabled {
+			au.Permissions = nil
+			au.TwoFactorSetupRequired = true
+		}
+		c.Locals("authUser", au)
 
 		return c.Next()
 	}
@@ -94,7 +123,7 @@ func VerifyUser(paramName string) fiber.Handler {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
 		}
 
-		if au.IsAdmin {
+		if au.HasPermission(domain.PermUsersManage) {
 			return c.Next()
 		}
 
@@ -113,15 +142,39 @@ func VerifyUser(paramName string) fiber.Handler {
 	}
 }
 
-func VerifyAdmin() fiber.Handler {
+// RequirePermission lets the request through only when one of the user's
+// roles grants permission, e.g. RequirePermission(domain.PermBookingsUpdateStatus).
+func RequirePermission(permission string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		au := GetAuthUser(c)
 		if au == nil {
 			return c.SendStatus(fiber.StatusUnauthorized)
 		}
-		if !au.IsAdmin {
-			fmt.Printf(">>> VERIFY ADMIN FAILED: UserID=%d IsAdmin=%v <<<\n", au.ID, au.IsAdmin)
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "admin privilege required"})
+		if au.TwoFactorSetupRequired {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "set up two-factor authentication to use this feature"})
+		}
+		if !au.HasPermission(permission) {
+			logger.Debug("permission denied", zap.Int("UserID", au.ID), zap.String("Permission", permission))
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "permission denied: " + permission + " required"})
+		}
+		return c.Next()
+	}
+}
+
+// RequireVerifiedEmail blocks users who have not verified their email, when
+// auth.require_verified_email is on. Staff who can manage any booking are
+// never blocked.
+func RequireVerifiedEmail() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !viper.GetBool("auth.require_verified_email") {
+			return c.Next()
+		}
+		au := GetAuthUser(c)
+		if au == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+		}
+		if !au.EmailVerified && !au.HasPermission(domain.PermBookingsManageAny) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "please verify your email address first"})
 		}
 		return c.Next()
 	}
@@ -134,7 +187,7 @@ func VerifyBookingOwner(bookingSvc bookingGetter) fiber.Handler {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
 		}
 
-		if au.IsAdmin {
+		if au.HasPermission(domain.PermBookingsManageAny) {
 			return c.Next()
 		}
 
@@ -155,3 +208,32 @@ func VerifyBookingOwner(bookingSvc bookingGetter) fiber.Handler {
 		return c.Next()
 	}
 }
+
+func VerifyReservationOwner(bookingSvc reservationGetter) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		au := GetAuthUser(c)
+		if au == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+		}
+
+		if au.HasPermission(domain.PermBookingsManageAny) {
+			return c.Next()
+		}
+
+		reservationID, err := c.ParamsInt("reservation_id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid reservation id"})
+		}
+
+		res, err := bookingSvc.GetReservation(c.UserContext(), reservationID)
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "reservation not found"})
+		}
+
+		if res.UserID != au.ID {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "you don't have permission to this reservation"})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/common/logger"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+type idempotencyStore interface {
+	BeginRequest(ctx context.Context, userID int, key, requestHash string) (*domain.IdempotencyKey, error)
+	CompleteRequest(ctx context.Context, userID int, key string, status int, contentType string, body []byte) error
+	ReleaseRequest(ctx context.Context, userID int, key string) error
+}
+
+// Idempotency replays the stored response when a request carries an
+// Idempotency-Key the user already sent for the same method, URL and body.
+// Requests without the header run as usual. It must run after AuthMiddleware.
+func Idempotency(store idempotencyStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" {
+			return c.Next()
+		}
+
+		au := GetAuthUser(c)
+		if au == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+		}
+
+		stored, err := store.BeginRequest(c.UserContext(), au.ID, key, requestHash(c))
+		if err != nil {
+			var appErr errs.AppError
+			if errors.As(err, &appErr) {
+				return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "internal server error"})
+		}
+		if stored != nil {
+			c.Set(HeaderIdempotentReplayed, "true")
+			if stored.ContentType != "" {
+				c.Set(fiber.HeaderContentType, stored.ContentType)
+			}
+			return c.Status(stored.ResponseStatus).Send(stored.ResponseBody)
+		}
+
+		if err := c.Next(); err != nil {
+			store.ReleaseRequest(c.UserContext(), au.ID, key)
+			return err
+		}
+
+		// Server errors are not final, so the client may retry them with the same key.
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			store.ReleaseRequest(c.UserContext(), au.ID, key)
+			return nil
+		}
+
+		body := append([]byte(nil), c.Response().Body()...)
+		contentType := string(c.Response().Header.ContentType())
+		if err := store.CompleteRequest(c.UserContext(), au.ID, key, status, contentType, body); err != nil {
+			logger.ErrorErr(err, "CompleteRequest failed in Idempotency", zap.Int("UserID", au.ID))
+		}
+
+		return nil
+	}
+}
+
+// requestHash fingerprints the method, URL and body, so a key reused for a
+// different request can be told apart from a retry.
+func requestHash(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(c.OriginalURL()))
+	h.Write([]byte{0})
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/spf13/viper"
+)
+
+// LookupLimiter slows down guessing on the public booking lookup. Only
+// failed lookups count against the limit, so a guest checking their own
+// booking again is never blocked.
+func LookupLimiter(key func(c *fiber.Ctx) string) fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:                    viper.GetInt("lookup.max_failed_attempts"),
+		Expiration:             time.Duration(viper.GetInt("lookup.window_minutes")) * time.Minute,
+		KeyGenerator:           key,
+		SkipSuccessfulRequests: true,
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "too many failed lookups, please try again later"})
+		},
+	})
+}
+
+// LookupByIP limits failed lookups per client. Behind the proxy c.IP() is the
+// client's own address, taken from X-Real-IP (see app.trusted_proxies).
+func LookupByIP(c *fiber.Ctx) string {
+	return "ip:" + c.IP()
+}
+
+// LookupByEmail limits failed lookups per guest email, so codes for one
+// guest cannot be guessed from many addresses.
+func LookupByEmail(c *fiber.Ctx) string {
+	email := c.Query("email")
+	if email == "" {
+		var body struct {
+			Email string `json:"email"`
+		}
+		_ = c.BodyParser(&body)
+		email = body.Email
+	}
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/handlers"
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
 	"github.com/ingwrok/hotelBooking/internal/core/services"
 )
 
@@ -14,7 +15,7 @@ func AddonRoutes(app *fiber.App, h *handlers.AddonHandler, userSvc *services.Use
 	categories.Get("/", h.ListAddonCategories)
 	categories.Get("/:addon_category_id", h.GetAddonCategory)
 
-	categoriesAdmin := categories.Group("/", middleware.AuthMiddleware(userSvc), middleware.VerifyAdmin())
+	categoriesAdmin := categories.Group("/", middleware.AuthMiddleware(userSvc), middleware.RequirePermission(domain.PermAddonsManage))
 	categoriesAdmin.Post("/", h.CreateAddonCategory)
 	categoriesAdmin.Put("/:addon_category_id", h.UpdateAddonCategory)
 	categoriesAdmin.Delete("/:addon_category_id", h.DeleteAddonCategory)
@@ -22,11 +23,15 @@ func AddonRoutes(app *fiber.App, h *handlers.AddonHandler, userSvc *services.Use
 	addons := api.Group("/addons")
 	addons.Get("/", h.ListAddons)
 	addons.Get("/:addon_id", h.GetAddon)
+	addons.Get("/:addon_id/availability", h.GetAvailability)
 	addons.Get("/category/:addon_category_id", h.ListAddonsByCategory)
 
-	addonsAdmin := addons.Group("/", middleware.AuthMiddleware(userSvc), middleware.VerifyAdmin())
+	addonsAdmin := addons.Group("/", middleware.AuthMiddleware(userSvc), middleware.RequirePermission(domain.PermAddonsManage))
 	addonsAdmin.Post("/upload", h.UploadImage)
 	addonsAdmin.Post("/", h.CreateAddon)
 	addonsAdmin.Put("/:addon_id", h.UpdateAddon)
 	addonsAdmin.Delete("/:addon_id", h.DeleteAddon)
+	addonsAdmin.Post("/:addon_id/slots", h.CreateAddonSlot)
+	addonsAdmin.Put("/:addon_id/slots/:slot_id", h.UpdateAddonSlot)
+	addonsAdmin.Delete("/:addon_id/slots/:slot_id", h.DeleteAddonSlot)
 }
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/handlers"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/services"
+)
+
+func AllotmentRoutes(app *fiber.App, h *handlers.AllotmentHandler, userSvc *services.UserService) {
+	admin := app.Group("/api/allotments", middleware.AuthMiddleware(userSvc), middleware.RequirePermission(domain.PermAllotmentsManage))
+
+	admin.Get("/", h.ListAllotments)
+	admin.Get("/:allotment_id", h.GetAllotment)
+	admin.Get("/:allotment_id/pickup", h.GetPickupReport)
+	admin.Post("/", h.CreateAllotment)
+	admin.Put("/:allotment_id", h.UpdateAllotment)
+	admin.Delete("/:allotment_id", h.RemoveAllotment)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/handlers"
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
 	"github.com/ingwrok/hotelBooking/internal/core/services"
 )
 
@@ -13,7 +14,7 @@ func AmenityRoutes(app *fiber.App, h *handlers.AmenityHandler, userSvc *services
 	amenities.Get("/:amenity_id", h.GetAmenity)
 	amenities.Get("/", h.ListAmenities)
 
-	admin := amenities.Group("/", middleware.AuthMiddleware(userSvc), middleware.VerifyAdmin())
+	admin := amenities.Group("/", middleware.AuthMiddleware(userSvc), middleware.RequirePermission(domain.PermAmenitiesManage))
 	admin.Post("/", h.CreateAmenity)
 	admin.Patch("/:amenity_id", h.UpdateAmenity)
 	admin.Delete("/:amenity_id", h.RemoveAmenity)
@@ -4,23 +4,44 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/handlers"
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
 	"github.com/ingwrok/hotelBooking/internal/core/services"
 )
 
-func BookingRoutes(app *fiber.App, h *handlers.BookingHandler, userSvc *services.UserService, bookingSvc *services.BookingService) {
+func BookingRoutes(app *fiber.App, h *handlers.BookingHandler, payHandler *handlers.PaymentHandler, folioHandler *handlers.FolioHandler, userSvc *services.UserService, bookingSvc *services.BookingService, idempotencySvc *services.IdempotencyService) {
+	// Payment providers call the webhook directly, so it sits outside the auth group.
+	app.Post("/api/payments/webhook", payHandler.Webhook)
+
+	// Guests without an account find a booking by confirmation code and email.
+	// These are registered ahead of the auth group so it does not catch them.
+	byIP := middleware.LookupLimiter(middleware.LookupByIP)
+	byEmail := middleware.LookupLimiter(middleware.LookupByEmail)
+	app.Get("/api/bookings/lookup", byIP, byEmail, h.LookupBooking)
+	app.Post("/api/bookings/lookup/cancel", byIP, byEmail, h.CancelBookingByCode)
+
 	bookings := app.Group("/api/bookings", middleware.AuthMiddleware(userSvc))
+	idem := middleware.Idempotency(idempotencySvc)
 
 	bookings.Get("/my", h.GetBookings)
 
-	bookings.Post("/", h.CreateBooking)
+	bookings.Post("/", middleware.RequireVerifiedEmail(), idem, h.CreateBooking)
 
 	// Admin Routes
-	bookings.Get("/all", middleware.VerifyAdmin(), h.GetAllBookings)
+	bookings.Get("/all", middleware.RequirePermission(domain.PermBookingsReadAll), h.GetAllBookings)
 
 	bookings.Get("/:booking_id", middleware.VerifyBookingOwner(bookingSvc), h.GetFullBooking)
+	bookings.Patch("/:booking_id", middleware.VerifyBookingOwner(bookingSvc), idem, h.ModifyStay)
 	bookings.Get("/:booking_id/addons", middleware.VerifyBookingOwner(bookingSvc), h.GetAddons)
-	bookings.Put("/:booking_id/addons", middleware.VerifyBookingOwner(bookingSvc), h.UpdateAddons)
-	bookings.Post("/:booking_id/pay", middleware.VerifyBookingOwner(bookingSvc), h.SimulatePayment)
+	bookings.Get("/:booking_id/history", middleware.VerifyBookingOwner(bookingSvc), h.GetStatusHistory)
+	bookings.Put("/:booking_id/addons", middleware.VerifyBookingOwner(bookingSvc), idem, h.UpdateAddons)
+	bookings.Post("/:booking_id/pay", middleware.VerifyBookingOwner(bookingSvc), idem, payHandler.PayBooking)
+	bookings.Get("/:booking_id/payments", middleware.VerifyBookingOwner(bookingSvc), payHandler.GetBookingPayments)
+	bookings.Post("/:booking_id/payments", middleware.VerifyBookingOwner(bookingSvc), idem, payHandler.PayBalance)
+	bookings.Post("/:booking_id/payments/record", middleware.RequirePermission(domain.PermPaymentsRecord), idem, payHandler.RecordPayment)
+	bookings.Post("/:booking_id/refunds", middleware.RequirePermission(domain.PermPaymentsRefund), idem, payHandler.RefundPayment)
+	bookings.Get("/:booking_id/folio", middleware.VerifyBookingOwner(bookingSvc), folioHandler.GetFolio)
+	bookings.Post("/:booking_id/folio/adjustments", middleware.RequirePermission(domain.PermFolioAdjust), idem, folioHandler.PostAdjustment)
+	bookings.Post("/:booking_id/cancel", middleware.VerifyBookingOwner(bookingSvc), idem, h.CancelBooking)
 
-	bookings.Patch("/:booking_id/status", middleware.VerifyAdmin(), h.UpdateStatus)
+	bookings.Patch("/:booking_id/status", middleware.RequirePermission(domain.PermBookingsUpdateStatus), idem, h.UpdateStatus)
 }
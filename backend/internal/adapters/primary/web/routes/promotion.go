@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/handlers"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/services"
+)
+
+func PromotionRoutes(app *fiber.App, h *handlers.PromotionHandler, userSvc *services.UserService) {
+	admin := app.Group("/api/promotions", middleware.AuthMiddleware(userSvc), middleware.RequirePermission(domain.PermPromotionsManage))
+
+	admin.Get("/", h.ListPromotions)
+	admin.Get("/:promotion_id", h.GetPromotion)
+	admin.Post("/", h.CreatePromotion)
+	admin.Put("/:promotion_id", h.UpdatePromotion)
+	admin.Delete("/:promotion_id", h.RemovePromotion)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/handlers"
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
 	"github.com/ingwrok/hotelBooking/internal/core/services"
 )
 
@@ -14,11 +15,17 @@ func RatePlanRoutes(app *fiber.App, h *handlers.RatePlanHandler, userSvc *servic
   ratePlans.Get("/:rate_plan_id", h.GetRatePlan)
   ratePlans.Get("/:rate_plan_id/room-types/:room_type_id", h.GetPrice)
   ratePlans.Get("/room-types/:room_type_id", h.ListRatePlansByRoomType)
+  ratePlans.Get("/:rate_plan_id/room-types/:room_type_id/calendar", h.GetCalendar)
+  ratePlans.Get("/:rate_plan_id/room-types/:room_type_id/occupancy", h.GetOccupancyPricing)
 
-  admin := ratePlans.Group("/", middleware.AuthMiddleware(userSvc), middleware.VerifyAdmin())
+  admin := ratePlans.Group("/", middleware.AuthMiddleware(userSvc), middleware.RequirePermission(domain.PermRatePlansManage))
   admin.Post("/", h.CreateRatePlan)
   admin.Put("/:rate_plan_id", h.UpdateRatePlan)
   admin.Delete("/:rate_plan_id", h.RemoveRatePlan)
   admin.Put("/:rate_plan_id/room-types/:room_type_id", h.UpdateRoomTypePrice)
   admin.Delete("/:rate_plan_id/room-types/:room_type_id", h.RemoveRoomTypePrice)
+  admin.Put("/:rate_plan_id/room-types/:room_type_id/calendar", h.SetCalendarPrice)
+  admin.Delete("/:rate_plan_id/room-types/:room_type_id/calendar", h.ClearCalendarPrice)
+  admin.Put("/:rate_plan_id/room-types/:room_type_id/occupancy", h.SetOccupancyPricing)
+  admin.Delete("/:rate_plan_id/room-types/:room_type_id/occupancy", h.RemoveOccupancyPricing)
 }
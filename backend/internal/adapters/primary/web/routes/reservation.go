@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/handlers"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
+	"github.com/ingwrok/hotelBooking/internal/core/services"
+)
+
+func ReservationRoutes(app *fiber.App, h *handlers.BookingHandler, payHandler *handlers.PaymentHandler, userSvc *services.UserService, bookingSvc *services.BookingService, idempotencySvc *services.IdempotencyService) {
+	reservations := app.Group("/api/reservations", middleware.AuthMiddleware(userSvc))
+	idem := middleware.Idempotency(idempotencySvc)
+
+	reservations.Post("/", middleware.RequireVerifiedEmail(), idem, h.CreateReservation)
+	reservations.Get("/:reservation_id", middleware.VerifyReservationOwner(bookingSvc), h.GetReservation)
+	reservations.Post("/:reservation_id/pay", middleware.VerifyReservationOwner(bookingSvc), idem, payHandler.PayReservation)
+}
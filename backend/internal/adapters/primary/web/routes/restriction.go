@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/handlers"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/services"
+)
+
+func RestrictionRoutes(app *fiber.App, h *handlers.RestrictionHandler, userSvc *services.UserService) {
+	admin := app.Group("/api/restrictions", middleware.AuthMiddleware(userSvc), middleware.RequirePermission(domain.PermRestrictionsManage))
+
+	admin.Get("/room-types/:room_type_id", h.ListRestrictionsByRoomType)
+	admin.Get("/:restriction_id", h.GetRestriction)
+	admin.Post("/", h.CreateRestriction)
+	admin.Put("/:restriction_id", h.UpdateRestriction)
+	admin.Delete("/:restriction_id", h.RemoveRestriction)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/handlers"
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
 	"github.com/ingwrok/hotelBooking/internal/core/services"
 )
 
@@ -15,11 +16,11 @@ func RoomRoutes(app *fiber.App, h *handlers.RoomHandler, userSvc *services.UserS
 	rooms.Post("/availability/count", h.CountAvailableRooms)
 	rooms.Post("/availability/find", h.FindAvailableRoom)
 
-	admin := rooms.Group("/", middleware.AuthMiddleware(userSvc), middleware.VerifyAdmin())
-	admin.Get("/:room_id/blocks", h.GetRoomBlocks)
-	admin.Post("/block", h.BlockRoom)
-	admin.Post("/:room_type_id", h.CreateRoom)
-	admin.Patch("/:room_id/status", h.ChangeRoomStatus)
-	admin.Delete("/blocks/:block_id", h.UnblockRoom)
-	admin.Delete("/:room_id", h.RemoveRoom)
+	staff := rooms.Group("/", middleware.AuthMiddleware(userSvc))
+	staff.Get("/:room_id/blocks", middleware.RequirePermission(domain.PermRoomsBlock), h.GetRoomBlocks)
+	staff.Post("/block", middleware.RequirePermission(domain.PermRoomsBlock), h.BlockRoom)
+	staff.Post("/:room_type_id", middleware.RequirePermission(domain.PermRoomsManage), h.CreateRoom)
+	staff.Patch("/:room_id/status", middleware.RequirePermission(domain.PermRoomsUpdateStatus), h.ChangeRoomStatus)
+	staff.Delete("/blocks/:block_id", middleware.RequirePermission(domain.PermRoomsBlock), h.UnblockRoom)
+	staff.Delete("/:room_id", middleware.RequirePermission(domain.PermRoomsManage), h.RemoveRoom)
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/handlers"
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
 	"github.com/ingwrok/hotelBooking/internal/core/services"
 )
 
@@ -14,7 +15,7 @@ func RoomTypeRoutes(app *fiber.App, h *handlers.RoomTypeHandler, userSvc *servic
 	roomTypes.Get("/:room_type_id", h.GetRoomType)
 	roomTypes.Get("/", h.ListRoomTypes)
 
-	admin := roomTypes.Group("", middleware.AuthMiddleware(userSvc), middleware.VerifyAdmin())
+	admin := roomTypes.Group("", middleware.AuthMiddleware(userSvc), middleware.RequirePermission(domain.PermRoomTypesManage))
 	admin.Post("/upload", h.UploadImage)
 	admin.Post("/", h.CreateRoomType)
 	admin.Patch("/:room_type_id", h.UpdateRoomType)
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/handlers"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/services"
+)
+
+func TaxRuleRoutes(app *fiber.App, h *handlers.TaxRuleHandler, userSvc *services.UserService) {
+	admin := app.Group("/api/tax-rules", middleware.AuthMiddleware(userSvc), middleware.RequirePermission(domain.PermTaxRulesManage))
+
+	admin.Get("/", h.ListTaxRules)
+	admin.Get("/:tax_rule_id", h.GetTaxRule)
+	admin.Post("/", h.CreateTaxRule)
+	admin.Put("/:tax_rule_id", h.UpdateTaxRule)
+	admin.Delete("/:tax_rule_id", h.RemoveTaxRule)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/handlers"
 	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
 	"github.com/ingwrok/hotelBooking/internal/core/services"
 )
 
@@ -11,13 +12,39 @@ func UserRoutes(app *fiber.App, h *handlers.UserHandler, userSvc *services.UserS
 	auth := app.Group("/api/auth")
 	auth.Post("/register", h.Register)
 	auth.Post("/login", h.Login)
+	auth.Post("/login/2fa", h.LoginTwoFactor)
+	auth.Post("/refresh", h.Refresh)
 	auth.Post("/logout", h.Logout)
+	auth.Post("/logout-all", middleware.AuthMiddleware(userSvc), h.LogoutAll)
+	auth.Post("/verify-email", h.VerifyEmail)
+	auth.Post("/verify-email/resend", middleware.AuthMiddleware(userSvc), h.ResendVerification)
+	auth.Post("/forgot-password", h.ForgotPassword)
+	auth.Post("/reset-password", h.ResetPassword)
+	auth.Post("/unlock", h.UnlockAccount)
+
+	twoFactor := app.Group("/api/auth/2fa", middleware.AuthMiddleware(userSvc))
+	twoFactor.Get("/", h.GetTwoFactorStatus)
+	twoFactor.Post("/setup", h.SetupTwoFactor)
+	twoFactor.Post("/confirm", h.ConfirmTwoFactor)
+	twoFactor.Post("/disable", h.DisableTwoFactor)
+	twoFactor.Post("/recovery-codes", h.RegenerateRecoveryCodes)
 
 	users := app.Group("/api/users", middleware.AuthMiddleware(userSvc))
 
 	users.Get("/:id", middleware.VerifyUser("id"), h.GetUser)
 	users.Put("/:id", middleware.VerifyUser("id"), h.UpdateUser)
 
-	users.Get("/", middleware.VerifyAdmin(), h.GetUsers)
-	users.Delete("/:id", middleware.VerifyAdmin(), h.DeleteUser)
+	users.Get("/", middleware.RequirePermission(domain.PermUsersRead), h.GetUsers)
+	users.Delete("/:id", middleware.RequirePermission(domain.PermUsersDelete), h.DeleteUser)
+	users.Post("/:id/roles", middleware.RequirePermission(domain.PermRolesManage), h.AssignRole)
+	users.Delete("/:id/roles/:role", middleware.RequirePermission(domain.PermRolesManage), h.RevokeRole)
+	users.Post("/:id/unlock", middleware.RequirePermission(domain.PermUsersManage), h.UnlockUser)
+	users.Delete("/:id/two-factor", middleware.RequirePermission(domain.PermUsersManage), h.ResetTwoFactor)
+
+	roles := app.Group("/api/roles", middleware.AuthMiddleware(userSvc), middleware.RequirePermission(domain.PermRolesManage))
+	roles.Get("/", h.GetRoles)
+	roles.Put("/:role/two-factor", h.SetRoleTwoFactor)
+
+	audit := app.Group("/api/audit-logs", middleware.AuthMiddleware(userSvc), middleware.RequirePermission(domain.PermAuditRead))
+	audit.Get("/", h.GetAuditLog)
 }
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/handlers"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/services"
+)
+
+func WaitlistRoutes(app *fiber.App, h *handlers.BookingHandler, userSvc *services.UserService) {
+	waitlist := app.Group("/api/waitlist", middleware.AuthMiddleware(userSvc))
+
+	waitlist.Post("/", middleware.RequireVerifiedEmail(), h.JoinWaitlist)
+	waitlist.Get("/my", h.GetMyWaitlist)
+	waitlist.Delete("/:entry_id", h.LeaveWaitlist)
+	waitlist.Post("/:entry_id/accept", h.AcceptWaitlistOffer)
+
+	// Admin Routes
+	waitlist.Get("/", middleware.RequirePermission(domain.PermWaitlistRead), h.ListWaitlist)
+}
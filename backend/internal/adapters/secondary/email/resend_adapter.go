@@ -37,17 +37,17 @@ func NewResendAdapter() *ResendAdapter {
 func (a *ResendAdapter) SendBookingConfirmation(ctx context.Context, booking *domain.BookingDetail, addons []*domain.BookingAddon) error {
 	// Construct Email Body
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Subject: Booking Confirmation #%d - %s\n\n", booking.BookingID, strings.ToUpper(booking.Status)))
+	sb.WriteString(fmt.Sprintf("Subject: Booking Confirmation %s - %s\n\n", booking.ConfirmationCode, strings.ToUpper(booking.Status)))
 	sb.WriteString(fmt.Sprintf("Dear %s,\n\nThank you for choosing our hotel!\n", booking.UserName))
 	sb.WriteString(fmt.Sprintf("Here are your booking details:\n\n"))
 
-	sb.WriteString(fmt.Sprintf("Booking ID:  #%d\n", booking.BookingID))
+	sb.WriteString(fmt.Sprintf("Booking Ref: %s\n", booking.ConfirmationCode))
 	sb.WriteString(fmt.Sprintf("Status:      %s\n", strings.ToUpper(booking.Status)))
 	sb.WriteString(fmt.Sprintf("Room Type:   %s\n", booking.RoomTypeName))
 	sb.WriteString(fmt.Sprintf("Rate Plan:   %s\n", booking.RatePlanName))
 	sb.WriteString(fmt.Sprintf("Check-in:    %s\n", booking.CheckInDate.Format("02 Jan 2006")))
 	sb.WriteString(fmt.Sprintf("Check-out:   %s\n", booking.CheckOutDate.Format("02 Jan 2006")))
-	sb.WriteString(fmt.Sprintf("Guests:      %d Adults\n", booking.NumAdults))
+	sb.WriteString(fmt.Sprintf("Guests:      %s\n", guests(booking)))
 
 	if booking.RoomNumber != "" {
 		sb.WriteString(fmt.Sprintf("Room Number: %s\n", booking.RoomNumber))
@@ -55,33 +55,106 @@ func (a *ResendAdapter) SendBookingConfirmation(ctx context.Context, booking *do
 	sb.WriteString("\n----------------------------------------\n")
 	sb.WriteString("PRICE BREAKDOWN\n")
 	sb.WriteString("----------------------------------------\n")
-	sb.WriteString(fmt.Sprintf("Room Charge:    THB %.2f\n", booking.RoomSubTotal))
+	sb.WriteString(fmt.Sprintf("Room Charge:    %s\n", booking.RoomSubTotal.Display()))
 
 	if len(addons) > 0 {
 		sb.WriteString("Add-ons:\n")
 		for _, ad := range addons {
-			sb.WriteString(fmt.Sprintf("- %s (x%d): THB %.2f\n", ad.AddonName, ad.Quantity, ad.PriceAtBooking*float64(ad.Quantity)))
+			sb.WriteString(addonLine(ad))
 		}
-		sb.WriteString(fmt.Sprintf("Addon Subtotal: THB %.2f\n", booking.AddonSubTotal))
+		sb.WriteString(fmt.Sprintf("Addon Subtotal: %s\n", booking.AddonSubTotal.Display()))
 	}
 
-	sb.WriteString(fmt.Sprintf("Taxes (7%%):     THB %.2f\n", booking.TaxesAmount))
+	if booking.DiscountAmount.IsPositive() {
+		sb.WriteString(fmt.Sprintf("Discount (%s): -%s\n", booking.PromoCode, booking.DiscountAmount.Display()))
+	}
+	for _, tax := range booking.Taxes {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", tax.Name, tax.Amount.Display()))
+	}
+	if len(booking.Taxes) == 0 && booking.TaxesAmount.IsPositive() {
+		sb.WriteString(fmt.Sprintf("Taxes & Fees:   %s\n", booking.TaxesAmount.Display()))
+	}
 	sb.WriteString("----------------------------------------\n")
-	sb.WriteString(fmt.Sprintf("TOTAL PRICE:    THB %.2f\n", booking.TotalPrice))
+	sb.WriteString(fmt.Sprintf("TOTAL PRICE:    %s\n", booking.TotalPrice.Display()))
 	sb.WriteString("----------------------------------------\n\n")
 
 	sb.WriteString("We look forward to welcoming you!\n")
 
 	body := sb.String()
 
-	logger.Info("-------- EMAIL CONTENT START --------")
-	fmt.Println(body)
-	logger.Info("-------- EMAIL CONTENT END --------")
+	return a.Send(ctx, &domain.EmailMessage{
+		To:      booking.Email,
+		Subject: fmt.Sprintf("Booking Confirmation %s", booking.ConfirmationCode),
+		Body:    body,
+	})
+}
+
+func (a *ResendAdapter) SendBookingCancellation(ctx context.Context, booking *domain.BookingDetail) error {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Subject: Booking Cancelled %s\n\n", booking.ConfirmationCode))
+	sb.WriteString(fmt.Sprintf("Dear %s,\n\nYour booking has been cancelled.\n\n", booking.UserName))
+
+	sb.WriteString(fmt.Sprintf("Booking Ref: %s\n", booking.ConfirmationCode))
+	sb.WriteString(fmt.Sprintf("Room Type:   %s\n", booking.RoomTypeName))
+	sb.WriteString(fmt.Sprintf("Rate Plan:   %s\n", booking.RatePlanName))
+	sb.WriteString(fmt.Sprintf("Check-in:    %s\n", booking.CheckInDate.Format("02 Jan 2006")))
+	sb.WriteString(fmt.Sprintf("Check-out:   %s\n", booking.CheckOutDate.Format("02 Jan 2006")))
+	if booking.CancellationReason != "" {
+		sb.WriteString(fmt.Sprintf("Reason:      %s\n", booking.CancellationReason))
+	}
+	sb.WriteString("\n----------------------------------------\n")
+	sb.WriteString(fmt.Sprintf("Cancellation Fee: %s\n", booking.CancellationFee.Display()))
+	sb.WriteString("----------------------------------------\n\n")
+
+	sb.WriteString("We hope to welcome you another time.\n")
+
+	body := sb.String()
+
+	return a.Send(ctx, &domain.EmailMessage{
+		To:      booking.Email,
+		Subject: fmt.Sprintf("Booking Cancelled %s", booking.ConfirmationCode),
+		Body:    body,
+	})
+}
+
+func (a *ResendAdapter) SendReservationConfirmation(ctx context.Context, res *domain.ReservationDetail) error {
+	body, err := reservationBody(res)
+	if err != nil {
+		return err
+	}
+
+	return a.Send(ctx, &domain.EmailMessage{
+		To:      res.Email,
+		Subject: fmt.Sprintf("Reservation Confirmation #%d", res.ReservationID),
+		Body:    body,
+	})
+}
+
+func (a *ResendAdapter) SendWaitlistOffer(ctx context.Context, booking *domain.BookingDetail, entry *domain.WaitlistEntry) error {
+	body := waitlistOfferBody(booking, entry)
+
+	recipient := entry.Email
+	if recipient == "" {
+		recipient = booking.Email
+	}
+	return a.Send(ctx, &domain.EmailMessage{
+		To:      recipient,
+		Subject: fmt.Sprintf("A room is available - Booking %s", booking.ConfirmationCode),
+		Body:    body,
+	})
+}
+
+func (a *ResendAdapter) Send(ctx context.Context, msg *domain.EmailMessage) error {
+	// Emails carry secret links and confirmation codes, so they are only
+	// printed when there is no client to deliver them.
 	if a.client == nil {
+		logger.Info("-------- EMAIL CONTENT START --------")
+		fmt.Printf("To: %s\nSubject: %s\n\n%s\n", msg.To, msg.Subject, msg.Body)
+		logger.Info("-------- EMAIL CONTENT END --------")
 		return nil
 	}
 
-	recipient := booking.Email
+	recipient := msg.To
 	if recipient == "" {
 		recipient = os.Getenv("SMTP_DEBUG_RECIPIENT")
 	}
@@ -89,8 +162,8 @@ func (a *ResendAdapter) SendBookingConfirmation(ctx context.Context, booking *do
 	params := &resend.SendEmailRequest{
 		From:    a.from,
 		To:      []string{recipient},
-		Subject: fmt.Sprintf("Booking Confirmation #%d", booking.BookingID),
-		Text:    body,
+		Subject: msg.Subject,
+		Text:    msg.Body,
 	}
 
 	_, err := a.client.Emails.SendWithContext(ctx, params)
@@ -0,0 +1,91 @@
+package email
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+// reservationBody lists every room of a reservation with one total, so a
+// multi-room booking gets a single confirmation.
+func reservationBody(res *domain.ReservationDetail) (string, error) {
+	total, paid, due, err := res.Totals()
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Subject: Reservation Confirmation #%d\n\n", res.ReservationID))
+	sb.WriteString(fmt.Sprintf("Dear %s,\n\nThank you for choosing our hotel!\n", res.UserName))
+	sb.WriteString("Here are your reservation details:\n\n")
+
+	sb.WriteString(fmt.Sprintf("Reservation: #%d\n", res.ReservationID))
+	if len(res.Bookings) > 0 {
+		sb.WriteString(fmt.Sprintf("Check-in:    %s\n", res.Bookings[0].CheckInDate.Format("02 Jan 2006")))
+		sb.WriteString(fmt.Sprintf("Check-out:   %s\n", res.Bookings[0].CheckOutDate.Format("02 Jan 2006")))
+	}
+	sb.WriteString(fmt.Sprintf("Rooms:       %d\n", len(res.Bookings)))
+
+	for i, booking := range res.Bookings {
+		sb.WriteString("\n----------------------------------------\n")
+		sb.WriteString(fmt.Sprintf("ROOM %d - Confirmation %s (%s)\n", i+1, booking.ConfirmationCode, strings.ToUpper(booking.Status)))
+		sb.WriteString("----------------------------------------\n")
+		sb.WriteString(fmt.Sprintf("Room Type:   %s\n", booking.RoomTypeName))
+		sb.WriteString(fmt.Sprintf("Rate Plan:   %s\n", booking.RatePlanName))
+		sb.WriteString(fmt.Sprintf("Guests:      %s\n", guests(booking)))
+		if booking.RoomNumber != "" {
+			sb.WriteString(fmt.Sprintf("Room Number: %s\n", booking.RoomNumber))
+		}
+		sb.WriteString(fmt.Sprintf("Room Charge:    %s\n", booking.RoomSubTotal.Display()))
+		for _, ad := range booking.BookingAddon {
+			sb.WriteString(addonLine(ad))
+		}
+		if booking.DiscountAmount.IsPositive() {
+			sb.WriteString(fmt.Sprintf("Discount (%s): -%s\n", booking.PromoCode, booking.DiscountAmount.Display()))
+		}
+		for _, tax := range booking.Taxes {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", tax.Name, tax.Amount.Display()))
+		}
+		sb.WriteString(fmt.Sprintf("Room Total:     %s\n", booking.TotalPrice.Display()))
+	}
+
+	sb.WriteString("\n----------------------------------------\n")
+	sb.WriteString(fmt.Sprintf("TOTAL PRICE:    %s\n", total.Display()))
+	sb.WriteString(fmt.Sprintf("PAID:           %s\n", paid.Display()))
+	sb.WriteString(fmt.Sprintf("BALANCE DUE:    %s\n", due.Display()))
+	sb.WriteString("----------------------------------------\n\n")
+
+	sb.WriteString("We look forward to welcoming you!\n")
+	return sb.String(), nil
+}
+
+// guests describes the party, e.g. "2 Adults, 1 Child (age 5)".
+func guests(booking *domain.BookingDetail) string {
+	s := fmt.Sprintf("%d Adults", booking.NumAdults)
+	switch len(booking.ChildAges) {
+	case 0:
+		return s
+	case 1:
+		return s + fmt.Sprintf(", 1 Child (age %d)", booking.ChildAges[0])
+	}
+
+	ages := make([]string, len(booking.ChildAges))
+	for i, age := range booking.ChildAges {
+		ages[i] = fmt.Sprint(age)
+	}
+	return s + fmt.Sprintf(", %d Children (ages %s)", len(booking.ChildAges), strings.Join(ages, ", "))
+}
+
+// addonLine shows how an addon was charged, e.g.
+// "- Breakfast: THB 350.00 x 2 guests x 3 nights = THB 2100.00".
+func addonLine(ad *domain.BookingAddon) string {
+	s := fmt.Sprintf("- %s: %s = %s", ad.AddonName, ad.Breakdown(), ad.Total().Display())
+	if !ad.ServiceDate.IsZero() {
+		s += fmt.Sprintf(" on %s", ad.ServiceDate.Format("02 Jan 2006"))
+	}
+	if ad.SlotName != "" {
+		s += fmt.Sprintf(" (%s)", ad.SlotName)
+	}
+	return s + "\n"
+}
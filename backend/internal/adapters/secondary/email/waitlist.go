@@ -0,0 +1,31 @@
+package email
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+// waitlistOfferBody tells a waitlisted guest which room is held for them and
+// until when.
+func waitlistOfferBody(booking *domain.BookingDetail, entry *domain.WaitlistEntry) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Subject: A room is available - Booking %s\n\n", booking.ConfirmationCode))
+	sb.WriteString(fmt.Sprintf("Dear %s,\n\nGood news! A room you were waiting for has become available.\n", booking.UserName))
+	sb.WriteString("We are holding it for you:\n\n")
+
+	sb.WriteString(fmt.Sprintf("Booking Ref: %s\n", booking.ConfirmationCode))
+	sb.WriteString(fmt.Sprintf("Room Type:   %s\n", booking.RoomTypeName))
+	sb.WriteString(fmt.Sprintf("Rate Plan:   %s\n", booking.RatePlanName))
+	sb.WriteString(fmt.Sprintf("Check-in:    %s\n", booking.CheckInDate.Format("02 Jan 2006")))
+	sb.WriteString(fmt.Sprintf("Check-out:   %s\n", booking.CheckOutDate.Format("02 Jan 2006")))
+	sb.WriteString(fmt.Sprintf("Guests:      %s\n", guests(booking)))
+	sb.WriteString("\n----------------------------------------\n")
+	sb.WriteString(fmt.Sprintf("TOTAL PRICE:    %s\n", booking.TotalPrice.Display()))
+	sb.WriteString(fmt.Sprintf("HELD UNTIL:     %s\n", entry.OfferExpiresAt.Format("02 Jan 2006 15:04")))
+	sb.WriteString("----------------------------------------\n\n")
+
+	sb.WriteString("Accept the offer before then to keep the room; after that it goes to the next guest in line.\n")
+	return sb.String()
+}
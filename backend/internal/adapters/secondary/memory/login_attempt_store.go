@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+)
+
+// LoginAttemptStore keeps failed logins in process memory. Counts are lost on
+// restart and not shared between instances.
+type LoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]domain.LoginAttempt
+}
+
+func NewLoginAttemptStore() ports.LoginAttemptStore {
+	return &LoginAttemptStore{attempts: make(map[string]domain.LoginAttempt)}
+}
+
+func (s *LoginAttemptStore) GetLoginAttempt(ctx context.Context, key string) (*domain.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.attempts[key]
+	if !ok {
+		return nil, fmt.Errorf("login attempts for %s: %w", key, errs.ErrNotFound)
+	}
+	return &a, nil
+}
+
+func (s *LoginAttemptStore) RecordLoginFailure(ctx context.Context, key string, window time.Duration) (*domain.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	a, ok := s.attempts[key]
+	if !ok || a.FirstFailedAt.Before(now.Add(-window)) {
+		a.Key = key
+		a.Failures = 0
+		a.FirstFailedAt = now
+	}
+	a.Failures++
+	a.LastFailedAt = now
+	s.attempts[key] = a
+
+	return &a, nil
+}
+
+func (s *LoginAttemptStore) LockLogin(ctx context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.attempts[key]
+	if !ok {
+		return fmt.Errorf("login attempts for %s: %w", key, errs.ErrNotFound)
+	}
+	a.LockedUntil = until
+	s.attempts[key] = a
+	return nil
+}
+
+func (s *LoginAttemptStore) ResetLoginAttempts(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
+
+func (s *LoginAttemptStore) DeleteStaleLoginAttempts(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var deleted int64
+	for key, a := range s.attempts {
+		if a.LastFailedAt.Before(before) && !a.Locked(now) {
+			delete(s.attempts, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,278 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/common/logger"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"go.uber.org/zap"
+)
+
+// Payment method tokens understood by the mock provider. An empty method is
+// treated as MockMethodSuccess so the frontend can pay without a card form.
+const (
+	MockMethodSuccess = "mock_success"
+	MockMethodDecline = "mock_decline"
+	MockMethodDelayed = "mock_delayed"
+)
+
+// SignatureHeader carries the webhook signature, "t=<unix time>,v1=<hex hmac>".
+const SignatureHeader = "X-Webhook-Signature"
+
+// signatureTolerance is how old a signed webhook may be before it is rejected as a replay.
+const signatureTolerance = 5 * time.Minute
+
+type mockIntent struct {
+	amount   domain.Money
+	method   string
+	captured bool
+	refunded domain.Money
+}
+
+type webhookPayload struct {
+	Type          string       `json:"type"`
+	ProviderRef   string       `json:"providerRef"`
+	Amount        domain.Money `json:"amount"`
+	Currency      string       `json:"currency"`
+	FailureReason string       `json:"failureReason,omitempty"`
+}
+
+// MockGateway is an in-process payment provider for local development. It never
+// moves money; capturing an intent posts a signed webhook back to the API the
+// same way a real provider would, right away or after a delay depending on the
+// payment method.
+type MockGateway struct {
+	secret     []byte
+	webhookURL string
+	delay      time.Duration
+	client     *http.Client
+
+	mu      sync.Mutex
+	intents map[string]*mockIntent
+}
+
+func NewMockGateway(secret, webhookURL string, delay time.Duration) *MockGateway {
+	if secret == "" {
+		logger.Warn("payment webhook secret missing. Mock webhooks will not verify.")
+	}
+	return &MockGateway{
+		secret:     []byte(secret),
+		webhookURL: webhookURL,
+		delay:      delay,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		intents:    make(map[string]*mockIntent),
+	}
+}
+
+func (g *MockGateway) Name() string {
+	return "mock"
+}
+
+func (g *MockGateway) CreateIntent(ctx context.Context, req *domain.PaymentIntentRequest) (*domain.PaymentIntent, error) {
+	method := req.PaymentMethod
+	if method == "" {
+		method = MockMethodSuccess
+	}
+	switch method {
+	case MockMethodSuccess, MockMethodDecline, MockMethodDelayed:
+	default:
+		return nil, fmt.Errorf("mock gateway: unknown payment method %q", method)
+	}
+
+	ref, err := randomRef("mock_pi_")
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	g.intents[ref] = &mockIntent{
+		amount:   req.Amount,
+		method:   method,
+		refunded: domain.NewMoney(0, req.Amount.Currency),
+	}
+	g.mu.Unlock()
+
+	logger.Info("mock payment intent created", zap.String("ref", ref), zap.Int("BookingID", req.BookingID), zap.Int("ReservationID", req.ReservationID), zap.String("method", method))
+	return &domain.PaymentIntent{
+		ProviderRef:  ref,
+		ClientSecret: ref + "_secret",
+		Status:       "requires_capture",
+	}, nil
+}
+
+func (g *MockGateway) Capture(ctx context.Context, providerRef string) error {
+	g.mu.Lock()
+	intent, ok := g.intents[providerRef]
+	if ok && intent.captured {
+		ok = false
+	}
+	if ok {
+		intent.captured = true
+	}
+	g.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("mock gateway: no capturable intent %s", providerRef)
+	}
+
+	event := webhookPayload{
+		Type:        domain.PaymentEventSucceeded,
+		ProviderRef: providerRef,
+		Amount:      intent.amount,
+	}
+
+	switch intent.method {
+	case MockMethodDecline:
+		event.Type = domain.PaymentEventFailed
+		event.FailureReason = "card declined"
+		go g.deliver(event, 0)
+	case MockMethodDelayed:
+		go g.deliver(event, g.delay)
+	default:
+		go g.deliver(event, 0)
+	}
+
+	return nil
+}
+
+func (g *MockGateway) Refund(ctx context.Context, providerRef string, amount domain.Money) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	intent, ok := g.intents[providerRef]
+	if !ok || !intent.captured || intent.method == MockMethodDecline {
+		return fmt.Errorf("mock gateway: no captured payment %s", providerRef)
+	}
+	refunded, err := intent.refunded.Add(amount)
+	if err != nil {
+		return fmt.Errorf("mock gateway: %w", err)
+	}
+	over, err := refunded.GreaterThan(intent.amount)
+	if err != nil {
+		return fmt.Errorf("mock gateway: %w", err)
+	}
+	if over {
+		return fmt.Errorf("mock gateway: refund exceeds captured amount for %s", providerRef)
+	}
+	intent.refunded = refunded
+
+	go g.deliver(webhookPayload{
+		Type:        domain.PaymentEventRefunded,
+		ProviderRef: providerRef,
+		Amount:      amount,
+	}, 0)
+	return nil
+}
+
+func (g *MockGateway) VerifyWebhook(payload []byte, signature string) (*domain.PaymentEvent, error) {
+	var ts, sig string
+	for _, part := range strings.Split(signature, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return nil, errors.New("malformed webhook signature")
+	}
+	if age := time.Since(time.Unix(unix, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return nil, errors.New("webhook signature expired")
+	}
+
+	expected, err := hex.DecodeString(sig)
+	if err != nil || len(g.secret) == 0 || !hmac.Equal(expected, g.sign(ts, payload)) {
+		return nil, errors.New("webhook signature mismatch")
+	}
+
+	var p webhookPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	if p.Currency != "" {
+		p.Amount.Currency = p.Currency
+	}
+
+	return &domain.PaymentEvent{
+		Type:          p.Type,
+		ProviderRef:   p.ProviderRef,
+		Amount:        p.Amount,
+		FailureReason: p.FailureReason,
+	}, nil
+}
+
+// SignPayload returns the signature header value for a payload, for replaying
+// webhooks by hand during development.
+func (g *MockGateway) SignPayload(payload []byte, at time.Time) string {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(g.sign(ts, payload))
+}
+
+func (g *MockGateway) sign(ts string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func (g *MockGateway) deliver(event webhookPayload, after time.Duration) {
+	if after > 0 {
+		time.Sleep(after)
+	}
+
+	event.Currency = event.Amount.Currency
+	body, err := json.Marshal(event)
+	if err != nil {
+		logger.ErrorErr(err, "mock webhook marshal failed")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		logger.ErrorErr(err, "mock webhook request failed")
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, g.SignPayload(body, time.Now()))
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		logger.ErrorErr(err, "mock webhook delivery failed", zap.String("ref", event.ProviderRef))
+		return
+	}
+	defer resp.Body.Close()
+
+	logger.Info("mock webhook delivered",
+		zap.String("type", event.Type),
+		zap.String("ref", event.ProviderRef),
+		zap.Int("status", resp.StatusCode),
+	)
+}
+
+func randomRef(prefix string) (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,64 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql/model"
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type AccountTokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewAccountTokenRepository(db *sqlx.DB) ports.AccountTokenRepository {
+	return &AccountTokenRepository{db: db}
+}
+
+func (r *AccountTokenRepository) CreateAccountToken(ctx context.Context, token *domain.AccountToken) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qVoid := `UPDATE account_tokens SET used_at = NOW() WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`
+	if _, err := tx.ExecContext(ctx, qVoid, token.UserID, token.Purpose); err != nil {
+		return err
+	}
+
+	q := `INSERT INTO account_tokens (user_id, purpose, token_hash, email, expires_at)
+				VALUES ($1, $2, $3, $4, $5)
+				RETURNING token_id, created_at`
+	err = tx.QueryRowContext(ctx, q, token.UserID, token.Purpose, token.TokenHash, token.Email, token.ExpiresAt).
+		Scan(&token.TokenID, &token.CreatedAt)
+	if err != nil {
+		if hasPgCode(err, pgForeignKeyViolation) {
+			return fmt.Errorf("user id %d: %w", token.UserID, errs.ErrNotFound)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *AccountTokenRepository) ConsumeAccountToken(ctx context.Context, purpose, tokenHash string) (*domain.AccountToken, error) {
+	var m model.AccountToken
+	q := `UPDATE account_tokens SET used_at = NOW()
+				WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+				RETURNING token_id, user_id, purpose, token_hash, email, created_at, expires_at, used_at`
+
+	if err := r.db.GetContext(ctx, &m, q, tokenHash, purpose); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%s token: %w", purpose, errs.ErrNotFound)
+		}
+		return nil, err
+	}
+
+	return m.ToDomain(), nil
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql/model"
 	"github.com/ingwrok/hotelBooking/internal/common/errs"
 	"github.com/ingwrok/hotelBooking/internal/core/domain"
 	"github.com/ingwrok/hotelBooking/internal/core/ports"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type AddonRepository struct {
@@ -124,12 +126,14 @@ func (r *AddonRepository) DeleteAddonCategory(ctx context.Context, categoryID in
 
 func (r *AddonRepository) CreateAddon(ctx context.Context, addon *domain.Addon) error {
 	m := model.FromDomainAddon(addon)
-	q := `INSERT INTO addons (category_id, name, description, price, unit_name, picture_url)
-	      VALUES ($1, $2, $3, $4, $5, $6)
+	q := `INSERT INTO addons (category_id, name, description, price, unit_name, picture_url, is_extra_bed,
+					charge_unit, requires_service_date, daily_capacity)
+	      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 				RETURNING addon_id`
 
 	var newID int
-	err := r.db.QueryRowContext(ctx, q, m.CategoryID, m.Name, m.Description, m.Price, m.UnitName, m.PictureURL).Scan(&newID)
+	err := r.db.QueryRowContext(ctx, q, m.CategoryID, m.Name, m.Description, m.Price, m.UnitName, m.PictureURL, m.IsExtraBed,
+		m.ChargeUnit, m.RequiresServiceDate, m.DailyCapacity).Scan(&newID)
 	if err != nil {
 		return err
 	}
@@ -166,8 +170,12 @@ func (r *AddonRepository) UpdateAddon(ctx context.Context, addon *domain.Addon)
 					description = $3,
 					price = $4,
 					unit_name = $5,
-					picture_url = $6
-				WHERE addon_id = $7`
+					picture_url = $6,
+					is_extra_bed = $7,
+					charge_unit = $8,
+					requires_service_date = $9,
+					daily_capacity = $10
+				WHERE addon_id = $11`
 
 	result, err := r.db.ExecContext(ctx, q,
 		m.CategoryID,
@@ -176,6 +184,10 @@ func (r *AddonRepository) UpdateAddon(ctx context.Context, addon *domain.Addon)
 		m.Price,
 		m.UnitName,
 		m.PictureURL,
+		m.IsExtraBed,
+		m.ChargeUnit,
+		m.RequiresServiceDate,
+		m.DailyCapacity,
 		m.AddonID,
 	)
 	if err != nil {
@@ -196,7 +208,8 @@ func (r *AddonRepository) UpdateAddon(ctx context.Context, addon *domain.Addon)
 
 func (r *AddonRepository) GetAddonByID(ctx context.Context, addonID int) (*domain.Addon, error) {
 	var m model.Addon
-	q := `SELECT addon_id, category_id, name, description, price, unit_name, picture_url
+	q := `SELECT addon_id, category_id, name, description, price, unit_name, picture_url, is_extra_bed,
+					charge_unit, requires_service_date, daily_capacity
 	      FROM addons
 				WHERE addon_id = $1`
 
@@ -208,12 +221,18 @@ func (r *AddonRepository) GetAddonByID(ctx context.Context, addonID int) (*domai
 		return nil, err
 	}
 
-	return m.ToDomain(), nil
+	addon := m.ToDomain()
+	addon.Slots, err = r.GetAddonSlots(ctx, addonID)
+	if err != nil {
+		return nil, err
+	}
+	return addon, nil
 }
 
 func (r *AddonRepository) GetAllAddons(ctx context.Context) ([]*domain.Addon, error) {
 	var models []model.Addon
-	q := `SELECT addon_id, category_id, name, description, price, unit_name, picture_url
+	q := `SELECT addon_id, category_id, name, description, price, unit_name, picture_url, is_extra_bed,
+					charge_unit, requires_service_date, daily_capacity
 	      FROM addons`
 
 	err := r.db.SelectContext(ctx, &models, q)
@@ -231,7 +250,8 @@ func (r *AddonRepository) GetAllAddons(ctx context.Context) ([]*domain.Addon, er
 
 func (r *AddonRepository) GetAddonByCategoryID(ctx context.Context, categoryID int) ([]*domain.Addon, error) {
 	var models []model.Addon
-	q := `SELECT addon_id, category_id, name, description, price, unit_name, picture_url
+	q := `SELECT addon_id, category_id, name, description, price, unit_name, picture_url, is_extra_bed,
+					charge_unit, requires_service_date, daily_capacity
 	      FROM addons
 				WHERE category_id = $1`
 
@@ -247,3 +267,213 @@ func (r *AddonRepository) GetAddonByCategoryID(ctx context.Context, categoryID i
 
 	return addons, nil
 }
+
+func (r *AddonRepository) CreateAddonSlot(ctx context.Context, slot *domain.AddonSlot) error {
+	m := model.FromDomainAddonSlot(slot)
+	q := `INSERT INTO addon_slots (addon_id, name, capacity)
+	      VALUES ($1, $2, $3)
+				RETURNING slot_id`
+
+	err := r.db.QueryRowContext(ctx, q, m.AddonID, m.Name, m.Capacity).Scan(&slot.SlotID)
+	if err != nil {
+		if hasPgCode(err, pgForeignKeyViolation) {
+			return fmt.Errorf("addon id %d: %w", m.AddonID, errs.ErrNotFound)
+		}
+		if hasPgCode(err, pgUniqueViolation) {
+			return fmt.Errorf("slot %q of addon %d: %w", m.Name, m.AddonID, errs.ErrConflict)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *AddonRepository) UpdateAddonSlot(ctx context.Context, slot *domain.AddonSlot) error {
+	m := model.FromDomainAddonSlot(slot)
+	q := `UPDATE addon_slots
+	      SET name = $1,
+					capacity = $2
+				WHERE slot_id = $3 AND addon_id = $4`
+
+	result, err := r.db.ExecContext(ctx, q, m.Name, m.Capacity, m.SlotID, m.AddonID)
+	if err != nil {
+		if hasPgCode(err, pgUniqueViolation) {
+			return fmt.Errorf("slot %q of addon %d: %w", m.Name, m.AddonID, errs.ErrConflict)
+		}
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("slot id %d of addon %d: %w", m.SlotID, m.AddonID, errs.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *AddonRepository) DeleteAddonSlot(ctx context.Context, addonID, slotID int) error {
+	q := `DELETE FROM addon_slots WHERE slot_id = $1 AND addon_id = $2`
+
+	result, err := r.db.ExecContext(ctx, q, slotID, addonID)
+	if err != nil {
+		if hasPgCode(err, pgForeignKeyViolation) {
+			return fmt.Errorf("slot id %d is booked: %w", slotID, errs.ErrConflict)
+		}
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("slot id %d of addon %d: %w", slotID, addonID, errs.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *AddonRepository) GetAddonSlots(ctx context.Context, addonID int) ([]*domain.AddonSlot, error) {
+	var models []model.AddonSlot
+	q := `SELECT slot_id, addon_id, name, capacity
+	      FROM addon_slots
+				WHERE addon_id = $1
+				ORDER BY name`
+
+	if err := r.db.SelectContext(ctx, &models, q, addonID); err != nil {
+		return nil, err
+	}
+
+	slots := make([]*domain.AddonSlot, len(models))
+	for i, m := range models {
+		slots[i] = m.ToDomain()
+	}
+	return slots, nil
+}
+
+// addonUsageQuery sums the units active bookings hold per addon, slot and day
+// between $2 and $3, leaving out booking $4. The days a line uses follow
+// domain.BookingAddon.ServiceDays and the units DailyUnits.
+const addonUsageQuery = `
+	SELECT ba.addon_id, COALESCE(ba.slot_id, 0) AS slot_id, d.day,
+		SUM(ba.quantity * GREATEST(ba.guests, 1)) AS units
+	FROM booking_addons ba
+	JOIN bookings b ON b.booking_id = ba.booking_id
+	CROSS JOIN LATERAL (
+		SELECT ba.service_date AS day
+		WHERE ba.service_date IS NOT NULL
+		UNION ALL
+		SELECT g::date FROM generate_series(b.check_in_date, b.check_out_date - 1, INTERVAL '1 day') AS g
+		WHERE ba.service_date IS NULL AND ba.charge_unit IN ('per_night', 'per_person_per_night')
+		UNION ALL
+		SELECT b.check_in_date
+		WHERE ba.service_date IS NULL AND ba.charge_unit NOT IN ('per_night', 'per_person_per_night')
+	) AS d
+	WHERE ba.addon_id = ANY($1)
+		AND ba.booking_id <> $4
+		AND b.status NOT IN ('cancelled', 'expired', 'no-show')
+		AND d.day BETWEEN $2::date AND $3::date
+	GROUP BY ba.addon_id, COALESCE(ba.slot_id, 0), d.day`
+
+func (r *AddonRepository) GetAddonUsage(ctx context.Context, addonID int, from, to time.Time) ([]*domain.AddonUsage, error) {
+	var models []model.AddonUsage
+	err := r.db.SelectContext(ctx, &models, addonUsageQuery, pq.Int64Array{int64(addonID)}, from, to, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]*domain.AddonUsage, len(models))
+	for i, m := range models {
+		usage[i] = m.ToDomain()
+	}
+	return usage, nil
+}
+
+// addonDay keys capacity by addon, slot (0 for the whole addon) and day.
+type addonDay struct {
+	addonID int
+	slotID  int
+	day     string
+}
+
+// claimAddonCapacity checks, with the addons locked, that the lines still fit
+// the daily stock and slot capacity left on each day they are served. Lines
+// already stored for bookingID are not counted against them.
+func claimAddonCapacity(ctx context.Context, tx *sqlx.Tx, bookingID int, lines []*domain.BookingAddon, checkIn, checkOut time.Time) error {
+	demand := make(map[addonDay]int)
+	var ids pq.Int64Array
+	var from, to time.Time
+	for _, line := range lines {
+		ids = append(ids, int64(line.AddonID))
+		for _, day := range line.ServiceDays(checkIn, checkOut) {
+			if from.IsZero() || day.Before(from) {
+				from = day
+			}
+			if day.After(to) {
+				to = day
+			}
+			demand[addonDay{line.AddonID, 0, day.Format("2006-01-02")}] += line.DailyUnits()
+			if line.SlotID > 0 {
+				demand[addonDay{line.AddonID, line.SlotID, day.Format("2006-01-02")}] += line.DailyUnits()
+			}
+		}
+	}
+	if len(demand) == 0 {
+		return nil
+	}
+
+	var addons []model.Addon
+	q := `SELECT addon_id, name, daily_capacity FROM addons WHERE addon_id = ANY($1) ORDER BY addon_id FOR UPDATE`
+	if err := tx.SelectContext(ctx, &addons, q, ids); err != nil {
+		return err
+	}
+	var slots []model.AddonSlot
+	q = `SELECT slot_id, addon_id, name, capacity FROM addon_slots WHERE addon_id = ANY($1)`
+	if err := tx.SelectContext(ctx, &slots, q, ids); err != nil {
+		return err
+	}
+
+	capacity := make(map[addonDay]int)
+	names := make(map[addonDay]string)
+	for _, a := range addons {
+		if a.DailyCapacity != nil {
+			capacity[addonDay{a.AddonID, 0, ""}] = *a.DailyCapacity
+		}
+		names[addonDay{a.AddonID, 0, ""}] = a.Name
+	}
+	for _, s := range slots {
+		capacity[addonDay{s.AddonID, s.SlotID, ""}] = s.Capacity
+		names[addonDay{s.AddonID, s.SlotID, ""}] = s.Name
+	}
+	if len(capacity) == 0 {
+		return nil
+	}
+
+	var usage []model.AddonUsage
+	if err := tx.SelectContext(ctx, &usage, addonUsageQuery, ids, from, to, bookingID); err != nil {
+		return err
+	}
+	used := make(map[addonDay]int)
+	for _, u := range usage {
+		day := u.Date.Format("2006-01-02")
+		used[addonDay{u.AddonID, 0, day}] += u.Units
+		if u.SlotID > 0 {
+			used[addonDay{u.AddonID, u.SlotID, day}] += u.Units
+		}
+	}
+
+	for key, units := range demand {
+		limit, ok := capacity[addonDay{key.addonID, key.slotID, ""}]
+		if !ok {
+			continue
+		}
+		if used[key]+units > limit {
+			name := names[addonDay{key.addonID, 0, ""}]
+			if key.slotID > 0 {
+				name += " " + names[addonDay{key.addonID, key.slotID, ""}]
+			}
+			return fmt.Errorf("%s on %s, %d left: %w", name, key.day, max(limit-used[key], 0), errs.ErrFullyBooked)
+		}
+	}
+	return nil
+}
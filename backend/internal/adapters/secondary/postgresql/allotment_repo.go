@@ -0,0 +1,299 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql/model"
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type AllotmentRepository struct {
+	db *sqlx.DB
+}
+
+func NewAllotmentRepository(db *sqlx.DB) ports.AllotmentRepository {
+	return &AllotmentRepository{db: db}
+}
+
+const allotmentColumns = `allotment_id, group_code, name, room_type_id, start_date, end_date, rooms_allotted, cutoff_date, is_active, created_at, updated_at`
+
+// allotmentPickup counts, for group allotment ga and night n.stay_date, the
+// rooms the group's live bookings occupy.
+const allotmentPickup = `(
+	SELECT COUNT(*) FROM bookings gb
+	WHERE gb.allotment_id = ga.allotment_id
+		AND gb.status NOT IN ('cancelled', 'expired', 'no-show')
+		AND gb.check_in_date <= n.stay_date
+		AND gb.check_out_date > n.stay_date
+)`
+
+// allotmentHolds returns per room type the most rooms that group allotments
+// still hold back from the public on any night between $1 and $2. Picked up
+// rooms are already booked, so only the unclaimed rest is held, and nothing is
+// held once an allotment reaches its cutoff date.
+const allotmentHolds = `
+	SELECT held.room_type_id, MAX(held.rooms) AS rooms
+	FROM (
+		SELECT ga.room_type_id, n.stay_date,
+			SUM(GREATEST(ga.rooms_allotted - ` + allotmentPickup + `, 0)) AS rooms
+		FROM group_allotments ga
+		JOIN generate_series($1::date, $2::date - 1, INTERVAL '1 day') AS n(stay_date)
+			ON n.stay_date >= ga.start_date AND n.stay_date < ga.end_date
+		WHERE ga.is_active AND ga.cutoff_date > CURRENT_DATE
+		GROUP BY ga.room_type_id, n.stay_date
+	) held
+	GROUP BY held.room_type_id`
+
+func (r *AllotmentRepository) CreateAllotment(ctx context.Context, allotment *domain.GroupAllotment) error {
+	m := model.FromDomainGroupAllotment(allotment)
+
+	q := `INSERT INTO group_allotments (group_code, name, room_type_id, start_date, end_date, rooms_allotted, cutoff_date, is_active)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+				RETURNING allotment_id, created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, q, m.GroupCode, m.Name, m.RoomTypeID, m.StartDate, m.EndDate,
+		m.RoomsAllotted, m.CutoffDate, m.IsActive).Scan(&allotment.AllotmentID, &allotment.CreatedAt, &allotment.UpdatedAt)
+	if err != nil {
+		return allotmentWriteError(err, m)
+	}
+
+	return nil
+}
+
+func (r *AllotmentRepository) UpdateAllotment(ctx context.Context, allotment *domain.GroupAllotment) error {
+	m := model.FromDomainGroupAllotment(allotment)
+
+	q := `UPDATE group_allotments
+				SET group_code = $1,
+					name = $2,
+					room_type_id = $3,
+					start_date = $4,
+					end_date = $5,
+					rooms_allotted = $6,
+					cutoff_date = $7,
+					is_active = $8,
+					updated_at = NOW()
+				WHERE allotment_id = $9`
+
+	result, err := r.db.ExecContext(ctx, q, m.GroupCode, m.Name, m.RoomTypeID, m.StartDate, m.EndDate,
+		m.RoomsAllotted, m.CutoffDate, m.IsActive, m.AllotmentID)
+	if err != nil {
+		return allotmentWriteError(err, m)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("no allotment found with id %d: %w", m.AllotmentID, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func allotmentWriteError(err error, m *model.GroupAllotment) error {
+	if hasPgCode(err, pgUniqueViolation) {
+		return fmt.Errorf("group code %s already exists: %w", m.GroupCode, errs.ErrConflict)
+	}
+	if hasPgCode(err, pgForeignKeyViolation) {
+		return fmt.Errorf("room type id %d: %w", m.RoomTypeID, errs.ErrNotFound)
+	}
+	return err
+}
+
+func (r *AllotmentRepository) DeleteAllotment(ctx context.Context, allotmentID int) error {
+	q := `DELETE FROM group_allotments WHERE allotment_id = $1`
+
+	result, err := r.db.ExecContext(ctx, q, allotmentID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("no allotment found with id %d: %w", allotmentID, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *AllotmentRepository) GetAllotmentByID(ctx context.Context, allotmentID int) (*domain.GroupAllotment, error) {
+	var m model.GroupAllotment
+	q := `SELECT ` + allotmentColumns + ` FROM group_allotments WHERE allotment_id = $1`
+
+	err := r.db.GetContext(ctx, &m, q, allotmentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("allotment id %d: %w", allotmentID, errs.ErrNotFound)
+		}
+		return nil, err
+	}
+
+	return m.ToDomain(), nil
+}
+
+func (r *AllotmentRepository) GetAllotmentByCode(ctx context.Context, groupCode string) (*domain.GroupAllotment, error) {
+	var m model.GroupAllotment
+	q := `SELECT ` + allotmentColumns + ` FROM group_allotments WHERE group_code = $1`
+
+	err := r.db.GetContext(ctx, &m, q, groupCode)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("group code %s: %w", groupCode, errs.ErrNotFound)
+		}
+		return nil, err
+	}
+
+	return m.ToDomain(), nil
+}
+
+func (r *AllotmentRepository) GetAllAllotments(ctx context.Context) ([]*domain.GroupAllotment, error) {
+	var models []model.GroupAllotment
+	q := `SELECT ` + allotmentColumns + ` FROM group_allotments ORDER BY start_date DESC, allotment_id`
+
+	err := r.db.SelectContext(ctx, &models, q)
+	if err != nil {
+		return nil, err
+	}
+
+	allotments := make([]*domain.GroupAllotment, len(models))
+	for i := range models {
+		allotments[i] = models[i].ToDomain()
+	}
+	return allotments, nil
+}
+
+func (r *AllotmentRepository) GetPickupReport(ctx context.Context, allotmentID int) (*domain.PickupReport, error) {
+	allotment, err := r.GetAllotmentByID(ctx, allotmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var nights []model.AllotmentNight
+	q := `SELECT n.stay_date::date AS stay_date, ga.rooms_allotted AS allotted, ` + allotmentPickup + ` AS picked_up
+				FROM group_allotments ga
+				JOIN generate_series(ga.start_date, ga.end_date - 1, INTERVAL '1 day') AS n(stay_date) ON TRUE
+				WHERE ga.allotment_id = $1
+				ORDER BY n.stay_date`
+	if err := r.db.SelectContext(ctx, &nights, q, allotmentID); err != nil {
+		return nil, err
+	}
+
+	report := &domain.PickupReport{Allotment: allotment}
+	for i := range nights {
+		report.Nights = append(report.Nights, nights[i].ToDomain())
+	}
+
+	q = `SELECT COUNT(*) FROM bookings WHERE allotment_id = $1 AND status NOT IN ('cancelled', 'expired', 'no-show')`
+	if err := r.db.GetContext(ctx, &report.Bookings, q, allotmentID); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// claimAllotment checks inside the booking transaction that the allotment can
+// take one more room for the whole stay, returning errs.ErrSoldOut if not. Locking the allotment row serialises
+// concurrent group bookings. excludeBookingID leaves a booking that is moving
+// out of the count.
+func claimAllotment(ctx context.Context, tx *sqlx.Tx, allotmentID, roomTypeID, excludeBookingID int, checkIn, checkOut time.Time) error {
+	var a struct {
+		RoomTypeID    int  `db:"room_type_id"`
+		RoomsAllotted int  `db:"rooms_allotted"`
+		IsActive      bool `db:"is_active"`
+		Fits          bool `db:"fits"`
+	}
+	q := `SELECT room_type_id, rooms_allotted, is_active, start_date <= $2::date AND end_date >= $3::date AS fits
+				FROM group_allotments WHERE allotment_id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &a, q, allotmentID, checkIn, checkOut); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("allotment id %d: %w", allotmentID, errs.ErrNotFound)
+		}
+		return err
+	}
+	if !a.IsActive || a.RoomTypeID != roomTypeID || !a.Fits {
+		// The allotment was changed since the booking was checked against it.
+		return fmt.Errorf("stay no longer fits allotment %d: %w", allotmentID, errs.ErrSoldOut)
+	}
+
+	var full bool
+	q = `SELECT EXISTS (
+					SELECT 1
+					FROM generate_series($2::date, $3::date - 1, INTERVAL '1 day') AS n(stay_date)
+					WHERE (
+						SELECT COUNT(*) FROM bookings gb
+						WHERE gb.allotment_id = $1
+							AND gb.booking_id <> $4
+							AND gb.status NOT IN ('cancelled', 'expired', 'no-show')
+							AND gb.check_in_date <= n.stay_date
+							AND gb.check_out_date > n.stay_date
+					) >= $5
+				)`
+	if err := tx.GetContext(ctx, &full, q, allotmentID, checkIn, checkOut, excludeBookingID, a.RoomsAllotted); err != nil {
+		return err
+	}
+	if full {
+		return fmt.Errorf("allotment %d is fully picked up: %w", allotmentID, errs.ErrSoldOut)
+	}
+
+	return nil
+}
+
+// lockAllotmentHolds locks the live group allotments that hold rooms of the
+// given types back from the public during the stay. Public bookings of those
+// types and group pick-ups then commit one at a time, so checkAllotmentHolds
+// sees every booking written before its own. It returns the types that have
+// such allotments; the others have nothing held and need no check.
+func lockAllotmentHolds(ctx context.Context, tx *sqlx.Tx, roomTypeIDs []int, checkIn, checkOut time.Time) (map[int]bool, error) {
+	if len(roomTypeIDs) == 0 {
+		return nil, nil
+	}
+	ids := make(pq.Int64Array, len(roomTypeIDs))
+	for i, id := range roomTypeIDs {
+		ids[i] = int64(id)
+	}
+
+	var types []int
+	q := `SELECT room_type_id FROM group_allotments
+				WHERE room_type_id = ANY($1)
+					AND is_active AND cutoff_date > CURRENT_DATE
+					AND start_date < $3 AND end_date > $2
+				ORDER BY allotment_id
+				FOR UPDATE`
+	if err := tx.SelectContext(ctx, &types, q, ids, checkIn, checkOut); err != nil {
+		return nil, err
+	}
+
+	locked := make(map[int]bool)
+	for _, id := range types {
+		locked[id] = true
+	}
+	return locked, nil
+}
+
+// checkAllotmentHolds returns errs.ErrSoldOut when the rooms of roomTypeID
+// still free over the stay no longer cover what group allotments hold, that
+// is when a public booking written in tx took a room promised to a group.
+func checkAllotmentHolds(ctx context.Context, tx *sqlx.Tx, roomTypeID int, checkIn, checkOut time.Time) error {
+	free, held, err := roomCounts(ctx, tx, checkIn, checkOut)
+	if err != nil {
+		return err
+	}
+	if free[roomTypeID] < held[roomTypeID] {
+		return fmt.Errorf("no public room of type %d left for the stay: %w", roomTypeID, errs.ErrSoldOut)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package postgresql
+
+import (
+	"context"
+
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql/model"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type AuditRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditRepository(db *sqlx.DB) ports.AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) RecordAudit(ctx context.Context, entry *domain.AuditEntry) error {
+	m := model.FromDomainAuditEntry(entry)
+	q := `INSERT INTO audit_logs (user_id, event, username, ip_address, user_agent, details)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				RETURNING audit_id, created_at`
+
+	return r.db.QueryRowxContext(ctx, q, m.UserID, m.Event, m.Username, m.IPAddress, m.UserAgent, m.Details).
+		Scan(&entry.AuditID, &entry.CreatedAt)
+}
+
+func (r *AuditRepository) ListAudit(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	var models []model.AuditEntry
+	q := `SELECT audit_id, user_id, event, username, ip_address, user_agent, details, created_at
+				FROM audit_logs
+				WHERE ($1 = 0 OR user_id = $1)
+					AND ($2 = '' OR event = $2)
+				ORDER BY created_at DESC, audit_id DESC
+				LIMIT $3`
+
+	if err := r.db.SelectContext(ctx, &models, q, filter.UserID, filter.Event, filter.Limit); err != nil {
+		return nil, err
+	}
+
+	entries := make([]*domain.AuditEntry, len(models))
+	for i, m := range models {
+		entries[i] = m.ToDomain()
+	}
+	return entries, nil
+}
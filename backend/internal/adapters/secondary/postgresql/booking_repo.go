@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
 
 	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql/model"
 	"github.com/ingwrok/hotelBooking/internal/common/errs"
 	"github.com/ingwrok/hotelBooking/internal/core/domain"
 	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/ingwrok/hotelBooking/internal/core/utils"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -20,50 +22,252 @@ func NewBookingRepository(db *sqlx.DB) ports.BookingRepository {
 	return &BookingRepository{db: db}
 }
 
-func (r *BookingRepository) CreateBooking(ctx context.Context, booking *domain.Booking, baddons []*domain.BookingAddon) error {
+func (r *BookingRepository) CreateBooking(ctx context.Context, booking *domain.Booking, baddons []*domain.BookingAddon, charges []*domain.FolioEntry) error {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	held, err := lockAllotmentHolds(ctx, tx, publicRoomTypes(booking), booking.CheckInDate, booking.CheckOutDate)
+	if err != nil {
+		return err
+	}
+
+	booking.ReservationID, err = insertReservation(ctx, tx, booking.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := insertBooking(ctx, tx, booking, baddons, charges); err != nil {
+		return err
+	}
+
+	if held[booking.RoomTypeID] {
+		if err := checkAllotmentHolds(ctx, tx, booking.RoomTypeID, booking.CheckInDate, booking.CheckOutDate); err != nil {
+			return err
+		}
+	}
+
+	if booking.WaitlistEntryID > 0 {
+		if err := offerWaitlistEntry(ctx, tx, booking); err != nil {
+			return err
+		}
+	}
+
+	if booking.PromotionID > 0 {
+		if err := redeemPromotion(ctx, tx, booking); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CreateReservation writes the reservation and all of its bookings in one
+// transaction, so either every room is held or none is. charges[i] are the
+// folio charges of res.Bookings[i]. Each booking carrying a promotion redeems
+// it once; AddReservation gives the promo code to a single booking.
+func (r *BookingRepository) CreateReservation(ctx context.Context, res *domain.Reservation, charges [][]*domain.FolioEntry) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	held, err := lockAllotmentHolds(ctx, tx, publicRoomTypes(res.Bookings...), res.CheckInDate, res.CheckOutDate)
+	if err != nil {
+		return err
+	}
+
+	res.ReservationID, err = insertReservation(ctx, tx, res.UserID)
+	if err != nil {
+		return err
+	}
+
+	for i, booking := range res.Bookings {
+		booking.ReservationID = res.ReservationID
+		if err := insertBooking(ctx, tx, booking, booking.BookingAddon, charges[i]); err != nil {
+			return err
+		}
+
+		if booking.PromotionID > 0 {
+			if err := redeemPromotion(ctx, tx, booking); err != nil {
+				return err
+			}
+		}
+	}
+
+	for roomTypeID := range held {
+		if err := checkAllotmentHolds(ctx, tx, roomTypeID, res.CheckInDate, res.CheckOutDate); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// publicRoomTypes lists the room types of the bookings sold to the public
+// rather than picked up from a group allotment.
+func publicRoomTypes(bookings ...*domain.Booking) []int {
+	var ids []int
+	for _, b := range bookings {
+		if b.AllotmentID == 0 && !slices.Contains(ids, b.RoomTypeID) {
+			ids = append(ids, b.RoomTypeID)
+		}
+	}
+	return ids
+}
+
+func insertReservation(ctx context.Context, tx *sqlx.Tx, userID int) (int, error) {
+	var reservationID int
+	q := `INSERT INTO reservations (user_id) VALUES ($1) RETURNING reservation_id`
+	if err := tx.QueryRowContext(ctx, q, userID).Scan(&reservationID); err != nil {
+		if hasPgCode(err, pgForeignKeyViolation) {
+			return 0, fmt.Errorf("user id %d: %w", userID, errs.ErrNotFound)
+		}
+		return 0, err
+	}
+	return reservationID, nil
+}
+
+// GetReservation returns the reservation with each of its bookings in full.
+func (r *BookingRepository) GetReservation(ctx context.Context, reservationID int) (*domain.ReservationDetail, error) {
+	var m model.Reservation
+	q := `SELECT res.reservation_id, res.user_id, res.created_at, u.email AS user_email, u.username AS user_name
+				FROM reservations res
+				JOIN users u ON res.user_id = u.user_id
+				WHERE res.reservation_id = $1`
+	err := r.db.GetContext(ctx, &m, q, reservationID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reservation id %d: %w", reservationID, errs.ErrNotFound)
+		}
+		return nil, err
+	}
+
+	var bookingIDs []int
+	q = `SELECT booking_id FROM bookings WHERE reservation_id = $1 ORDER BY booking_id`
+	if err := r.db.SelectContext(ctx, &bookingIDs, q, reservationID); err != nil {
+		return nil, err
+	}
+
+	res := m.ToDomain()
+	for _, id := range bookingIDs {
+		booking, err := r.GetBookingWithAddons(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		res.Bookings = append(res.Bookings, booking)
+	}
+	return res, nil
+}
+
+const maxConfirmationCodeAttempts = 5
+
+// insertBooking writes one room stay with its addons, nights, taxes and folio
+// charges under booking.ReservationID.
+func insertBooking(ctx context.Context, tx *sqlx.Tx, booking *domain.Booking, baddons []*domain.BookingAddon, charges []*domain.FolioEntry) error {
+	if booking.AllotmentID > 0 {
+		err := claimAllotment(ctx, tx, booking.AllotmentID, booking.RoomTypeID, 0, booking.CheckInDate, booking.CheckOutDate)
+		if err != nil {
+			return err
+		}
+	}
+
 	mb := model.FromDomainBooking(booking)
+	// A code already taken makes the insert return no row, and a fresh one is
+	// drawn; with 32^8 codes a second try is already rare.
 	queryBooking := `
 		INSERT INTO bookings (
-			user_id, rate_plan_id, room_id, check_in_date, check_out_date,
-			num_adults, room_subtotal, addon_subtotal,
-			taxes_amount, total_price, expired_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			reservation_id, user_id, rate_plan_id, room_id, check_in_date, check_out_date,
+			num_adults, child_ages, status, room_subtotal, addon_subtotal,
+			taxes_amount, total_price, expired_at,
+			promotion_id, promo_code, discount_amount, allotment_id, confirmation_code
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+		ON CONFLICT (confirmation_code) DO NOTHING
 		RETURNING booking_id`
 
 	var bookingID int
-	err = tx.QueryRowContext(ctx, queryBooking,
-		mb.UserID, mb.RatePlanID, mb.RoomID, mb.CheckInDate, mb.CheckOutDate,
-		mb.NumAdults, mb.RoomSubTotal, mb.AddonSubTotal,
-		mb.TaxesAmount, mb.TotalPrice, mb.ExpiredAt,
-	).Scan(&bookingID)
+	for attempt := 1; ; attempt++ {
+		code, err := utils.NewConfirmationCode()
+		if err != nil {
+			return err
+		}
 
-	if err != nil {
+		err = tx.QueryRowContext(ctx, queryBooking,
+			mb.ReservationID, mb.UserID, mb.RatePlanID, mb.RoomID, mb.CheckInDate, mb.CheckOutDate,
+			mb.NumAdults, mb.ChildAges, mb.Status, mb.RoomSubTotal, mb.AddonSubTotal,
+			mb.TaxesAmount, mb.TotalPrice, mb.ExpiredAt,
+			mb.PromotionID, mb.PromoCode, mb.DiscountAmount, mb.AllotmentID, code,
+		).Scan(&bookingID)
+		if err == sql.ErrNoRows && attempt < maxConfirmationCodeAttempts {
+			continue
+		}
+		if err != nil {
+			if hasPgCode(err, pgExclusionViolation) {
+				return fmt.Errorf("room %d is already booked for these dates: %w", mb.RoomID, errs.ErrConflict)
+			}
+			return err
+		}
+
+		booking.ConfirmationCode = code
+		break
+	}
+
+	if err := claimAddonCapacity(ctx, tx, bookingID, baddons, booking.CheckInDate, booking.CheckOutDate); err != nil {
 		return err
 	}
 
 	if len(baddons) > 0 {
-		queryAddon := `
-			INSERT INTO booking_addons (booking_id, addon_id, quantity, price_at_time_of_booking)
-			VALUES ($1, $2, $3, $4)`
-
 		for _, daddon := range baddons {
-			mAddon := model.FromDomainBookingAddon(daddon)
-			_, err := tx.ExecContext(ctx, queryAddon, bookingID, mAddon.AddonID, mAddon.Quantity, mAddon.PriceAtBooking)
-			if err != nil {
+			if err := insertBookingAddon(ctx, tx, bookingID, daddon); err != nil {
 				return err
 			}
 		}
 	}
 
+	queryNight := `INSERT INTO booking_nights (booking_id, stay_date, price, extra_guest_charge) VALUES ($1, $2, $3, $4)`
+	for _, night := range booking.Nights {
+		mNight := model.FromDomainNightlyRate(night)
+		_, err := tx.ExecContext(ctx, queryNight, bookingID, mNight.StayDate, mNight.Price, mNight.ExtraGuestCharge)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := insertBookingTaxes(ctx, tx, bookingID, booking.Taxes); err != nil {
+		return err
+	}
+
 	booking.BookingID = bookingID
 
-	return tx.Commit()
+	if err := insertFolioEntries(ctx, tx, bookingID, charges); err != nil {
+		return err
+	}
+
+	return insertStatusHistory(ctx, tx, &domain.BookingStatusHistory{
+		BookingID:   bookingID,
+		ActorUserID: booking.UserID,
+		NewStatus:   booking.Status,
+		Reason:      "booking created",
+	})
+}
+
+func insertBookingAddon(ctx context.Context, tx *sqlx.Tx, bookingID int, a *domain.BookingAddon) error {
+	m := model.FromDomainBookingAddon(a)
+	q := `INSERT INTO booking_addons (booking_id, addon_id, quantity, price_at_time_of_booking,
+				charge_unit, nights, guests, service_date, slot_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING booking_addon_id`
+
+	err := tx.QueryRowContext(ctx, q, bookingID, m.AddonID, m.Quantity, m.PriceAtBooking,
+		m.ChargeUnit, m.Nights, m.Guests, m.ServiceDate, m.SlotID).Scan(&a.BookingAddonID)
+	if err != nil {
+		return err
+	}
+	a.BookingID = bookingID
+	return nil
 }
 
 func (r *BookingRepository) GetBookingWithAddons(ctx context.Context, bookingID int) (*domain.BookingDetail, error) {
@@ -80,6 +284,7 @@ func (r *BookingRepository) GetBookingWithAddons(ctx context.Context, bookingID
 				rp.name as rate_plan_name, 
 				r.room_number, 
 				rt.name as room_type_name,
+				` + folioBalanceColumn + `,
 				u.email as user_email,
 				u.username as user_name
 			FROM bookings b
@@ -98,22 +303,59 @@ func (r *BookingRepository) GetBookingWithAddons(ctx context.Context, bookingID
 	}
 
 	var mAddons []*model.BookingAddon
-	queryAddons := `SELECT ba.*,a.name as addon_name
+	queryAddons := `SELECT ba.*,a.name as addon_name, COALESCE(s.name, '') AS slot_name
 									FROM booking_addons ba
 									JOIN addons a ON ba.addon_id = a.addon_id
+									LEFT JOIN addon_slots s ON ba.slot_id = s.slot_id
 									WHERE ba.booking_id = $1`
 	err = tx.SelectContext(ctx, &mAddons, queryAddons, bookingID)
 	if err != nil {
 		return nil, err
 	}
 
+	var mNights []*model.NightlyRate
+	queryNights := `SELECT stay_date, price, extra_guest_charge FROM booking_nights WHERE booking_id = $1 ORDER BY stay_date`
+	err = tx.SelectContext(ctx, &mNights, queryNights, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
 	booking := mBookingDetail.ToDomainDetail(mAddons)
+	for _, n := range mNights {
+		booking.Nights = append(booking.Nights, n.ToDomain())
+	}
+
+	booking.Taxes, err = getBookingTaxes(ctx, tx, bookingID)
+	if err != nil {
+		return nil, err
+	}
 	return booking, tx.Commit()
 }
 
-func (r *BookingRepository) UpdateBookingStatus(ctx context.Context, bookingID int, status string) error {
-	q := `UPDATE bookings SET status=$1 WHERE booking_id=$2`
-	result, err := r.db.ExecContext(ctx, q, status, bookingID)
+func (r *BookingRepository) GetBookingIDByConfirmationCode(ctx context.Context, code string) (int, error) {
+	var bookingID int
+	q := `SELECT booking_id FROM bookings WHERE confirmation_code = $1`
+
+	if err := r.db.GetContext(ctx, &bookingID, q, code); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("confirmation code %s: %w", code, errs.ErrNotFound)
+		}
+		return 0, err
+	}
+
+	return bookingID, nil
+}
+
+func (r *BookingRepository) UpdateBookingStatus(ctx context.Context, change *domain.BookingStatusHistory) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Guarding on the old status keeps concurrent transitions from overwriting each other.
+	q := `UPDATE bookings SET status = $1, updated_at = NOW() WHERE booking_id = $2 AND status = $3`
+	result, err := tx.ExecContext(ctx, q, change.NewStatus, change.BookingID, change.OldStatus)
 	if err != nil {
 		return err
 	}
@@ -124,21 +366,34 @@ func (r *BookingRepository) UpdateBookingStatus(ctx context.Context, bookingID i
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("no booking found with id %d: %w", bookingID, errs.ErrNotFound)
+		return fmt.Errorf("no booking found with id %d and status %s: %w", change.BookingID, change.OldStatus, errs.ErrNotFound)
 	}
 
-	return nil
+	if err := insertStatusHistory(ctx, tx, change); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *BookingRepository) SyncBookingAddons(ctx context.Context, bookingID int, addons []*domain.BookingAddon, newTotalPrice float64) error {
+func (r *BookingRepository) CancelBooking(ctx context.Context, change *domain.BookingStatusHistory, fee domain.Money, entries []*domain.FolioEntry) error {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	queryUpdateTotal := `UPDATE bookings SET total_price = $1, updated_at = NOW() WHERE booking_id = $2`
-	result, err := tx.ExecContext(ctx, queryUpdateTotal, newTotalPrice, bookingID)
+	q := `
+		UPDATE bookings
+		SET status = 'cancelled',
+			cancellation_reason = $1,
+			cancellation_fee = $2,
+			cancelled_at = NOW(),
+			updated_at = NOW()
+		WHERE booking_id = $3
+		  AND status = $4`
+
+	result, err := tx.ExecContext(ctx, q, change.Reason, model.AmountOf(fee), change.BookingID, change.OldStatus)
 	if err != nil {
 		return err
 	}
@@ -147,35 +402,268 @@ func (r *BookingRepository) SyncBookingAddons(ctx context.Context, bookingID int
 	if err != nil {
 		return err
 	}
+
 	if rows == 0 {
-		return fmt.Errorf("booking id %d not found", bookingID)
+		return fmt.Errorf("no cancellable booking found with id %d: %w", change.BookingID, errs.ErrNotFound)
+	}
+
+	if err := insertStatusHistory(ctx, tx, change); err != nil {
+		return err
+	}
+
+	if err := insertFolioEntries(ctx, tx, change.BookingID, entries); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *BookingRepository) GetBookingStatusHistory(ctx context.Context, bookingID int) ([]*domain.BookingStatusHistory, error) {
+	q := `SELECT history_id, booking_id, actor_user_id, old_status, new_status, reason, created_at
+				FROM booking_status_history
+				WHERE booking_id = $1
+				ORDER BY created_at, history_id`
+
+	var ms []model.BookingStatusHistory
+	err := r.db.SelectContext(ctx, &ms, q, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]*domain.BookingStatusHistory, len(ms))
+	for i, m := range ms {
+		history[i] = m.ToDomain()
+	}
+	return history, nil
+}
+
+func insertStatusHistory(ctx context.Context, tx *sqlx.Tx, change *domain.BookingStatusHistory) error {
+	m := model.FromDomainBookingStatusHistory(change)
+	q := `INSERT INTO booking_status_history (booking_id, actor_user_id, old_status, new_status, reason)
+				VALUES ($1, $2, $3, $4, $5)
+				RETURNING history_id, created_at`
+
+	return tx.QueryRowContext(ctx, q, m.BookingID, m.ActorUserID, m.OldStatus, m.NewStatus, m.Reason).
+		Scan(&change.HistoryID, &change.CreatedAt)
+}
+
+func (r *BookingRepository) SyncBookingAddons(ctx context.Context, booking *domain.BookingDetail, entries []*domain.FolioEntry) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback()
 
-	_, err = tx.ExecContext(ctx, "DELETE FROM booking_addons WHERE booking_id = $1", bookingID)
+	queryUpdateTotal := `
+		UPDATE bookings
+		SET addon_subtotal = $1,
+			taxes_amount = $2,
+			total_price = $3,
+			updated_at = NOW()
+		WHERE booking_id = $4`
+	result, err := tx.ExecContext(ctx, queryUpdateTotal, model.AmountOf(booking.AddonSubTotal), model.AmountOf(booking.TaxesAmount), model.AmountOf(booking.TotalPrice), booking.BookingID)
 	if err != nil {
 		return err
 	}
 
-	queryInsert := `INSERT INTO booking_addons (booking_id, addon_id, quantity, price_at_time_of_booking) VALUES ($1, $2, $3, $4)`
-	for _, a := range addons {
-		mAddon := model.FromDomainBookingAddon(a)
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("booking id %d: %w", booking.BookingID, errs.ErrNotFound)
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM booking_addons WHERE booking_id = $1", booking.BookingID)
+	if err != nil {
+		return err
+	}
+
+	if err := claimAddonCapacity(ctx, tx, booking.BookingID, booking.BookingAddon, booking.CheckInDate, booking.CheckOutDate); err != nil {
+		return err
+	}
+
+	for _, a := range booking.BookingAddon {
+		if err := insertBookingAddon(ctx, tx, booking.BookingID, a); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM booking_taxes WHERE booking_id = $1", booking.BookingID)
+	if err != nil {
+		return err
+	}
+
+	if err := insertBookingTaxes(ctx, tx, booking.BookingID, booking.Taxes); err != nil {
+		return err
+	}
+
+	if err := insertFolioEntries(ctx, tx, booking.BookingID, entries); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *BookingRepository) ModifyStay(ctx context.Context, old *domain.BookingDetail, quote *domain.StayQuote, entries []*domain.FolioEntry) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// A payment started for the old total would confirm the new one underpaid.
+	_, paying, err := lockPendingPayments(ctx, tx, old.BookingID, 0)
+	if err != nil {
+		return err
+	}
+	if paying {
+		return fmt.Errorf("booking id %d has a payment in flight: %w", old.BookingID, errs.ErrPaymentPending)
+	}
 
-		_, err = tx.ExecContext(ctx, queryInsert, bookingID, mAddon.AddonID, mAddon.Quantity, mAddon.PriceAtBooking)
+	var held map[int]bool
+	if old.AllotmentID > 0 {
+		err := claimAllotment(ctx, tx, old.AllotmentID, quote.RoomTypeID, old.BookingID, quote.CheckInDate, quote.CheckOutDate)
 		if err != nil {
 			return err
 		}
+	} else {
+		held, err = lockAllotmentHolds(ctx, tx, []int{quote.RoomTypeID}, quote.CheckInDate, quote.CheckOutDate)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := claimAddonCapacity(ctx, tx, old.BookingID, quote.BookingAddon, quote.CheckInDate, quote.CheckOutDate); err != nil {
+		return err
+	}
+
+	// The guard on the old stay and total keeps the change from landing on a
+	// booking that moved after it was priced.
+	q := `
+		UPDATE bookings
+		SET room_id = $1,
+			check_in_date = $2,
+			check_out_date = $3,
+			room_subtotal = $4,
+			addon_subtotal = $5,
+			discount_amount = $6,
+			taxes_amount = $7,
+			total_price = $8,
+			updated_at = NOW()
+		WHERE booking_id = $9
+		  AND status = $10
+		  AND room_id = $11
+		  AND check_in_date = $12
+		  AND check_out_date = $13
+		  AND total_price = $14`
+
+	result, err := tx.ExecContext(ctx, q,
+		quote.RoomID, quote.CheckInDate, quote.CheckOutDate,
+		model.AmountOf(quote.RoomSubTotal), model.AmountOf(quote.AddonSubTotal), model.AmountOf(quote.DiscountAmount),
+		model.AmountOf(quote.TaxesAmount), model.AmountOf(quote.TotalPrice),
+		old.BookingID, old.Status, old.RoomID, old.CheckInDate, old.CheckOutDate, model.AmountOf(old.TotalPrice),
+	)
+	if err != nil {
+		if hasPgCode(err, pgExclusionViolation) {
+			return fmt.Errorf("room %d is already booked for these dates: %w", quote.RoomID, errs.ErrConflict)
+		}
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("booking id %d changed since it was priced: %w", old.BookingID, errs.ErrNotFound)
+	}
+
+	if held[quote.RoomTypeID] {
+		if err := checkAllotmentHolds(ctx, tx, quote.RoomTypeID, quote.CheckInDate, quote.CheckOutDate); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM booking_nights WHERE booking_id = $1", old.BookingID); err != nil {
+		return err
+	}
+
+	queryNight := `INSERT INTO booking_nights (booking_id, stay_date, price, extra_guest_charge) VALUES ($1, $2, $3, $4)`
+	for _, night := range quote.Nights {
+		mNight := model.FromDomainNightlyRate(night)
+		if _, err := tx.ExecContext(ctx, queryNight, old.BookingID, mNight.StayDate, mNight.Price, mNight.ExtraGuestCharge); err != nil {
+			return err
+		}
+	}
+
+	// Per-night addons follow the new stay length.
+	queryAddon := `UPDATE booking_addons SET nights = $1, guests = $2 WHERE booking_addon_id = $3 AND booking_id = $4`
+	for _, a := range quote.BookingAddon {
+		if _, err := tx.ExecContext(ctx, queryAddon, a.Nights, a.Guests, a.BookingAddonID, old.BookingID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM booking_taxes WHERE booking_id = $1", old.BookingID); err != nil {
+		return err
+	}
+
+	if err := insertBookingTaxes(ctx, tx, old.BookingID, quote.Taxes); err != nil {
+		return err
+	}
+
+	if err := insertFolioEntries(ctx, tx, old.BookingID, entries); err != nil {
+		return err
 	}
 
 	return tx.Commit()
 }
 
+func insertBookingTaxes(ctx context.Context, tx *sqlx.Tx, bookingID int, taxes []*domain.BookingTax) error {
+	q := `INSERT INTO booking_taxes (booking_id, tax_rule_id, name, amount)
+				VALUES ($1, $2, $3, $4)
+				RETURNING booking_tax_id`
+
+	for _, tax := range taxes {
+		m := model.FromDomainBookingTax(tax)
+		err := tx.QueryRowContext(ctx, q, bookingID, m.TaxRuleID, m.Name, m.Amount).Scan(&tax.BookingTaxID)
+		if err != nil {
+			return err
+		}
+		tax.BookingID = bookingID
+	}
+	return nil
+}
+
+func getBookingTaxes(ctx context.Context, tx *sqlx.Tx, bookingID int) ([]*domain.BookingTax, error) {
+	var models []model.BookingTax
+	q := `SELECT booking_tax_id, booking_id, tax_rule_id, name, amount
+				FROM booking_taxes
+				WHERE booking_id = $1
+				ORDER BY booking_tax_id`
+
+	err := tx.SelectContext(ctx, &models, q, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	taxes := make([]*domain.BookingTax, len(models))
+	for i := range models {
+		taxes[i] = models[i].ToDomain()
+	}
+	return taxes, nil
+}
+
 func (r *BookingRepository) GetBookingAddonsByBookingID(ctx context.Context, bookingID int) ([]*domain.BookingAddon, error) {
 	var mAddons []*model.BookingAddon
 	query := `SELECT
             	ba.booking_addon_id,ba.booking_id, ba.addon_id, ba.quantity, ba.price_at_time_of_booking,
-            	a.name as addon_name
+            	ba.charge_unit, ba.nights, ba.guests, ba.service_date, ba.slot_id,
+            	a.name as addon_name, COALESCE(s.name, '') AS slot_name
        			FROM booking_addons ba
         		JOIN addons a ON ba.addon_id = a.addon_id
+        		LEFT JOIN addon_slots s ON ba.slot_id = s.slot_id
         		WHERE ba.booking_id = $1`
 
 	err := r.db.SelectContext(ctx, &mAddons, query, bookingID)
@@ -191,19 +679,43 @@ func (r *BookingRepository) GetBookingAddonsByBookingID(ctx context.Context, boo
 }
 
 func (r *BookingRepository) CancelExpiredBookings(ctx context.Context) (int64, error) {
+	// Expired bookings were never confirmed, so their charges are released in full.
+	// Waitlist holds lapse even on plans that confirm without payment.
 	q := `
-        UPDATE bookings
-        SET status = 'cancelled'
-        WHERE status = 'pending'
-          AND expired_at < NOW()`
-
-	result, err := r.db.ExecContext(ctx, q)
-	if err != nil {
-		return 0, err
-	}
-
-	rows, err := result.RowsAffected()
-	if err != nil {
+        WITH expired AS (
+            UPDATE bookings b
+            SET status = 'expired', updated_at = NOW()
+            WHERE b.status = 'pending'
+              AND b.expired_at < NOW()
+              AND b.amount_paid = 0
+              AND (
+                  NOT EXISTS (
+                      SELECT 1 FROM rate_plans rp
+                      WHERE rp.rate_plan_id = b.rate_plan_id
+                        AND rp.allow_pay_later
+                        AND rp.deposit_percent = 0
+                        AND NOT rp.requires_guarantee
+                  )
+                  OR EXISTS (
+                      SELECT 1 FROM waitlist_entries w
+                      WHERE w.booking_id = b.booking_id AND w.status = 'offered'
+                  )
+              )
+            RETURNING b.booking_id, b.total_price
+        ), history AS (
+            INSERT INTO booking_status_history (booking_id, old_status, new_status, reason)
+            SELECT booking_id, 'pending', 'expired', 'payment window elapsed'
+            FROM expired
+        ), released AS (
+            INSERT INTO folio_entries (booking_id, entry_type, category, description, amount)
+            SELECT booking_id, 'adjustment', 'cancellation', 'Charges released on expiry', -total_price
+            FROM expired
+            WHERE total_price > 0
+        )
+        SELECT COUNT(*) FROM expired`
+
+	var rows int64
+	if err := r.db.QueryRowContext(ctx, q).Scan(&rows); err != nil {
 		return 0, err
 	}
 
@@ -223,7 +735,8 @@ func (r *BookingRepository) GetBookingsByUserID(ctx context.Context, userID int)
 				b.*,
 				rp.name as rate_plan_name,
 				r.room_number,
-				rt.name as room_type_name
+				rt.name as room_type_name,
+				` + folioBalanceColumn + `
 			FROM bookings b
 			JOIN rate_plans rp ON b.rate_plan_id = rp.rate_plan_id
 			JOIN rooms r ON b.room_id = r.room_id
@@ -239,16 +752,22 @@ func (r *BookingRepository) GetBookingsByUserID(ctx context.Context, userID int)
 	var result []*domain.BookingDetail
 	for _, m := range mBookingDetail {
 		var mAddons []*model.BookingAddon
-		queryAddons := `SELECT ba.*,a.name as addon_name
+		queryAddons := `SELECT ba.*,a.name as addon_name, COALESCE(s.name, '') AS slot_name
 										FROM booking_addons ba
 										JOIN addons a ON ba.addon_id = a.addon_id
+										LEFT JOIN addon_slots s ON ba.slot_id = s.slot_id
 										WHERE ba.booking_id = $1`
 		err = tx.SelectContext(ctx, &mAddons, queryAddons, m.BookingID)
 		if err != nil {
 			return nil, err
 		}
 
-		result = append(result, m.ToDomainDetail(mAddons))
+		booking := m.ToDomainDetail(mAddons)
+		booking.Taxes, err = getBookingTaxes(ctx, tx, m.BookingID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, booking)
 	}
 	return result, tx.Commit()
 }
@@ -267,6 +786,7 @@ func (r *BookingRepository) GetAllBookings(ctx context.Context) ([]*domain.Booki
 				rp.name as rate_plan_name,
 				r.room_number,
 				rt.name as room_type_name,
+				` + folioBalanceColumn + `,
 				u.username as user_name,
 				u.email as user_email
 			FROM bookings b
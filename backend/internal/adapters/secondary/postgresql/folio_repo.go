@@ -0,0 +1,78 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql/model"
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+// folioBalanceColumn selects a booking's balance, derived from its folio, for
+// queries over bookings aliased as b.
+const folioBalanceColumn = `(SELECT COALESCE(SUM(f.amount), 0) FROM folio_entries f WHERE f.booking_id = b.booking_id) AS balance`
+
+const folioColumns = `entry_id, booking_id, entry_type, category, description, amount, currency, payment_id, actor_user_id, created_at`
+
+type FolioRepository struct {
+	db *sqlx.DB
+}
+
+func NewFolioRepository(db *sqlx.DB) ports.FolioRepository {
+	return &FolioRepository{db: db}
+}
+
+func (r *FolioRepository) GetFolioEntries(ctx context.Context, bookingID int) ([]*domain.FolioEntry, error) {
+	var models []model.FolioEntry
+	q := `SELECT ` + folioColumns + ` FROM folio_entries WHERE booking_id = $1 ORDER BY created_at, entry_id`
+
+	err := r.db.SelectContext(ctx, &models, q, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*domain.FolioEntry, len(models))
+	for i := range models {
+		entries[i] = models[i].ToDomain()
+	}
+	return entries, nil
+}
+
+func (r *FolioRepository) PostEntry(ctx context.Context, entry *domain.FolioEntry) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertFolioEntries(ctx, tx, entry.BookingID, []*domain.FolioEntry{entry}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertFolioEntries posts entries to a booking's folio as part of a larger
+// transaction, so the ledger always moves together with the booking.
+func insertFolioEntries(ctx context.Context, tx *sqlx.Tx, bookingID int, entries []*domain.FolioEntry) error {
+	q := `INSERT INTO folio_entries (booking_id, entry_type, category, description, amount, currency, payment_id, actor_user_id)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+				RETURNING entry_id, created_at`
+
+	for _, entry := range entries {
+		entry.BookingID = bookingID
+		m := model.FromDomainFolioEntry(entry)
+		err := tx.QueryRowContext(ctx, q, bookingID, m.EntryType, m.Category, m.Description, m.Amount, m.Currency,
+			m.PaymentID, m.ActorUserID).Scan(&entry.EntryID, &entry.CreatedAt)
+		if err != nil {
+			if hasPgCode(err, pgForeignKeyViolation) {
+				return fmt.Errorf("booking id %d: %w", bookingID, errs.ErrNotFound)
+			}
+			return err
+		}
+	}
+	return nil
+}
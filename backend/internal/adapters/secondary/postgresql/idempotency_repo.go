@@ -0,0 +1,107 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql/model"
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type IdempotencyRepository struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyRepository(db *sqlx.DB) ports.IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+const idempotencyColumns = `user_id, idem_key, request_hash, status, response_status, content_type, response_body, created_at, expires_at, locked_until`
+
+func (r *IdempotencyRepository) ReserveIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) (*domain.IdempotencyKey, bool, error) {
+	// An expired key that the cleanup job has not removed yet is taken over as
+	// if it were new, and so is a processing key whose lease ran out because
+	// its request never finished; a live one makes the insert return no row.
+	q := `INSERT INTO idempotency_keys (user_id, idem_key, request_hash, status, expires_at, locked_until)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				ON CONFLICT (user_id, idem_key) DO UPDATE SET
+					request_hash = EXCLUDED.request_hash,
+					status = EXCLUDED.status,
+					response_status = NULL,
+					content_type = NULL,
+					response_body = NULL,
+					created_at = CURRENT_TIMESTAMP,
+					expires_at = EXCLUDED.expires_at,
+					locked_until = EXCLUDED.locked_until
+				WHERE idempotency_keys.expires_at <= CURRENT_TIMESTAMP
+					OR (idempotency_keys.status = $4 AND idempotency_keys.locked_until <= CURRENT_TIMESTAMP)
+				RETURNING created_at`
+
+	err := r.db.QueryRowContext(ctx, q, key.UserID, key.Key, key.RequestHash, domain.IdempotencyStatusProcessing, key.ExpiresAt, key.LockedUntil).
+		Scan(&key.CreatedAt)
+	if err == nil {
+		key.Status = domain.IdempotencyStatusProcessing
+		return key, true, nil
+	}
+	if err != sql.ErrNoRows {
+		if hasPgCode(err, pgForeignKeyViolation) {
+			return nil, false, fmt.Errorf("user id %d: %w", key.UserID, errs.ErrNotFound)
+		}
+		return nil, false, err
+	}
+
+	var m model.IdempotencyKey
+	sel := `SELECT ` + idempotencyColumns + ` FROM idempotency_keys WHERE user_id = $1 AND idem_key = $2`
+	if err := r.db.GetContext(ctx, &m, sel, key.UserID, key.Key); err != nil {
+		if err == sql.ErrNoRows {
+			// Deleted between the two statements; the caller may retry.
+			return nil, false, fmt.Errorf("idempotency key %q: %w", key.Key, errs.ErrConflict)
+		}
+		return nil, false, err
+	}
+
+	return m.ToDomain(), false, nil
+}
+
+func (r *IdempotencyRepository) CompleteIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) error {
+	q := `UPDATE idempotency_keys
+				SET status = $1, response_status = $2, content_type = $3, response_body = $4
+				WHERE user_id = $5 AND idem_key = $6 AND status = $7`
+
+	res, err := r.db.ExecContext(ctx, q, domain.IdempotencyStatusCompleted, key.ResponseStatus, key.ContentType, key.ResponseBody,
+		key.UserID, key.Key, domain.IdempotencyStatusProcessing)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("processing idempotency key %q: %w", key.Key, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepository) ReleaseIdempotencyKey(ctx context.Context, userID int, key string) error {
+	q := `DELETE FROM idempotency_keys WHERE user_id = $1 AND idem_key = $2 AND status = $3`
+
+	_, err := r.db.ExecContext(ctx, q, userID, key, domain.IdempotencyStatusProcessing)
+	return err
+}
+
+func (r *IdempotencyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	q := `DELETE FROM idempotency_keys WHERE expires_at <= CURRENT_TIMESTAMP`
+
+	res, err := r.db.ExecContext(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,88 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql/model"
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type LoginAttemptRepository struct {
+	db *sqlx.DB
+}
+
+func NewLoginAttemptRepository(db *sqlx.DB) ports.LoginAttemptStore {
+	return &LoginAttemptRepository{db: db}
+}
+
+const loginAttemptColumns = `attempt_key, failures, first_failed_at, last_failed_at, locked_until`
+
+func (r *LoginAttemptRepository) GetLoginAttempt(ctx context.Context, key string) (*domain.LoginAttempt, error) {
+	var m model.LoginAttempt
+	q := `SELECT ` + loginAttemptColumns + ` FROM login_attempts WHERE attempt_key = $1`
+
+	if err := r.db.GetContext(ctx, &m, q, key); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("login attempts for %s: %w", key, errs.ErrNotFound)
+		}
+		return nil, err
+	}
+	return m.ToDomain(), nil
+}
+
+// RecordLoginFailure counts in a single upsert so concurrent failures on the
+// same key are never lost.
+func (r *LoginAttemptRepository) RecordLoginFailure(ctx context.Context, key string, window time.Duration) (*domain.LoginAttempt, error) {
+	var m model.LoginAttempt
+	q := `INSERT INTO login_attempts (attempt_key, failures, first_failed_at, last_failed_at)
+				VALUES ($1, 1, NOW(), NOW())
+				ON CONFLICT (attempt_key) DO UPDATE SET
+					failures = CASE WHEN login_attempts.first_failed_at < NOW() - make_interval(secs => $2)
+						THEN 1 ELSE login_attempts.failures + 1 END,
+					first_failed_at = CASE WHEN login_attempts.first_failed_at < NOW() - make_interval(secs => $2)
+						THEN NOW() ELSE login_attempts.first_failed_at END,
+					last_failed_at = NOW()
+				RETURNING ` + loginAttemptColumns
+
+	if err := r.db.GetContext(ctx, &m, q, key, window.Seconds()); err != nil {
+		return nil, err
+	}
+	return m.ToDomain(), nil
+}
+
+func (r *LoginAttemptRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	q := `UPDATE login_attempts SET locked_until = $2 WHERE attempt_key = $1`
+
+	res, err := r.db.ExecContext(ctx, q, key, until)
+	if err != nil {
+		return err
+	}
+	if aff, _ := res.RowsAffected(); aff == 0 {
+		return fmt.Errorf("login attempts for %s: %w", key, errs.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *LoginAttemptRepository) ResetLoginAttempts(ctx context.Context, key string) error {
+	q := `DELETE FROM login_attempts WHERE attempt_key = $1`
+	_, err := r.db.ExecContext(ctx, q, key)
+	return err
+}
+
+func (r *LoginAttemptRepository) DeleteStaleLoginAttempts(ctx context.Context, before time.Time) (int64, error) {
+	q := `DELETE FROM login_attempts
+				WHERE last_failed_at < $1
+					AND (locked_until IS NULL OR locked_until <= NOW())`
+
+	res, err := r.db.ExecContext(ctx, q, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
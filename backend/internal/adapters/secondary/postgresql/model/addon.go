@@ -1,6 +1,10 @@
 package model
 
-import "github.com/ingwrok/hotelBooking/internal/core/domain"
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
 
 type AddonCategory struct {
 	CategoryID int    `db:"category_id"`
@@ -22,13 +26,17 @@ func FromDomainAddonCategory(d *domain.AddonCategory) *AddonCategory {
 }
 
 type Addon struct {
-	AddonID     int     `db:"addon_id"`
-	CategoryID  int     `db:"category_id"`
-	Name        string  `db:"name"`
-	Description string  `db:"description"`
-	Price       float64 `db:"price"`
-	UnitName    string  `db:"unit_name"`
-	PictureURL  *string `db:"picture_url"`
+	AddonID             int     `db:"addon_id"`
+	CategoryID          int     `db:"category_id"`
+	Name                string  `db:"name"`
+	Description         string  `db:"description"`
+	Price               Amount  `db:"price"`
+	UnitName            string  `db:"unit_name"`
+	PictureURL          *string `db:"picture_url"`
+	IsExtraBed          bool    `db:"is_extra_bed"`
+	ChargeUnit          string  `db:"charge_unit"`
+	RequiresServiceDate bool    `db:"requires_service_date"`
+	DailyCapacity       *int    `db:"daily_capacity"`
 }
 
 func (m *Addon) ToDomain() *domain.Addon {
@@ -37,7 +45,7 @@ func (m *Addon) ToDomain() *domain.Addon {
 		CategoryID:  m.CategoryID,
 		Name:        m.Name,
 		Description: m.Description,
-		Price:       m.Price,
+		Price:       m.Price.Money(),
 		UnitName:    m.UnitName,
 		PictureURL: func() string {
 			if m.PictureURL != nil {
@@ -45,17 +53,65 @@ func (m *Addon) ToDomain() *domain.Addon {
 			}
 			return ""
 		}(),
+		IsExtraBed:          m.IsExtraBed,
+		ChargeUnit:          m.ChargeUnit,
+		RequiresServiceDate: m.RequiresServiceDate,
+		DailyCapacity:       derefInt(m.DailyCapacity),
 	}
 }
 
 func FromDomainAddon(d *domain.Addon) *Addon {
 	return &Addon{
-		AddonID:     d.AddonID,
-		CategoryID:  d.CategoryID,
-		Name:        d.Name,
-		Description: d.Description,
-		Price:       d.Price,
-		UnitName:    d.UnitName,
-		PictureURL:  &d.PictureURL,
+		AddonID:             d.AddonID,
+		CategoryID:          d.CategoryID,
+		Name:                d.Name,
+		Description:         d.Description,
+		Price:               AmountOf(d.Price),
+		UnitName:            d.UnitName,
+		PictureURL:          &d.PictureURL,
+		IsExtraBed:          d.IsExtraBed,
+		ChargeUnit:          d.ChargeUnit,
+		RequiresServiceDate: d.RequiresServiceDate,
+		DailyCapacity:       nullableInt(d.DailyCapacity),
+	}
+}
+
+type AddonSlot struct {
+	SlotID   int    `db:"slot_id"`
+	AddonID  int    `db:"addon_id"`
+	Name     string `db:"name"`
+	Capacity int    `db:"capacity"`
+}
+
+func (m *AddonSlot) ToDomain() *domain.AddonSlot {
+	return &domain.AddonSlot{
+		SlotID:   m.SlotID,
+		AddonID:  m.AddonID,
+		Name:     m.Name,
+		Capacity: m.Capacity,
+	}
+}
+
+func FromDomainAddonSlot(d *domain.AddonSlot) *AddonSlot {
+	return &AddonSlot{
+		SlotID:   d.SlotID,
+		AddonID:  d.AddonID,
+		Name:     d.Name,
+		Capacity: d.Capacity,
+	}
+}
+
+type AddonUsage struct {
+	AddonID int       `db:"addon_id"`
+	SlotID  int       `db:"slot_id"`
+	Date    time.Time `db:"day"`
+	Units   int       `db:"units"`
+}
+
+func (m *AddonUsage) ToDomain() *domain.AddonUsage {
+	return &domain.AddonUsage{
+		SlotID: m.SlotID,
+		Date:   m.Date,
+		Units:  m.Units,
 	}
 }
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type GroupAllotment struct {
+	AllotmentID   int       `db:"allotment_id"`
+	GroupCode     string    `db:"group_code"`
+	Name          string    `db:"name"`
+	RoomTypeID    int       `db:"room_type_id"`
+	StartDate     time.Time `db:"start_date"`
+	EndDate       time.Time `db:"end_date"`
+	RoomsAllotted int       `db:"rooms_allotted"`
+	CutoffDate    time.Time `db:"cutoff_date"`
+	IsActive      bool      `db:"is_active"`
+	CreatedAt     time.Time `db:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
+
+func (m *GroupAllotment) ToDomain() *domain.GroupAllotment {
+	return &domain.GroupAllotment{
+		AllotmentID:   m.AllotmentID,
+		GroupCode:     m.GroupCode,
+		Name:          m.Name,
+		RoomTypeID:    m.RoomTypeID,
+		StartDate:     m.StartDate,
+		EndDate:       m.EndDate,
+		RoomsAllotted: m.RoomsAllotted,
+		CutoffDate:    m.CutoffDate,
+		IsActive:      m.IsActive,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+}
+
+func FromDomainGroupAllotment(d *domain.GroupAllotment) *GroupAllotment {
+	return &GroupAllotment{
+		AllotmentID:   d.AllotmentID,
+		GroupCode:     d.GroupCode,
+		Name:          d.Name,
+		RoomTypeID:    d.RoomTypeID,
+		StartDate:     d.StartDate,
+		EndDate:       d.EndDate,
+		RoomsAllotted: d.RoomsAllotted,
+		CutoffDate:    d.CutoffDate,
+		IsActive:      d.IsActive,
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+	}
+}
+
+type AllotmentNight struct {
+	StayDate time.Time `db:"stay_date"`
+	Allotted int       `db:"allotted"`
+	PickedUp int       `db:"picked_up"`
+}
+
+func (m *AllotmentNight) ToDomain() *domain.AllotmentNight {
+	return &domain.AllotmentNight{
+		StayDate: m.StayDate,
+		Allotted: m.Allotted,
+		PickedUp: m.PickedUp,
+	}
+}
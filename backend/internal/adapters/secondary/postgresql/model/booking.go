@@ -4,86 +4,127 @@ import (
 	"time"
 
 	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/lib/pq"
 )
 
 type Booking struct {
-	BookingID     int       `db:"booking_id"`
-	UserID        int       `db:"user_id"`
-	RatePlanID    int       `db:"rate_plan_id"`
-	RoomID        int       `db:"room_id"`
-	CheckInDate   time.Time `db:"check_in_date"`
-	CheckOutDate  time.Time `db:"check_out_date"`
-	NumAdults     int       `db:"num_adults"`
-	Status        string    `db:"status"`
-	RoomSubTotal  float64   `db:"room_subtotal"`
-	AddonSubTotal float64   `db:"addon_subtotal"`
-	TaxesAmount   float64   `db:"taxes_amount"`
-	TotalPrice    float64   `db:"total_price"`
-	CreatedAt     time.Time `db:"created_at"`
-	UpdatedAt     time.Time `db:"updated_at"`
-	ExpiredAt     time.Time `db:"expired_at"`
+	BookingID     int           `db:"booking_id"`
+	ReservationID int           `db:"reservation_id"`
+	UserID        int           `db:"user_id"`
+	RatePlanID    int           `db:"rate_plan_id"`
+	RoomID        int           `db:"room_id"`
+	CheckInDate   time.Time     `db:"check_in_date"`
+	CheckOutDate  time.Time     `db:"check_out_date"`
+	NumAdults     int           `db:"num_adults"`
+	ChildAges     pq.Int64Array `db:"child_ages"`
+	Status        string        `db:"status"`
+	RoomSubTotal  Amount        `db:"room_subtotal"`
+	AddonSubTotal Amount        `db:"addon_subtotal"`
+	TaxesAmount   Amount        `db:"taxes_amount"`
+	TotalPrice    Amount        `db:"total_price"`
+	CreatedAt     time.Time     `db:"created_at"`
+	UpdatedAt     time.Time     `db:"updated_at"`
+	ExpiredAt     *time.Time    `db:"expired_at"`
+
+	CancellationReason *string    `db:"cancellation_reason"`
+	CancellationFee    Amount     `db:"cancellation_fee"`
+	CancelledAt        *time.Time `db:"cancelled_at"`
+
+	PromotionID    *int    `db:"promotion_id"`
+	PromoCode      *string `db:"promo_code"`
+	DiscountAmount Amount  `db:"discount_amount"`
+
+	AllotmentID *int `db:"allotment_id"`
+
+	ConfirmationCode string `db:"confirmation_code"`
+
+	AmountPaid Amount `db:"amount_paid"`
 }
 
 func (m *Booking) ToDomain(addons []*BookingAddon) *domain.Booking {
 
 	var domainAddons []*domain.BookingAddon
 	for _, a := range addons {
-		domainAddons = append(domainAddons, &domain.BookingAddon{
-			BookingAddonID: a.BookingAddonID,
-			BookingID:      a.BookingID,
-			AddonID:        a.AddonID,
-			Quantity:       a.Quantity,
-			PriceAtBooking: a.PriceAtBooking,
-		})
+		domainAddons = append(domainAddons, a.ToDomain())
 	}
 
 	return &domain.Booking{
 		BookingID:     m.BookingID,
+		ReservationID: m.ReservationID,
 		UserID:        m.UserID,
 		RatePlanID:    m.RatePlanID,
 		RoomID:        m.RoomID,
 		CheckInDate:   m.CheckInDate,
 		CheckOutDate:  m.CheckOutDate,
 		NumAdults:     m.NumAdults,
+		ChildAges:     toInts(m.ChildAges),
 		Status:        m.Status,
-		RoomSubTotal:  m.RoomSubTotal,
-		AddonSubTotal: m.AddonSubTotal,
-		TaxesAmount:   m.TaxesAmount,
-		TotalPrice:    m.TotalPrice,
+		RoomSubTotal:  m.RoomSubTotal.Money(),
+		AddonSubTotal: m.AddonSubTotal.Money(),
+		TaxesAmount:   m.TaxesAmount.Money(),
+		TotalPrice:    m.TotalPrice.Money(),
 		CreatedAt:     m.CreatedAt,
 		UpdatedAt:     m.UpdatedAt,
-		ExpiredAt:     m.ExpiredAt,
+		ExpiredAt:     derefTime(m.ExpiredAt),
 		BookingAddon:  domainAddons,
+
+		CancellationReason: derefString(m.CancellationReason),
+		CancellationFee:    m.CancellationFee.Money(),
+		CancelledAt:        derefTime(m.CancelledAt),
+
+		PromotionID:    derefInt(m.PromotionID),
+		PromoCode:      derefString(m.PromoCode),
+		DiscountAmount: m.DiscountAmount.Money(),
+		AllotmentID:    derefInt(m.AllotmentID),
+		AmountPaid:     m.AmountPaid.Money(),
+
+		ConfirmationCode: m.ConfirmationCode,
 	}
 }
 
 func FromDomainBooking(booking *domain.Booking) *Booking {
 	return &Booking{
 		BookingID:     booking.BookingID,
+		ReservationID: booking.ReservationID,
 		UserID:        booking.UserID,
 		RatePlanID:    booking.RatePlanID,
 		RoomID:        booking.RoomID,
 		CheckInDate:   booking.CheckInDate,
 		CheckOutDate:  booking.CheckOutDate,
 		NumAdults:     booking.NumAdults,
+		ChildAges:     toInt64Array(booking.ChildAges),
 		Status:        booking.Status,
-		RoomSubTotal:  booking.RoomSubTotal,
-		AddonSubTotal: booking.AddonSubTotal,
-		TaxesAmount:   booking.TaxesAmount,
-		TotalPrice:    booking.TotalPrice,
+		RoomSubTotal:  AmountOf(booking.RoomSubTotal),
+		AddonSubTotal: AmountOf(booking.AddonSubTotal),
+		TaxesAmount:   AmountOf(booking.TaxesAmount),
+		TotalPrice:    AmountOf(booking.TotalPrice),
 		CreatedAt:     booking.CreatedAt,
 		UpdatedAt:     booking.UpdatedAt,
-		ExpiredAt:     booking.ExpiredAt,
+		ExpiredAt:     nullableTime(booking.ExpiredAt),
+
+		PromotionID:    nullableInt(booking.PromotionID),
+		PromoCode:      nullableString(booking.PromoCode),
+		DiscountAmount: AmountOf(booking.DiscountAmount),
+		AllotmentID:    nullableInt(booking.AllotmentID),
+		AmountPaid:     AmountOf(booking.AmountPaid),
+
+		ConfirmationCode: booking.ConfirmationCode,
 	}
 }
 
 type BookingAddon struct {
-	BookingAddonID int     `db:"booking_addon_id"`
-	BookingID      int     `db:"booking_id"`
-	AddonID        int     `db:"addon_id"`
-	AddonName      string  `db:"addon_name"`
-	Quantity       int     `db:"quantity"`
-	PriceAtBooking float64 `db:"price_at_time_of_booking"`
+	BookingAddonID int        `db:"booking_addon_id"`
+	BookingID      int        `db:"booking_id"`
+	AddonID        int        `db:"addon_id"`
+	AddonName      string     `db:"addon_name"`
+	Quantity       int        `db:"quantity"`
+	PriceAtBooking Amount     `db:"price_at_time_of_booking"`
+	ChargeUnit     string     `db:"charge_unit"`
+	Nights         int        `db:"nights"`
+	Guests         int        `db:"guests"`
+	ServiceDate    *time.Time `db:"service_date"`
+	SlotID         *int       `db:"slot_id"`
+	SlotName       string     `db:"slot_name"`
 }
 
 func (m *BookingAddon) ToDomain() *domain.BookingAddon {
@@ -93,7 +134,13 @@ func (m *BookingAddon) ToDomain() *domain.BookingAddon {
 		AddonID:        m.AddonID,
 		AddonName:      m.AddonName,
 		Quantity:       m.Quantity,
-		PriceAtBooking: m.PriceAtBooking,
+		PriceAtBooking: m.PriceAtBooking.Money(),
+		ChargeUnit:     m.ChargeUnit,
+		Nights:         m.Nights,
+		Guests:         m.Guests,
+		ServiceDate:    derefTime(m.ServiceDate),
+		SlotID:         derefInt(m.SlotID),
+		SlotName:       m.SlotName,
 	}
 }
 
@@ -103,7 +150,12 @@ func FromDomainBookingAddon(bookingAddon *domain.BookingAddon) *BookingAddon {
 		BookingID:      bookingAddon.BookingID,
 		AddonID:        bookingAddon.AddonID,
 		Quantity:       bookingAddon.Quantity,
-		PriceAtBooking: bookingAddon.PriceAtBooking,
+		PriceAtBooking: AmountOf(bookingAddon.PriceAtBooking),
+		ChargeUnit:     bookingAddon.ChargeUnit,
+		Nights:         bookingAddon.Nights,
+		Guests:         bookingAddon.Guests,
+		ServiceDate:    nullableTime(bookingAddon.ServiceDate),
+		SlotID:         nullableInt(bookingAddon.SlotID),
 	}
 }
 
@@ -114,6 +166,7 @@ type BookingDetail struct {
 	RoomTypeName string `db:"room_type_name"`
 	UserEmail    string `db:"user_email"`
 	UserName     string `db:"user_name"`
+	Balance      Amount `db:"balance"`
 }
 
 func (m *BookingDetail) ToDomainDetail(addons []*BookingAddon) *domain.BookingDetail {
@@ -125,25 +178,123 @@ func (m *BookingDetail) ToDomainDetail(addons []*BookingAddon) *domain.BookingDe
 
 	return &domain.BookingDetail{
 		BookingID:     m.BookingID,
+		ReservationID: m.ReservationID,
 		UserID:        m.UserID,
 		RatePlanID:    m.RatePlanID,
 		RoomID:        m.RoomID,
 		CheckInDate:   m.CheckInDate,
 		CheckOutDate:  m.CheckOutDate,
 		NumAdults:     m.NumAdults,
+		ChildAges:     toInts(m.ChildAges),
 		Status:        m.Status,
-		RoomSubTotal:  m.RoomSubTotal,
-		AddonSubTotal: m.AddonSubTotal,
-		TaxesAmount:   m.TaxesAmount,
-		TotalPrice:    m.TotalPrice,
+		RoomSubTotal:  m.RoomSubTotal.Money(),
+		AddonSubTotal: m.AddonSubTotal.Money(),
+		TaxesAmount:   m.TaxesAmount.Money(),
+		TotalPrice:    m.TotalPrice.Money(),
 		CreatedAt:     m.CreatedAt,
 		UpdatedAt:     m.UpdatedAt,
-		ExpiredAt:     m.ExpiredAt,
+		ExpiredAt:     derefTime(m.ExpiredAt),
 		BookingAddon:  domainAddons,
 		RatePlanName:  m.RatePlanName,
 		RoomNumber:    m.RoomNumber,
 		RoomTypeName:  m.RoomTypeName,
 		Email:         m.UserEmail,
 		UserName:      m.UserName,
+
+		CancellationReason: derefString(m.CancellationReason),
+		CancellationFee:    m.CancellationFee.Money(),
+		CancelledAt:        derefTime(m.CancelledAt),
+
+		PromotionID:    derefInt(m.PromotionID),
+		PromoCode:      derefString(m.PromoCode),
+		DiscountAmount: m.DiscountAmount.Money(),
+		AllotmentID:    derefInt(m.AllotmentID),
+		AmountPaid:     m.AmountPaid.Money(),
+		Balance:        m.Balance.Money(),
+
+		ConfirmationCode: m.ConfirmationCode,
+	}
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func derefTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+func derefInt(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+// nullableString maps the empty string to NULL.
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// nullableInt maps the zero value to NULL.
+func nullableInt(i int) *int {
+	if i == 0 {
+		return nil
+	}
+	return &i
+}
+
+type BookingStatusHistory struct {
+	HistoryID   int       `db:"history_id"`
+	BookingID   int       `db:"booking_id"`
+	ActorUserID *int      `db:"actor_user_id"`
+	OldStatus   *string   `db:"old_status"`
+	NewStatus   string    `db:"new_status"`
+	Reason      *string   `db:"reason"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func (m *BookingStatusHistory) ToDomain() *domain.BookingStatusHistory {
+	var actorID int
+	if m.ActorUserID != nil {
+		actorID = *m.ActorUserID
+	}
+
+	return &domain.BookingStatusHistory{
+		HistoryID:   m.HistoryID,
+		BookingID:   m.BookingID,
+		ActorUserID: actorID,
+		OldStatus:   derefString(m.OldStatus),
+		NewStatus:   m.NewStatus,
+		Reason:      derefString(m.Reason),
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+func FromDomainBookingStatusHistory(d *domain.BookingStatusHistory) *BookingStatusHistory {
+	m := &BookingStatusHistory{
+		HistoryID: d.HistoryID,
+		BookingID: d.BookingID,
+		NewStatus: d.NewStatus,
+		CreatedAt: d.CreatedAt,
+	}
+	if d.ActorUserID > 0 {
+		m.ActorUserID = &d.ActorUserID
+	}
+	if d.OldStatus != "" {
+		m.OldStatus = &d.OldStatus
+	}
+	if d.Reason != "" {
+		m.Reason = &d.Reason
 	}
+	return m
 }
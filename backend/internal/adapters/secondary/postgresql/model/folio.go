@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type FolioEntry struct {
+	EntryID     int       `db:"entry_id"`
+	BookingID   int       `db:"booking_id"`
+	EntryType   string    `db:"entry_type"`
+	Category    string    `db:"category"`
+	Description string    `db:"description"`
+	Amount      Amount    `db:"amount"`
+	Currency    string    `db:"currency"`
+	PaymentID   *int      `db:"payment_id"`
+	ActorUserID *int      `db:"actor_user_id"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func (m *FolioEntry) ToDomain() *domain.FolioEntry {
+	return &domain.FolioEntry{
+		EntryID:     m.EntryID,
+		BookingID:   m.BookingID,
+		EntryType:   m.EntryType,
+		Category:    m.Category,
+		Description: m.Description,
+		Amount:      domain.NewMoney(int64(m.Amount), m.Currency),
+		PaymentID:   derefInt(m.PaymentID),
+		ActorUserID: derefInt(m.ActorUserID),
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+func FromDomainFolioEntry(d *domain.FolioEntry) *FolioEntry {
+	currency := d.Amount.Currency
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+	return &FolioEntry{
+		EntryID:     d.EntryID,
+		BookingID:   d.BookingID,
+		EntryType:   d.EntryType,
+		Category:    d.Category,
+		Description: d.Description,
+		Amount:      AmountOf(d.Amount),
+		Currency:    currency,
+		PaymentID:   nullableInt(d.PaymentID),
+		ActorUserID: nullableInt(d.ActorUserID),
+		CreatedAt:   d.CreatedAt,
+	}
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type IdempotencyKey struct {
+	UserID         int            `db:"user_id"`
+	Key            string         `db:"idem_key"`
+	RequestHash    string         `db:"request_hash"`
+	Status         string         `db:"status"`
+	ResponseStatus sql.NullInt32  `db:"response_status"`
+	ContentType    sql.NullString `db:"content_type"`
+	ResponseBody   []byte         `db:"response_body"`
+	CreatedAt      time.Time      `db:"created_at"`
+	ExpiresAt      time.Time      `db:"expires_at"`
+	LockedUntil    time.Time      `db:"locked_until"`
+}
+
+func (m *IdempotencyKey) ToDomain() *domain.IdempotencyKey {
+	return &domain.IdempotencyKey{
+		UserID:         m.UserID,
+		Key:            m.Key,
+		RequestHash:    m.RequestHash,
+		Status:         m.Status,
+		ResponseStatus: int(m.ResponseStatus.Int32),
+		ContentType:    m.ContentType.String,
+		ResponseBody:   m.ResponseBody,
+		CreatedAt:      m.CreatedAt,
+		ExpiresAt:      m.ExpiresAt,
+		LockedUntil:    m.LockedUntil,
+	}
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type LoginAttempt struct {
+	Key           string     `db:"attempt_key"`
+	Failures      int        `db:"failures"`
+	FirstFailedAt time.Time  `db:"first_failed_at"`
+	LastFailedAt  time.Time  `db:"last_failed_at"`
+	LockedUntil   *time.Time `db:"locked_until"`
+}
+
+func (m *LoginAttempt) ToDomain() *domain.LoginAttempt {
+	return &domain.LoginAttempt{
+		Key:           m.Key,
+		Failures:      m.Failures,
+		FirstFailedAt: m.FirstFailedAt,
+		LastFailedAt:  m.LastFailedAt,
+		LockedUntil:   derefTime(m.LockedUntil),
+	}
+}
+
+type AuditEntry struct {
+	AuditID   int64     `db:"audit_id"`
+	UserID    *int      `db:"user_id"`
+	Event     string    `db:"event"`
+	Username  string    `db:"username"`
+	IPAddress string    `db:"ip_address"`
+	UserAgent string    `db:"user_agent"`
+	Details   string    `db:"details"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func FromDomainAuditEntry(d *domain.AuditEntry) *AuditEntry {
+	return &AuditEntry{
+		AuditID:   d.AuditID,
+		UserID:    nullableInt(d.UserID),
+		Event:     d.Event,
+		Username:  d.Username,
+		IPAddress: d.IPAddress,
+		UserAgent: d.UserAgent,
+		Details:   d.Details,
+		CreatedAt: d.CreatedAt,
+	}
+}
+
+func (m *AuditEntry) ToDomain() *domain.AuditEntry {
+	return &domain.AuditEntry{
+		AuditID:   m.AuditID,
+		UserID:    derefInt(m.UserID),
+		Event:     m.Event,
+		Username:  m.Username,
+		IPAddress: m.IPAddress,
+		UserAgent: m.UserAgent,
+		Details:   m.Details,
+		CreatedAt: m.CreatedAt,
+	}
+}
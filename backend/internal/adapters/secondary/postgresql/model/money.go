@@ -0,0 +1,51 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+// Amount maps a DECIMAL column to minor units of domain.DefaultCurrency.
+// Postgres hands NUMERIC values over as text, so no float64 is involved.
+type Amount int64
+
+func (a *Amount) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Errorf("cannot scan %T into Amount", src)
+	}
+
+	m, err := domain.ParseMoney(s, domain.DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	*a = Amount(m.Amount)
+	return nil
+}
+
+func (a Amount) Value() (driver.Value, error) {
+	return a.Money().String(), nil
+}
+
+func (a Amount) Money() domain.Money {
+	return domain.NewMoney(int64(a), domain.DefaultCurrency)
+}
+
+func AmountOf(m domain.Money) Amount {
+	return Amount(m.Amount)
+}
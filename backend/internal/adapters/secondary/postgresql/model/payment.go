@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type Payment struct {
+	PaymentID     int       `db:"payment_id"`
+	BookingID     *int      `db:"booking_id"`
+	ReservationID *int      `db:"reservation_id"`
+	Provider      string    `db:"provider"`
+	ProviderRef   string    `db:"provider_ref"`
+	Amount        Amount    `db:"amount"`
+	Currency      string    `db:"currency"`
+	Status        string    `db:"status"`
+	FailureReason *string   `db:"failure_reason"`
+	RecordedBy    *int      `db:"recorded_by"`
+	CreatedAt     time.Time `db:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
+
+func (m *Payment) ToDomain() *domain.Payment {
+	return &domain.Payment{
+		PaymentID:     m.PaymentID,
+		BookingID:     derefInt(m.BookingID),
+		ReservationID: derefInt(m.ReservationID),
+		Provider:      m.Provider,
+		ProviderRef:   m.ProviderRef,
+		Amount:        domain.NewMoney(int64(m.Amount), m.Currency),
+		Status:        m.Status,
+		FailureReason: derefString(m.FailureReason),
+		RecordedBy:    derefInt(m.RecordedBy),
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+}
+
+func FromDomainPayment(d *domain.Payment) *Payment {
+	currency := d.Amount.Currency
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+	return &Payment{
+		PaymentID:     d.PaymentID,
+		BookingID:     nullableInt(d.BookingID),
+		ReservationID: nullableInt(d.ReservationID),
+		Provider:      d.Provider,
+		ProviderRef:   d.ProviderRef,
+		Amount:        AmountOf(d.Amount),
+		Currency:      currency,
+		Status:        d.Status,
+		FailureReason: nullableString(d.FailureReason),
+		RecordedBy:    nullableInt(d.RecordedBy),
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+	}
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/lib/pq"
+)
+
+type Promotion struct {
+	PromotionID    int           `db:"promotion_id"`
+	Code           string        `db:"code"`
+	Description    *string       `db:"description"`
+	DiscountType   string        `db:"discount_type"`
+	DiscountRate   float64       `db:"discount_rate"`
+	DiscountAmount Amount        `db:"discount_amount"`
+	MinSpend       Amount        `db:"min_spend"`
+	BookingStart   *time.Time    `db:"booking_start"`
+	BookingEnd     *time.Time    `db:"booking_end"`
+	StayStart      *time.Time    `db:"stay_start"`
+	StayEnd        *time.Time    `db:"stay_end"`
+	RoomTypeIDs    pq.Int64Array `db:"room_type_ids"`
+	RatePlanIDs    pq.Int64Array `db:"rate_plan_ids"`
+	MaxRedemptions *int          `db:"max_redemptions"`
+	MaxPerUser     *int          `db:"max_per_user"`
+	IsActive       bool          `db:"is_active"`
+	Redemptions    int           `db:"redemptions"`
+	CreatedAt      time.Time     `db:"created_at"`
+	UpdatedAt      time.Time     `db:"updated_at"`
+}
+
+func (m *Promotion) ToDomain() *domain.Promotion {
+	return &domain.Promotion{
+		PromotionID:    m.PromotionID,
+		Code:           m.Code,
+		Description:    derefString(m.Description),
+		DiscountType:   m.DiscountType,
+		DiscountRate:   m.DiscountRate,
+		DiscountAmount: m.DiscountAmount.Money(),
+		MinSpend:       m.MinSpend.Money(),
+		BookingStart:   derefTime(m.BookingStart),
+		BookingEnd:     derefTime(m.BookingEnd),
+		StayStart:      derefTime(m.StayStart),
+		StayEnd:        derefTime(m.StayEnd),
+		RoomTypeIDs:    toInts(m.RoomTypeIDs),
+		RatePlanIDs:    toInts(m.RatePlanIDs),
+		MaxRedemptions: derefInt(m.MaxRedemptions),
+		MaxPerUser:     derefInt(m.MaxPerUser),
+		IsActive:       m.IsActive,
+		Redemptions:    m.Redemptions,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
+
+func FromDomainPromotion(d *domain.Promotion) *Promotion {
+	return &Promotion{
+		PromotionID:    d.PromotionID,
+		Code:           d.Code,
+		Description:    nullableString(d.Description),
+		DiscountType:   d.DiscountType,
+		DiscountRate:   d.DiscountRate,
+		DiscountAmount: AmountOf(d.DiscountAmount),
+		MinSpend:       AmountOf(d.MinSpend),
+		BookingStart:   nullableTime(d.BookingStart),
+		BookingEnd:     nullableTime(d.BookingEnd),
+		StayStart:      nullableTime(d.StayStart),
+		StayEnd:        nullableTime(d.StayEnd),
+		RoomTypeIDs:    toInt64Array(d.RoomTypeIDs),
+		RatePlanIDs:    toInt64Array(d.RatePlanIDs),
+		MaxRedemptions: nullableInt(d.MaxRedemptions),
+		MaxPerUser:     nullableInt(d.MaxPerUser),
+		IsActive:       d.IsActive,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+}
+
+func nullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func toInts(a pq.Int64Array) []int {
+	ids := make([]int, len(a))
+	for i, v := range a {
+		ids[i] = int(v)
+	}
+	return ids
+}
+
+func toInt64Array(ids []int) pq.Int64Array {
+	a := make(pq.Int64Array, len(ids))
+	for i, v := range ids {
+		a[i] = int64(v)
+	}
+	return a
+}
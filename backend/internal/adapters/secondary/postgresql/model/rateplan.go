@@ -7,51 +7,57 @@ import (
 )
 
 type RatePlan struct {
-	RatePlanID       int       `db:"rate_plan_id"`
-	Name             string    `db:"name"`
-	Description      string    `db:"description"`
-	IsSpecialPackage bool      `db:"is_special_package"`
-	AllowFreeCancel  bool      `db:"allow_free_cancel"`
-	AllowPayLater    bool      `db:"allow_pay_later"`
-	CreatedAt        time.Time `db:"created_at"`
-	UpdatedAt        time.Time `db:"updated_at"`
+	RatePlanID        int       `db:"rate_plan_id"`
+	Name              string    `db:"name"`
+	Description       string    `db:"description"`
+	IsSpecialPackage  bool      `db:"is_special_package"`
+	AllowFreeCancel   bool      `db:"allow_free_cancel"`
+	AllowPayLater     bool      `db:"allow_pay_later"`
+	DepositPercent    float64   `db:"deposit_percent"`
+	RequiresGuarantee bool      `db:"requires_guarantee"`
+	CreatedAt         time.Time `db:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at"`
 }
 
 func (m *RatePlan) ToDomain() *domain.RatePlan {
 	return &domain.RatePlan{
-		RatePlanID:       m.RatePlanID,
-		Name:             m.Name,
-		Description:      m.Description,
-		IsSpecialPackage: m.IsSpecialPackage,
-		AllowFreeCancel:  m.AllowFreeCancel,
-		AllowPayLater:    m.AllowPayLater,
-		CreatedAt:        m.CreatedAt,
-		UpdatedAt:        m.UpdatedAt,
+		RatePlanID:        m.RatePlanID,
+		Name:              m.Name,
+		Description:       m.Description,
+		IsSpecialPackage:  m.IsSpecialPackage,
+		AllowFreeCancel:   m.AllowFreeCancel,
+		AllowPayLater:     m.AllowPayLater,
+		DepositPercent:    m.DepositPercent,
+		RequiresGuarantee: m.RequiresGuarantee,
+		CreatedAt:         m.CreatedAt,
+		UpdatedAt:         m.UpdatedAt,
 	}
 }
 
 func FromDomainRatePlan(ratePlan *domain.RatePlan) *RatePlan {
 	return &RatePlan{
-		RatePlanID:       ratePlan.RatePlanID,
-		Name:             ratePlan.Name,
-		Description:      ratePlan.Description,
-		IsSpecialPackage: ratePlan.IsSpecialPackage,
-		AllowFreeCancel:  ratePlan.AllowFreeCancel,
-		AllowPayLater:    ratePlan.AllowPayLater,
+		RatePlanID:        ratePlan.RatePlanID,
+		Name:              ratePlan.Name,
+		Description:       ratePlan.Description,
+		IsSpecialPackage:  ratePlan.IsSpecialPackage,
+		AllowFreeCancel:   ratePlan.AllowFreeCancel,
+		AllowPayLater:     ratePlan.AllowPayLater,
+		DepositPercent:    ratePlan.DepositPercent,
+		RequiresGuarantee: ratePlan.RequiresGuarantee,
 	}
 }
 
 type RoomTypeRatePrice struct {
-	RoomTypeID int     `db:"room_type_id"`
-	RatePlanID int     `db:"rate_plan_id"`
-	Price      float64 `db:"price"`
+	RoomTypeID int    `db:"room_type_id"`
+	RatePlanID int    `db:"rate_plan_id"`
+	Price      Amount `db:"price"`
 }
 
 func (m *RoomTypeRatePrice) ToDomain() *domain.RoomTypeRatePrice {
 	return &domain.RoomTypeRatePrice{
 		RoomTypeID: m.RoomTypeID,
 		RatePlanID: m.RatePlanID,
-		Price:      m.Price,
+		Price:      m.Price.Money(),
 	}
 }
 
@@ -59,44 +65,115 @@ func FromDomainRoomTypeRatePrice(roomTypeRatePrice *domain.RoomTypeRatePrice) *R
 	return &RoomTypeRatePrice{
 		RoomTypeID: roomTypeRatePrice.RoomTypeID,
 		RatePlanID: roomTypeRatePrice.RatePlanID,
-		Price:      roomTypeRatePrice.Price,
+		Price:      AmountOf(roomTypeRatePrice.Price),
 	}
 }
 
 type RatePlanFull struct {
-	RatePlanID       int       `db:"rate_plan_id"`
-	Name             string    `db:"name"`
-	Description      string    `db:"description"`
-	IsSpecialPackage bool      `db:"is_special_package"`
-	AllowFreeCancel  bool      `db:"allow_free_cancel"`
-	AllowPayLater    bool      `db:"allow_pay_later"`
-	Price            float64   `db:"price"`
-	CreatedAt        time.Time `db:"created_at"`
-	UpdatedAt        time.Time `db:"updated_at"`
+	RatePlanID        int       `db:"rate_plan_id"`
+	Name              string    `db:"name"`
+	Description       string    `db:"description"`
+	IsSpecialPackage  bool      `db:"is_special_package"`
+	AllowFreeCancel   bool      `db:"allow_free_cancel"`
+	AllowPayLater     bool      `db:"allow_pay_later"`
+	DepositPercent    float64   `db:"deposit_percent"`
+	RequiresGuarantee bool      `db:"requires_guarantee"`
+	Price             Amount    `db:"price"`
+	CreatedAt         time.Time `db:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at"`
 }
 
 func (m *RatePlanFull) ToDomain() *domain.RatePlanFull {
 	return &domain.RatePlanFull{
-		RatePlanID:       m.RatePlanID,
-		Name:             m.Name,
-		Description:      m.Description,
-		IsSpecialPackage: m.IsSpecialPackage,
-		AllowFreeCancel:  m.AllowFreeCancel,
-		AllowPayLater:    m.AllowPayLater,
-		Price:            m.Price,
-		CreatedAt:        m.CreatedAt,
-		UpdatedAt:        m.UpdatedAt,
+		RatePlanID:        m.RatePlanID,
+		Name:              m.Name,
+		Description:       m.Description,
+		IsSpecialPackage:  m.IsSpecialPackage,
+		AllowFreeCancel:   m.AllowFreeCancel,
+		AllowPayLater:     m.AllowPayLater,
+		DepositPercent:    m.DepositPercent,
+		RequiresGuarantee: m.RequiresGuarantee,
+		Price:             m.Price.Money(),
+		CreatedAt:         m.CreatedAt,
+		UpdatedAt:         m.UpdatedAt,
 	}
 }
 
 func FromDomainRatePlanFull(ratePlanFull *domain.RatePlanFull) *RatePlanFull {
 	return &RatePlanFull{
-		RatePlanID:       ratePlanFull.RatePlanID,
-		Name:             ratePlanFull.Name,
-		Description:      ratePlanFull.Description,
-		IsSpecialPackage: ratePlanFull.IsSpecialPackage,
-		AllowFreeCancel:  ratePlanFull.AllowFreeCancel,
-		AllowPayLater:    ratePlanFull.AllowPayLater,
-		Price:            ratePlanFull.Price,
+		RatePlanID:        ratePlanFull.RatePlanID,
+		Name:              ratePlanFull.Name,
+		Description:       ratePlanFull.Description,
+		IsSpecialPackage:  ratePlanFull.IsSpecialPackage,
+		AllowFreeCancel:   ratePlanFull.AllowFreeCancel,
+		AllowPayLater:     ratePlanFull.AllowPayLater,
+		DepositPercent:    ratePlanFull.DepositPercent,
+		RequiresGuarantee: ratePlanFull.RequiresGuarantee,
+		Price:             AmountOf(ratePlanFull.Price),
+	}
+}
+
+type NightlyRate struct {
+	StayDate         time.Time `db:"stay_date"`
+	Price            Amount    `db:"price"`
+	ExtraGuestCharge Amount    `db:"extra_guest_charge"`
+}
+
+func (m *NightlyRate) ToDomain() *domain.NightlyRate {
+	return &domain.NightlyRate{
+		StayDate:         m.StayDate,
+		Price:            m.Price.Money(),
+		ExtraGuestCharge: m.ExtraGuestCharge.Money(),
+	}
+}
+
+func FromDomainNightlyRate(d *domain.NightlyRate) *NightlyRate {
+	return &NightlyRate{
+		StayDate:         d.StayDate,
+		Price:            AmountOf(d.Price),
+		ExtraGuestCharge: AmountOf(d.ExtraGuestCharge),
+	}
+}
+
+type OccupancyPricing struct {
+	RoomTypeID    int    `db:"room_type_id"`
+	RatePlanID    int    `db:"rate_plan_id"`
+	BaseOccupancy int    `db:"base_occupancy"`
+	ExtraAdultFee Amount `db:"extra_adult_fee"`
+}
+
+func (m *OccupancyPricing) ToDomain(bands []*ChildAgeBand) *domain.OccupancyPricing {
+	p := &domain.OccupancyPricing{
+		RoomTypeID:    m.RoomTypeID,
+		RatePlanID:    m.RatePlanID,
+		BaseOccupancy: m.BaseOccupancy,
+		ExtraAdultFee: m.ExtraAdultFee.Money(),
+	}
+	for _, b := range bands {
+		p.ChildFees = append(p.ChildFees, b.ToDomain())
+	}
+	return p
+}
+
+func FromDomainOccupancyPricing(d *domain.OccupancyPricing) *OccupancyPricing {
+	return &OccupancyPricing{
+		RoomTypeID:    d.RoomTypeID,
+		RatePlanID:    d.RatePlanID,
+		BaseOccupancy: d.BaseOccupancy,
+		ExtraAdultFee: AmountOf(d.ExtraAdultFee),
+	}
+}
+
+type ChildAgeBand struct {
+	MinAge int    `db:"min_age"`
+	MaxAge int    `db:"max_age"`
+	Fee    Amount `db:"fee"`
+}
+
+func (m *ChildAgeBand) ToDomain() *domain.ChildAgeBand {
+	return &domain.ChildAgeBand{
+		MinAge: m.MinAge,
+		MaxAge: m.MaxAge,
+		Fee:    m.Fee.Money(),
 	}
 }
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type RefreshToken struct {
+	TokenID   int        `db:"token_id"`
+	UserID    int        `db:"user_id"`
+	FamilyID  string     `db:"family_id"`
+	TokenHash string     `db:"token_hash"`
+	UserAgent string     `db:"user_agent"`
+	IPAddress string     `db:"ip_address"`
+	CreatedAt time.Time  `db:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+}
+
+func (m *RefreshToken) ToDomain() *domain.RefreshToken {
+	return &domain.RefreshToken{
+		TokenID:   m.TokenID,
+		UserID:    m.UserID,
+		FamilyID:  m.FamilyID,
+		TokenHash: m.TokenHash,
+		UserAgent: m.UserAgent,
+		IPAddress: m.IPAddress,
+		CreatedAt: m.CreatedAt,
+		ExpiresAt: m.ExpiresAt,
+		UsedAt:    derefTime(m.UsedAt),
+		RevokedAt: derefTime(m.RevokedAt),
+	}
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type Reservation struct {
+	ReservationID int       `db:"reservation_id"`
+	UserID        int       `db:"user_id"`
+	UserEmail     string    `db:"user_email"`
+	UserName      string    `db:"user_name"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+func (m *Reservation) ToDomain() *domain.ReservationDetail {
+	return &domain.ReservationDetail{
+		ReservationID: m.ReservationID,
+		UserID:        m.UserID,
+		Email:         m.UserEmail,
+		UserName:      m.UserName,
+		CreatedAt:     m.CreatedAt,
+	}
+}
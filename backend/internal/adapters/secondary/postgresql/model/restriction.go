@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type StayRestriction struct {
+	RestrictionID     int       `db:"restriction_id"`
+	RoomTypeID        int       `db:"room_type_id"`
+	RatePlanID        *int      `db:"rate_plan_id"`
+	StartDate         time.Time `db:"start_date"`
+	EndDate           time.Time `db:"end_date"`
+	MinLOS            *int      `db:"min_los"`
+	MaxLOS            *int      `db:"max_los"`
+	ClosedToArrival   bool      `db:"closed_to_arrival"`
+	ClosedToDeparture bool      `db:"closed_to_departure"`
+	StopSell          bool      `db:"stop_sell"`
+	CreatedAt         time.Time `db:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at"`
+}
+
+func (m *StayRestriction) ToDomain() *domain.StayRestriction {
+	return &domain.StayRestriction{
+		RestrictionID:     m.RestrictionID,
+		RoomTypeID:        m.RoomTypeID,
+		RatePlanID:        derefInt(m.RatePlanID),
+		StartDate:         m.StartDate,
+		EndDate:           m.EndDate,
+		MinLOS:            derefInt(m.MinLOS),
+		MaxLOS:            derefInt(m.MaxLOS),
+		ClosedToArrival:   m.ClosedToArrival,
+		ClosedToDeparture: m.ClosedToDeparture,
+		StopSell:          m.StopSell,
+		CreatedAt:         m.CreatedAt,
+		UpdatedAt:         m.UpdatedAt,
+	}
+}
+
+func FromDomainStayRestriction(d *domain.StayRestriction) *StayRestriction {
+	return &StayRestriction{
+		RestrictionID:     d.RestrictionID,
+		RoomTypeID:        d.RoomTypeID,
+		RatePlanID:        nullableInt(d.RatePlanID),
+		StartDate:         d.StartDate,
+		EndDate:           d.EndDate,
+		MinLOS:            nullableInt(d.MinLOS),
+		MaxLOS:            nullableInt(d.MaxLOS),
+		ClosedToArrival:   d.ClosedToArrival,
+		ClosedToDeparture: d.ClosedToDeparture,
+		StopSell:          d.StopSell,
+		CreatedAt:         d.CreatedAt,
+		UpdatedAt:         d.UpdatedAt,
+	}
+}
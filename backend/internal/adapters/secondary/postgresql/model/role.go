@@ -0,0 +1,26 @@
+package model
+
+import (
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/lib/pq"
+)
+
+type Role struct {
+	RoleID      int            `db:"role_id"`
+	Name        string         `db:"name"`
+	Description string         `db:"description"`
+	Permissions pq.StringArray `db:"permissions"`
+
+	RequireTwoFactor bool `db:"require_two_factor"`
+}
+
+func (m *Role) ToDomain() *domain.Role {
+	return &domain.Role{
+		RoleID:      m.RoleID,
+		Name:        m.Name,
+		Description: m.Description,
+		Permissions: []string(m.Permissions),
+
+		RequireTwoFactor: m.RequireTwoFactor,
+	}
+}
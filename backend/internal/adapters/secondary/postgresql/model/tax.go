@@ -0,0 +1,84 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type TaxRule struct {
+	TaxRuleID  int       `db:"tax_rule_id"`
+	Name       string    `db:"name"`
+	CalcType   string    `db:"calc_type"`
+	Rate       float64   `db:"rate"`
+	Amount     Amount    `db:"amount"`
+	Basis      string    `db:"basis"`
+	AppliesTo  string    `db:"applies_to"`
+	IsCompound bool      `db:"is_compound"`
+	Priority   int       `db:"priority"`
+	IsActive   bool      `db:"is_active"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+func (m *TaxRule) ToDomain() *domain.TaxRule {
+	return &domain.TaxRule{
+		TaxRuleID:  m.TaxRuleID,
+		Name:       m.Name,
+		CalcType:   m.CalcType,
+		Rate:       m.Rate,
+		Amount:     m.Amount.Money(),
+		Basis:      m.Basis,
+		AppliesTo:  m.AppliesTo,
+		IsCompound: m.IsCompound,
+		Priority:   m.Priority,
+		IsActive:   m.IsActive,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+}
+
+func FromDomainTaxRule(d *domain.TaxRule) *TaxRule {
+	return &TaxRule{
+		TaxRuleID:  d.TaxRuleID,
+		Name:       d.Name,
+		CalcType:   d.CalcType,
+		Rate:       d.Rate,
+		Amount:     AmountOf(d.Amount),
+		Basis:      d.Basis,
+		AppliesTo:  d.AppliesTo,
+		IsCompound: d.IsCompound,
+		Priority:   d.Priority,
+		IsActive:   d.IsActive,
+		CreatedAt:  d.CreatedAt,
+		UpdatedAt:  d.UpdatedAt,
+	}
+}
+
+type BookingTax struct {
+	BookingTaxID int    `db:"booking_tax_id"`
+	BookingID    int    `db:"booking_id"`
+	TaxRuleID    *int   `db:"tax_rule_id"`
+	Name         string `db:"name"`
+	Amount       Amount `db:"amount"`
+}
+
+func (m *BookingTax) ToDomain() *domain.BookingTax {
+	return &domain.BookingTax{
+		BookingTaxID: m.BookingTaxID,
+		BookingID:    m.BookingID,
+		TaxRuleID:    derefInt(m.TaxRuleID),
+		Name:         m.Name,
+		Amount:       m.Amount.Money(),
+	}
+}
+
+func FromDomainBookingTax(d *domain.BookingTax) *BookingTax {
+	return &BookingTax{
+		BookingTaxID: d.BookingTaxID,
+		BookingID:    d.BookingID,
+		TaxRuleID:    nullableInt(d.TaxRuleID),
+		Name:         d.Name,
+		Amount:       AmountOf(d.Amount),
+	}
+}
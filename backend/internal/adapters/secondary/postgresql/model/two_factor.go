@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type TOTPSecret struct {
+	UserID       int        `db:"user_id"`
+	Secret       string     `db:"secret"`
+	CreatedAt    time.Time  `db:"created_at"`
+	ConfirmedAt  *time.Time `db:"confirmed_at"`
+	LastUsedStep int64      `db:"last_used_step"`
+}
+
+func (m *TOTPSecret) ToDomain() *domain.TOTPSecret {
+	return &domain.TOTPSecret{
+		UserID:       m.UserID,
+		Secret:       m.Secret,
+		CreatedAt:    m.CreatedAt,
+		ConfirmedAt:  derefTime(m.ConfirmedAt),
+		LastUsedStep: m.LastUsedStep,
+	}
+}
+
+type LoginChallenge struct {
+	ChallengeID    int        `db:"challenge_id"`
+	UserID         int        `db:"user_id"`
+	TokenHash      string     `db:"token_hash"`
+	FailedAttempts int        `db:"failed_attempts"`
+	CreatedAt      time.Time  `db:"created_at"`
+	ExpiresAt      time.Time  `db:"expires_at"`
+	UsedAt         *time.Time `db:"used_at"`
+}
+
+func (m *LoginChallenge) ToDomain() *domain.LoginChallenge {
+	return &domain.LoginChallenge{
+		ChallengeID:    m.ChallengeID,
+		UserID:         m.UserID,
+		TokenHash:      m.TokenHash,
+		FailedAttempts: m.FailedAttempts,
+		CreatedAt:      m.CreatedAt,
+		ExpiresAt:      m.ExpiresAt,
+		UsedAt:         derefTime(m.UsedAt),
+	}
+}
@@ -1,13 +1,31 @@
 package model
 
-import "github.com/ingwrok/hotelBooking/internal/core/domain"
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
 
 type User struct {
 	UserID       int    `db:"user_id"`
 	Username     string `db:"username"`
 	Email        string `db:"email"`
 	PasswordHash string `db:"password_hash"`
-	IsAdmin      bool   `db:"is_admin"`
+	TokenVersion int    `db:"token_version"`
+
+	EmailVerifiedAt *time.Time `db:"email_verified_at"`
+}
+
+// UserAccess is one role or permission name held by a user.
+type UserAccess struct {
+	UserID int    `db:"user_id"`
+	Name   string `db:"name"`
+}
+
+type UserTwoFactor struct {
+	UserID   int  `db:"user_id"`
+	Enabled  bool `db:"enabled"`
+	Required bool `db:"required"`
 }
 
 func (m *User) ToDomain() *domain.User {
@@ -16,7 +34,9 @@ func (m *User) ToDomain() *domain.User {
 		Username:     m.Username,
 		Email:        m.Email,
 		PasswordHash: m.PasswordHash,
-		IsAdmin:      m.IsAdmin,
+		TokenVersion: m.TokenVersion,
+
+		EmailVerifiedAt: derefTime(m.EmailVerifiedAt),
 	}
 }
 
@@ -26,6 +46,32 @@ func FromDomain(d *domain.User) *User {
 		Username:     d.Username,
 		Email:        d.Email,
 		PasswordHash: d.PasswordHash,
-		IsAdmin:      d.IsAdmin,
+		TokenVersion: d.TokenVersion,
+
+		EmailVerifiedAt: nullableTime(d.EmailVerifiedAt),
+	}
+}
+
+type AccountToken struct {
+	TokenID   int        `db:"token_id"`
+	UserID    int        `db:"user_id"`
+	Purpose   string     `db:"purpose"`
+	TokenHash string     `db:"token_hash"`
+	Email     string     `db:"email"`
+	CreatedAt time.Time  `db:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+}
+
+func (m *AccountToken) ToDomain() *domain.AccountToken {
+	return &domain.AccountToken{
+		TokenID:   m.TokenID,
+		UserID:    m.UserID,
+		Purpose:   m.Purpose,
+		TokenHash: m.TokenHash,
+		Email:     m.Email,
+		CreatedAt: m.CreatedAt,
+		ExpiresAt: m.ExpiresAt,
+		UsedAt:    derefTime(m.UsedAt),
 	}
 }
\ No newline at end of file
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/lib/pq"
+)
+
+type WaitlistEntry struct {
+	EntryID        int           `db:"entry_id"`
+	UserID         int           `db:"user_id"`
+	RoomTypeID     int           `db:"room_type_id"`
+	RatePlanID     int           `db:"rate_plan_id"`
+	CheckInDate    time.Time     `db:"check_in_date"`
+	CheckOutDate   time.Time     `db:"check_out_date"`
+	NumAdults      int           `db:"num_adults"`
+	ChildAges      pq.Int64Array `db:"child_ages"`
+	Email          string        `db:"email"`
+	Status         string        `db:"status"`
+	BookingID      *int          `db:"booking_id"`
+	OfferedAt      *time.Time    `db:"offered_at"`
+	OfferExpiresAt *time.Time    `db:"offer_expires_at"`
+	CreatedAt      time.Time     `db:"created_at"`
+	UpdatedAt      time.Time     `db:"updated_at"`
+}
+
+func (m *WaitlistEntry) ToDomain() *domain.WaitlistEntry {
+	return &domain.WaitlistEntry{
+		EntryID:        m.EntryID,
+		UserID:         m.UserID,
+		RoomTypeID:     m.RoomTypeID,
+		RatePlanID:     m.RatePlanID,
+		CheckInDate:    m.CheckInDate,
+		CheckOutDate:   m.CheckOutDate,
+		NumAdults:      m.NumAdults,
+		ChildAges:      toInts(m.ChildAges),
+		Email:          m.Email,
+		Status:         m.Status,
+		BookingID:      derefInt(m.BookingID),
+		OfferedAt:      derefTime(m.OfferedAt),
+		OfferExpiresAt: derefTime(m.OfferExpiresAt),
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
+
+func FromDomainWaitlistEntry(d *domain.WaitlistEntry) *WaitlistEntry {
+	return &WaitlistEntry{
+		EntryID:        d.EntryID,
+		UserID:         d.UserID,
+		RoomTypeID:     d.RoomTypeID,
+		RatePlanID:     d.RatePlanID,
+		CheckInDate:    d.CheckInDate,
+		CheckOutDate:   d.CheckOutDate,
+		NumAdults:      d.NumAdults,
+		ChildAges:      toInt64Array(d.ChildAges),
+		Email:          d.Email,
+		Status:         d.Status,
+		BookingID:      nullableInt(d.BookingID),
+		OfferedAt:      nullableTime(d.OfferedAt),
+		OfferExpiresAt: nullableTime(d.OfferExpiresAt),
+	}
+}
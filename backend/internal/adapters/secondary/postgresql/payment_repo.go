@@ -0,0 +1,618 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql/model"
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type PaymentRepository struct {
+	db *sqlx.DB
+}
+
+func NewPaymentRepository(db *sqlx.DB) ports.PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+const paymentColumns = `payment_id, booking_id, reservation_id, provider, provider_ref, amount, currency, status, failure_reason, recorded_by, created_at, updated_at`
+
+func (r *PaymentRepository) CreatePayment(ctx context.Context, payment *domain.Payment) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	reservationID, inFlight, err := lockPendingPayments(ctx, tx, payment.BookingID, payment.ReservationID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return paymentNotFound(payment)
+		}
+		return err
+	}
+	if inFlight {
+		return fmt.Errorf("a payment for reservation %d is already pending: %w", reservationID, errs.ErrConflict)
+	}
+
+	m := model.FromDomainPayment(payment)
+	err = insertPayment(ctx, tx, m).Scan(&payment.PaymentID, &payment.CreatedAt, &payment.UpdatedAt)
+	if err != nil {
+		return paymentInsertError(err, payment)
+	}
+
+	return tx.Commit()
+}
+
+// lockPendingPayments locks the reservation of a booking, or the reservation
+// itself when bookingID is 0, and reports whether any of the payments
+// GetPaymentsByBookingID or GetPaymentsByReservationID returns is pending.
+// Holding the lock serialises starting payments against each other and
+// against stay changes.
+func lockPendingPayments(ctx context.Context, tx *sqlx.Tx, bookingID, reservationID int) (int, bool, error) {
+	q := `SELECT reservation_id FROM reservations
+				WHERE reservation_id = COALESCE(NULLIF($2::int, 0), (SELECT reservation_id FROM bookings WHERE booking_id = $1))
+				FOR UPDATE`
+	if err := tx.QueryRowContext(ctx, q, bookingID, reservationID).Scan(&reservationID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, false, fmt.Errorf("booking id %d, reservation id %d: %w", bookingID, reservationID, errs.ErrNotFound)
+		}
+		return 0, false, err
+	}
+
+	q = `SELECT EXISTS (SELECT 1 FROM payments
+				WHERE status = $1
+					AND (reservation_id = $2 OR booking_id = $3))`
+	args := []interface{}{domain.PaymentStatusPending, reservationID, bookingID}
+	if bookingID == 0 {
+		q = `SELECT EXISTS (SELECT 1 FROM payments
+				WHERE status = $1
+					AND (reservation_id = $2 OR booking_id IN (SELECT booking_id FROM bookings WHERE reservation_id = $2)))`
+		args = args[:2]
+	}
+	var pending bool
+	if err := tx.GetContext(ctx, &pending, q, args...); err != nil {
+		return 0, false, err
+	}
+	return reservationID, pending, nil
+}
+
+func (r *PaymentRepository) RecordPayment(ctx context.Context, payment *domain.Payment, reason string) (string, bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", false, err
+	}
+	defer tx.Rollback()
+
+	m := model.FromDomainPayment(payment)
+	err = insertPayment(ctx, tx, m).Scan(&payment.PaymentID, &payment.CreatedAt, &payment.UpdatedAt)
+	if err != nil {
+		return "", false, paymentInsertError(err, payment)
+	}
+
+	previous, confirmed, err := creditBooking(ctx, tx, payment, reason)
+	if err != nil {
+		return "", false, err
+	}
+
+	return previous, confirmed, tx.Commit()
+}
+
+func insertPayment(ctx context.Context, db sqlx.QueryerContext, m *model.Payment) *sqlx.Row {
+	q := `INSERT INTO payments (booking_id, reservation_id, provider, provider_ref, amount, currency, status, failure_reason, recorded_by)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+				RETURNING payment_id, created_at, updated_at`
+
+	return db.QueryRowxContext(ctx, q, m.BookingID, m.ReservationID, m.Provider, m.ProviderRef, m.Amount, m.Currency,
+		m.Status, m.FailureReason, m.RecordedBy)
+}
+
+func paymentInsertError(err error, payment *domain.Payment) error {
+	if hasPgCode(err, pgForeignKeyViolation) {
+		return paymentNotFound(payment)
+	}
+	if hasPgCode(err, pgUniqueViolation) {
+		return fmt.Errorf("payment %s/%s already recorded: %w", payment.Provider, payment.ProviderRef, errs.ErrConflict)
+	}
+	return err
+}
+
+func paymentNotFound(payment *domain.Payment) error {
+	if payment.BookingID == 0 {
+		return fmt.Errorf("reservation id %d: %w", payment.ReservationID, errs.ErrNotFound)
+	}
+	return fmt.Errorf("booking id %d: %w", payment.BookingID, errs.ErrNotFound)
+}
+
+func (r *PaymentRepository) GetPaymentByProviderRef(ctx context.Context, provider, providerRef string) (*domain.Payment, error) {
+	var m model.Payment
+	q := `SELECT ` + paymentColumns + ` FROM payments WHERE provider = $1 AND provider_ref = $2`
+
+	err := r.db.GetContext(ctx, &m, q, provider, providerRef)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("payment %s/%s: %w", provider, providerRef, errs.ErrNotFound)
+		}
+		return nil, err
+	}
+
+	return m.ToDomain(), nil
+}
+
+// GetPaymentsByBookingID also returns the payments taken for the booking's
+// whole reservation, since part of each may have been credited to it.
+func (r *PaymentRepository) GetPaymentsByBookingID(ctx context.Context, bookingID int) ([]*domain.Payment, error) {
+	q := `SELECT ` + paymentColumns + ` FROM payments
+				WHERE booking_id = $1
+					OR reservation_id = (SELECT reservation_id FROM bookings WHERE booking_id = $1)
+				ORDER BY created_at, payment_id`
+
+	return r.selectPayments(ctx, q, bookingID)
+}
+
+// GetPaymentsByReservationID returns the reservation's own payments along with
+// those taken for any one of its bookings.
+func (r *PaymentRepository) GetPaymentsByReservationID(ctx context.Context, reservationID int) ([]*domain.Payment, error) {
+	q := `SELECT ` + paymentColumns + ` FROM payments
+				WHERE reservation_id = $1
+					OR booking_id IN (SELECT booking_id FROM bookings WHERE reservation_id = $1)
+				ORDER BY created_at, payment_id`
+
+	return r.selectPayments(ctx, q, reservationID)
+}
+
+func (r *PaymentRepository) selectPayments(ctx context.Context, q string, args ...interface{}) ([]*domain.Payment, error) {
+	var models []model.Payment
+	err := r.db.SelectContext(ctx, &models, q, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	payments := make([]*domain.Payment, len(models))
+	for i := range models {
+		payments[i] = models[i].ToDomain()
+	}
+	return payments, nil
+}
+
+func (r *PaymentRepository) UpdatePaymentStatus(ctx context.Context, paymentID int, oldStatus, newStatus, reason string) error {
+	q := `UPDATE payments
+				SET status = $1,
+					failure_reason = COALESCE(NULLIF($2, ''), failure_reason),
+					updated_at = NOW()
+				WHERE payment_id = $3 AND status = $4`
+
+	result, err := r.db.ExecContext(ctx, q, newStatus, reason, paymentID, oldStatus)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("no payment found with id %d and status %s: %w", paymentID, oldStatus, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PaymentRepository) ConfirmPayment(ctx context.Context, paymentID int, reason string) (string, bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", false, err
+	}
+	defer tx.Rollback()
+
+	q := `UPDATE payments SET status = $1, updated_at = NOW()
+				WHERE payment_id = $2 AND status = $3
+				RETURNING ` + paymentColumns
+
+	var m model.Payment
+	err = tx.QueryRowxContext(ctx, q, domain.PaymentStatusSucceeded, paymentID, domain.PaymentStatusPending).StructScan(&m)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", false, fmt.Errorf("no pending payment found with id %d: %w", paymentID, errs.ErrNotFound)
+		}
+		return "", false, err
+	}
+
+	previous, confirmed, err := creditBooking(ctx, tx, m.ToDomain(), reason)
+	if err != nil {
+		return "", false, err
+	}
+
+	return previous, confirmed, tx.Commit()
+}
+
+func (r *PaymentRepository) ReserveRefund(ctx context.Context, paymentID int, amount domain.Money) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Locking the payment serialises refunds against it.
+	m, err := lockPayment(ctx, tx, paymentID)
+	if err != nil {
+		return 0, err
+	}
+
+	refunded, err := refundedAmount(ctx, tx, paymentID)
+	if err != nil {
+		return 0, err
+	}
+	remaining, err := m.Amount.Money().Sub(refunded)
+	if err != nil {
+		return 0, err
+	}
+	tooMuch, err := amount.GreaterThan(remaining)
+	if err != nil {
+		return 0, err
+	}
+	if m.Status != domain.PaymentStatusSucceeded || tooMuch {
+		return 0, fmt.Errorf("refund of %s exceeds what is left of payment %d: %w", amount, paymentID, errs.ErrConflict)
+	}
+
+	var refundID int
+	q := `INSERT INTO refunds (payment_id, amount) VALUES ($1, $2) RETURNING refund_id`
+	if err := tx.QueryRowxContext(ctx, q, paymentID, model.AmountOf(amount)).Scan(&refundID); err != nil {
+		return 0, err
+	}
+
+	return refundID, tx.Commit()
+}
+
+func (r *PaymentRepository) RecordRefund(ctx context.Context, refundID int, entry *domain.FolioEntry) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	m, err := lockPayment(ctx, tx, entry.PaymentID)
+	if err != nil {
+		return err
+	}
+
+	q := `UPDATE refunds SET status = $1, updated_at = NOW()
+				WHERE refund_id = $2 AND payment_id = $3 AND status = $4`
+	res, err := tx.ExecContext(ctx, q, domain.RefundStatusSucceeded, refundID, entry.PaymentID, domain.RefundStatusPending)
+	if err != nil {
+		return err
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return err
+	} else if rows == 0 {
+		return fmt.Errorf("pending refund id %d: %w", refundID, errs.ErrNotFound)
+	}
+
+	if entry.BookingID == 0 {
+		entry.BookingID = m.ToDomain().BookingID
+	}
+	var entries []*domain.FolioEntry
+	if entry.BookingID != 0 {
+		entries = []*domain.FolioEntry{entry}
+	} else {
+		entries, err = allocateRefund(ctx, tx, entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, e := range entries {
+		if err := insertFolioEntries(ctx, tx, e.BookingID, []*domain.FolioEntry{e}); err != nil {
+			return err
+		}
+		if err := syncAmountPaid(ctx, tx, e.BookingID); err != nil {
+			return err
+		}
+	}
+	if entries[0] != entry {
+		// The caller's entry stands for the whole refund.
+		entry.EntryID, entry.BookingID, entry.CreatedAt = entries[0].EntryID, entries[0].BookingID, entries[0].CreatedAt
+	}
+
+	posted, err := postedRefunds(ctx, tx, entry.PaymentID)
+	if err != nil {
+		return err
+	}
+	if posted == m.Amount.Money() {
+		q := `UPDATE payments SET status = $1, updated_at = NOW() WHERE payment_id = $2`
+		if _, err := tx.ExecContext(ctx, q, domain.PaymentStatusRefunded, entry.PaymentID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *PaymentRepository) ReleaseRefund(ctx context.Context, refundID int, reason string) error {
+	q := `UPDATE refunds SET status = $1, failure_reason = $2, updated_at = NOW()
+				WHERE refund_id = $3 AND status = $4`
+	res, err := r.db.ExecContext(ctx, q, domain.RefundStatusFailed, reason, refundID, domain.RefundStatusPending)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("pending refund id %d: %w", refundID, errs.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *PaymentRepository) GetRefundedAmount(ctx context.Context, paymentID int) (domain.Money, error) {
+	return refundedAmount(ctx, r.db, paymentID)
+}
+
+func lockPayment(ctx context.Context, tx *sqlx.Tx, paymentID int) (*model.Payment, error) {
+	var m model.Payment
+	q := `SELECT ` + paymentColumns + ` FROM payments WHERE payment_id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &m, q, paymentID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("payment id %d: %w", paymentID, errs.ErrNotFound)
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// refundedAmount is what has been refunded of a payment plus what is reserved
+// for refunds still waiting on the provider.
+func refundedAmount(ctx context.Context, db sqlx.QueryerContext, paymentID int) (domain.Money, error) {
+	posted, err := postedRefunds(ctx, db, paymentID)
+	if err != nil {
+		return domain.Money{}, err
+	}
+
+	var pending model.Amount
+	q := `SELECT COALESCE(SUM(amount), 0) FROM refunds WHERE payment_id = $1 AND status = $2`
+	if err := sqlx.GetContext(ctx, db, &pending, q, paymentID, domain.RefundStatusPending); err != nil {
+		return domain.Money{}, err
+	}
+	return posted.Add(pending.Money())
+}
+
+func postedRefunds(ctx context.Context, db sqlx.QueryerContext, paymentID int) (domain.Money, error) {
+	var refunded model.Amount
+	q := `SELECT COALESCE(SUM(amount), 0) FROM folio_entries WHERE payment_id = $1 AND entry_type = 'refund'`
+	if err := sqlx.GetContext(ctx, db, &refunded, q, paymentID); err != nil {
+		return domain.Money{}, err
+	}
+	return refunded.Money(), nil
+}
+
+// allocateRefund splits a refund of a reservation payment across the bookings
+// the payment was credited to, taking back from the last booking first.
+func allocateRefund(ctx context.Context, tx *sqlx.Tx, entry *domain.FolioEntry) ([]*domain.FolioEntry, error) {
+	var credits []struct {
+		BookingID int          `db:"booking_id"`
+		Net       model.Amount `db:"net"`
+	}
+	q := `SELECT booking_id, -SUM(amount) AS net FROM folio_entries
+				WHERE payment_id = $1
+				GROUP BY booking_id
+				HAVING -SUM(amount) > 0
+				ORDER BY booking_id DESC`
+	if err := tx.SelectContext(ctx, &credits, q, entry.PaymentID); err != nil {
+		return nil, err
+	}
+
+	var entries []*domain.FolioEntry
+	left := entry.Amount
+	for _, c := range credits {
+		if !left.IsPositive() {
+			break
+		}
+		part, err := left.Min(c.Net.Money())
+		if err != nil {
+			return nil, err
+		}
+		e := *entry
+		e.BookingID = c.BookingID
+		e.Amount = part
+		entries = append(entries, &e)
+		if left, err = left.Sub(part); err != nil {
+			return nil, err
+		}
+	}
+	if left.IsPositive() {
+		return nil, fmt.Errorf("refund of %s exceeds what was credited from payment %d: %w", entry.Amount, entry.PaymentID, errs.ErrConflict)
+	}
+	return entries, nil
+}
+
+// creditBooking posts a succeeded payment to the folio and returns the
+// booking's status from before and whether the payment confirmed it. Money that
+// arrived is always posted; on top of that, a pending booking is confirmed once
+// what it has been paid covers the amount due on its rate plan. Bookings that
+// no longer take payments are otherwise left alone so the caller can refund
+// the payment.
+//
+// A reservation payment is shared out over the reservation's bookings, each
+// taking up to its balance in booking order with anything over going to the
+// last one. Every pending booking it covers is confirmed, and the status
+// returned is pending if any of them was, else that of the last booking still
+// open. It reports confirmed when at least one booking was.
+func creditBooking(ctx context.Context, tx *sqlx.Tx, payment *domain.Payment, reason string) (string, bool, error) {
+	if payment.BookingID == 0 {
+		return creditReservation(ctx, tx, payment, reason)
+	}
+
+	var status string
+	err := tx.QueryRowContext(ctx, `SELECT status FROM bookings WHERE booking_id = $1 FOR UPDATE`, payment.BookingID).Scan(&status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", false, fmt.Errorf("booking id %d: %w", payment.BookingID, errs.ErrNotFound)
+		}
+		return "", false, err
+	}
+
+	if err := postPayment(ctx, tx, payment, payment.BookingID, payment.Amount); err != nil {
+		return "", false, err
+	}
+
+	confirmed := false
+	if status == domain.BookingStatusPending {
+		if confirmed, err = confirmIfPaid(ctx, tx, payment.BookingID, payment.RecordedBy, reason); err != nil {
+			return "", false, err
+		}
+	}
+
+	return status, confirmed, nil
+}
+
+func creditReservation(ctx context.Context, tx *sqlx.Tx, payment *domain.Payment, reason string) (string, bool, error) {
+	var lines []struct {
+		BookingID int          `db:"booking_id"`
+		Status    string       `db:"status"`
+		Balance   model.Amount `db:"balance"`
+	}
+	q := `SELECT b.booking_id, b.status, ` + folioBalanceColumn + `
+				FROM bookings b
+				WHERE b.reservation_id = $1
+				ORDER BY b.booking_id
+				FOR UPDATE OF b`
+	if err := tx.SelectContext(ctx, &lines, q, payment.ReservationID); err != nil {
+		return "", false, err
+	}
+	if len(lines) == 0 {
+		return "", false, fmt.Errorf("reservation id %d: %w", payment.ReservationID, errs.ErrNotFound)
+	}
+
+	// Only bookings that still take payments share in it; if none do, it all
+	// goes to the last booking so it can be refunded from there.
+	var open []int
+	for i, line := range lines {
+		switch line.Status {
+		case domain.BookingStatusPending, domain.BookingStatusConfirmed, domain.BookingStatusCheckedIn:
+			open = append(open, i)
+		}
+	}
+	if len(open) == 0 {
+		last := lines[len(lines)-1]
+		if err := postPayment(ctx, tx, payment, last.BookingID, payment.Amount); err != nil {
+			return "", false, err
+		}
+		return last.Status, false, nil
+	}
+
+	status := lines[open[len(open)-1]].Status
+	confirmed := false
+	left := payment.Amount
+	for n, i := range open {
+		line := lines[i]
+		part := left
+		if n < len(open)-1 {
+			var err error
+			if part, err = left.Min(line.Balance.Money()); err != nil {
+				return "", false, err
+			}
+			if part.IsNegative() {
+				part = domain.NewMoney(0, left.Currency)
+			}
+		}
+		if part.IsPositive() {
+			if err := postPayment(ctx, tx, payment, line.BookingID, part); err != nil {
+				return "", false, err
+			}
+			var err error
+			if left, err = left.Sub(part); err != nil {
+				return "", false, err
+			}
+		}
+
+		if line.Status == domain.BookingStatusPending {
+			status = domain.BookingStatusPending
+			ok, err := confirmIfPaid(ctx, tx, line.BookingID, payment.RecordedBy, reason)
+			if err != nil {
+				return "", false, err
+			}
+			confirmed = confirmed || ok
+		}
+	}
+
+	return status, confirmed, nil
+}
+
+// postPayment credits amount of a payment to one booking's folio.
+func postPayment(ctx context.Context, tx *sqlx.Tx, payment *domain.Payment, bookingID int, amount domain.Money) error {
+	err := insertFolioEntries(ctx, tx, bookingID, []*domain.FolioEntry{{
+		EntryType:   domain.FolioEntryPayment,
+		Category:    domain.FolioCategoryPayment,
+		Description: "Payment via " + payment.Provider,
+		Amount:      amount.Neg(),
+		PaymentID:   payment.PaymentID,
+		ActorUserID: payment.RecordedBy,
+	}})
+	if err != nil {
+		return err
+	}
+
+	return syncAmountPaid(ctx, tx, bookingID)
+}
+
+// confirmIfPaid confirms a pending booking whose payments cover the amount due
+// to confirm it on its rate plan, and leaves it pending otherwise, as when it
+// was repriced while the payment was in flight. It reports whether it did.
+func confirmIfPaid(ctx context.Context, tx *sqlx.Tx, bookingID, actorID int, reason string) (bool, error) {
+	var line struct {
+		TotalPrice     model.Amount `db:"total_price"`
+		AmountPaid     model.Amount `db:"amount_paid"`
+		AllowPayLater  bool         `db:"allow_pay_later"`
+		DepositPercent float64      `db:"deposit_percent"`
+	}
+	q := `SELECT b.total_price, b.amount_paid, COALESCE(rp.allow_pay_later, FALSE) AS allow_pay_later, rp.deposit_percent
+				FROM bookings b
+				JOIN rate_plans rp ON rp.rate_plan_id = b.rate_plan_id
+				WHERE b.booking_id = $1`
+	if err := tx.GetContext(ctx, &line, q, bookingID); err != nil {
+		return false, err
+	}
+
+	rp := &domain.RatePlan{AllowPayLater: line.AllowPayLater, DepositPercent: line.DepositPercent}
+	short, err := line.AmountPaid.Money().LessThan(rp.AmountDueNow(line.TotalPrice.Money()))
+	if err != nil || short {
+		return false, err
+	}
+	return true, confirmPendingBooking(ctx, tx, bookingID, actorID, reason)
+}
+
+func confirmPendingBooking(ctx context.Context, tx *sqlx.Tx, bookingID, actorID int, reason string) error {
+	q := `UPDATE bookings SET status = $1, updated_at = NOW() WHERE booking_id = $2`
+	if _, err := tx.ExecContext(ctx, q, domain.BookingStatusConfirmed, bookingID); err != nil {
+		return err
+	}
+	return insertStatusHistory(ctx, tx, &domain.BookingStatusHistory{
+		BookingID:   bookingID,
+		ActorUserID: actorID,
+		OldStatus:   domain.BookingStatusPending,
+		NewStatus:   domain.BookingStatusConfirmed,
+		Reason:      reason,
+	})
+}
+
+// syncAmountPaid recalculates bookings.amount_paid as the net of the payment
+// and refund entries on the booking's folio.
+func syncAmountPaid(ctx context.Context, tx *sqlx.Tx, bookingID int) error {
+	q := `UPDATE bookings
+				SET amount_paid = (
+					SELECT COALESCE(-SUM(amount), 0) FROM folio_entries
+					WHERE booking_id = $1 AND entry_type IN ('payment', 'refund')
+				),
+				updated_at = NOW()
+				WHERE booking_id = $1`
+
+	_, err := tx.ExecContext(ctx, q, bookingID)
+	return err
+}
@@ -0,0 +1,222 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql/model"
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type PromotionRepository struct {
+	db *sqlx.DB
+}
+
+func NewPromotionRepository(db *sqlx.DB) ports.PromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+// activeRedemptionFilter leaves out redemptions of bookings that were given up,
+// so a cancelled or expired booking hands its redemption back to the cap.
+const activeRedemptionFilter = `b.status NOT IN ('cancelled', 'expired', 'no-show')`
+
+const promotionSelect = `
+	SELECT p.promotion_id, p.code, p.description, p.discount_type, p.discount_rate, p.discount_amount,
+		p.min_spend, p.booking_start, p.booking_end, p.stay_start, p.stay_end, p.room_type_ids, p.rate_plan_ids,
+		p.max_redemptions, p.max_per_user, p.is_active, p.created_at, p.updated_at,
+		(SELECT COUNT(*)
+			FROM promotion_redemptions pr
+			JOIN bookings b ON pr.booking_id = b.booking_id
+			WHERE pr.promotion_id = p.promotion_id AND ` + activeRedemptionFilter + `) AS redemptions
+	FROM promotions p`
+
+func (r *PromotionRepository) CreatePromotion(ctx context.Context, promo *domain.Promotion) error {
+	m := model.FromDomainPromotion(promo)
+
+	q := `INSERT INTO promotions (code, description, discount_type, discount_rate, discount_amount, min_spend,
+					booking_start, booking_end, stay_start, stay_end, room_type_ids, rate_plan_ids,
+					max_redemptions, max_per_user, is_active)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+				RETURNING promotion_id`
+
+	var newID int
+	err := r.db.QueryRowContext(ctx, q, m.Code, m.Description, m.DiscountType, m.DiscountRate, m.DiscountAmount, m.MinSpend,
+		m.BookingStart, m.BookingEnd, m.StayStart, m.StayEnd, m.RoomTypeIDs, m.RatePlanIDs,
+		m.MaxRedemptions, m.MaxPerUser, m.IsActive).Scan(&newID)
+	if err != nil {
+		if hasPgCode(err, pgUniqueViolation) {
+			return fmt.Errorf("promo code %s already exists: %w", m.Code, errs.ErrConflict)
+		}
+		return err
+	}
+
+	promo.PromotionID = newID
+	return nil
+}
+
+func (r *PromotionRepository) UpdatePromotion(ctx context.Context, promo *domain.Promotion) error {
+	m := model.FromDomainPromotion(promo)
+
+	q := `UPDATE promotions
+				SET code = $1,
+					description = $2,
+					discount_type = $3,
+					discount_rate = $4,
+					discount_amount = $5,
+					min_spend = $6,
+					booking_start = $7,
+					booking_end = $8,
+					stay_start = $9,
+					stay_end = $10,
+					room_type_ids = $11,
+					rate_plan_ids = $12,
+					max_redemptions = $13,
+					max_per_user = $14,
+					is_active = $15,
+					updated_at = NOW()
+				WHERE promotion_id = $16`
+
+	result, err := r.db.ExecContext(ctx, q, m.Code, m.Description, m.DiscountType, m.DiscountRate, m.DiscountAmount, m.MinSpend,
+		m.BookingStart, m.BookingEnd, m.StayStart, m.StayEnd, m.RoomTypeIDs, m.RatePlanIDs,
+		m.MaxRedemptions, m.MaxPerUser, m.IsActive, m.PromotionID)
+	if err != nil {
+		if hasPgCode(err, pgUniqueViolation) {
+			return fmt.Errorf("promo code %s already exists: %w", m.Code, errs.ErrConflict)
+		}
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("no promotion found with id %d: %w", m.PromotionID, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PromotionRepository) DeletePromotion(ctx context.Context, promotionID int) error {
+	q := `DELETE FROM promotions WHERE promotion_id = $1`
+
+	result, err := r.db.ExecContext(ctx, q, promotionID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("no promotion found with id %d: %w", promotionID, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PromotionRepository) GetPromotionByID(ctx context.Context, promotionID int) (*domain.Promotion, error) {
+	var m model.Promotion
+	q := promotionSelect + ` WHERE p.promotion_id = $1`
+
+	err := r.db.GetContext(ctx, &m, q, promotionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("promotion id %d: %w", promotionID, errs.ErrNotFound)
+		}
+		return nil, err
+	}
+
+	return m.ToDomain(), nil
+}
+
+func (r *PromotionRepository) GetPromotionByCode(ctx context.Context, code string) (*domain.Promotion, error) {
+	var m model.Promotion
+	q := promotionSelect + ` WHERE p.code = $1`
+
+	err := r.db.GetContext(ctx, &m, q, code)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("promo code %s: %w", code, errs.ErrNotFound)
+		}
+		return nil, err
+	}
+
+	return m.ToDomain(), nil
+}
+
+func (r *PromotionRepository) GetAllPromotions(ctx context.Context) ([]*domain.Promotion, error) {
+	var models []model.Promotion
+	q := promotionSelect + ` ORDER BY p.created_at DESC`
+
+	err := r.db.SelectContext(ctx, &models, q)
+	if err != nil {
+		return nil, err
+	}
+
+	promos := make([]*domain.Promotion, len(models))
+	for i := range models {
+		promos[i] = models[i].ToDomain()
+	}
+	return promos, nil
+}
+
+func (r *PromotionRepository) CountUserRedemptions(ctx context.Context, promotionID, userID int) (int, error) {
+	q := `SELECT COUNT(*)
+				FROM promotion_redemptions pr
+				JOIN bookings b ON pr.booking_id = b.booking_id
+				WHERE pr.promotion_id = $1 AND pr.user_id = $2 AND ` + activeRedemptionFilter
+
+	var count int
+	err := r.db.GetContext(ctx, &count, q, promotionID, userID)
+	return count, err
+}
+
+// redeemPromotion records the booking's use of its promotion inside the booking
+// transaction. Locking the promotion row serialises concurrent redemptions so the
+// caps are checked against every committed booking.
+func redeemPromotion(ctx context.Context, tx *sqlx.Tx, booking *domain.Booking) error {
+	var caps struct {
+		MaxRedemptions *int `db:"max_redemptions"`
+		MaxPerUser     *int `db:"max_per_user"`
+	}
+	q := `SELECT max_redemptions, max_per_user FROM promotions WHERE promotion_id = $1 AND is_active FOR UPDATE`
+	err := tx.GetContext(ctx, &caps, q, booking.PromotionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("active promotion id %d: %w", booking.PromotionID, errs.ErrNotFound)
+		}
+		return err
+	}
+
+	var used struct {
+		Total   int `db:"total"`
+		ForUser int `db:"for_user"`
+	}
+	q = `SELECT COUNT(*) AS total, COUNT(*) FILTER (WHERE pr.user_id = $2) AS for_user
+				FROM promotion_redemptions pr
+				JOIN bookings b ON pr.booking_id = b.booking_id
+				WHERE pr.promotion_id = $1 AND ` + activeRedemptionFilter
+	err = tx.GetContext(ctx, &used, q, booking.PromotionID, booking.UserID)
+	if err != nil {
+		return err
+	}
+
+	if caps.MaxRedemptions != nil && used.Total >= *caps.MaxRedemptions {
+		return fmt.Errorf("promotion %d is fully redeemed: %w", booking.PromotionID, errs.ErrLimitReached)
+	}
+	if caps.MaxPerUser != nil && used.ForUser >= *caps.MaxPerUser {
+		return fmt.Errorf("user %d used promotion %d too often: %w", booking.UserID, booking.PromotionID, errs.ErrLimitReached)
+	}
+
+	q = `INSERT INTO promotion_redemptions (promotion_id, booking_id, user_id, discount_amount) VALUES ($1, $2, $3, $4)`
+	_, err = tx.ExecContext(ctx, q, booking.PromotionID, booking.BookingID, booking.UserID, model.AmountOf(booking.DiscountAmount))
+	return err
+}
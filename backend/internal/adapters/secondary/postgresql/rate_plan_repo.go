@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql/model"
 	"github.com/ingwrok/hotelBooking/internal/common/errs"
@@ -23,12 +24,14 @@ func NewRatePlanRepository(db *sqlx.DB) ports.RatePlanRepository {
 func (r *RatePlanRepository) CreateRatePlan(ctx context.Context, rp *domain.RatePlan) error {
 	m := model.FromDomainRatePlan(rp)
 
-	q := `INSERT INTO rate_plans (name, description, is_special_package, allow_free_cancel, allow_pay_later)
-				VALUES ($1, $2, $3, $4, $5)
+	q := `INSERT INTO rate_plans (name, description, is_special_package, allow_free_cancel, allow_pay_later,
+					deposit_percent, requires_guarantee)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)
 				RETURNING rate_plan_id`
 
 	var newID int
-	err := r.db.QueryRowContext(ctx, q, m.Name, m.Description, m.IsSpecialPackage, m.AllowFreeCancel, m.AllowPayLater).Scan(&newID)
+	err := r.db.QueryRowContext(ctx, q, m.Name, m.Description, m.IsSpecialPackage, m.AllowFreeCancel, m.AllowPayLater,
+		m.DepositPercent, m.RequiresGuarantee).Scan(&newID)
 	if err != nil {
 		return err
 	}
@@ -45,10 +48,13 @@ func (r *RatePlanRepository) UpdateRatePlan(ctx context.Context, rp *domain.Rate
 					description = $2,
 					is_special_package = $3,
 					allow_free_cancel = $4,
-					allow_pay_later = $5
-				WHERE rate_plan_id = $6`
+					allow_pay_later = $5,
+					deposit_percent = $6,
+					requires_guarantee = $7
+				WHERE rate_plan_id = $8`
 
-	result, err := r.db.ExecContext(ctx, q, m.Name, m.Description, m.IsSpecialPackage, m.AllowFreeCancel, m.AllowPayLater, m.RatePlanID)
+	result, err := r.db.ExecContext(ctx, q, m.Name, m.Description, m.IsSpecialPackage, m.AllowFreeCancel, m.AllowPayLater,
+		m.DepositPercent, m.RequiresGuarantee, m.RatePlanID)
 	if err != nil {
 		return err
 	}
@@ -86,7 +92,7 @@ func (r *RatePlanRepository) DeleteRatePlan(ctx context.Context, ratePlanID int)
 }
 
 func (r *RatePlanRepository) GetRatePlanByID(ctx context.Context, ratePlanID int) (*domain.RatePlan, error) {
-	q := `SELECT rate_plan_id, name, description, is_special_package, allow_free_cancel, allow_pay_later, created_at, updated_at
+	q := `SELECT rate_plan_id, name, description, is_special_package, allow_free_cancel, allow_pay_later, deposit_percent, requires_guarantee, created_at, updated_at
 				FROM rate_plans
 				WHERE rate_plan_id = $1`
 
@@ -103,7 +109,7 @@ func (r *RatePlanRepository) GetRatePlanByID(ctx context.Context, ratePlanID int
 }
 
 func (r *RatePlanRepository) GetAllRatePlans(ctx context.Context) ([]*domain.RatePlan, error) {
-	q := `SELECT rate_plan_id, name, description, is_special_package, allow_free_cancel, allow_pay_later, created_at, updated_at
+	q := `SELECT rate_plan_id, name, description, is_special_package, allow_free_cancel, allow_pay_later, deposit_percent, requires_guarantee, created_at, updated_at
 				FROM rate_plans`
 
 	var ms []model.RatePlan
@@ -120,13 +126,13 @@ func (r *RatePlanRepository) GetAllRatePlans(ctx context.Context) ([]*domain.Rat
 	return rps, nil
 }
 
-func (r *RatePlanRepository) SetRoomTypePrice(ctx context.Context, roomTypeID, ratePlanID int, price float64) error {
+func (r *RatePlanRepository) SetRoomTypePrice(ctx context.Context, roomTypeID, ratePlanID int, price domain.Money) error {
 	q := `INSERT INTO room_type_rate_prices (room_type_id, rate_plan_id, price)
         VALUES ($1, $2, $3)
         ON CONFLICT (room_type_id, rate_plan_id)
         DO UPDATE SET price = EXCLUDED.price;
 				`
-	_, err := r.db.ExecContext(ctx, q, roomTypeID, ratePlanID, price)
+	_, err := r.db.ExecContext(ctx, q, roomTypeID, ratePlanID, model.AmountOf(price))
 	if err != nil {
 		return err
 	}
@@ -134,21 +140,21 @@ func (r *RatePlanRepository) SetRoomTypePrice(ctx context.Context, roomTypeID, r
 	return err
 }
 
-func (r *RatePlanRepository) GetPriceByRoomType(ctx context.Context, roomTypeID, ratePlanID int) (float64, error) {
+func (r *RatePlanRepository) GetPriceByRoomType(ctx context.Context, roomTypeID, ratePlanID int) (domain.Money, error) {
 	q := `SELECT price
 				FROM room_type_rate_prices
 				WHERE room_type_id = $1 AND rate_plan_id = $2`
 
-	var price float64
+	var price model.Amount
 	err := r.db.GetContext(ctx, &price, q, roomTypeID, ratePlanID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return 0, fmt.Errorf("room type id %d: %w", roomTypeID, errs.ErrNotFound)
+			return domain.Money{}, fmt.Errorf("room type id %d: %w", roomTypeID, errs.ErrNotFound)
 		}
-		return 0, err
+		return domain.Money{}, err
 	}
 
-	return price, nil
+	return price.Money(), nil
 }
 
 func (r *RatePlanRepository) DeleteRoomTypePrice(ctx context.Context, roomTypeID, ratePlanID int) error {
@@ -172,7 +178,7 @@ func (r *RatePlanRepository) DeleteRoomTypePrice(ctx context.Context, roomTypeID
 }
 
 func (r *RatePlanRepository) GetAllRatePlansByRoomTypeID(ctx context.Context, roomTypeID int) ([]*domain.RatePlanFull, error) {
-	q := `SELECT rp.rate_plan_id, rp.name, rp.description, rp.is_special_package, rp.allow_free_cancel, rp.allow_pay_later, rtrp.price, rp.created_at, rp.updated_at
+	q := `SELECT rp.rate_plan_id, rp.name, rp.description, rp.is_special_package, rp.allow_free_cancel, rp.allow_pay_later, rp.deposit_percent, rp.requires_guarantee, rtrp.price, rp.created_at, rp.updated_at
 				FROM rate_plans rp
 				JOIN room_type_rate_prices rtrp ON rp.rate_plan_id = rtrp.rate_plan_id
 				WHERE rtrp.room_type_id = $1
@@ -191,4 +197,159 @@ func (r *RatePlanRepository) GetAllRatePlansByRoomTypeID(ctx context.Context, ro
 	}
 
 	return rps, nil
-}
\ No newline at end of file
+}
+
+func (r *RatePlanRepository) SetCalendarPrices(ctx context.Context, entries []*domain.RateCalendarEntry) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := `INSERT INTO room_type_rate_calendar (room_type_id, rate_plan_id, stay_date, price)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT (room_type_id, rate_plan_id, stay_date)
+				DO UPDATE SET price = EXCLUDED.price`
+
+	for _, e := range entries {
+		_, err := tx.ExecContext(ctx, q, e.RoomTypeID, e.RatePlanID, e.StayDate, model.AmountOf(e.Price))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *RatePlanRepository) DeleteCalendarPrices(ctx context.Context, roomTypeID, ratePlanID int, from, to time.Time) (int64, error) {
+	q := `DELETE FROM room_type_rate_calendar
+				WHERE room_type_id = $1 AND rate_plan_id = $2
+				  AND stay_date >= $3 AND stay_date <= $4`
+
+	result, err := r.db.ExecContext(ctx, q, roomTypeID, ratePlanID, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// GetNightlyRates returns the price of every night from checkIn up to (not including)
+// checkOut. Nights without a calendar override fall back to the flat room type price.
+func (r *RatePlanRepository) GetNightlyRates(ctx context.Context, roomTypeID, ratePlanID int, checkIn, checkOut time.Time) ([]*domain.NightlyRate, error) {
+	q := `SELECT d::date AS stay_date, COALESCE(c.price, p.price) AS price
+				FROM generate_series($3::date, $4::date - 1, INTERVAL '1 day') AS d
+				LEFT JOIN room_type_rate_calendar c
+					ON c.room_type_id = $1 AND c.rate_plan_id = $2 AND c.stay_date = d::date
+				LEFT JOIN room_type_rate_prices p
+					ON p.room_type_id = $1 AND p.rate_plan_id = $2
+				ORDER BY d`
+
+	type row struct {
+		StayDate time.Time     `db:"stay_date"`
+		Price    *model.Amount `db:"price"`
+	}
+
+	var rows []row
+	err := r.db.SelectContext(ctx, &rows, q, roomTypeID, ratePlanID, checkIn, checkOut)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]*domain.NightlyRate, len(rows))
+	for i, row := range rows {
+		if row.Price == nil {
+			return nil, fmt.Errorf("no price for room type %d on %s: %w", roomTypeID, row.StayDate.Format("2006-01-02"), errs.ErrNotFound)
+		}
+		rates[i] = &domain.NightlyRate{StayDate: row.StayDate, Price: row.Price.Money()}
+	}
+
+	return rates, nil
+}
+
+// SetOccupancyPricing replaces the occupancy pricing of a room type on a rate
+// plan, age bands included.
+func (r *RatePlanRepository) SetOccupancyPricing(ctx context.Context, p *domain.OccupancyPricing) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	m := model.FromDomainOccupancyPricing(p)
+	q := `INSERT INTO occupancy_pricing (room_type_id, rate_plan_id, base_occupancy, extra_adult_fee)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT (room_type_id, rate_plan_id)
+				DO UPDATE SET base_occupancy = EXCLUDED.base_occupancy,
+					extra_adult_fee = EXCLUDED.extra_adult_fee`
+	_, err = tx.ExecContext(ctx, q, m.RoomTypeID, m.RatePlanID, m.BaseOccupancy, m.ExtraAdultFee)
+	if err != nil {
+		if hasPgCode(err, pgForeignKeyViolation) {
+			return fmt.Errorf("room type %d or rate plan %d: %w", m.RoomTypeID, m.RatePlanID, errs.ErrNotFound)
+		}
+		return err
+	}
+
+	q = `DELETE FROM occupancy_child_fees WHERE room_type_id = $1 AND rate_plan_id = $2`
+	if _, err := tx.ExecContext(ctx, q, m.RoomTypeID, m.RatePlanID); err != nil {
+		return err
+	}
+
+	q = `INSERT INTO occupancy_child_fees (room_type_id, rate_plan_id, min_age, max_age, fee)
+				VALUES ($1, $2, $3, $4, $5)`
+	for _, band := range p.ChildFees {
+		_, err := tx.ExecContext(ctx, q, m.RoomTypeID, m.RatePlanID, band.MinAge, band.MaxAge, model.AmountOf(band.Fee))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *RatePlanRepository) GetOccupancyPricing(ctx context.Context, roomTypeID, ratePlanID int) (*domain.OccupancyPricing, error) {
+	q := `SELECT room_type_id, rate_plan_id, base_occupancy, extra_adult_fee
+				FROM occupancy_pricing
+				WHERE room_type_id = $1 AND rate_plan_id = $2`
+
+	var m model.OccupancyPricing
+	err := r.db.GetContext(ctx, &m, q, roomTypeID, ratePlanID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("occupancy pricing for room type %d and rate plan %d: %w", roomTypeID, ratePlanID, errs.ErrNotFound)
+		}
+		return nil, err
+	}
+
+	q = `SELECT min_age, max_age, fee
+				FROM occupancy_child_fees
+				WHERE room_type_id = $1 AND rate_plan_id = $2
+				ORDER BY min_age`
+
+	var bands []*model.ChildAgeBand
+	if err := r.db.SelectContext(ctx, &bands, q, roomTypeID, ratePlanID); err != nil {
+		return nil, err
+	}
+
+	return m.ToDomain(bands), nil
+}
+
+func (r *RatePlanRepository) DeleteOccupancyPricing(ctx context.Context, roomTypeID, ratePlanID int) error {
+	q := `DELETE FROM occupancy_pricing WHERE room_type_id = $1 AND rate_plan_id = $2`
+
+	result, err := r.db.ExecContext(ctx, q, roomTypeID, ratePlanID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("no occupancy pricing for room type %d and rate plan %d: %w", roomTypeID, ratePlanID, errs.ErrNotFound)
+	}
+
+	return nil
+}
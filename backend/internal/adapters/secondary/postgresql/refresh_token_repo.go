@@ -0,0 +1,116 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql/model"
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type RefreshTokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewRefreshTokenRepository(db *sqlx.DB) ports.RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+const refreshTokenColumns = `token_id, user_id, family_id, token_hash, user_agent, ip_address, created_at, expires_at, used_at, revoked_at`
+
+func (r *RefreshTokenRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	return insertRefreshToken(ctx, r.db, token)
+}
+
+func insertRefreshToken(ctx context.Context, q sqlx.QueryerContext, token *domain.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (user_id, family_id, token_hash, user_agent, ip_address, expires_at)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				RETURNING token_id, created_at`
+
+	err := q.QueryRowxContext(ctx, query, token.UserID, token.FamilyID, token.TokenHash, token.UserAgent, token.IPAddress, token.ExpiresAt).
+		Scan(&token.TokenID, &token.CreatedAt)
+	if err != nil {
+		if hasPgCode(err, pgForeignKeyViolation) {
+			return fmt.Errorf("user id %d: %w", token.UserID, errs.ErrNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	var m model.RefreshToken
+	q := `SELECT ` + refreshTokenColumns + ` FROM refresh_tokens WHERE token_hash = $1`
+
+	if err := r.db.GetContext(ctx, &m, q, tokenHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("refresh token: %w", errs.ErrNotFound)
+		}
+		return nil, err
+	}
+
+	return m.ToDomain(), nil
+}
+
+func (r *RefreshTokenRepository) RotateRefreshToken(ctx context.Context, oldID int, next *domain.RefreshToken) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := `UPDATE refresh_tokens SET used_at = NOW()
+				WHERE token_id = $1 AND used_at IS NULL AND revoked_at IS NULL`
+	res, err := tx.ExecContext(ctx, q, oldID)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("refresh token %d already spent: %w", oldID, errs.ErrConflict)
+	}
+
+	if err := insertRefreshToken(ctx, tx, next); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *RefreshTokenRepository) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	q := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`
+
+	_, err := r.db.ExecContext(ctx, q, familyID)
+	return err
+}
+
+func (r *RefreshTokenRepository) RevokeUserTokens(ctx context.Context, userID int) error {
+	q := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+
+	_, err := r.db.ExecContext(ctx, q, userID)
+	return err
+}
+
+// DeleteExpiredRefreshTokens drops whole families once their newest token has
+// expired, so reuse of an older link is still caught while the family lives.
+func (r *RefreshTokenRepository) DeleteExpiredRefreshTokens(ctx context.Context) (int64, error) {
+	q := `DELETE FROM refresh_tokens
+				WHERE family_id IN (
+					SELECT family_id FROM refresh_tokens
+					GROUP BY family_id
+					HAVING MAX(expires_at) <= NOW()
+				)`
+
+	res, err := r.db.ExecContext(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,160 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql/model"
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type RestrictionRepository struct {
+	db *sqlx.DB
+}
+
+func NewRestrictionRepository(db *sqlx.DB) ports.RestrictionRepository {
+	return &RestrictionRepository{db: db}
+}
+
+const restrictionColumns = `restriction_id, room_type_id, rate_plan_id, start_date, end_date,
+				min_los, max_los, closed_to_arrival, closed_to_departure, stop_sell, created_at, updated_at`
+
+func (r *RestrictionRepository) CreateRestriction(ctx context.Context, restriction *domain.StayRestriction) error {
+	m := model.FromDomainStayRestriction(restriction)
+
+	q := `INSERT INTO stay_restrictions (room_type_id, rate_plan_id, start_date, end_date,
+					min_los, max_los, closed_to_arrival, closed_to_departure, stop_sell)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+				RETURNING restriction_id`
+
+	var newID int
+	err := r.db.QueryRowContext(ctx, q, m.RoomTypeID, m.RatePlanID, m.StartDate, m.EndDate,
+		m.MinLOS, m.MaxLOS, m.ClosedToArrival, m.ClosedToDeparture, m.StopSell).Scan(&newID)
+	if err != nil {
+		if hasPgCode(err, pgForeignKeyViolation) {
+			return fmt.Errorf("room type %d or rate plan %d: %w", m.RoomTypeID, restriction.RatePlanID, errs.ErrNotFound)
+		}
+		return err
+	}
+
+	restriction.RestrictionID = newID
+	return nil
+}
+
+func (r *RestrictionRepository) UpdateRestriction(ctx context.Context, restriction *domain.StayRestriction) error {
+	m := model.FromDomainStayRestriction(restriction)
+
+	q := `UPDATE stay_restrictions
+				SET room_type_id = $1,
+					rate_plan_id = $2,
+					start_date = $3,
+					end_date = $4,
+					min_los = $5,
+					max_los = $6,
+					closed_to_arrival = $7,
+					closed_to_departure = $8,
+					stop_sell = $9,
+					updated_at = NOW()
+				WHERE restriction_id = $10`
+
+	result, err := r.db.ExecContext(ctx, q, m.RoomTypeID, m.RatePlanID, m.StartDate, m.EndDate,
+		m.MinLOS, m.MaxLOS, m.ClosedToArrival, m.ClosedToDeparture, m.StopSell, m.RestrictionID)
+	if err != nil {
+		if hasPgCode(err, pgForeignKeyViolation) {
+			return fmt.Errorf("room type %d or rate plan %d: %w", m.RoomTypeID, restriction.RatePlanID, errs.ErrNotFound)
+		}
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("no restriction found with id %d: %w", m.RestrictionID, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *RestrictionRepository) DeleteRestriction(ctx context.Context, restrictionID int) error {
+	q := `DELETE FROM stay_restrictions WHERE restriction_id = $1`
+
+	result, err := r.db.ExecContext(ctx, q, restrictionID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("no restriction found with id %d: %w", restrictionID, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *RestrictionRepository) GetRestrictionByID(ctx context.Context, restrictionID int) (*domain.StayRestriction, error) {
+	var m model.StayRestriction
+	q := `SELECT ` + restrictionColumns + ` FROM stay_restrictions WHERE restriction_id = $1`
+
+	err := r.db.GetContext(ctx, &m, q, restrictionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("restriction id %d: %w", restrictionID, errs.ErrNotFound)
+		}
+		return nil, err
+	}
+
+	return m.ToDomain(), nil
+}
+
+func (r *RestrictionRepository) GetRestrictionsByRoomType(ctx context.Context, roomTypeID int) ([]*domain.StayRestriction, error) {
+	var models []model.StayRestriction
+	q := `SELECT ` + restrictionColumns + `
+				FROM stay_restrictions
+				WHERE room_type_id = $1
+				ORDER BY start_date, restriction_id`
+
+	err := r.db.SelectContext(ctx, &models, q, roomTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainRestrictions(models), nil
+}
+
+func (r *RestrictionRepository) GetRestrictionsForStay(ctx context.Context, roomTypeID, ratePlanID int, checkIn, checkOut time.Time) ([]*domain.StayRestriction, error) {
+	var models []model.StayRestriction
+	q := `SELECT ` + restrictionColumns + `
+				FROM stay_restrictions
+				WHERE ($1 = 0 OR room_type_id = $1)
+					AND (rate_plan_id IS NULL OR rate_plan_id = $2)
+					AND start_date <= $4::date
+					AND end_date >= $3::date
+				ORDER BY room_type_id, start_date, restriction_id`
+
+	err := r.db.SelectContext(ctx, &models, q, roomTypeID, ratePlanID, checkIn, checkOut)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainRestrictions(models), nil
+}
+
+func toDomainRestrictions(models []model.StayRestriction) []*domain.StayRestriction {
+	restrictions := make([]*domain.StayRestriction, len(models))
+	for i := range models {
+		restrictions[i] = models[i].ToDomain()
+	}
+	return restrictions
+}
@@ -0,0 +1,125 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql/model"
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type RoleRepository struct {
+	db *sqlx.DB
+}
+
+func NewRoleRepository(db *sqlx.DB) ports.RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+func (r *RoleRepository) GetRoles(ctx context.Context) ([]*domain.Role, error) {
+	var models []model.Role
+	q := `SELECT r.role_id, r.name, r.description, r.require_two_factor,
+					COALESCE(ARRAY_AGG(p.name ORDER BY p.name) FILTER (WHERE p.name IS NOT NULL), '{}') AS permissions
+				FROM roles r
+				LEFT JOIN role_permissions rp ON rp.role_id = r.role_id
+				LEFT JOIN permissions p ON p.permission_id = rp.permission_id
+				GROUP BY r.role_id
+				ORDER BY r.role_id`
+
+	if err := r.db.SelectContext(ctx, &models, q); err != nil {
+		return nil, err
+	}
+
+	roles := make([]*domain.Role, len(models))
+	for i, m := range models {
+		roles[i] = m.ToDomain()
+	}
+	return roles, nil
+}
+
+func (r *RoleRepository) AssignRole(ctx context.Context, userID int, role string) error {
+	q := `INSERT INTO user_roles (user_id, role_id) SELECT $1, role_id FROM roles WHERE name = $2`
+
+	res, err := r.db.ExecContext(ctx, q, userID, role)
+	if err != nil {
+		if hasPgCode(err, pgUniqueViolation) {
+			return fmt.Errorf("user %d already has role %s: %w", userID, role, errs.ErrConflict)
+		}
+		if hasPgCode(err, pgForeignKeyViolation) {
+			return fmt.Errorf("user id %d: %w", userID, errs.ErrNotFound)
+		}
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("role %s: %w", role, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *RoleRepository) RevokeRole(ctx context.Context, userID int, role string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Locking the role serializes revocations, so two admins removing each
+	// other at once cannot both succeed.
+	var roleID int
+	err = tx.QueryRowContext(ctx, `SELECT role_id FROM roles WHERE name = $1 FOR UPDATE`, role).Scan(&roleID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("role %s: %w", role, errs.ErrNotFound)
+		}
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2`, userID, roleID)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("user %d does not have role %s: %w", userID, role, errs.ErrNotFound)
+	}
+
+	if role == domain.RoleAdmin {
+		var admins int
+		if err := tx.GetContext(ctx, &admins, `SELECT COUNT(*) FROM user_roles WHERE role_id = $1`, roleID); err != nil {
+			return err
+		}
+		if admins == 0 {
+			return fmt.Errorf("cannot revoke the last admin: %w", errs.ErrConflict)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *RoleRepository) SetRoleTwoFactor(ctx context.Context, role string, required bool) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE roles SET require_two_factor = $2 WHERE name = $1`, role, required)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("role %s: %w", role, errs.ErrNotFound)
+	}
+	return nil
+}
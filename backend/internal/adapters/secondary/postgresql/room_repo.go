@@ -187,6 +187,24 @@ func (r *RoomRepository) DeleteRoomBlock(ctx context.Context, blockID int) error
 }
 
 func (r *RoomRepository) GetAvailableRoomCounts(ctx context.Context, checkIn, checkOut time.Time) (map[int]int, error) {
+	counts, held, err := roomCounts(ctx, r.db, checkIn, checkOut)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rooms a group allotment still holds are not for sale to the public.
+	for typeID, rooms := range held {
+		if free, ok := counts[typeID]; ok {
+			counts[typeID] = max(free-rooms, 0)
+		}
+	}
+	return counts, nil
+
+}
+
+// roomCounts returns per room type the rooms free for the whole stay and the
+// most rooms group allotments hold back from the public on any of its nights.
+func roomCounts(ctx context.Context, db sqlx.QueryerContext, checkIn, checkOut time.Time) (free, held map[int]int, err error) {
 	q := `
     SELECT
       r.room_type_id,
@@ -197,7 +215,7 @@ func (r *RoomRepository) GetAvailableRoomCounts(ctx context.Context, checkIn, ch
       SELECT 1
       FROM bookings b
       WHERE b.room_id = r.room_id
-        AND b.status != 'cancelled'
+        AND b.status NOT IN ('cancelled', 'expired', 'no-show')
         AND b.check_in_date < $2
         AND b.check_out_date > $1
     )
@@ -216,20 +234,37 @@ func (r *RoomRepository) GetAvailableRoomCounts(ctx context.Context, checkIn, ch
 	}
 
 	var rows []result
-	err := r.db.SelectContext(ctx, &rows, q, checkIn, checkOut)
-	if err != nil {
-		return nil, err
+	if err := sqlx.SelectContext(ctx, db, &rows, q, checkIn, checkOut); err != nil {
+		return nil, nil, err
 	}
-	counts := make(map[int]int)
+	free = make(map[int]int)
 	for _, row := range rows {
-		counts[row.TypeID] = row.Count
+		free[row.TypeID] = row.Count
 	}
-	return counts, nil
 
+	var holds []struct {
+		TypeID int `db:"room_type_id"`
+		Rooms  int `db:"rooms"`
+	}
+	if err := sqlx.SelectContext(ctx, db, &holds, allotmentHolds, checkIn, checkOut); err != nil {
+		return nil, nil, err
+	}
+	held = make(map[int]int)
+	for _, h := range holds {
+		held[h.TypeID] = h.Rooms
+	}
+	return free, held, nil
 }
 
 // สุ่มหยิบห้องว่าง 1 ห้องจาก Type ที่ระบุ
 func (r *RoomRepository) GetAnyAvailableRoomID(ctx context.Context, roomTypeID int, checkIn, checkOut time.Time) (int, error) {
+	return r.GetAvailableRoomIDForBooking(ctx, 0, roomTypeID, checkIn, checkOut)
+}
+
+// GetAvailableRoomIDForBooking finds a free room for an existing booking that is
+// moving. The booking's own nights do not count against availability, and its
+// current room is preferred so the guest keeps it when possible.
+func (r *RoomRepository) GetAvailableRoomIDForBooking(ctx context.Context, bookingID, roomTypeID int, checkIn, checkOut time.Time) (int, error) {
 	q := `
     SELECT r.room_id
     FROM rooms r
@@ -238,7 +273,8 @@ func (r *RoomRepository) GetAnyAvailableRoomID(ctx context.Context, roomTypeID i
       AND NOT EXISTS (
           SELECT 1 FROM bookings b
           WHERE b.room_id = r.room_id
-            AND b.status != 'cancelled'
+            AND b.booking_id <> $4
+            AND b.status NOT IN ('cancelled', 'expired', 'no-show')
             AND b.check_in_date < $3
             AND b.check_out_date > $2
       )
@@ -248,11 +284,12 @@ func (r *RoomRepository) GetAnyAvailableRoomID(ctx context.Context, roomTypeID i
             AND rb.start_date < $3
             AND rb.end_date > $2
       )
-    ORDER BY r.room_number
+    ORDER BY EXISTS (SELECT 1 FROM bookings cur WHERE cur.booking_id = $4 AND cur.room_id = r.room_id) DESC,
+      r.room_number
     LIMIT 1;
   `
 	var roomID int
-	err := r.db.QueryRowContext(ctx, q, roomTypeID, checkIn, checkOut).Scan(&roomID)
+	err := r.db.QueryRowContext(ctx, q, roomTypeID, checkIn, checkOut, bookingID).Scan(&roomID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, fmt.Errorf("no available room : %w", errs.ErrNotFound)
@@ -262,3 +299,33 @@ func (r *RoomRepository) GetAnyAvailableRoomID(ctx context.Context, roomTypeID i
 	return roomID, nil
 
 }
+
+func (r *RoomRepository) GetAvailableRoomIDs(ctx context.Context, roomTypeID int, checkIn, checkOut time.Time, limit int) ([]int, error) {
+	q := `
+    SELECT r.room_id
+    FROM rooms r
+    WHERE r.room_type_id = $1
+      AND r.status != 'maintenance'
+      AND NOT EXISTS (
+          SELECT 1 FROM bookings b
+          WHERE b.room_id = r.room_id
+            AND b.status NOT IN ('cancelled', 'expired', 'no-show')
+            AND b.check_in_date < $3
+            AND b.check_out_date > $2
+      )
+      AND NOT EXISTS (
+          SELECT 1 FROM room_blocks rb
+          WHERE rb.room_id = r.room_id
+            AND rb.start_date < $3
+            AND rb.end_date > $2
+      )
+    ORDER BY r.room_number
+    LIMIT $4;
+  `
+	var roomIDs []int
+	err := r.db.SelectContext(ctx, &roomIDs, q, roomTypeID, checkIn, checkOut, limit)
+	if err != nil {
+		return nil, err
+	}
+	return roomIDs, nil
+}
@@ -0,0 +1,134 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql/model"
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type TaxRuleRepository struct {
+	db *sqlx.DB
+}
+
+func NewTaxRuleRepository(db *sqlx.DB) ports.TaxRuleRepository {
+	return &TaxRuleRepository{db: db}
+}
+
+const taxRuleColumns = `tax_rule_id, name, calc_type, rate, amount, basis, applies_to, is_compound, priority, is_active, created_at, updated_at`
+
+func (r *TaxRuleRepository) CreateTaxRule(ctx context.Context, rule *domain.TaxRule) error {
+	m := model.FromDomainTaxRule(rule)
+
+	q := `INSERT INTO tax_rules (name, calc_type, rate, amount, basis, applies_to, is_compound, priority, is_active)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+				RETURNING tax_rule_id`
+
+	var newID int
+	err := r.db.QueryRowContext(ctx, q, m.Name, m.CalcType, m.Rate, m.Amount, m.Basis, m.AppliesTo,
+		m.IsCompound, m.Priority, m.IsActive).Scan(&newID)
+	if err != nil {
+		return err
+	}
+
+	rule.TaxRuleID = newID
+	return nil
+}
+
+func (r *TaxRuleRepository) UpdateTaxRule(ctx context.Context, rule *domain.TaxRule) error {
+	m := model.FromDomainTaxRule(rule)
+
+	q := `UPDATE tax_rules
+				SET name = $1,
+					calc_type = $2,
+					rate = $3,
+					amount = $4,
+					basis = $5,
+					applies_to = $6,
+					is_compound = $7,
+					priority = $8,
+					is_active = $9,
+					updated_at = NOW()
+				WHERE tax_rule_id = $10`
+
+	result, err := r.db.ExecContext(ctx, q, m.Name, m.CalcType, m.Rate, m.Amount, m.Basis, m.AppliesTo,
+		m.IsCompound, m.Priority, m.IsActive, m.TaxRuleID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("no tax rule found with id %d: %w", m.TaxRuleID, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *TaxRuleRepository) DeleteTaxRule(ctx context.Context, taxRuleID int) error {
+	q := `DELETE FROM tax_rules WHERE tax_rule_id = $1`
+
+	result, err := r.db.ExecContext(ctx, q, taxRuleID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("no tax rule found with id %d: %w", taxRuleID, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *TaxRuleRepository) GetTaxRuleByID(ctx context.Context, taxRuleID int) (*domain.TaxRule, error) {
+	var m model.TaxRule
+	q := `SELECT ` + taxRuleColumns + ` FROM tax_rules WHERE tax_rule_id = $1`
+
+	err := r.db.GetContext(ctx, &m, q, taxRuleID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("tax rule id %d: %w", taxRuleID, errs.ErrNotFound)
+		}
+		return nil, err
+	}
+
+	return m.ToDomain(), nil
+}
+
+func (r *TaxRuleRepository) GetAllTaxRules(ctx context.Context) ([]*domain.TaxRule, error) {
+	q := `SELECT ` + taxRuleColumns + ` FROM tax_rules ORDER BY priority, tax_rule_id`
+	return r.selectTaxRules(ctx, q)
+}
+
+func (r *TaxRuleRepository) GetActiveTaxRules(ctx context.Context) ([]*domain.TaxRule, error) {
+	q := `SELECT ` + taxRuleColumns + ` FROM tax_rules WHERE is_active ORDER BY priority, tax_rule_id`
+	return r.selectTaxRules(ctx, q)
+}
+
+func (r *TaxRuleRepository) selectTaxRules(ctx context.Context, q string) ([]*domain.TaxRule, error) {
+	var models []model.TaxRule
+	err := r.db.SelectContext(ctx, &models, q)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]*domain.TaxRule, len(models))
+	for i := range models {
+		rules[i] = models[i].ToDomain()
+	}
+	return rules, nil
+}
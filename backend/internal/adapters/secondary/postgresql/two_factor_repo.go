@@ -0,0 +1,215 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql/model"
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type TwoFactorRepository struct {
+	db *sqlx.DB
+}
+
+func NewTwoFactorRepository(db *sqlx.DB) ports.TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+func (r *TwoFactorRepository) GetTOTP(ctx context.Context, userID int) (*domain.TOTPSecret, error) {
+	var m model.TOTPSecret
+	q := `SELECT user_id, secret, created_at, confirmed_at, last_used_step FROM user_totp WHERE user_id = $1`
+
+	if err := r.db.GetContext(ctx, &m, q, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("totp for user %d: %w", userID, errs.ErrNotFound)
+		}
+		return nil, err
+	}
+	return m.ToDomain(), nil
+}
+
+func (r *TwoFactorRepository) SaveTOTP(ctx context.Context, userID int, sealedSecret string) error {
+	q := `INSERT INTO user_totp (user_id, secret) VALUES ($1, $2)
+				ON CONFLICT (user_id) DO UPDATE
+					SET secret = EXCLUDED.secret, created_at = NOW(), last_used_step = 0
+					WHERE user_totp.confirmed_at IS NULL`
+
+	res, err := r.db.ExecContext(ctx, q, userID, sealedSecret)
+	if err != nil {
+		if hasPgCode(err, pgForeignKeyViolation) {
+			return fmt.Errorf("user id %d: %w", userID, errs.ErrNotFound)
+		}
+		return err
+	}
+	if aff, _ := res.RowsAffected(); aff == 0 {
+		return fmt.Errorf("totp for user %d already confirmed: %w", userID, errs.ErrConflict)
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) ConfirmTOTP(ctx context.Context, userID int, step int64, codeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := `UPDATE user_totp SET confirmed_at = NOW(), last_used_step = $2
+				WHERE user_id = $1 AND confirmed_at IS NULL`
+	res, err := tx.ExecContext(ctx, q, userID, step)
+	if err != nil {
+		return err
+	}
+	if aff, _ := res.RowsAffected(); aff == 0 {
+		return fmt.Errorf("no pending totp for user %d: %w", userID, errs.ErrConflict)
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *TwoFactorRepository) UseTOTPStep(ctx context.Context, userID int, step int64) error {
+	q := `UPDATE user_totp SET last_used_step = $2
+				WHERE user_id = $1 AND confirmed_at IS NOT NULL AND last_used_step < $2`
+
+	res, err := r.db.ExecContext(ctx, q, userID, step)
+	if err != nil {
+		return err
+	}
+	if aff, _ := res.RowsAffected(); aff == 0 {
+		return fmt.Errorf("totp step %d for user %d already used: %w", step, userID, errs.ErrConflict)
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) DeleteTOTP(ctx context.Context, userID int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if aff, _ := res.RowsAffected(); aff == 0 {
+		return fmt.Errorf("totp for user %d: %w", userID, errs.ErrNotFound)
+	}
+	return tx.Commit()
+}
+
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, userID int, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, h := range codeHashes {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID int, codeHash string) error {
+	q := `UPDATE recovery_codes SET used_at = NOW()
+				WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+
+	res, err := r.db.ExecContext(ctx, q, userID, codeHash)
+	if err != nil {
+		return err
+	}
+	if aff, _ := res.RowsAffected(); aff == 0 {
+		return fmt.Errorf("recovery code for user %d: %w", userID, errs.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID int) (int, error) {
+	var n int
+	q := `SELECT COUNT(*) FROM recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+	if err := r.db.GetContext(ctx, &n, q, userID); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (r *TwoFactorRepository) CreateLoginChallenge(ctx context.Context, challenge *domain.LoginChallenge) error {
+	q := `INSERT INTO login_challenges (user_id, token_hash, expires_at)
+				VALUES ($1, $2, $3)
+				RETURNING challenge_id, created_at`
+
+	err := r.db.QueryRowxContext(ctx, q, challenge.UserID, challenge.TokenHash, challenge.ExpiresAt).
+		Scan(&challenge.ChallengeID, &challenge.CreatedAt)
+	if err != nil {
+		if hasPgCode(err, pgForeignKeyViolation) {
+			return fmt.Errorf("user id %d: %w", challenge.UserID, errs.ErrNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) GetLoginChallengeByHash(ctx context.Context, tokenHash string) (*domain.LoginChallenge, error) {
+	var m model.LoginChallenge
+	q := `SELECT challenge_id, user_id, token_hash, failed_attempts, created_at, expires_at, used_at
+				FROM login_challenges WHERE token_hash = $1`
+
+	if err := r.db.GetContext(ctx, &m, q, tokenHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("login challenge: %w", errs.ErrNotFound)
+		}
+		return nil, err
+	}
+	return m.ToDomain(), nil
+}
+
+func (r *TwoFactorRepository) FailLoginChallenge(ctx context.Context, challengeID int) error {
+	q := `UPDATE login_challenges SET failed_attempts = failed_attempts + 1 WHERE challenge_id = $1`
+	_, err := r.db.ExecContext(ctx, q, challengeID)
+	return err
+}
+
+func (r *TwoFactorRepository) ConsumeLoginChallenge(ctx context.Context, challengeID int) error {
+	q := `UPDATE login_challenges SET used_at = NOW() WHERE challenge_id = $1 AND used_at IS NULL`
+
+	res, err := r.db.ExecContext(ctx, q, challengeID)
+	if err != nil {
+		return err
+	}
+	if aff, _ := res.RowsAffected(); aff == 0 {
+		return fmt.Errorf("login challenge %d already used: %w", challengeID, errs.ErrConflict)
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) DeleteExpiredLoginChallenges(ctx context.Context) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM login_challenges WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
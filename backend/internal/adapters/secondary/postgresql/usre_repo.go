@@ -11,6 +11,7 @@ import (
 	"github.com/ingwrok/hotelBooking/internal/core/domain"
 	"github.com/ingwrok/hotelBooking/internal/core/ports"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type UserRepository struct{ db *sqlx.DB }
@@ -22,13 +23,28 @@ func NewUserRepository(db *sqlx.DB) ports.UserRepoPort {
 func (r *UserRepository) Create(ctx context.Context, u *domain.User) error {
 	m := model.FromDomain(u)
 
-	q := `INSERT INTO users(username, email, password_hash, is_admin) VALUES($1,$2,$3,$4) RETURNING user_id`
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := `INSERT INTO users(username, email, password_hash) VALUES($1,$2,$3) RETURNING user_id`
 
 	var newID int
-	err := r.db.QueryRowContext(ctx, q, m.Username, m.Email, m.PasswordHash, m.IsAdmin).Scan(&newID)
+	err = tx.QueryRowContext(ctx, q, m.Username, m.Email, m.PasswordHash).Scan(&newID)
 	if err != nil {
 		return err
 	}
+
+	qRoles := `INSERT INTO user_roles (user_id, role_id) SELECT $1, role_id FROM roles WHERE name = ANY($2)`
+	if _, err := tx.ExecContext(ctx, qRoles, newID, pq.StringArray(u.Roles)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
 	u.UserID = newID
 	return nil
 }
@@ -36,7 +52,7 @@ func (r *UserRepository) Create(ctx context.Context, u *domain.User) error {
 func (r *UserRepository) GetByID(ctx context.Context, id int) (*domain.User, error) {
 	var m model.User
 
-	q := `SELECT user_id, username, email, password_hash, is_admin FROM users WHERE user_id=$1`
+	q := `SELECT user_id, username, email, password_hash, token_version, email_verified_at FROM users WHERE user_id=$1`
 
 	err := r.db.GetContext(ctx, &m, q, id)
 	if err != nil {
@@ -45,12 +61,17 @@ func (r *UserRepository) GetByID(ctx context.Context, id int) (*domain.User, err
 		}
 		return nil, err
 	}
-	return m.ToDomain(), nil
+
+	u := m.ToDomain()
+	if err := r.loadAccess(ctx, []*domain.User{u}); err != nil {
+		return nil, err
+	}
+	return u, nil
 }
 
 func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
 	var m model.User
-	q := `SELECT user_id, username, email, password_hash, is_admin FROM users WHERE username=$1`
+	q := `SELECT user_id, username, email, password_hash, token_version, email_verified_at FROM users WHERE username=$1`
 
 	err := r.db.GetContext(ctx, &m, q, username)
 	if err != nil {
@@ -59,7 +80,12 @@ func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*d
 		}
 		return nil, err
 	}
-	return m.ToDomain(), nil
+
+	u := m.ToDomain()
+	if err := r.loadAccess(ctx, []*domain.User{u}); err != nil {
+		return nil, err
+	}
+	return u, nil
 }
 
 func (r *UserRepository) Update(ctx context.Context, id int, fields map[string]interface{}) error {
@@ -68,9 +94,10 @@ func (r *UserRepository) Update(ctx context.Context, id int, fields map[string]i
 	}
 
 	allowed := map[string]bool{
-		"username":      true,
-		"email":         true,
-		"password_hash": true,
+		"username":          true,
+		"email":             true,
+		"password_hash":     true,
+		"email_verified_at": true,
 	}
 
 	var set []string
@@ -98,6 +125,49 @@ func (r *UserRepository) Update(ctx context.Context, id int, fields map[string]i
 	return nil
 }
 
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	var m model.User
+	q := `SELECT user_id, username, email, password_hash, token_version, email_verified_at FROM users WHERE LOWER(email)=LOWER($1)`
+
+	err := r.db.GetContext(ctx, &m, q, email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found: %w", errs.ErrNotFound)
+		}
+		return nil, err
+	}
+
+	u := m.ToDomain()
+	if err := r.loadAccess(ctx, []*domain.User{u}); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// MarkEmailVerified verifies email only if it is still the user's address.
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id int, email string) error {
+	q := `UPDATE users SET email_verified_at = NOW() WHERE user_id=$1 AND email=$2`
+	res, err := r.db.ExecContext(ctx, q, id, email)
+	if err != nil {
+		return err
+	}
+	if aff, _ := res.RowsAffected(); aff == 0 {
+		return fmt.Errorf("user %d with email %s: %w", id, email, errs.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *UserRepository) BumpTokenVersion(ctx context.Context, id int) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET token_version = token_version + 1 WHERE user_id=$1`, id)
+	if err != nil {
+		return err
+	}
+	if aff, _ := res.RowsAffected(); aff == 0 {
+		return fmt.Errorf("no user found with id: %d: %w", id, errs.ErrNotFound)
+	}
+	return nil
+}
+
 func (r *UserRepository) Delete(ctx context.Context, id int) error {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -105,12 +175,24 @@ func (r *UserRepository) Delete(ctx context.Context, id int) error {
 	}
 	defer tx.Rollback()
 
+	// Locking the admin role serializes this with RevokeRole, so the last two
+	// admins cannot remove each other at once.
+	var wasAdmin bool
+	q := `SELECT EXISTS (
+					SELECT 1 FROM user_roles
+					WHERE user_id = $1
+						AND role_id = (SELECT role_id FROM roles WHERE name = $2 FOR UPDATE)
+				)`
+	if err := tx.GetContext(ctx, &wasAdmin, q, id, domain.RoleAdmin); err != nil {
+		return err
+	}
+
 	_, err = tx.ExecContext(ctx, `DELETE FROM bookings WHERE user_id=$1`, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete user bookings: %w", err)
 	}
 
-	q := `DELETE FROM users WHERE user_id=$1`
+	q = `DELETE FROM users WHERE user_id=$1`
 	res, err := tx.ExecContext(ctx, q, id)
 	if err != nil {
 		return err
@@ -124,12 +206,23 @@ func (r *UserRepository) Delete(ctx context.Context, id int) error {
 		return fmt.Errorf("no user found with id: %d: %w", id, errs.ErrNotFound)
 	}
 
+	if wasAdmin {
+		var admins int
+		q = `SELECT COUNT(*) FROM user_roles ur JOIN roles r ON r.role_id = ur.role_id WHERE r.name = $1`
+		if err := tx.GetContext(ctx, &admins, q, domain.RoleAdmin); err != nil {
+			return err
+		}
+		if admins == 0 {
+			return fmt.Errorf("cannot delete the last admin: %w", errs.ErrConflict)
+		}
+	}
+
 	return tx.Commit()
 }
 
 func (r *UserRepository) GetAll(ctx context.Context) ([]*domain.User, error) {
 	var models []model.User
-	q := `SELECT user_id, username, email, password_hash, is_admin FROM users`
+	q := `SELECT user_id, username, email, password_hash, token_version, email_verified_at FROM users`
 
 	err := r.db.SelectContext(ctx, &models, q)
 	if err != nil {
@@ -140,5 +233,67 @@ func (r *UserRepository) GetAll(ctx context.Context) ([]*domain.User, error) {
 	for i, m := range models {
 		users[i] = m.ToDomain()
 	}
+	if err := r.loadAccess(ctx, users); err != nil {
+		return nil, err
+	}
 	return users, nil
 }
+
+// loadAccess fills in the roles of users and the permissions those roles grant.
+func (r *UserRepository) loadAccess(ctx context.Context, users []*domain.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*domain.User, len(users))
+	ids := make([]int64, len(users))
+	for i, u := range users {
+		byID[u.UserID] = u
+		ids[i] = int64(u.UserID)
+	}
+
+	var roles []model.UserAccess
+	qRoles := `SELECT ur.user_id, r.name
+				FROM user_roles ur
+				JOIN roles r ON r.role_id = ur.role_id
+				WHERE ur.user_id = ANY($1)
+				ORDER BY r.name`
+	if err := r.db.SelectContext(ctx, &roles, qRoles, pq.Int64Array(ids)); err != nil {
+		return err
+	}
+	for _, a := range roles {
+		byID[a.UserID].Roles = append(byID[a.UserID].Roles, a.Name)
+	}
+
+	var perms []model.UserAccess
+	qPerms := `SELECT DISTINCT ur.user_id, p.name
+				FROM user_roles ur
+				JOIN role_permissions rp ON rp.role_id = ur.role_id
+				JOIN permissions p ON p.permission_id = rp.permission_id
+				WHERE ur.user_id = ANY($1)
+				ORDER BY p.name`
+	if err := r.db.SelectContext(ctx, &perms, qPerms, pq.Int64Array(ids)); err != nil {
+		return err
+	}
+	for _, a := range perms {
+		byID[a.UserID].Permissions = append(byID[a.UserID].Permissions, a.Name)
+	}
+
+	var twoFactor []model.UserTwoFactor
+	qTwoFactor := `SELECT u.user_id,
+					EXISTS (SELECT 1 FROM user_totp t
+						WHERE t.user_id = u.user_id AND t.confirmed_at IS NOT NULL) AS enabled,
+					EXISTS (SELECT 1 FROM user_roles ur JOIN roles r ON r.role_id = ur.role_id
+						WHERE ur.user_id = u.user_id AND r.require_two_factor) AS required
+				FROM users u
+				WHERE u.user_id = ANY($1)`
+	if err := r.db.SelectContext(ctx, &twoFactor, qTwoFactor, pq.Int64Array(ids)); err != nil {
+		return err
+	}
+	for _, t := range twoFactor {
+		byID[t.UserID].TwoFactorEnabled = t.Enabled
+		byID[t.UserID].TwoFactorRequired = t.Required
+	}
+
+	return nil
+}
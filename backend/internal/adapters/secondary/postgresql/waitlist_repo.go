@@ -0,0 +1,181 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql/model"
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type WaitlistRepository struct {
+	db *sqlx.DB
+}
+
+func NewWaitlistRepository(db *sqlx.DB) ports.WaitlistRepository {
+	return &WaitlistRepository{db: db}
+}
+
+const waitlistColumns = `entry_id, user_id, room_type_id, rate_plan_id, check_in_date, check_out_date, num_adults, child_ages,
+	email, status, booking_id, offered_at, offer_expires_at, created_at, updated_at`
+
+func (r *WaitlistRepository) CreateWaitlistEntry(ctx context.Context, entry *domain.WaitlistEntry) error {
+	m := model.FromDomainWaitlistEntry(entry)
+
+	q := `INSERT INTO waitlist_entries (user_id, room_type_id, rate_plan_id, check_in_date, check_out_date, num_adults, child_ages, email, status)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+				RETURNING entry_id, created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, q, m.UserID, m.RoomTypeID, m.RatePlanID, m.CheckInDate, m.CheckOutDate,
+		m.NumAdults, m.ChildAges, m.Email, m.Status).Scan(&entry.EntryID, &entry.CreatedAt, &entry.UpdatedAt)
+	if err != nil {
+		if hasPgCode(err, pgUniqueViolation) {
+			return fmt.Errorf("user %d is already waiting for room type %d: %w", m.UserID, m.RoomTypeID, errs.ErrConflict)
+		}
+		if hasPgCode(err, pgForeignKeyViolation) {
+			return fmt.Errorf("room type %d or rate plan %d: %w", m.RoomTypeID, m.RatePlanID, errs.ErrNotFound)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *WaitlistRepository) GetWaitlistEntry(ctx context.Context, entryID int) (*domain.WaitlistEntry, error) {
+	var m model.WaitlistEntry
+	q := `SELECT ` + waitlistColumns + ` FROM waitlist_entries WHERE entry_id = $1`
+
+	if err := r.db.GetContext(ctx, &m, q, entryID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("waitlist entry id %d: %w", entryID, errs.ErrNotFound)
+		}
+		return nil, err
+	}
+
+	return m.ToDomain(), nil
+}
+
+func (r *WaitlistRepository) GetWaitlistByUserID(ctx context.Context, userID int) ([]*domain.WaitlistEntry, error) {
+	q := `SELECT ` + waitlistColumns + ` FROM waitlist_entries WHERE user_id = $1 ORDER BY created_at DESC, entry_id DESC`
+	return r.selectEntries(ctx, q, userID)
+}
+
+func (r *WaitlistRepository) GetWaitlist(ctx context.Context, roomTypeID int) ([]*domain.WaitlistEntry, error) {
+	q := `SELECT ` + waitlistColumns + ` FROM waitlist_entries
+				WHERE ($1 = 0 OR room_type_id = $1)
+				ORDER BY created_at, entry_id`
+	return r.selectEntries(ctx, q, roomTypeID)
+}
+
+func (r *WaitlistRepository) GetWaitingEntries(ctx context.Context) ([]*domain.WaitlistEntry, error) {
+	q := `SELECT ` + waitlistColumns + ` FROM waitlist_entries
+				WHERE status = 'waiting'
+				ORDER BY created_at, entry_id`
+	return r.selectEntries(ctx, q)
+}
+
+func (r *WaitlistRepository) selectEntries(ctx context.Context, q string, args ...any) ([]*domain.WaitlistEntry, error) {
+	var models []model.WaitlistEntry
+	if err := r.db.SelectContext(ctx, &models, q, args...); err != nil {
+		return nil, err
+	}
+
+	entries := make([]*domain.WaitlistEntry, len(models))
+	for i, m := range models {
+		entries[i] = m.ToDomain()
+	}
+	return entries, nil
+}
+
+func (r *WaitlistRepository) UpdateWaitlistStatus(ctx context.Context, entryID int, from, to string) error {
+	q := `UPDATE waitlist_entries SET status = $1, updated_at = NOW() WHERE entry_id = $2 AND status = $3`
+
+	result, err := r.db.ExecContext(ctx, q, to, entryID, from)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("waitlist entry id %d is not %s: %w", entryID, from, errs.ErrNotFound)
+	}
+	return nil
+}
+
+// SettleWaitlist closes offers whose hold is no longer pending, as booked when
+// the guest confirmed it and as expired otherwise, and expires entries still
+// waiting when their stay starts on or before today.
+func (r *WaitlistRepository) SettleWaitlist(ctx context.Context, today time.Time) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	q := `UPDATE waitlist_entries w
+				SET status = CASE WHEN b.status IN ('confirmed', 'checked-in', 'checked-out') THEN 'booked' ELSE 'expired' END,
+					updated_at = NOW()
+				FROM bookings b
+				WHERE w.booking_id = b.booking_id
+					AND w.status = 'offered'
+					AND b.status <> 'pending'`
+	offers, err := tx.ExecContext(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+
+	// An offer whose hold was deleted can never be taken up.
+	q = `UPDATE waitlist_entries SET status = 'expired', updated_at = NOW()
+				WHERE (status = 'offered' AND booking_id IS NULL)
+					OR (status = 'waiting' AND check_in_date <= $1)`
+	stale, err := tx.ExecContext(ctx, q, today)
+	if err != nil {
+		return 0, err
+	}
+
+	settled, err := offers.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	n, err := stale.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return settled + n, tx.Commit()
+}
+
+// offerWaitlistEntry records booking as the hold offered to its waitlist
+// entry. It fails with ErrNotFound when the entry stopped waiting meanwhile,
+// so the hold is rolled back with it.
+func offerWaitlistEntry(ctx context.Context, tx *sqlx.Tx, booking *domain.Booking) error {
+	q := `UPDATE waitlist_entries
+				SET status = 'offered',
+					booking_id = $2,
+					offered_at = NOW(),
+					offer_expires_at = $3,
+					updated_at = NOW()
+				WHERE entry_id = $1 AND status = 'waiting'`
+
+	result, err := tx.ExecContext(ctx, q, booking.WaitlistEntryID, booking.BookingID, booking.ExpiredAt)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("waitlist entry id %d is no longer waiting: %w", booking.WaitlistEntryID, errs.ErrNotFound)
+	}
+	return nil
+}
@@ -11,6 +11,14 @@ var (
 	ErrUnexpected   = errors.New("unexpected error")
 	ErrUnauthorized = errors.New("unauthorized")
 	ErrForbidden    = errors.New("forbidden")
+	ErrConflict     = errors.New("conflict")
+	ErrLimitReached = errors.New("limit reached")
+	ErrSoldOut      = errors.New("sold out")
+	ErrFullyBooked  = errors.New("fully booked")
+	// ErrPaymentPending means a payment is in flight for what was to be changed.
+	ErrPaymentPending = errors.New("payment pending")
+
+	ErrTooManyRequests = errors.New("too many requests")
 )
 
 type AppError struct {
@@ -66,3 +74,19 @@ func NewForbiddenError(msg string) error {
 		Err:     ErrForbidden,
 	}
 }
+
+func NewConflictError(msg string) error {
+	return AppError{
+		Code:    http.StatusConflict,
+		Message: msg,
+		Err:     ErrConflict,
+	}
+}
+
+func NewTooManyRequestsError(msg string) error {
+	return AppError{
+		Code:    http.StatusTooManyRequests,
+		Message: msg,
+		Err:     ErrTooManyRequests,
+	}
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+const (
+	AccountTokenVerifyEmail   = "verify_email"
+	AccountTokenResetPassword = "reset_password"
+	AccountTokenUnlockAccount = "unlock_account"
+)
+
+// AccountToken is a single-use link mailed to a user to verify their email,
+// reset their password or unlock their account. Only its hash is stored.
+type AccountToken struct {
+	TokenID   int
+	UserID    int
+	Purpose   string
+	TokenHash string
+	Email     string // the address the link was sent to
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    time.Time
+}
@@ -1,5 +1,15 @@
 package domain
 
+import "time"
+
+// Charge units say how an addon's price is multiplied for a stay.
+const (
+	AddonChargePerStay        = "per_stay"
+	AddonChargePerNight       = "per_night"
+	AddonChargePerPersonNight = "per_person_per_night"
+	AddonChargePerItem        = "per_item"
+)
+
 type AddonCategory struct {
 	CategoryID int
 	Name       string
@@ -10,7 +20,94 @@ type Addon struct {
 	CategoryID  int
 	Name        string
 	Description string
-	Price       float64
+	Price       Money
 	UnitName    string
 	PictureURL  string
+	// IsExtraBed marks the addon that provides beds for guests beyond a
+	// room's base occupancy.
+	IsExtraBed bool
+	ChargeUnit string
+	// RequiresServiceDate is set for addons delivered on one day of the stay,
+	// such as an airport transfer or a spa slot.
+	RequiresServiceDate bool
+	// DailyCapacity caps how much of the addon is sold per day; 0 is unlimited.
+	DailyCapacity int
+	// Slots are the time slots guests pick from. Only loaded for a single
+	// addon.
+	Slots []*AddonSlot
+}
+
+// AddonSlot is a named time of day with its own daily capacity, such as the
+// 10:00 spa slot or the 19:30 dinner seating.
+type AddonSlot struct {
+	SlotID   int
+	AddonID  int
+	Name     string
+	Capacity int
+}
+
+func (a *Addon) Slot(slotID int) *AddonSlot {
+	for _, slot := range a.Slots {
+		if slot.SlotID == slotID {
+			return slot
+		}
+	}
+	return nil
+}
+
+// AddonUsage is how many units of an addon active bookings hold on one day,
+// per slot (SlotID 0 when booked without one).
+type AddonUsage struct {
+	SlotID int
+	Date   time.Time
+	Units  int
+}
+
+// AddonAvailability is what is left of an addon on one day. Remaining is -1
+// when the addon has no daily capacity.
+type AddonAvailability struct {
+	Date      time.Time
+	Booked    int
+	Remaining int
+	Slots     []*AddonSlotAvailability
+}
+
+type AddonSlotAvailability struct {
+	SlotID    int
+	Name      string
+	Capacity  int
+	Booked    int
+	Remaining int
+}
+
+// Availability works out the capacity left on day from the usage of active
+// bookings on that day.
+func (a *Addon) Availability(day time.Time, usage []*AddonUsage) *AddonAvailability {
+	bySlot := make(map[int]int)
+	res := &AddonAvailability{Date: day, Remaining: -1}
+	for _, u := range usage {
+		if !u.Date.Equal(day) {
+			continue
+		}
+		res.Booked += u.Units
+		bySlot[u.SlotID] += u.Units
+	}
+	if a.DailyCapacity > 0 {
+		res.Remaining = max(a.DailyCapacity-res.Booked, 0)
+	}
+
+	for _, slot := range a.Slots {
+		left := max(slot.Capacity-bySlot[slot.SlotID], 0)
+		if res.Remaining >= 0 {
+			left = min(left, res.Remaining)
+		}
+		res.Slots = append(res.Slots, &AddonSlotAvailability{
+			SlotID:    slot.SlotID,
+			Name:      slot.Name,
+			Capacity:  slot.Capacity,
+			Booked:    bySlot[slot.SlotID],
+			Remaining: left,
+		})
+	}
+	return res
 }
@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+// GroupAllotment holds RoomsAllotted rooms of one type on every night from
+// StartDate up to EndDate for bookings made with GroupCode. Rooms nobody has
+// claimed by CutoffDate go back to public inventory.
+type GroupAllotment struct {
+	AllotmentID   int
+	GroupCode     string
+	Name          string
+	RoomTypeID    int
+	StartDate     time.Time
+	EndDate       time.Time
+	RoomsAllotted int
+	CutoffDate    time.Time
+	IsActive      bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Released reports whether the unclaimed rooms are back in public inventory,
+// which happens at the start of the cutoff day.
+func (a *GroupAllotment) Released(now time.Time) bool {
+	y, m, d := now.Date()
+	cy, cm, cd := a.CutoffDate.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return !a.IsActive || !today.Before(time.Date(cy, cm, cd, 0, 0, 0, 0, time.UTC))
+}
+
+// AllotmentNight is one night of a pickup report.
+type AllotmentNight struct {
+	StayDate time.Time
+	Allotted int
+	PickedUp int
+}
+
+func (n *AllotmentNight) Remaining() int {
+	if n.PickedUp >= n.Allotted {
+		return 0
+	}
+	return n.Allotted - n.PickedUp
+}
+
+// PickupReport shows how much of an allotment the group has claimed.
+type PickupReport struct {
+	Allotment *GroupAllotment
+	Nights    []*AllotmentNight
+	Bookings  int
+}
+
+func (r *PickupReport) RoomNightsAllotted() int {
+	total := 0
+	for _, n := range r.Nights {
+		total += n.Allotted
+	}
+	return total
+}
+
+func (r *PickupReport) RoomNightsPickedUp() int {
+	total := 0
+	for _, n := range r.Nights {
+		total += n.PickedUp
+	}
+	return total
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+const (
+	AuditLoginThrottled   = "login_throttled"    // a username started collecting delays
+	AuditLoginWhileLocked = "login_while_locked" // a login was tried on a locked account
+	AuditAccountLocked    = "account_locked"
+	AuditAccountUnlocked  = "account_unlocked"
+	AuditIPBlocked        = "ip_blocked"
+
+	AuditTwoFactorEnabled  = "two_factor_enabled"
+	AuditTwoFactorDisabled = "two_factor_disabled"
+	AuditTwoFactorFailed   = "two_factor_failed" // right password, wrong code
+	AuditRecoveryCodeUsed  = "recovery_code_used"
+)
+
+// AuditEntry records a security event. UserID is 0 when the username the
+// event is about does not exist.
+type AuditEntry struct {
+	AuditID   int64
+	UserID    int
+	Event     string
+	Username  string
+	IPAddress string
+	UserAgent string
+	Details   string
+	CreatedAt time.Time
+}
+
+type AuditFilter struct {
+	UserID int
+	Event  string
+	Limit  int
+}
@@ -1,49 +1,157 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	BookingStatusPending    = "pending"
+	BookingStatusConfirmed  = "confirmed"
+	BookingStatusCancelled  = "cancelled"
+	BookingStatusExpired    = "expired"
+	BookingStatusNoShow     = "no-show"
+	BookingStatusCheckedIn  = "checked-in"
+	BookingStatusCheckedOut = "checked-out"
+)
 
 type Booking struct {
 	BookingID     int
+	ReservationID int
 	UserID        int
 	RatePlanID    int
+	RoomTypeID    int
 	RoomID        int
 	CheckInDate   time.Time
 	CheckOutDate  time.Time
 	NumAdults     int
+	ChildAges     []int
 	Email         string
 	Status        string
-	RoomSubTotal  float64
-	AddonSubTotal float64
-	TaxesAmount   float64
-	TotalPrice    float64
+	RoomSubTotal  Money
+	AddonSubTotal Money
+	TaxesAmount   Money
+	TotalPrice    Money
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	ExpiredAt     time.Time
 	BookingAddon  []*BookingAddon
+	Nights        []*NightlyRate
+	Taxes         []*BookingTax
+
+	CancellationReason string
+	CancellationFee    Money
+	CancelledAt        time.Time
+
+	PromotionID    int
+	PromoCode      string
+	DiscountAmount Money
+
+	// GroupCode books against a group allotment, recorded as AllotmentID.
+	GroupCode   string
+	AllotmentID int
+
+	// WaitlistEntryID marks the booking as the hold offered to that entry.
+	WaitlistEntryID int
+
+	// ConfirmationCode is what guests quote instead of the sequential ID;
+	// it is issued when the booking is stored.
+	ConfirmationCode string
+
+	AmountPaid Money
 }
 
 type BookingDetail struct {
 	BookingID     int
+	ReservationID int
 	UserID        int
 	RatePlanID    int
 	RoomID        int
 	CheckInDate   time.Time
 	CheckOutDate  time.Time
 	NumAdults     int
+	ChildAges     []int
 	Status        string
-	RoomSubTotal  float64
-	AddonSubTotal float64
-	TaxesAmount   float64
-	TotalPrice    float64
+	RoomSubTotal  Money
+	AddonSubTotal Money
+	TaxesAmount   Money
+	TotalPrice    Money
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	ExpiredAt     time.Time
 	BookingAddon  []*BookingAddon
+	Nights        []*NightlyRate
+	Taxes         []*BookingTax
 	RatePlanName  string
 	RoomNumber    string
 	RoomTypeName  string
 	Email         string
 	UserName      string
+
+	CancellationReason string
+	CancellationFee    Money
+	CancelledAt        time.Time
+
+	PromotionID    int
+	PromoCode      string
+	DiscountAmount Money
+
+	AllotmentID int
+
+	ConfirmationCode string
+
+	AmountPaid Money
+	Balance    Money // sum of the folio entries; negative when the guest is owed a refund
+}
+
+// BalanceDue is what the guest still owes; it is due at check-in.
+func (b *BookingDetail) BalanceDue() Money {
+	if b.Balance.IsNegative() {
+		return NewMoney(0, b.Balance.Currency)
+	}
+	return b.Balance
+}
+
+// Reservation groups the room stays booked together in one checkout. They
+// share a confirmation number, dates and payment, and are held all or nothing.
+type Reservation struct {
+	ReservationID int
+	UserID        int
+	Email         string
+	CheckInDate   time.Time
+	CheckOutDate  time.Time
+	PromoCode     string
+	GroupCode     string
+	Bookings      []*Booking
+	CreatedAt     time.Time
+}
+
+type ReservationDetail struct {
+	ReservationID int
+	UserID        int
+	Email         string
+	UserName      string
+	Bookings      []*BookingDetail
+	CreatedAt     time.Time
+}
+
+// Totals sums the price, the payments and the balance due of every room.
+func (r *ReservationDetail) Totals() (total, paid, due Money, err error) {
+	prices := make([]Money, len(r.Bookings))
+	payments := make([]Money, len(r.Bookings))
+	balances := make([]Money, len(r.Bookings))
+	for i, b := range r.Bookings {
+		prices[i], payments[i], balances[i] = b.TotalPrice, b.AmountPaid, b.BalanceDue()
+	}
+
+	if total, err = Sum(prices...); err != nil {
+		return
+	}
+	if paid, err = Sum(payments...); err != nil {
+		return
+	}
+	due, err = Sum(balances...)
+	return
 }
 
 type BookingAddon struct {
@@ -52,5 +160,122 @@ type BookingAddon struct {
 	AddonID        int
 	AddonName      string
 	Quantity       int
-	PriceAtBooking float64
+	PriceAtBooking Money // unit price
+	ChargeUnit     string
+	Nights         int // nights charged for, 0 when the unit is not per night
+	Guests         int // guests charged for, 0 when the unit is not per person
+	ServiceDate    time.Time
+	SlotID         int // 0 when the addon has no time slots
+	SlotName       string
+}
+
+// DailyUnits is how much of the addon's daily capacity the line takes on each
+// of its service days.
+func (a *BookingAddon) DailyUnits() int {
+	if a.Guests > 0 {
+		return a.Quantity * a.Guests
+	}
+	return a.Quantity
+}
+
+// ServiceDays are the days the addon is delivered: its service date if it has
+// one, every night of the stay for nightly addons, otherwise the check-in day.
+func (a *BookingAddon) ServiceDays(checkIn, checkOut time.Time) []time.Time {
+	if !a.ServiceDate.IsZero() {
+		return []time.Time{dateOnly(a.ServiceDate)}
+	}
+	if a.ChargeUnit != AddonChargePerNight && a.ChargeUnit != AddonChargePerPersonNight {
+		return []time.Time{dateOnly(checkIn)}
+	}
+
+	var days []time.Time
+	for d := dateOnly(checkIn); d.Before(dateOnly(checkOut)); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+	return days
+}
+
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Units is how many times the unit price is charged.
+func (a *BookingAddon) Units() int64 {
+	units := int64(a.Quantity)
+	if a.Nights > 0 {
+		units *= int64(a.Nights)
+	}
+	if a.Guests > 0 {
+		units *= int64(a.Guests)
+	}
+	return units
+}
+
+func (a *BookingAddon) Total() Money {
+	return a.PriceAtBooking.Mul(a.Units())
+}
+
+// Breakdown shows how Total is worked out, e.g. "THB 350.00 x 2 guests x 3 nights".
+func (a *BookingAddon) Breakdown() string {
+	s := a.PriceAtBooking.Display()
+	if a.Quantity != 1 || (a.Nights == 0 && a.Guests == 0 && a.ChargeUnit != AddonChargePerStay) {
+		s += fmt.Sprintf(" x %d", a.Quantity)
+	}
+	if a.Guests > 0 {
+		s += fmt.Sprintf(" x %d %s", a.Guests, plural(a.Guests, "guest"))
+	}
+	if a.Nights > 0 {
+		s += fmt.Sprintf(" x %d %s", a.Nights, plural(a.Nights, "night"))
+	}
+	return s
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+type BookingStatusHistory struct {
+	HistoryID   int
+	BookingID   int
+	ActorUserID int
+	OldStatus   string
+	NewStatus   string
+	Reason      string
+	CreatedAt   time.Time
+}
+
+// StayChange asks to move a booking to new dates or another room type. Zero
+// fields keep the booking's current value.
+type StayChange struct {
+	CheckInDate  time.Time
+	CheckOutDate time.Time
+	RoomTypeID   int
+	// ExpectedTotal, when set, is the total the guest saw in the preview; the
+	// change is refused if the price has moved since.
+	ExpectedTotal *Money
+}
+
+// StayQuote prices a StayChange against the booking as it is now.
+type StayQuote struct {
+	BookingID      int
+	RoomTypeID     int
+	RoomID         int
+	CheckInDate    time.Time
+	CheckOutDate   time.Time
+	Nights         []*NightlyRate
+	RoomSubTotal   Money
+	BookingAddon   []*BookingAddon // the booking's addons sized to the new stay
+	AddonSubTotal  Money
+	DiscountAmount Money
+	TaxesAmount    Money
+	Taxes          []*BookingTax
+	TotalPrice     Money
+	OldTotalPrice  Money
+	Difference     Money // TotalPrice - OldTotalPrice, posted to the folio when applied
+	PromoDropped   bool  // the promo code no longer applies to the new stay
+	Applied        bool
 }
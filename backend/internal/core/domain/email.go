@@ -0,0 +1,8 @@
+package domain
+
+// EmailMessage is a plain-text email for the generic Send of the email port.
+type EmailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
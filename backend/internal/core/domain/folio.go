@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// Folio entry types. Charges and refunds raise what the guest owes, payments
+// lower it, and adjustments go either way.
+const (
+	FolioEntryCharge     = "charge"
+	FolioEntryPayment    = "payment"
+	FolioEntryRefund     = "refund"
+	FolioEntryAdjustment = "adjustment"
+)
+
+// Folio entry categories say what an entry is for.
+const (
+	FolioCategoryRoom         = "room"
+	FolioCategoryAddon        = "addon"
+	FolioCategoryTax          = "tax"
+	FolioCategoryDiscount     = "discount"
+	FolioCategoryCancellation = "cancellation"
+	FolioCategoryPayment      = "payment"
+	FolioCategoryOther        = "other"
+)
+
+// FolioEntry is one line of a booking's ledger. Amount is signed from the
+// guest's side: positive entries are owed by the guest, negative ones are
+// owed to the guest.
+type FolioEntry struct {
+	EntryID     int
+	BookingID   int
+	EntryType   string
+	Category    string
+	Description string
+	Amount      Money
+	PaymentID   int
+	ActorUserID int
+	CreatedAt   time.Time
+}
+
+// Folio is a booking's ledger and the balance derived from it.
+type Folio struct {
+	BookingID int
+	Entries   []*FolioEntry
+	Balance   Money
+}
+
+// FolioBalance sums the entries.
+func FolioBalance(entries []*FolioEntry) (Money, error) {
+	amounts := make([]Money, len(entries))
+	for i, e := range entries {
+		amounts[i] = e.Amount
+	}
+	return Sum(amounts...)
+}
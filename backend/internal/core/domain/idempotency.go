@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+const (
+	IdempotencyStatusProcessing = "processing" // the first request is still running
+	IdempotencyStatusCompleted  = "completed"  // the response is stored for replay
+)
+
+// IdempotencyKey records the first request a user sent with a given
+// Idempotency-Key header and, once it finished, its response.
+type IdempotencyKey struct {
+	UserID      int
+	Key         string
+	RequestHash string
+	Status      string
+
+	ResponseStatus int
+	ContentType    string
+	ResponseBody   []byte
+
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	LockedUntil time.Time // when a processing key may be taken over by a retry
+}
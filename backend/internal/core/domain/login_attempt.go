@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// LoginAttempt tracks failed logins for one key: a username or a client IP.
+type LoginAttempt struct {
+	Key           string
+	Failures      int
+	FirstFailedAt time.Time
+	LastFailedAt  time.Time
+	LockedUntil   time.Time
+}
+
+func (a *LoginAttempt) Locked(now time.Time) bool {
+	return now.Before(a.LockedUntil)
+}
@@ -0,0 +1,305 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency every price in the system is quoted in.
+const DefaultCurrency = "THB"
+
+// Rounding modes for amounts that need more precision than the minor unit.
+// RoundPerLine rounds each line before summing; RoundAtTotal keeps the exact
+// lines, rounds their sum and spreads the difference back over the lines.
+const (
+	RoundPerLine = "line"
+	RoundAtTotal = "total"
+)
+
+// percentScale is the precision of percentage rates: four decimal places,
+// matching the DECIMAL(10,4) columns they are stored in.
+const percentScale = 10000
+
+// MicrosPerMinor is the number of micro units in one minor unit. Percentages
+// of an amount are exact at this precision.
+const MicrosPerMinor = 100 * percentScale
+
+var currencyDigits = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+}
+
+// ErrCurrencyMismatch is returned when amounts in two different currencies are
+// added, subtracted or compared.
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Money is an exact amount in the currency's minor units (satang for THB).
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func NewMoney(minor int64, currency string) Money {
+	return Money{Amount: minor, Currency: currency}
+}
+
+// THB returns an amount of satang.
+func THB(satang int64) Money {
+	return NewMoney(satang, DefaultCurrency)
+}
+
+// Digits returns how many decimal places the currency's minor unit has.
+func Digits(currency string) int {
+	if d, ok := currencyDigits[currency]; ok {
+		return d
+	}
+	return 2
+}
+
+func minorPerMajor(currency string) int64 {
+	n := int64(1)
+	for i := 0; i < Digits(currency); i++ {
+		n *= 10
+	}
+	return n
+}
+
+// ParseMoney reads a decimal string such as "1250.50" without going through
+// floating point. More decimal places than the currency has are rejected.
+func ParseMoney(s, currency string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Money{}, fmt.Errorf("empty amount")
+	}
+
+	neg := false
+	switch s[0] {
+	case '-':
+		neg = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	// ParseInt would take a second sign, so both parts must be bare digits.
+	whole, frac, _ := strings.Cut(s, ".")
+	if !isDigits(whole) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+	digits := Digits(currency)
+	// Postgres pads DECIMAL values with zeros, so only significant digits count.
+	trimmed := strings.TrimRight(frac, "0")
+	if len(trimmed) > digits {
+		return Money{}, fmt.Errorf("amount %q has more than %d decimal places", s, digits)
+	}
+	if whole == "" && frac == "" {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	major, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || major < 0 {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+
+	var minor int64
+	if trimmed != "" {
+		padded := trimmed + strings.Repeat("0", digits-len(trimmed))
+		minor, err = strconv.ParseInt(padded, 10, 64)
+		if err != nil {
+			return Money{}, fmt.Errorf("invalid amount %q", s)
+		}
+	}
+
+	amount := major*minorPerMajor(currency) + minor
+	if neg {
+		amount = -amount
+	}
+	return NewMoney(amount, currency), nil
+}
+
+// isDigits reports whether s holds only ASCII digits; the empty string does.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (m Money) IsZero() bool     { return m.Amount == 0 }
+func (m Money) IsPositive() bool { return m.Amount > 0 }
+func (m Money) IsNegative() bool { return m.Amount < 0 }
+
+func (m Money) Add(o Money) (Money, error) {
+	currency, err := m.sameCurrency(o)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(m.Amount+o.Amount, currency), nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	currency, err := m.sameCurrency(o)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(m.Amount-o.Amount, currency), nil
+}
+
+// Sum adds up amounts that share a currency. The sum of no amounts is zero in
+// the default currency.
+func Sum(amounts ...Money) (Money, error) {
+	total := NewMoney(0, "")
+	for _, a := range amounts {
+		var err error
+		if total, err = total.Add(a); err != nil {
+			return Money{}, err
+		}
+	}
+	if total.Currency == "" {
+		total.Currency = DefaultCurrency
+	}
+	return total, nil
+}
+
+func (m Money) Mul(n int64) Money {
+	return NewMoney(m.Amount*n, m.Currency)
+}
+
+func (m Money) Neg() Money {
+	return NewMoney(-m.Amount, m.Currency)
+}
+
+func (m Money) LessThan(o Money) (bool, error) {
+	if _, err := m.sameCurrency(o); err != nil {
+		return false, err
+	}
+	return m.Amount < o.Amount, nil
+}
+
+func (m Money) GreaterThan(o Money) (bool, error) {
+	if _, err := m.sameCurrency(o); err != nil {
+		return false, err
+	}
+	return m.Amount > o.Amount, nil
+}
+
+// Min returns the smaller of the two amounts.
+func (m Money) Min(o Money) (Money, error) {
+	less, err := o.LessThan(m)
+	if err != nil {
+		return Money{}, err
+	}
+	if less {
+		return o, nil
+	}
+	return m, nil
+}
+
+// MulPercent returns pct percent of the amount rounded half-up to the minor unit.
+func (m Money) MulPercent(pct float64) Money {
+	return MoneyFromMicros(m.PercentMicros(pct), m.Currency)
+}
+
+// PercentMicros returns pct percent of the amount in micro units, exact for
+// rates with up to four decimal places.
+func (m Money) PercentMicros(pct float64) int64 {
+	return m.Amount * int64(math.Round(pct*percentScale))
+}
+
+// DivRound splits the amount into n parts and returns one part, rounded half-up.
+func (m Money) DivRound(n int64) Money {
+	return NewMoney(divRoundHalfUp(m.Amount, n), m.Currency)
+}
+
+// MoneyFromMicros rounds a micro-unit amount half-up to the minor unit.
+func MoneyFromMicros(micros int64, currency string) Money {
+	return NewMoney(divRoundHalfUp(micros, MicrosPerMinor), currency)
+}
+
+// String formats the amount in major units with the currency's decimal places, e.g. "1250.50".
+func (m Money) String() string {
+	digits := Digits(m.currencyOrDefault())
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	if digits == 0 {
+		return sign + strconv.FormatInt(amount, 10)
+	}
+	per := minorPerMajor(m.currencyOrDefault())
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/per, digits, amount%per)
+}
+
+// Display formats the amount with its currency code for people, e.g. "THB 1250.50".
+func (m Money) Display() string {
+	return m.currencyOrDefault() + " " + m.String()
+}
+
+// MarshalJSON writes the amount as an exact JSON number such as 1250.50.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a numeric string in the default currency.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" || s == "" {
+		*m = NewMoney(0, DefaultCurrency)
+		return nil
+	}
+	parsed, err := ParseMoney(s, DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) currencyOrDefault() string {
+	if m.Currency == "" {
+		return DefaultCurrency
+	}
+	return m.Currency
+}
+
+// sameCurrency returns the shared currency. A Money without a currency adopts
+// the other's; two different currencies are an ErrCurrencyMismatch.
+func (m Money) sameCurrency(o Money) (string, error) {
+	switch {
+	case m.Currency == o.Currency:
+		return m.currencyOrDefault(), nil
+	case m.Currency == "":
+		return o.Currency, nil
+	case o.Currency == "":
+		return m.Currency, nil
+	}
+	return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+}
+
+// divRoundHalfUp divides and rounds halves away from zero.
+func divRoundHalfUp(num, den int64) int64 {
+	q := num / den
+	r := num % den
+	if r < 0 {
+		r = -r
+	}
+	if 2*r >= den {
+		if num < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q
+}
@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in       string
+		currency string
+		want     int64
+		wantErr  bool
+	}{
+		{in: "1250.50", currency: "THB", want: 125050},
+		{in: "1250.5", currency: "THB", want: 125050},
+		{in: "1250", currency: "THB", want: 125000},
+		{in: "0.01", currency: "THB", want: 1},
+		{in: ".5", currency: "THB", want: 50},
+		{in: "7.", currency: "THB", want: 700},
+		{in: "-12.34", currency: "THB", want: -1234},
+		{in: "+12.34", currency: "THB", want: 1234},
+		{in: " 99.90 ", currency: "THB", want: 9990},
+		{in: "100.0000", currency: "THB", want: 10000}, // Postgres DECIMAL padding
+		{in: "1500", currency: "JPY", want: 1500},
+		{in: "1500.00", currency: "JPY", want: 1500},
+		{in: "1.001", currency: "THB", wantErr: true},
+		{in: "1.5", currency: "JPY", wantErr: true},
+		{in: "", currency: "THB", wantErr: true},
+		{in: ".", currency: "THB", wantErr: true},
+		{in: "-", currency: "THB", wantErr: true},
+		{in: "abc", currency: "THB", wantErr: true},
+		{in: "1.2x", currency: "THB", wantErr: true},
+		{in: "--1", currency: "THB", wantErr: true},
+		{in: "1e3", currency: "THB", wantErr: true},
+		{in: "1.-5", currency: "THB", wantErr: true},
+		{in: "1.+5", currency: "THB", wantErr: true},
+		{in: "-+5", currency: "THB", wantErr: true},
+		{in: "+-5", currency: "THB", wantErr: true},
+		{in: "1. 5", currency: "THB", wantErr: true},
+		{in: "1_000", currency: "THB", wantErr: true},
+		{in: "1.2.3", currency: "THB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.in, tt.currency)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseMoney(%q, %s) = %v, want an error", tt.in, tt.currency, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseMoney(%q, %s): %v", tt.in, tt.currency, err)
+			continue
+		}
+		if got.Amount != tt.want || got.Currency != tt.currency {
+			t.Errorf("ParseMoney(%q, %s) = %d %s, want %d %s", tt.in, tt.currency, got.Amount, got.Currency, tt.want, tt.currency)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{THB(125050), "1250.50"},
+		{THB(5), "0.05"},
+		{THB(-1234), "-12.34"},
+		{THB(0), "0.00"},
+		{NewMoney(1500, "JPY"), "1500"},
+		{Money{Amount: 100}, "1.00"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("%#v.String() = %q, want %q", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestRoundHalfUp(t *testing.T) {
+	tests := []struct {
+		micros int64
+		want   int64
+	}{
+		{0, 0},
+		{1_000_000, 1},
+		{1_499_999, 1},
+		{1_500_000, 2},
+		{2_500_000, 3},
+		{499_999, 0},
+		{500_000, 1},
+		{-500_000, -1},
+		{-1_499_999, -1},
+		{-1_500_000, -2},
+	}
+
+	for _, tt := range tests {
+		if got := MoneyFromMicros(tt.micros, DefaultCurrency); got.Amount != tt.want {
+			t.Errorf("MoneyFromMicros(%d) = %d, want %d", tt.micros, got.Amount, tt.want)
+		}
+	}
+}
+
+func TestDivRound(t *testing.T) {
+	tests := []struct {
+		amount int64
+		n      int64
+		want   int64
+	}{
+		{1000, 3, 333},
+		{2000, 3, 667},
+		{5, 2, 3},
+		{-5, 2, -3},
+		{7, 7, 1},
+	}
+
+	for _, tt := range tests {
+		if got := THB(tt.amount).DivRound(tt.n); got.Amount != tt.want {
+			t.Errorf("THB(%d).DivRound(%d) = %d, want %d", tt.amount, tt.n, got.Amount, tt.want)
+		}
+	}
+}
+
+func TestMulPercent(t *testing.T) {
+	tests := []struct {
+		amount int64
+		pct    float64
+		want   int64
+	}{
+		{100000, 7, 7000},
+		{100000, 10, 10000},
+		{100000, 0, 0},
+		{100000, 100, 100000},
+		{999, 7, 70},    // 69.93 rounds up
+		{1050, 10, 105}, // exact
+		{15, 10, 2},     // 1.5 rounds half up
+		{14, 10, 1},     // 1.4 rounds down
+		{100000, 12.5, 12500},
+		{333, 33.3333, 111}, // 110.9998889 at four decimal places of rate
+		{-15, 10, -2},       // halves round away from zero
+		{12345, 0.0001, 0},
+	}
+
+	for _, tt := range tests {
+		if got := THB(tt.amount).MulPercent(tt.pct); got.Amount != tt.want || got.Currency != DefaultCurrency {
+			t.Errorf("THB(%d).MulPercent(%v) = %d %s, want %d", tt.amount, tt.pct, got.Amount, got.Currency, tt.want)
+		}
+	}
+}
+
+func TestMoneyCurrencies(t *testing.T) {
+	usd := NewMoney(100, "USD")
+
+	if _, err := THB(100).Add(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("THB + USD: got %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := THB(100).Sub(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("THB - USD: got %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := THB(100).LessThan(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("THB < USD: got %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := Sum(THB(1), THB(2), usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sum(THB, THB, USD): got %v, want ErrCurrencyMismatch", err)
+	}
+
+	// An amount without a currency takes the other's.
+	got, err := Money{Amount: 50}.Add(usd)
+	if err != nil || got != NewMoney(150, "USD") {
+		t.Errorf("zero-currency + USD = %v, %v; want 150 USD", got, err)
+	}
+
+	total, err := Sum()
+	if err != nil || total != THB(0) {
+		t.Errorf("Sum() = %v, %v; want 0 THB", total, err)
+	}
+	total, err = Sum(THB(100), THB(250).Neg(), THB(25))
+	if err != nil || total != THB(-125) {
+		t.Errorf("Sum = %v, %v; want -125 THB", total, err)
+	}
+}
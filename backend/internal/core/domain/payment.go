@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusSucceeded = "succeeded"
+	PaymentStatusFailed    = "failed"
+	PaymentStatusRefunded  = "refunded"
+
+	RefundStatusPending   = "pending"
+	RefundStatusSucceeded = "succeeded"
+	RefundStatusFailed    = "failed"
+
+	PaymentEventSucceeded = "payment.succeeded"
+	PaymentEventFailed    = "payment.failed"
+	PaymentEventRefunded  = "payment.refunded"
+)
+
+// Payment is one attempt to pay for a booking through a payment provider. A
+// payment for a whole reservation has a ReservationID and no BookingID.
+type Payment struct {
+	PaymentID     int
+	BookingID     int
+	ReservationID int
+	Provider      string
+	ProviderRef   string
+	Amount        Money
+	Status        string
+	FailureReason string
+	RecordedBy    int // staff member who took a front desk payment
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// PaymentIntentRequest asks the provider to charge Amount for a booking.
+// PaymentMethod is the provider's token for the guest's card or wallet.
+type PaymentIntentRequest struct {
+	BookingID     int
+	ReservationID int
+	Amount        Money
+	Email         string
+	PaymentMethod string
+}
+
+// PaymentIntent is the provider's answer to a PaymentIntentRequest. ClientSecret
+// is handed to the frontend when the provider needs the guest to finish the payment.
+type PaymentIntent struct {
+	ProviderRef  string
+	ClientSecret string
+	Status       string
+}
+
+// PaymentEvent is a verified webhook notification from the provider.
+type PaymentEvent struct {
+	Type          string
+	ProviderRef   string
+	Amount        Money
+	FailureReason string
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+const (
+	DiscountTypePercent = "percent"
+	DiscountTypeFixed   = "fixed"
+)
+
+// Promotion is a promo code or voucher. Percent promotions take DiscountRate
+// percent off the room charges; fixed ones take DiscountAmount off. Zero-valued
+// windows and caps and empty RoomTypeIDs/RatePlanIDs leave that dimension
+// unrestricted.
+type Promotion struct {
+	PromotionID    int
+	Code           string
+	Description    string
+	DiscountType   string
+	DiscountRate   float64
+	DiscountAmount Money
+	MinSpend       Money
+	BookingStart   time.Time
+	BookingEnd     time.Time
+	StayStart      time.Time
+	StayEnd        time.Time
+	RoomTypeIDs    []int
+	RatePlanIDs    []int
+	MaxRedemptions int
+	MaxPerUser     int
+	IsActive       bool
+	Redemptions    int
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 type RatePlan struct {
 	RatePlanID       int
@@ -9,24 +12,115 @@ type RatePlan struct {
 	IsSpecialPackage bool
 	AllowFreeCancel  bool
 	AllowPayLater    bool
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
+	// DepositPercent and RequiresGuarantee only apply to pay-later plans: the booking
+	// is confirmed once the deposit is paid or the card is verified.
+	DepositPercent    float64
+	RequiresGuarantee bool
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// AmountDueNow is what must be paid to confirm a booking of total on the plan:
+// the full total, or on pay-later plans the deposit, which is zero for
+// guarantee-only and no-deposit plans.
+func (rp *RatePlan) AmountDueNow(total Money) Money {
+	if !rp.AllowPayLater {
+		return total
+	}
+	return total.MulPercent(rp.DepositPercent)
 }
 
 type RatePlanFull struct {
-	RatePlanID       int
-	Name             string
-	Description      string
-	IsSpecialPackage bool
-	AllowFreeCancel  bool
-	AllowPayLater    bool
-	Price            float64
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
+	RatePlanID        int
+	Name              string
+	Description       string
+	IsSpecialPackage  bool
+	AllowFreeCancel   bool
+	AllowPayLater     bool
+	DepositPercent    float64
+	RequiresGuarantee bool
+	Price             Money
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
 }
 
 type RoomTypeRatePrice struct {
 	RoomTypeID int
 	RatePlanID int
-	Price      float64
+	Price      Money
+}
+
+type RateCalendarEntry struct {
+	RoomTypeID int
+	RatePlanID int
+	StayDate   time.Time
+	Price      Money
+}
+
+type NightlyRate struct {
+	StayDate time.Time
+	Price    Money
+	// ExtraGuestCharge is the part of Price charged for guests beyond the
+	// base occupancy.
+	ExtraGuestCharge Money
+}
+
+// OccupancyPricing adjusts a room type's nightly rate on one rate plan for the
+// size of the party. The rate covers BaseOccupancy guests; each guest beyond
+// that pays a fee per night and needs an extra bed.
+type OccupancyPricing struct {
+	RoomTypeID    int
+	RatePlanID    int
+	BaseOccupancy int
+	ExtraAdultFee Money
+	ChildFees     []*ChildAgeBand
+}
+
+// ChildAgeBand is the nightly fee for an extra child aged MinAge to MaxAge.
+type ChildAgeBand struct {
+	MinAge int
+	MaxAge int
+	Fee    Money
+}
+
+// ExtraGuests is how many guests the party has beyond the base occupancy.
+func (p *OccupancyPricing) ExtraGuests(adults int, childAges []int) int {
+	extra := adults + len(childAges) - p.BaseOccupancy
+	if extra < 0 {
+		return 0
+	}
+	return extra
+}
+
+// NightlyCharge is the fee for one night for the guests beyond the base
+// occupancy. Adults take the base places first, then children from the oldest
+// down. A child whose age falls in no band pays the extra adult fee.
+func (p *OccupancyPricing) NightlyCharge(adults int, childAges []int) (Money, error) {
+	fees := []Money{NewMoney(0, p.ExtraAdultFee.Currency)}
+
+	free := p.BaseOccupancy - adults
+	if free < 0 {
+		fees = append(fees, p.ExtraAdultFee.Mul(int64(-free)))
+		free = 0
+	}
+
+	ages := append([]int(nil), childAges...)
+	sort.Sort(sort.Reverse(sort.IntSlice(ages)))
+	for _, age := range ages {
+		if free > 0 {
+			free--
+			continue
+		}
+		fees = append(fees, p.childFee(age))
+	}
+	return Sum(fees...)
+}
+
+func (p *OccupancyPricing) childFee(age int) Money {
+	for _, band := range p.ChildFees {
+		if age >= band.MinAge && age <= band.MaxAge {
+			return band.Fee
+		}
+	}
+	return p.ExtraAdultFee
 }
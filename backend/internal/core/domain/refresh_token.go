@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// RefreshToken is one link of a rotation chain. Only the hash of the token
+// handed to the client is kept. Tokens from the same login share a FamilyID.
+type RefreshToken struct {
+	TokenID   int
+	UserID    int
+	FamilyID  string
+	TokenHash string
+	UserAgent string
+	IPAddress string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    time.Time // set when the token was exchanged for its successor
+	RevokedAt time.Time
+}
+
+// Spent reports whether the token can no longer be exchanged; presenting a
+// spent token means it was stolen or replayed.
+func (t *RefreshToken) Spent() bool {
+	return !t.UsedAt.IsZero() || !t.RevokedAt.IsZero()
+}
+
+// Device describes the client a refresh token was issued to.
+type Device struct {
+	UserAgent string
+	IPAddress string
+}
+
+// TokenPair is what login and refresh hand to the client.
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
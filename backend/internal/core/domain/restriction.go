@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// StayRestriction limits how a room type can be sold between StartDate and
+// EndDate (both inclusive). RatePlanID 0 applies to every rate plan and a zero
+// MinLOS/MaxLOS means no limit.
+type StayRestriction struct {
+	RestrictionID     int
+	RoomTypeID        int
+	RatePlanID        int
+	StartDate         time.Time
+	EndDate           time.Time
+	MinLOS            int
+	MaxLOS            int
+	ClosedToArrival   bool
+	ClosedToDeparture bool
+	StopSell          bool
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
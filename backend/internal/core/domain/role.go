@@ -0,0 +1,47 @@
+package domain
+
+const (
+	RoleGuest          = "guest"
+	RoleFrontDesk      = "front-desk"
+	RoleHousekeeping   = "housekeeping"
+	RoleRevenueManager = "revenue-manager"
+	RoleAdmin          = "admin"
+)
+
+// Permissions are seeded with the roles in the database; these names are the
+// ones the routes check.
+const (
+	PermBookingsReadAll      = "bookings:read_all"
+	PermBookingsManageAny    = "bookings:manage_any" // act on bookings the user does not own
+	PermBookingsUpdateStatus = "bookings:update_status"
+	PermPaymentsRecord       = "payments:record"
+	PermPaymentsRefund       = "payments:refund"
+	PermFolioAdjust          = "folio:adjust"
+	PermWaitlistRead         = "waitlist:read"
+	PermRoomsManage          = "rooms:manage"
+	PermRoomsUpdateStatus    = "rooms:update_status"
+	PermRoomsBlock           = "rooms:block"
+	PermRoomTypesManage      = "room_types:manage"
+	PermAmenitiesManage      = "amenities:manage"
+	PermAddonsManage         = "addons:manage"
+	PermRatePlansManage      = "rate_plans:manage"
+	PermRestrictionsManage   = "restrictions:manage"
+	PermPromotionsManage     = "promotions:manage"
+	PermTaxRulesManage       = "tax_rules:manage"
+	PermAllotmentsManage     = "allotments:manage"
+	PermUsersRead            = "users:read"
+	PermUsersManage          = "users:manage" // view and edit users other than oneself
+	PermUsersDelete          = "users:delete"
+	PermRolesManage          = "roles:manage"
+	PermAuditRead            = "audit:read"
+)
+
+// Role is a named set of permissions assigned to users.
+type Role struct {
+	RoleID      int
+	Name        string
+	Description string
+	Permissions []string
+
+	RequireTwoFactor bool
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+const (
+	TaxCalcPercent = "percent"
+	TaxCalcFixed   = "fixed"
+
+	TaxBasisStay        = "stay"
+	TaxBasisNight       = "night"
+	TaxBasisPerson      = "person"
+	TaxBasisPersonNight = "person_night"
+
+	TaxAppliesToRoom  = "room"
+	TaxAppliesToAddon = "addon"
+	TaxAppliesToAll   = "all"
+)
+
+// TaxRule is a tax or fee. Percent rules charge Rate percent of the charges they
+// apply to; fixed rules charge Amount once per Basis unit.
+type TaxRule struct {
+	TaxRuleID  int
+	Name       string
+	CalcType   string
+	Rate       float64
+	Amount     Money
+	Basis      string
+	AppliesTo  string
+	IsCompound bool
+	Priority   int
+	IsActive   bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// BookingTax is one tax or fee line charged on a booking.
+type BookingTax struct {
+	BookingTaxID int
+	BookingID    int
+	TaxRuleID    int
+	Name         string
+	Amount       Money
+}
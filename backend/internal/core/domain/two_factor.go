@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// TOTPSecret is a user's authenticator enrolment. Secret is sealed with
+// utils.SealSecret; only the service opens it.
+type TOTPSecret struct {
+	UserID       int
+	Secret       string
+	CreatedAt    time.Time
+	ConfirmedAt  time.Time
+	LastUsedStep int64
+}
+
+// Confirmed reports whether the user proved the enrolment with a first code.
+func (t *TOTPSecret) Confirmed() bool {
+	return !t.ConfirmedAt.IsZero()
+}
+
+// TwoFactorSetup is shown once when enrolment starts.
+type TwoFactorSetup struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+type TwoFactorStatus struct {
+	Enabled           bool
+	Required          bool
+	RecoveryCodesLeft int
+}
+
+// LoginChallenge is the second step of a login for users with two-factor
+// enabled. Only its hash is stored.
+type LoginChallenge struct {
+	ChallengeID    int
+	UserID         int
+	TokenHash      string
+	FailedAttempts int
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+	UsedAt         time.Time
+}
+
+// LoginResult holds either the session tokens or, when a second factor is
+// needed, the challenge to answer with a code.
+type LoginResult struct {
+	Tokens *TokenPair
+	User   *User
+
+	ChallengeToken     string
+	ChallengeExpiresAt time.Time
+}
@@ -1,9 +1,35 @@
 package domain
 
+import (
+	"slices"
+	"time"
+)
+
 type User struct {
 	UserID int
 	Username string
 	Email string
 	PasswordHash string
-	IsAdmin bool
+	// Roles are the role names the user holds; Permissions is the union of
+	// the permissions those roles grant.
+	Roles []string
+	Permissions []string
+	// TokenVersion is embedded in access tokens; raising it revokes them all.
+	TokenVersion int
+	EmailVerifiedAt time.Time
+	// TwoFactorRequired is set when one of the user's roles demands two-factor.
+	TwoFactorEnabled bool
+	TwoFactorRequired bool
+}
+
+func (u *User) EmailVerified() bool {
+	return !u.EmailVerifiedAt.IsZero()
+}
+
+func (u *User) HasRole(role string) bool {
+	return slices.Contains(u.Roles, role)
+}
+
+func (u *User) HasPermission(permission string) bool {
+	return slices.Contains(u.Permissions, permission)
 }
\ No newline at end of file
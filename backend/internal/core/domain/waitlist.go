@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+const (
+	WaitlistStatusWaiting   = "waiting"
+	WaitlistStatusOffered   = "offered"   // a room is held for the guest until OfferExpiresAt
+	WaitlistStatusBooked    = "booked"    // the guest confirmed the held room
+	WaitlistStatusExpired   = "expired"   // the offer lapsed or the stay started
+	WaitlistStatusCancelled = "cancelled" // the guest left the waitlist
+)
+
+// WaitlistEntry is a guest waiting for a room type to free up for a stay.
+// Entries are offered rooms in the order they joined.
+type WaitlistEntry struct {
+	EntryID      int
+	UserID       int
+	RoomTypeID   int
+	RatePlanID   int
+	CheckInDate  time.Time
+	CheckOutDate time.Time
+	NumAdults    int
+	ChildAges    []int
+	Email        string
+	Status       string
+
+	// BookingID is the pending booking holding the offered room.
+	BookingID      int
+	OfferedAt      time.Time
+	OfferExpiresAt time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type AccountTokenRepository interface {
+	// CreateAccountToken stores token and voids the user's earlier unused
+	// tokens of the same purpose, so only the latest link works.
+	CreateAccountToken(ctx context.Context, token *domain.AccountToken) error
+	// ConsumeAccountToken marks the token used and returns it, or
+	// errs.ErrNotFound when it is unknown, used or expired.
+	ConsumeAccountToken(ctx context.Context, purpose, tokenHash string) (*domain.AccountToken, error)
+}
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/ingwrok/hotelBooking/internal/core/domain"
 )
@@ -22,4 +23,14 @@ type AddonRepository interface {
 	GetAllAddons(ctx context.Context) ([]*domain.Addon, error)
 	GetAddonByCategoryID(ctx context.Context, categoryID int) ([]*domain.Addon, error)
 
+	// time slots
+	CreateAddonSlot(ctx context.Context, slot *domain.AddonSlot) error
+	UpdateAddonSlot(ctx context.Context, slot *domain.AddonSlot) error
+	DeleteAddonSlot(ctx context.Context, addonID, slotID int) error
+	GetAddonSlots(ctx context.Context, addonID int) ([]*domain.AddonSlot, error)
+
+	// GetAddonUsage returns what active bookings hold of the addon per slot and
+	// day from from to to, inclusive.
+	GetAddonUsage(ctx context.Context, addonID int, from, to time.Time) ([]*domain.AddonUsage, error)
+
 }
\ No newline at end of file
@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type AllotmentRepository interface {
+	CreateAllotment(ctx context.Context, allotment *domain.GroupAllotment) error
+	UpdateAllotment(ctx context.Context, allotment *domain.GroupAllotment) error
+	DeleteAllotment(ctx context.Context, allotmentID int) error
+	GetAllotmentByID(ctx context.Context, allotmentID int) (*domain.GroupAllotment, error)
+	GetAllotmentByCode(ctx context.Context, groupCode string) (*domain.GroupAllotment, error)
+	GetAllAllotments(ctx context.Context) ([]*domain.GroupAllotment, error)
+	GetPickupReport(ctx context.Context, allotmentID int) (*domain.PickupReport, error)
+}
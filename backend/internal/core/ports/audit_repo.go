@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type AuditRepository interface {
+	RecordAudit(ctx context.Context, entry *domain.AuditEntry) error
+	// ListAudit returns the newest entries first.
+	ListAudit(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEntry, error)
+}
@@ -7,10 +7,30 @@ import (
 )
 
 type BookingRepository interface {
-	CreateBooking(ctx context.Context, booking *domain.Booking, addons []*domain.BookingAddon) error
+	// CreateBooking, CancelBooking and SyncBookingAddons post the given folio
+	// entries in the same transaction as the booking change.
+	CreateBooking(ctx context.Context, booking *domain.Booking, addons []*domain.BookingAddon, charges []*domain.FolioEntry) error
+	// CreateReservation holds every booking of the reservation or none of them;
+	// charges[i] belong to res.Bookings[i]. A room taken in the meantime returns
+	// errs.ErrConflict. errs.ErrSoldOut means a group allotment has no rooms
+	// left, or for a public booking that only rooms held by allotments are
+	// (CreateBooking likewise).
+	CreateReservation(ctx context.Context, res *domain.Reservation, charges [][]*domain.FolioEntry) error
+	GetReservation(ctx context.Context, reservationID int) (*domain.ReservationDetail, error)
 	GetBookingWithAddons(ctx context.Context, bookingID int) (*domain.BookingDetail, error)
-	UpdateBookingStatus(ctx context.Context, bookingID int, status string) error
-	SyncBookingAddons(ctx context.Context, bookingID int, addons []*domain.BookingAddon, newTotalPrice float64) error
+	GetBookingIDByConfirmationCode(ctx context.Context, code string) (int, error)
+	UpdateBookingStatus(ctx context.Context, change *domain.BookingStatusHistory) error
+	CancelBooking(ctx context.Context, change *domain.BookingStatusHistory, fee domain.Money, entries []*domain.FolioEntry) error
+	GetBookingStatusHistory(ctx context.Context, bookingID int) ([]*domain.BookingStatusHistory, error)
+	SyncBookingAddons(ctx context.Context, booking *domain.BookingDetail, entries []*domain.FolioEntry) error
+	// ModifyStay moves a booking to the quoted room and dates, replacing its nights,
+	// taxes and per-night addon counts. It returns errs.ErrNotFound when the
+	// booking changed since old was read and errs.ErrConflict when the room was
+	// taken in the meantime. A group booking must still fit its allotment and a
+	// public one must leave the allotments' rooms alone, else errs.ErrSoldOut.
+	// While a payment for the booking or its reservation is pending it returns
+	// errs.ErrPaymentPending.
+	ModifyStay(ctx context.Context, old *domain.BookingDetail, quote *domain.StayQuote, entries []*domain.FolioEntry) error
 	GetBookingAddonsByBookingID(ctx context.Context, bookingID int) ([]*domain.BookingAddon, error)
 	CancelExpiredBookings(ctx context.Context) (int64, error)
 	GetBookingsByUserID(ctx context.Context, userID int) ([]*domain.BookingDetail, error)
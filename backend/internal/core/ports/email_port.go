@@ -7,5 +7,12 @@ import (
 )
 
 type EmailRepository interface {
+	// Send delivers a message composed by the caller, for emails that are not
+	// about a booking (account verification, password reset...).
+	Send(ctx context.Context, msg *domain.EmailMessage) error
 	SendBookingConfirmation(ctx context.Context, booking *domain.BookingDetail, addons []*domain.BookingAddon) error
+	SendReservationConfirmation(ctx context.Context, res *domain.ReservationDetail) error
+	SendBookingCancellation(ctx context.Context, booking *domain.BookingDetail) error
+	// SendWaitlistOffer tells a waitlisted guest a room is held for them as booking.
+	SendWaitlistOffer(ctx context.Context, booking *domain.BookingDetail, entry *domain.WaitlistEntry) error
 }
@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type FolioRepository interface {
+	GetFolioEntries(ctx context.Context, bookingID int) ([]*domain.FolioEntry, error)
+	PostEntry(ctx context.Context, entry *domain.FolioEntry) error
+}
@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type IdempotencyRepository interface {
+	// ReserveIdempotencyKey stores key as processing unless the user already
+	// holds it unexpired and, while processing, within key.LockedUntil of the
+	// holder. Otherwise the stored key is returned and created is false.
+	ReserveIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) (stored *domain.IdempotencyKey, created bool, err error)
+	CompleteIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) error
+	ReleaseIdempotencyKey(ctx context.Context, userID int, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+// LoginAttemptStore keeps failed login counts. Postgres shares them between
+// instances; the in-memory store suits a single instance or development.
+type LoginAttemptStore interface {
+	// GetLoginAttempt returns errs.ErrNotFound when key has no failures.
+	GetLoginAttempt(ctx context.Context, key string) (*domain.LoginAttempt, error)
+	// RecordLoginFailure adds a failure to key. Failures older than window
+	// are forgotten and counting starts again at one.
+	RecordLoginFailure(ctx context.Context, key string, window time.Duration) (*domain.LoginAttempt, error)
+	LockLogin(ctx context.Context, key string, until time.Time) error
+	ResetLoginAttempts(ctx context.Context, key string) error
+	// DeleteStaleLoginAttempts drops unlocked keys whose last failure is
+	// before the given time.
+	DeleteStaleLoginAttempts(ctx context.Context, before time.Time) (int64, error)
+}
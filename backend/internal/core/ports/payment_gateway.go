@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+// PaymentGateway is a payment provider. Payment results arrive asynchronously
+// through webhooks, which must pass VerifyWebhook before they are trusted.
+type PaymentGateway interface {
+	Name() string
+	CreateIntent(ctx context.Context, req *domain.PaymentIntentRequest) (*domain.PaymentIntent, error)
+	Capture(ctx context.Context, providerRef string) error
+	Refund(ctx context.Context, providerRef string, amount domain.Money) error
+	VerifyWebhook(payload []byte, signature string) (*domain.PaymentEvent, error)
+}
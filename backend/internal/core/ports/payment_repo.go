@@ -0,0 +1,40 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type PaymentRepository interface {
+	// CreatePayment returns errs.ErrConflict while another payment for the booking,
+	// its reservation or, for a reservation payment, any of its bookings is pending.
+	CreatePayment(ctx context.Context, payment *domain.Payment) error
+	GetPaymentByProviderRef(ctx context.Context, provider, providerRef string) (*domain.Payment, error)
+	// GetPaymentsByBookingID includes payments taken for the booking's whole reservation.
+	GetPaymentsByBookingID(ctx context.Context, bookingID int) ([]*domain.Payment, error)
+	GetPaymentsByReservationID(ctx context.Context, reservationID int) ([]*domain.Payment, error)
+	UpdatePaymentStatus(ctx context.Context, paymentID int, oldStatus, newStatus, reason string) error
+	// ConfirmPayment marks a pending payment succeeded and posts it to the booking's
+	// folio in the same transaction, confirming the booking if it was still pending
+	// and is now paid what its rate plan needs to confirm it. It returns the
+	// booking's previous status so the caller can refund payments for bookings
+	// that no longer take them (cancelled, expired...), and whether it was confirmed.
+	ConfirmPayment(ctx context.Context, paymentID int, reason string) (string, bool, error)
+	// RecordPayment inserts an already succeeded payment and credits it like ConfirmPayment.
+	RecordPayment(ctx context.Context, payment *domain.Payment, reason string) (string, bool, error)
+	// ReserveRefund sets aside amount of a succeeded payment before the provider
+	// is asked to refund it and returns the reservation's id. Reserving more
+	// than is left of the payment, counting refunds still pending, returns
+	// errs.ErrConflict.
+	ReserveRefund(ctx context.Context, paymentID int, amount domain.Money) (int, error)
+	// RecordRefund completes a reserved refund and posts it to entry.BookingID,
+	// or when that is unset to the bookings a reservation payment was credited
+	// to. The payment is marked refunded once nothing is left of it. A refund
+	// that is no longer pending returns errs.ErrNotFound.
+	RecordRefund(ctx context.Context, refundID int, entry *domain.FolioEntry) error
+	// ReleaseRefund gives back a reserved refund the provider did not make.
+	ReleaseRefund(ctx context.Context, refundID int, reason string) error
+	// GetRefundedAmount includes refunds still pending.
+	GetRefundedAmount(ctx context.Context, paymentID int) (domain.Money, error)
+}
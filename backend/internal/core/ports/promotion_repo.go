@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type PromotionRepository interface {
+	CreatePromotion(ctx context.Context, promo *domain.Promotion) error
+	UpdatePromotion(ctx context.Context, promo *domain.Promotion) error
+	DeletePromotion(ctx context.Context, promotionID int) error
+	GetPromotionByID(ctx context.Context, promotionID int) (*domain.Promotion, error)
+	GetPromotionByCode(ctx context.Context, code string) (*domain.Promotion, error)
+	GetAllPromotions(ctx context.Context) ([]*domain.Promotion, error)
+	CountUserRedemptions(ctx context.Context, promotionID, userID int) (int, error)
+}
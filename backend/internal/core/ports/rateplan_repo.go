@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/ingwrok/hotelBooking/internal/core/domain"
 )
@@ -13,8 +14,18 @@ type RatePlanRepository interface {
 	GetRatePlanByID(ctx context.Context, ratePlanID int) (*domain.RatePlan, error)
 	GetAllRatePlans(ctx context.Context) ([]*domain.RatePlan, error)
 
-	SetRoomTypePrice(ctx context.Context, roomTypeID, ratePlanID int, price float64) error
-	GetPriceByRoomType(ctx context.Context, roomTypeID, ratePlanID int) (float64, error)
+	SetRoomTypePrice(ctx context.Context, roomTypeID, ratePlanID int, price domain.Money) error
+	GetPriceByRoomType(ctx context.Context, roomTypeID, ratePlanID int) (domain.Money, error)
 	DeleteRoomTypePrice(ctx context.Context, roomTypeID, ratePlanID int) error
 	GetAllRatePlansByRoomTypeID(ctx context.Context, roomTypeID int) ([]*domain.RatePlanFull, error)
+
+	// rate calendar
+	SetCalendarPrices(ctx context.Context, entries []*domain.RateCalendarEntry) error
+	DeleteCalendarPrices(ctx context.Context, roomTypeID, ratePlanID int, from, to time.Time) (int64, error)
+	GetNightlyRates(ctx context.Context, roomTypeID, ratePlanID int, checkIn, checkOut time.Time) ([]*domain.NightlyRate, error)
+
+	// occupancy pricing; GetOccupancyPricing returns ErrNotFound when the rate has none
+	SetOccupancyPricing(ctx context.Context, p *domain.OccupancyPricing) error
+	GetOccupancyPricing(ctx context.Context, roomTypeID, ratePlanID int) (*domain.OccupancyPricing, error)
+	DeleteOccupancyPricing(ctx context.Context, roomTypeID, ratePlanID int) error
 }
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	// RotateRefreshToken marks oldID used and stores next in one transaction.
+	// It returns errs.ErrConflict when oldID was used or revoked meanwhile.
+	RotateRefreshToken(ctx context.Context, oldID int, next *domain.RefreshToken) error
+	RevokeTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserTokens(ctx context.Context, userID int) error
+	DeleteExpiredRefreshTokens(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type RestrictionRepository interface {
+	CreateRestriction(ctx context.Context, r *domain.StayRestriction) error
+	UpdateRestriction(ctx context.Context, r *domain.StayRestriction) error
+	DeleteRestriction(ctx context.Context, restrictionID int) error
+	GetRestrictionByID(ctx context.Context, restrictionID int) (*domain.StayRestriction, error)
+	GetRestrictionsByRoomType(ctx context.Context, roomTypeID int) ([]*domain.StayRestriction, error)
+
+	// GetRestrictionsForStay returns the restrictions touching any date from checkIn
+	// to checkOut inclusive. roomTypeID 0 matches every room type; ratePlanID 0
+	// matches only the restrictions that apply to all rate plans.
+	GetRestrictionsForStay(ctx context.Context, roomTypeID, ratePlanID int, checkIn, checkOut time.Time) ([]*domain.StayRestriction, error)
+}
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type RoleRepository interface {
+	GetRoles(ctx context.Context) ([]*domain.Role, error)
+	// AssignRole returns errs.ErrNotFound for an unknown user or role and
+	// errs.ErrConflict when the user already holds the role.
+	AssignRole(ctx context.Context, userID int, role string) error
+	// RevokeRole returns errs.ErrNotFound when the user does not hold the role
+	// and errs.ErrConflict when it would leave no admin.
+	RevokeRole(ctx context.Context, userID int, role string) error
+	SetRoleTwoFactor(ctx context.Context, role string, required bool) error
+}
@@ -25,4 +25,8 @@ type RoomRepository interface {
     // Room Availability
     GetAvailableRoomCounts(ctx context.Context, checkIn, checkOut time.Time) (map[int]int, error)
     GetAnyAvailableRoomID(ctx context.Context, roomTypeID int, checkIn, checkOut time.Time) (int, error)
+    // GetAvailableRoomIDs returns up to limit free rooms of the type, fewer if that is all there is.
+    GetAvailableRoomIDs(ctx context.Context, roomTypeID int, checkIn, checkOut time.Time, limit int) ([]int, error)
+    // GetAvailableRoomIDForBooking ignores the booking's own nights and prefers its current room.
+    GetAvailableRoomIDForBooking(ctx context.Context, bookingID, roomTypeID int, checkIn, checkOut time.Time) (int, error)
 }
\ No newline at end of file
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type TaxRuleRepository interface {
+	CreateTaxRule(ctx context.Context, rule *domain.TaxRule) error
+	UpdateTaxRule(ctx context.Context, rule *domain.TaxRule) error
+	DeleteTaxRule(ctx context.Context, taxRuleID int) error
+	GetTaxRuleByID(ctx context.Context, taxRuleID int) (*domain.TaxRule, error)
+	GetAllTaxRules(ctx context.Context) ([]*domain.TaxRule, error)
+	GetActiveTaxRules(ctx context.Context) ([]*domain.TaxRule, error)
+}
@@ -0,0 +1,34 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type TwoFactorRepository interface {
+	GetTOTP(ctx context.Context, userID int) (*domain.TOTPSecret, error)
+	// SaveTOTP starts or restarts an enrolment. It returns errs.ErrConflict
+	// when the user already has a confirmed one.
+	SaveTOTP(ctx context.Context, userID int, sealedSecret string) error
+	// ConfirmTOTP enables the pending enrolment and stores its recovery
+	// codes, or returns errs.ErrConflict when there is none pending.
+	ConfirmTOTP(ctx context.Context, userID int, step int64, codeHashes []string) error
+	// UseTOTPStep records a code's time step, returning errs.ErrConflict if
+	// that step or a later one was already used.
+	UseTOTPStep(ctx context.Context, userID int, step int64) error
+	// DeleteTOTP removes the enrolment and the recovery codes.
+	DeleteTOTP(ctx context.Context, userID int) error
+
+	ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error
+	// UseRecoveryCode spends a code or returns errs.ErrNotFound.
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string) error
+	CountRecoveryCodes(ctx context.Context, userID int) (int, error)
+
+	CreateLoginChallenge(ctx context.Context, challenge *domain.LoginChallenge) error
+	GetLoginChallengeByHash(ctx context.Context, tokenHash string) (*domain.LoginChallenge, error)
+	FailLoginChallenge(ctx context.Context, challengeID int) error
+	// ConsumeLoginChallenge returns errs.ErrConflict if it was already used.
+	ConsumeLoginChallenge(ctx context.Context, challengeID int) error
+	DeleteExpiredLoginChallenges(ctx context.Context) (int64, error)
+}
@@ -10,7 +10,11 @@ type UserRepoPort interface {
 	Create(ctx context.Context, user *domain.User) error
 	GetByID(ctx context.Context, id int) (*domain.User, error)
 	GetByUsername(ctx context.Context, username string) (*domain.User, error)
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
 	Update(ctx context.Context, id int, fields map[string]interface{}) error
+	// BumpTokenVersion invalidates every access token issued to the user.
+	BumpTokenVersion(ctx context.Context, id int) error
+	MarkEmailVerified(ctx context.Context, id int, email string) error
 	Delete(ctx context.Context, id int) error
 	GetAll(ctx context.Context) ([]*domain.User, error)
 }
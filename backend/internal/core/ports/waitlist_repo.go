@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+type WaitlistRepository interface {
+	CreateWaitlistEntry(ctx context.Context, entry *domain.WaitlistEntry) error
+	GetWaitlistEntry(ctx context.Context, entryID int) (*domain.WaitlistEntry, error)
+	GetWaitlistByUserID(ctx context.Context, userID int) ([]*domain.WaitlistEntry, error)
+	// GetWaitlist lists entries for a room type, or every entry when roomTypeID is 0.
+	GetWaitlist(ctx context.Context, roomTypeID int) ([]*domain.WaitlistEntry, error)
+	// GetWaitingEntries returns the entries still waiting, oldest first.
+	GetWaitingEntries(ctx context.Context) ([]*domain.WaitlistEntry, error)
+	// UpdateWaitlistStatus moves an entry from one status to another, failing
+	// with ErrNotFound when it is no longer in from.
+	UpdateWaitlistStatus(ctx context.Context, entryID int, from, to string) error
+	// SettleWaitlist closes offers and entries that can no longer be taken up.
+	SettleWaitlist(ctx context.Context, today time.Time) (int64, error)
+}
@@ -3,12 +3,16 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/ingwrok/hotelBooking/internal/common/errs"
 	"github.com/ingwrok/hotelBooking/internal/common/logger"
 	"github.com/ingwrok/hotelBooking/internal/core/domain"
 	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/ingwrok/hotelBooking/internal/core/utils"
 	"go.uber.org/zap"
 )
 
@@ -131,14 +135,18 @@ func (s *AddonService) AddAddon(ctx context.Context, addon *domain.Addon) (*doma
 		zap.Int("CategoryID", addon.CategoryID),
 		zap.String("Name", addon.Name),
 		zap.String("Description", addon.Description),
-		zap.Float64("Price", addon.Price),
+		zap.Stringer("Price", addon.Price),
 		zap.String("UnitName", addon.UnitName),
 	)
 
-	if addon.CategoryID <= 0 || addon.Name == "" || addon.Description == "" || addon.Price <= 0 || addon.UnitName == "" {
+	if addon.CategoryID <= 0 || addon.Name == "" || addon.Description == "" || !addon.Price.IsPositive() || addon.UnitName == "" || addon.DailyCapacity < 0 {
 		logger.Warn("validation failed: missing or invalid input")
 		return nil, errs.NewValidationError("addon invalid input")
 	}
+	if err := normalizeChargeUnit(addon); err != nil {
+		logger.Warn("validation failed", zap.Error(err))
+		return nil, err
+	}
 
 	err := s.repo.CreateAddon(ctx, addon)
 	if err != nil {
@@ -178,14 +186,18 @@ func (s *AddonService) ChangeAddon(ctx context.Context, addon *domain.Addon) err
 		zap.Int("CategoryID", addon.CategoryID),
 		zap.String("Name", addon.Name),
 		zap.String("Description", addon.Description),
-		zap.Float64("Price", addon.Price),
+		zap.Stringer("Price", addon.Price),
 		zap.String("UnitName", addon.UnitName),
 	)
 
-	if addon.AddonID <= 0 || addon.CategoryID <= 0 || addon.Name == "" || addon.Description == "" || addon.Price <= 0 || addon.UnitName == "" {
+	if addon.AddonID <= 0 || addon.CategoryID <= 0 || addon.Name == "" || addon.Description == "" || !addon.Price.IsPositive() || addon.UnitName == "" || addon.DailyCapacity < 0 {
 		logger.Warn("validation failed: missing or invalid input")
 		return errs.NewValidationError("addon invalid input")
 	}
+	if err := normalizeChargeUnit(addon); err != nil {
+		logger.Warn("validation failed", zap.Error(err))
+		return err
+	}
 
 	err := s.repo.UpdateAddon(ctx, addon)
 	if err != nil {
@@ -254,6 +266,149 @@ func (s *AddonService) ListAddonsByCategory(ctx context.Context, categoryID int)
 	return addons, nil
 }
 
+// time slots
+func (s *AddonService) AddAddonSlot(ctx context.Context, slot *domain.AddonSlot) (*domain.AddonSlot, error) {
+	logger.Info("AddAddonSlot called",
+		zap.Int("AddonID", slot.AddonID),
+		zap.String("Name", slot.Name),
+		zap.Int("Capacity", slot.Capacity),
+	)
+
+	if err := validateAddonSlot(slot); err != nil {
+		logger.Warn("validation failed", zap.Error(err))
+		return nil, err
+	}
+
+	err := s.repo.CreateAddonSlot(ctx, slot)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("addon not found", zap.Int("AddonID", slot.AddonID))
+			return nil, errs.NewNotFoundError("addon not found")
+		}
+		if errors.Is(err, errs.ErrConflict) {
+			logger.Warn("duplicate slot name", zap.Int("AddonID", slot.AddonID), zap.String("Name", slot.Name))
+			return nil, errs.NewConflictError("addon already has a slot with this name")
+		}
+		logger.ErrorErr(err, "repo.CreateAddonSlot failed")
+		return nil, errs.NewUnexpectedError("failed to create addon slot")
+	}
+
+	logger.Info("addon slot created successfully", zap.Int("SlotID", slot.SlotID))
+	return slot, nil
+}
+
+func (s *AddonService) ChangeAddonSlot(ctx context.Context, slot *domain.AddonSlot) error {
+	logger.Info("ChangeAddonSlot called",
+		zap.Int("AddonID", slot.AddonID),
+		zap.Int("SlotID", slot.SlotID),
+		zap.String("Name", slot.Name),
+		zap.Int("Capacity", slot.Capacity),
+	)
+
+	if slot.SlotID <= 0 {
+		logger.Warn("validation failed: invalid slot ID")
+		return errs.NewValidationError("slot ID is required")
+	}
+	if err := validateAddonSlot(slot); err != nil {
+		logger.Warn("validation failed", zap.Error(err))
+		return err
+	}
+
+	err := s.repo.UpdateAddonSlot(ctx, slot)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("addon slot not found", zap.Int("SlotID", slot.SlotID))
+			return errs.NewNotFoundError("addon slot not found")
+		}
+		if errors.Is(err, errs.ErrConflict) {
+			logger.Warn("duplicate slot name", zap.Int("AddonID", slot.AddonID), zap.String("Name", slot.Name))
+			return errs.NewConflictError("addon already has a slot with this name")
+		}
+		logger.ErrorErr(err, "repo.UpdateAddonSlot failed")
+		return errs.NewUnexpectedError("failed to update addon slot")
+	}
+
+	logger.Info("addon slot updated successfully", zap.Int("SlotID", slot.SlotID))
+	return nil
+}
+
+func (s *AddonService) RemoveAddonSlot(ctx context.Context, addonID, slotID int) error {
+	logger.Info("RemoveAddonSlot called", zap.Int("AddonID", addonID), zap.Int("SlotID", slotID))
+
+	if addonID <= 0 || slotID <= 0 {
+		logger.Warn("validation failed: invalid addon or slot ID")
+		return errs.NewValidationError("addon ID and slot ID are required")
+	}
+
+	err := s.repo.DeleteAddonSlot(ctx, addonID, slotID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("addon slot not found", zap.Int("SlotID", slotID))
+			return errs.NewNotFoundError("addon slot not found")
+		}
+		if errors.Is(err, errs.ErrConflict) {
+			logger.Warn("addon slot is booked", zap.Int("SlotID", slotID))
+			return errs.NewConflictError("addon slot has bookings and cannot be deleted")
+		}
+		logger.ErrorErr(err, "repo.DeleteAddonSlot failed")
+		return errs.NewUnexpectedError("failed to delete addon slot")
+	}
+
+	logger.Info("addon slot deleted successfully", zap.Int("SlotID", slotID))
+	return nil
+}
+
+func validateAddonSlot(slot *domain.AddonSlot) error {
+	slot.Name = strings.TrimSpace(slot.Name)
+	if slot.AddonID <= 0 || slot.Name == "" {
+		return errs.NewValidationError("addon ID and slot name are required")
+	}
+	if slot.Capacity <= 0 {
+		return errs.NewValidationError("slot capacity must be at least 1")
+	}
+	return nil
+}
+
+// maxAvailabilityRangeDays caps how many days one availability lookup covers.
+const maxAvailabilityRangeDays = 92
+
+// GetAddonAvailability returns the capacity left of an addon on each day from
+// from to to, inclusive.
+func (s *AddonService) GetAddonAvailability(ctx context.Context, addonID int, from, to time.Time) ([]*domain.AddonAvailability, error) {
+	logger.Info("GetAddonAvailability called",
+		zap.Int("AddonID", addonID),
+		zap.Time("from", from),
+		zap.Time("to", to),
+	)
+
+	from, to = utils.DateOnly(from), utils.DateOnly(to)
+	if to.Before(from) {
+		return nil, errs.NewValidationError("end date must not be before start date")
+	}
+	if to.Sub(from) >= maxAvailabilityRangeDays*24*time.Hour {
+		return nil, errs.NewValidationError(fmt.Sprintf("availability can be looked up for at most %d days", maxAvailabilityRangeDays))
+	}
+
+	addon, err := s.GetAddon(ctx, addonID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.repo.GetAddonUsage(ctx, addonID, from, to)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetAddonUsage failed")
+		return nil, errs.NewUnexpectedError("failed to get addon availability")
+	}
+
+	var days []*domain.AddonAvailability
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, addon.Availability(day, usage))
+	}
+
+	logger.Debug("addon availability returned", zap.Int("AddonID", addonID), zap.Int("days", len(days)))
+	return days, nil
+}
+
 // Image Upload
 func (s *AddonService) UploadAddonImage(ctx context.Context, file io.Reader, filename string) (string, error) {
 	logger.Info("UploadAddonImage called", zap.String("filename", filename))
@@ -271,3 +426,17 @@ func (s *AddonService) UploadAddonImage(ctx context.Context, file io.Reader, fil
 
 	return url, nil
 }
+
+// normalizeChargeUnit defaults an addon to being charged per item, the way
+// addons were priced before charge units existed.
+func normalizeChargeUnit(addon *domain.Addon) error {
+	addon.ChargeUnit = strings.ToLower(strings.TrimSpace(addon.ChargeUnit))
+	switch addon.ChargeUnit {
+	case "":
+		addon.ChargeUnit = domain.AddonChargePerItem
+	case domain.AddonChargePerStay, domain.AddonChargePerNight, domain.AddonChargePerPersonNight, domain.AddonChargePerItem:
+	default:
+		return errs.NewValidationError("charge unit must be per_stay, per_night, per_person_per_night or per_item")
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/common/logger"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"go.uber.org/zap"
+)
+
+type AllotmentService struct {
+	repo ports.AllotmentRepository
+}
+
+func NewAllotmentService(repo ports.AllotmentRepository) *AllotmentService {
+	return &AllotmentService{repo: repo}
+}
+
+func (s *AllotmentService) AddAllotment(ctx context.Context, allotment *domain.GroupAllotment) (*domain.GroupAllotment, error) {
+	logger.Info("AddAllotment called", zap.String("GroupCode", allotment.GroupCode))
+
+	allotment.GroupCode = normalizeGroupCode(allotment.GroupCode)
+	if err := validateAllotment(allotment); err != nil {
+		logger.Warn("validation failed", zap.Error(err))
+		return nil, err
+	}
+
+	err := s.repo.CreateAllotment(ctx, allotment)
+	if err != nil {
+		return nil, allotmentWriteError(err, allotment, "repo.CreateAllotment failed", "failed to create allotment")
+	}
+
+	logger.Info("allotment created successfully", zap.Int("AllotmentID", allotment.AllotmentID))
+	return allotment, nil
+}
+
+func (s *AllotmentService) ChangeAllotment(ctx context.Context, allotment *domain.GroupAllotment) error {
+	logger.Info("ChangeAllotment called", zap.Int("AllotmentID", allotment.AllotmentID))
+
+	if allotment.AllotmentID <= 0 {
+		return errs.NewValidationError("invalid allotment ID")
+	}
+	allotment.GroupCode = normalizeGroupCode(allotment.GroupCode)
+	if err := validateAllotment(allotment); err != nil {
+		logger.Warn("validation failed", zap.Error(err))
+		return err
+	}
+
+	err := s.repo.UpdateAllotment(ctx, allotment)
+	if err != nil {
+		return allotmentWriteError(err, allotment, "repo.UpdateAllotment failed", "failed to update allotment")
+	}
+
+	logger.Info("allotment updated successfully", zap.Int("AllotmentID", allotment.AllotmentID))
+	return nil
+}
+
+func allotmentWriteError(err error, allotment *domain.GroupAllotment, logMsg, msg string) error {
+	if errors.Is(err, errs.ErrConflict) {
+		logger.Warn("duplicate group code", zap.String("GroupCode", allotment.GroupCode))
+		return errs.NewConflictError("group code already exists")
+	}
+	if errors.Is(err, errs.ErrNotFound) {
+		logger.Warn("allotment or room type not found", zap.Int("AllotmentID", allotment.AllotmentID), zap.Int("RoomTypeID", allotment.RoomTypeID))
+		return errs.NewNotFoundError("allotment or room type not found")
+	}
+	logger.ErrorErr(err, logMsg)
+	return errs.NewUnexpectedError(msg)
+}
+
+func (s *AllotmentService) RemoveAllotment(ctx context.Context, allotmentID int) error {
+	logger.Info("RemoveAllotment called", zap.Int("AllotmentID", allotmentID))
+
+	err := s.repo.DeleteAllotment(ctx, allotmentID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("allotment not found", zap.Int("AllotmentID", allotmentID))
+			return errs.NewNotFoundError("allotment not found")
+		}
+		logger.ErrorErr(err, "repo.DeleteAllotment failed")
+		return errs.NewUnexpectedError("failed to delete allotment")
+	}
+
+	logger.Info("allotment deleted successfully", zap.Int("AllotmentID", allotmentID))
+	return nil
+}
+
+func (s *AllotmentService) GetAllotment(ctx context.Context, allotmentID int) (*domain.GroupAllotment, error) {
+	logger.Info("GetAllotment called", zap.Int("AllotmentID", allotmentID))
+
+	if allotmentID <= 0 {
+		return nil, errs.NewValidationError("invalid allotment ID")
+	}
+
+	allotment, err := s.repo.GetAllotmentByID(ctx, allotmentID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("allotment not found", zap.Int("AllotmentID", allotmentID))
+			return nil, errs.NewNotFoundError("allotment not found")
+		}
+		logger.ErrorErr(err, "repo.GetAllotmentByID failed")
+		return nil, errs.NewUnexpectedError("failed to get allotment")
+	}
+
+	return allotment, nil
+}
+
+func (s *AllotmentService) ListAllotments(ctx context.Context) ([]*domain.GroupAllotment, error) {
+	logger.Info("ListAllotments called")
+
+	allotments, err := s.repo.GetAllAllotments(ctx)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetAllAllotments failed")
+		return nil, errs.NewUnexpectedError("failed to retrieve allotments")
+	}
+
+	logger.Debug("allotment list returned", zap.Int("count", len(allotments)))
+	return allotments, nil
+}
+
+// GetPickupReport shows night by night how many of the allotted rooms the
+// group has booked.
+func (s *AllotmentService) GetPickupReport(ctx context.Context, allotmentID int) (*domain.PickupReport, error) {
+	logger.Info("GetPickupReport called", zap.Int("AllotmentID", allotmentID))
+
+	if allotmentID <= 0 {
+		return nil, errs.NewValidationError("invalid allotment ID")
+	}
+
+	report, err := s.repo.GetPickupReport(ctx, allotmentID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("allotment not found", zap.Int("AllotmentID", allotmentID))
+			return nil, errs.NewNotFoundError("allotment not found")
+		}
+		logger.ErrorErr(err, "repo.GetPickupReport failed")
+		return nil, errs.NewUnexpectedError("failed to get pickup report")
+	}
+
+	return report, nil
+}
+
+func normalizeGroupCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func validateAllotment(allotment *domain.GroupAllotment) error {
+	if allotment.GroupCode == "" {
+		return errs.NewValidationError("group code is required")
+	}
+	if strings.TrimSpace(allotment.Name) == "" {
+		return errs.NewValidationError("group name is required")
+	}
+	if allotment.RoomTypeID <= 0 {
+		return errs.NewValidationError("room type ID is required")
+	}
+	if allotment.RoomsAllotted <= 0 {
+		return errs.NewValidationError("rooms allotted must be greater than 0")
+	}
+	if allotment.StartDate.IsZero() || allotment.EndDate.IsZero() || !allotment.EndDate.After(allotment.StartDate) {
+		return errs.NewValidationError("end date must be after start date")
+	}
+	if allotment.CutoffDate.IsZero() || allotment.CutoffDate.After(allotment.StartDate) {
+		return errs.NewValidationError("cutoff date must be on or before the start date")
+	}
+	return nil
+}
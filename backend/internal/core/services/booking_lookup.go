@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/common/logger"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/utils"
+	"go.uber.org/zap"
+)
+
+// LookupBooking finds a booking by its confirmation code for a guest who is
+// not logged in. The email must be the one the booking was made under; a
+// wrong code and a wrong email get the same answer so neither can be probed.
+func (s *BookingService) LookupBooking(ctx context.Context, code, email string) (*domain.BookingDetail, error) {
+	code = utils.NormalizeConfirmationCode(code)
+	email = strings.TrimSpace(email)
+	logger.Info("LookupBooking called", zap.String("Code", code))
+
+	if !utils.ValidConfirmationCode(code) {
+		return nil, errs.NewValidationError("invalid confirmation code")
+	}
+	if email == "" {
+		return nil, errs.NewValidationError("email is required")
+	}
+
+	bookingID, err := s.bookingRepo.GetBookingIDByConfirmationCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.NewNotFoundError("booking not found")
+		}
+		logger.ErrorErr(err, "repo.GetBookingIDByConfirmationCode failed")
+		return nil, errs.NewUnexpectedError("failed to get booking")
+	}
+
+	booking, err := s.GetFullDetails(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(booking.Email, email) {
+		logger.Warn("booking lookup with wrong email", zap.Int("BookingID", bookingID))
+		return nil, errs.NewNotFoundError("booking not found")
+	}
+
+	return booking, nil
+}
+
+// CancelBookingByCode lets a guest without an account cancel under the same
+// rules and fees as a logged-in cancellation.
+func (s *BookingService) CancelBookingByCode(ctx context.Context, code, email, reason string) (*domain.BookingDetail, error) {
+	booking, err := s.LookupBooking(ctx, code, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if reason == "" {
+		reason = "cancelled by guest with confirmation code"
+	}
+	return s.CancelBooking(ctx, booking.BookingID, booking.UserID, reason)
+}
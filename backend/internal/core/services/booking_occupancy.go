@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/common/logger"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"go.uber.org/zap"
+)
+
+// checkOccupancy checks the party against the room type's capacity and returns
+// the occupancy pricing of the rate, or nil when the rate has none. Every guest
+// beyond the base occupancy needs an extra bed from the booking's addons.
+func (s *BookingService) checkOccupancy(ctx context.Context, roomTypeID, ratePlanID, adults int, childAges []int, addons []*domain.BookingAddon) (*domain.OccupancyPricing, error) {
+	if adults < 1 {
+		return nil, errs.NewValidationError("at least one adult is required")
+	}
+	for _, age := range childAges {
+		if age < 0 || age > maxChildAge {
+			return nil, errs.NewValidationError(fmt.Sprintf("child ages must be between 0 and %d", maxChildAge))
+		}
+	}
+
+	rt, err := s.roomTypeRepo.GetRoomTypeByID(ctx, roomTypeID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.NewNotFoundError("room type not found")
+		}
+		logger.ErrorErr(err, "repo.GetRoomTypeByID failed")
+		return nil, errs.NewUnexpectedError("failed to get room type")
+	}
+
+	guests := adults + len(childAges)
+	if guests > rt.Capacity {
+		logger.Warn("party exceeds room capacity", zap.Int("RoomTypeID", roomTypeID), zap.Int("guests", guests), zap.Int("capacity", rt.Capacity))
+		return nil, errs.NewValidationError(fmt.Sprintf("%s sleeps at most %d guests", rt.Name, rt.Capacity))
+	}
+
+	pricing, err := s.rateplanRepo.GetOccupancyPricing(ctx, roomTypeID, ratePlanID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, nil
+		}
+		logger.ErrorErr(err, "repo.GetOccupancyPricing failed")
+		return nil, errs.NewUnexpectedError("failed to get occupancy pricing")
+	}
+
+	extra := pricing.ExtraGuests(adults, childAges)
+	if extra == 0 {
+		return pricing, nil
+	}
+
+	beds, err := s.extraBeds(ctx, addons)
+	if err != nil {
+		return nil, err
+	}
+	if beds < extra {
+		logger.Warn("extra beds missing", zap.Int("RoomTypeID", roomTypeID), zap.Int("needed", extra), zap.Int("booked", beds))
+		return nil, errs.NewValidationError(fmt.Sprintf("the room's rate covers %d guests; add %d extra bed(s) for the others", pricing.BaseOccupancy, extra-beds))
+	}
+
+	return pricing, nil
+}
+
+// extraBeds counts the extra beds among the addons.
+func (s *BookingService) extraBeds(ctx context.Context, addons []*domain.BookingAddon) (int, error) {
+	beds := 0
+	for _, ba := range addons {
+		addon, err := s.addonRepo.GetAddonByID(ctx, ba.AddonID)
+		if err != nil {
+			if errors.Is(err, errs.ErrNotFound) {
+				logger.Warn("addon not found", zap.Int("AddonID", ba.AddonID))
+				return 0, errs.NewNotFoundError("addon not found")
+			}
+			logger.ErrorErr(err, "repo.GetAddonByID failed")
+			return 0, errs.NewUnexpectedError("failed to get addon")
+		}
+		if addon.IsExtraBed {
+			beds += ba.Quantity
+		}
+	}
+	return beds, nil
+}
+
+// chargeExtraGuests adds the occupancy fee for the party to every night.
+func chargeExtraGuests(nights []*domain.NightlyRate, pricing *domain.OccupancyPricing, adults int, childAges []int) error {
+	if pricing == nil {
+		return nil
+	}
+
+	charge, err := pricing.NightlyCharge(adults, childAges)
+	if err != nil {
+		return moneyError(err)
+	}
+	for _, night := range nights {
+		night.ExtraGuestCharge = charge
+		if night.Price, err = night.Price.Add(charge); err != nil {
+			return moneyError(err)
+		}
+	}
+	return nil
+}
@@ -11,23 +11,40 @@ import (
 	"github.com/ingwrok/hotelBooking/internal/common/logger"
 	"github.com/ingwrok/hotelBooking/internal/core/domain"
 	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/ingwrok/hotelBooking/internal/core/utils"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
+// maxReservationRooms caps how many rooms one reservation can hold.
+const maxReservationRooms = 10
+
 type BookingService struct {
 	bookingRepo  ports.BookingRepository
 	roomRepo     ports.RoomRepository
+	roomTypeRepo ports.RoomTypeRepository
 	rateplanRepo ports.RatePlanRepository
 	addonRepo    ports.AddonRepository
+	restrictRepo ports.RestrictionRepository
+	promoRepo    ports.PromotionRepository
+	taxRepo      ports.TaxRuleRepository
+	groupRepo    ports.AllotmentRepository
+	waitlistRepo ports.WaitlistRepository
 	emailRepo    ports.EmailRepository
 }
 
-func NewBookingService(b ports.BookingRepository, r ports.RoomRepository, rp ports.RatePlanRepository, a ports.AddonRepository, e ports.EmailRepository) *BookingService {
+func NewBookingService(b ports.BookingRepository, r ports.RoomRepository, rt ports.RoomTypeRepository, rp ports.RatePlanRepository, a ports.AddonRepository, rs ports.RestrictionRepository, pr ports.PromotionRepository, t ports.TaxRuleRepository, g ports.AllotmentRepository, w ports.WaitlistRepository, e ports.EmailRepository) *BookingService {
 	return &BookingService{
 		bookingRepo:  b,
 		roomRepo:     r,
+		roomTypeRepo: rt,
 		rateplanRepo: rp,
 		addonRepo:    a,
+		restrictRepo: rs,
+		promoRepo:    pr,
+		taxRepo:      t,
+		groupRepo:    g,
+		waitlistRepo: w,
 		emailRepo:    e,
 	}
 }
@@ -39,79 +56,543 @@ func (s *BookingService) AddBooking(ctx context.Context, booking *domain.Booking
 		zap.Int("RoomTypeID", roomTypeID),
 	)
 
-	price, err := s.rateplanRepo.GetPriceByRoomType(ctx, roomTypeID, booking.RatePlanID)
-	if err != nil {
-		if errors.Is(err, errs.ErrNotFound) {
-			return nil, fmt.Errorf("rate plan id %d: %w", booking.RatePlanID, errs.ErrNotFound)
+	if err := s.priceBooking(ctx, booking, roomTypeID, true); err != nil {
+		return nil, err
+	}
+
+	booking.RoomTypeID = roomTypeID
+	if strings.TrimSpace(booking.GroupCode) != "" {
+		if err := s.applyGroupCode(ctx, booking); err != nil {
+			return nil, err
 		}
-		logger.ErrorErr(err, "GetPriceByRoomType failed")
+	} else if err := s.checkPublicRooms(ctx, map[int]int{roomTypeID: 1}, booking.CheckInDate, booking.CheckOutDate); err != nil {
 		return nil, err
 	}
 
-	roomID, err := s.roomRepo.GetAnyAvailableRoomID(ctx, roomTypeID, booking.CheckInDate, booking.CheckOutDate)
+	err := s.createWithAvailableRoom(ctx, booking, roomTypeID)
 	if err != nil {
-		if errors.Is(err, errs.ErrNotFound) {
-			logger.Warn("No rooms found for type", zap.Int("roomTypeID", roomTypeID), zap.Error(err))
-			return nil, errs.NewNotFoundError("no available room found for the specified type and dates")
-		}
-		logger.ErrorErr(err, "GetAnyAvailableRoomID failed")
-		return nil, errs.NewUnexpectedError("failed to find available room")
+		return nil, err
 	}
-	booking.RoomID = roomID
 
+	// Send Confirmation Email
+	go func() {
+		// Fetch full details (populated with joins) for the email
+		// Note: At this point, the transaction is committed, so we can read from DB.
+		details, dbErr := s.bookingRepo.GetBookingWithAddons(context.Background(), booking.BookingID)
+		if dbErr != nil {
+			logger.ErrorErr(dbErr, "failed to fetch booking for email")
+			return
+		}
+
+		emailCtx := context.Background()
+		if emailErr := s.emailRepo.SendBookingConfirmation(emailCtx, details, details.BookingAddon); emailErr != nil {
+			logger.ErrorErr(emailErr, "failed to send confirmation email")
+		}
+	}()
+
+	logger.Info("booking created successfully", zap.Int("BookingID", booking.BookingID))
+	return booking, err
+}
+
+// priceBooking prices a stay from its nightly rates, addons, promo code and
+// taxes, and sets the status the booking starts in. When promoRequired is false
+// a promo code that does not apply to the stay is dropped instead of failing.
+func (s *BookingService) priceBooking(ctx context.Context, booking *domain.Booking, roomTypeID int, promoRequired bool) error {
 	numNights := int(booking.CheckOutDate.Sub(booking.CheckInDate).Hours() / 24)
 	if numNights <= 0 {
 		logger.Warn("numNight must more 1")
-		return nil, fmt.Errorf("invalid stay duration")
+		return fmt.Errorf("invalid stay duration")
+	}
+
+	occupancy, err := s.checkOccupancy(ctx, roomTypeID, booking.RatePlanID, booking.NumAdults, booking.ChildAges, booking.BookingAddon)
+	if err != nil {
+		return err
+	}
+
+	restrictions, err := s.restrictRepo.GetRestrictionsForStay(ctx, roomTypeID, booking.RatePlanID, booking.CheckInDate, booking.CheckOutDate)
+	if err != nil {
+		logger.ErrorErr(err, "GetRestrictionsForStay failed")
+		return errs.NewUnexpectedError("failed to check stay restrictions")
+	}
+	if err := checkStayRestrictions(restrictions, booking.CheckInDate, booking.CheckOutDate); err != nil {
+		logger.Warn("stay restriction violated", zap.Int("RoomTypeID", roomTypeID), zap.Error(err))
+		return err
+	}
+
+	nights, err := s.rateplanRepo.GetNightlyRates(ctx, roomTypeID, booking.RatePlanID, booking.CheckInDate, booking.CheckOutDate)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return fmt.Errorf("rate plan id %d: %w", booking.RatePlanID, errs.ErrNotFound)
+		}
+		logger.ErrorErr(err, "GetNightlyRates failed")
+		return err
+	}
+
+	if err := chargeExtraGuests(nights, occupancy, booking.NumAdults, booking.ChildAges); err != nil {
+		return err
+	}
+	booking.Nights = nights
+	if booking.RoomSubTotal, err = sumNights(nights); err != nil {
+		return moneyError(err)
+	}
+
+	booking.AddonSubTotal, err = s.priceAddons(ctx, booking.BookingAddon, booking.CheckInDate, booking.CheckOutDate, booking.NumAdults+len(booking.ChildAges))
+	if err != nil {
+		return err
+	}
+
+	booking.PromotionID = 0
+	booking.DiscountAmount = domain.THB(0)
+	if strings.TrimSpace(booking.PromoCode) != "" {
+		if err := s.applyPromotion(ctx, booking, roomTypeID); err != nil {
+			if promoRequired || !errors.Is(err, errs.ErrValidation) {
+				return err
+			}
+			booking.PromoCode = ""
+		}
 	}
-	booking.RoomSubTotal = price * float64(numNights)
 
-	var addonTotal float64
-	for i := range booking.BookingAddon {
-		addon, err := s.addonRepo.GetAddonByID(ctx, booking.BookingAddon[i].AddonID)
+	roomCharge, err := booking.RoomSubTotal.Sub(booking.DiscountAmount)
+	if err != nil {
+		return moneyError(err)
+	}
+	booking.Taxes, booking.TaxesAmount, err = s.calculateTaxes(ctx, taxableCharges{
+		Room:   roomCharge,
+		Addon:  booking.AddonSubTotal,
+		Nights: numNights,
+		Guests: booking.NumAdults + len(booking.ChildAges),
+	})
+	if err != nil {
+		return err
+	}
+	if booking.TotalPrice, err = domain.Sum(roomCharge, booking.AddonSubTotal, booking.TaxesAmount); err != nil {
+		return moneyError(err)
+	}
+
+	rp, err := s.rateplanRepo.GetRatePlanByID(ctx, booking.RatePlanID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return fmt.Errorf("rate plan id %d: %w", booking.RatePlanID, errs.ErrNotFound)
+		}
+		logger.ErrorErr(err, "repo.GetRatePlanByID failed")
+		return errs.NewUnexpectedError("failed to get rate plan")
+	}
+
+	// Pay-later plans without a deposit or card guarantee are settled at the
+	// hotel, so there is nothing to wait for before confirming.
+	if needsPaymentToConfirm(rp) {
+		booking.Status = domain.BookingStatusPending
+		booking.ExpiredAt = time.Now().Add(30 * time.Minute)
+	} else {
+		booking.Status = domain.BookingStatusConfirmed
+	}
+
+	return nil
+}
+
+// priceAddons prices a stay's addon lines at the catalogue price, multiplied
+// out by their charge unit, and returns their total.
+func (s *BookingService) priceAddons(ctx context.Context, addons []*domain.BookingAddon, checkIn, checkOut time.Time, guests int) (domain.Money, error) {
+	total := domain.THB(0)
+	for _, line := range addons {
+		if line.Quantity <= 0 {
+			return total, errs.NewValidationError("addon quantity must be at least 1")
+		}
+
+		addon, err := s.addonRepo.GetAddonByID(ctx, line.AddonID)
 		if err != nil {
 			if errors.Is(err, errs.ErrNotFound) {
-				logger.Warn("addon not found", zap.Int("AddonID", addon.AddonID))
-				return nil, errs.NewNotFoundError("addon not found")
+				logger.Warn("addon not found", zap.Int("AddonID", line.AddonID))
+				return total, errs.NewNotFoundError("addon not found")
 			}
 			logger.ErrorErr(err, "repo.GetAddonByID failed")
-			return nil, errs.NewUnexpectedError("failed to get addon")
+			return total, errs.NewUnexpectedError("failed to get addon")
+		}
+
+		// A time slot only means something on a given day.
+		line.SlotName = ""
+		if line.SlotID > 0 || len(addon.Slots) > 0 {
+			slot := addon.Slot(line.SlotID)
+			if slot == nil {
+				if line.SlotID == 0 {
+					return total, errs.NewValidationError(fmt.Sprintf("%s needs a time slot", addon.Name))
+				}
+				return total, errs.NewValidationError(fmt.Sprintf("%s has no time slot %d", addon.Name, line.SlotID))
+			}
+			line.SlotName = slot.Name
+		}
+
+		if !addon.RequiresServiceDate && line.SlotID == 0 {
+			line.ServiceDate = time.Time{}
+		} else if line.ServiceDate.IsZero() {
+			return total, errs.NewValidationError(fmt.Sprintf("%s needs a service date", addon.Name))
+		}
+
+		line.AddonName = addon.Name
+		line.PriceAtBooking = addon.Price
+		line.ChargeUnit = addon.ChargeUnit
+		if err := sizeAddon(line, checkIn, checkOut, guests); err != nil {
+			return total, err
+		}
+		if total, err = total.Add(line.Total()); err != nil {
+			return total, moneyError(err)
+		}
+	}
+	return total, nil
+}
+
+// sizeAddon sets the nights and guests an addon line is charged for and checks
+// that its service date falls within the stay.
+func sizeAddon(line *domain.BookingAddon, checkIn, checkOut time.Time, guests int) error {
+	nights := int(checkOut.Sub(checkIn).Hours() / 24)
+
+	line.Nights, line.Guests = 0, 0
+	switch line.ChargeUnit {
+	case domain.AddonChargePerStay:
+		line.Quantity = 1
+	case domain.AddonChargePerNight:
+		line.Nights = nights
+	case domain.AddonChargePerPersonNight:
+		line.Quantity = 1
+		line.Nights = nights
+		line.Guests = guests
+	}
+
+	if !line.ServiceDate.IsZero() {
+		day := utils.DateOnly(line.ServiceDate)
+		if day.Before(utils.DateOnly(checkIn)) || day.After(utils.DateOnly(checkOut)) {
+			return errs.NewValidationError(fmt.Sprintf("%s must be scheduled between check-in and check-out", line.AddonName))
+		}
+	}
+	return nil
+}
+
+// applyGroupCode checks that the stay fits the allotment behind
+// booking.GroupCode and books it against that allotment. Whether a room is
+// still left in it is settled when the booking is inserted.
+func (s *BookingService) applyGroupCode(ctx context.Context, booking *domain.Booking) error {
+	code := normalizeGroupCode(booking.GroupCode)
+
+	allotment, err := s.groupRepo.GetAllotmentByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("group code not found", zap.String("GroupCode", code))
+			return errs.NewValidationError("invalid group code")
 		}
-		booking.BookingAddon[i].PriceAtBooking = addon.Price
-		addonTotal += (addon.Price * float64(booking.BookingAddon[i].Quantity))
+		logger.ErrorErr(err, "repo.GetAllotmentByCode failed")
+		return errs.NewUnexpectedError("failed to check group code")
 	}
-	booking.AddonSubTotal = addonTotal
 
-	booking.TaxesAmount = (booking.RoomSubTotal + booking.AddonSubTotal) * 0.07
-	booking.TotalPrice = booking.RoomSubTotal + booking.AddonSubTotal + booking.TaxesAmount
+	if allotment.Released(time.Now()) {
+		return errs.NewValidationError("booking window for this group has closed")
+	}
+	if allotment.RoomTypeID != booking.RoomTypeID {
+		return errs.NewValidationError("group code is not valid for this room type")
+	}
+	if utils.DateOnly(booking.CheckInDate).Before(utils.DateOnly(allotment.StartDate)) ||
+		utils.DateOnly(booking.CheckOutDate).After(utils.DateOnly(allotment.EndDate)) {
+		return errs.NewValidationError("stay is outside the group's dates")
+	}
+
+	booking.GroupCode = allotment.GroupCode
+	booking.AllotmentID = allotment.AllotmentID
+	return nil
+}
+
+// checkPublicRooms makes sure enough rooms of each type are on public sale for
+// the stay once group allotments have taken the rooms they hold. It only gives
+// an early answer; the booking transaction checks again under lock.
+func (s *BookingService) checkPublicRooms(ctx context.Context, needed map[int]int, checkIn, checkOut time.Time) error {
+	counts, err := s.roomRepo.GetAvailableRoomCounts(ctx, checkIn, checkOut)
+	if err != nil {
+		logger.ErrorErr(err, "GetAvailableRoomCounts failed")
+		return errs.NewUnexpectedError("failed to find available room")
+	}
+
+	for roomTypeID, rooms := range needed {
+		if counts[roomTypeID] < rooms {
+			logger.Warn("not enough public rooms for type", zap.Int("roomTypeID", roomTypeID),
+				zap.Int("wanted", rooms), zap.Int("free", counts[roomTypeID]))
+			return errs.NewNotFoundError("no available room found for the specified type and dates")
+		}
+	}
+	return nil
+}
+
+// applyPromotion looks up booking.PromoCode and records its discount on the booking.
+func (s *BookingService) applyPromotion(ctx context.Context, booking *domain.Booking, roomTypeID int) error {
+	code := normalizePromoCode(booking.PromoCode)
 
-	booking.Status = "pending"
-	booking.ExpiredAt = time.Now().Add(30 * time.Minute)
+	promo, err := s.promoRepo.GetPromotionByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("promo code not found", zap.String("Code", code))
+			return errs.NewValidationError("invalid promo code")
+		}
+		logger.ErrorErr(err, "repo.GetPromotionByCode failed")
+		return errs.NewUnexpectedError("failed to check promo code")
+	}
 
-	err = s.bookingRepo.CreateBooking(ctx, booking, booking.BookingAddon)
+	discount, err := promotionDiscount(promo, booking, roomTypeID, time.Now())
 	if err != nil {
-		logger.ErrorErr(err, "repo.CreateBooking failed")
+		logger.Warn("promo code rejected", zap.String("Code", code), zap.Error(err))
+		return err
+	}
+
+	if promo.MaxPerUser > 0 {
+		used, err := s.promoRepo.CountUserRedemptions(ctx, promo.PromotionID, booking.UserID)
+		if err != nil {
+			logger.ErrorErr(err, "repo.CountUserRedemptions failed")
+			return errs.NewUnexpectedError("failed to check promo code")
+		}
+		if used >= promo.MaxPerUser {
+			logger.Warn("promo code per-user limit reached", zap.String("Code", code), zap.Int("UserID", booking.UserID))
+			return errs.NewValidationError("you have already used this promo code the maximum number of times")
+		}
+	}
+
+	booking.PromotionID = promo.PromotionID
+	booking.PromoCode = promo.Code
+	booking.DiscountAmount = discount
+	return nil
+}
+
+// createWithAvailableRoom assigns a free room of the requested type and inserts the booking.
+// The database rejects overlapping bookings on one room, so when a concurrent request takes
+// the room first the insert is retried with the next free room of the same type
+// until none is left.
+func (s *BookingService) createWithAvailableRoom(ctx context.Context, booking *domain.Booking, roomTypeID int) error {
+	// A conflict means a concurrent booking took the room for good, so the
+	// free-room search runs dry before this can loop forever.
+	for attempt := 1; ; attempt++ {
+		roomID, err := s.roomRepo.GetAnyAvailableRoomID(ctx, roomTypeID, booking.CheckInDate, booking.CheckOutDate)
+		if err != nil {
+			if errors.Is(err, errs.ErrNotFound) {
+				logger.Warn("No rooms found for type", zap.Int("roomTypeID", roomTypeID), zap.Error(err))
+				return errs.NewNotFoundError("no available room found for the specified type and dates")
+			}
+			logger.ErrorErr(err, "GetAnyAvailableRoomID failed")
+			return errs.NewUnexpectedError("failed to find available room")
+		}
+		booking.RoomID = roomID
+
+		err = s.bookingRepo.CreateBooking(ctx, booking, booking.BookingAddon, bookingCharges(booking))
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, errs.ErrSoldOut) {
+			return soldOutError(err, booking.AllotmentID > 0)
+		}
+		if errors.Is(err, errs.ErrFullyBooked) {
+			return addonCapacityError(err)
+		}
+		if promoErr := redemptionError(err, booking.PromotionID); promoErr != nil {
+			return promoErr
+		}
+		if !errors.Is(err, errs.ErrConflict) {
+			logger.ErrorErr(err, "repo.CreateBooking failed")
+			return err
+		}
+
+		logger.Warn("room taken by a concurrent booking, retrying",
+			zap.Int("roomID", roomID),
+			zap.Int("attempt", attempt),
+		)
+	}
+}
+
+// redemptionError maps a failure to redeem the booking's promo code at insert
+// time to the error shown to the guest, or returns nil for any other failure.
+func redemptionError(err error, promotionID int) error {
+	if promotionID <= 0 {
+		return nil
+	}
+	if errors.Is(err, errs.ErrLimitReached) {
+		logger.Warn("promo code redemption cap reached", zap.Int("PromotionID", promotionID), zap.Error(err))
+		return errs.NewConflictError("promo code has reached its redemption limit")
+	}
+	if errors.Is(err, errs.ErrNotFound) {
+		logger.Warn("promo code deactivated during booking", zap.Int("PromotionID", promotionID))
+		return errs.NewValidationError("promo code is not active")
+	}
+	return nil
+}
+
+// soldOutError reports rooms that ran out by the time the booking was
+// written: the group's allotment for a group booking, otherwise the rooms
+// left on public sale once allotments have taken theirs.
+func soldOutError(err error, group bool) error {
+	if group {
+		logger.Warn("group allotment fully booked", zap.Error(err))
+		return errs.NewConflictError("the group's rooms are fully booked for these dates")
+	}
+	logger.Warn("no public rooms left", zap.Error(err))
+	return errs.NewNotFoundError("no available room found for the specified type and dates")
+}
+
+// addonCapacityError reports an addon whose daily stock or time slot was
+// used up by the time the booking was written.
+func addonCapacityError(err error) error {
+	logger.Warn("addon capacity reached", zap.Error(err))
+	return errs.NewConflictError("an add-on is fully booked on the requested day, please choose another date or time slot")
+}
+
+// AddReservation books several rooms in one go. Every room shares the
+// reservation's dates but has its own room type, rate plan, occupancy and
+// addons. A promo code is one redemption, so it discounts only the first room
+// it applies to. The rooms are held all together or not at all, and the guest
+// gets one confirmation for the lot.
+func (s *BookingService) AddReservation(ctx context.Context, res *domain.Reservation) (*domain.ReservationDetail, error) {
+	logger.Info("AddReservation called", zap.Int("UserID", res.UserID), zap.Int("rooms", len(res.Bookings)))
+
+	if len(res.Bookings) == 0 {
+		return nil, errs.NewValidationError("a reservation needs at least one room")
+	}
+	if len(res.Bookings) > maxReservationRooms {
+		return nil, errs.NewValidationError(fmt.Sprintf("a reservation can hold at most %d rooms", maxReservationRooms))
+	}
+
+	code := strings.TrimSpace(res.PromoCode)
+	promoApplied := false
+	for i, booking := range res.Bookings {
+		booking.UserID = res.UserID
+		booking.CheckInDate = res.CheckInDate
+		booking.CheckOutDate = res.CheckOutDate
+		booking.PromoCode = ""
+		booking.GroupCode = res.GroupCode
+
+		// The promo code only has to fit one of the rooms; the others are
+		// simply priced without it.
+		if !promoApplied {
+			booking.PromoCode = code
+		}
+		if err := s.priceBooking(ctx, booking, booking.RoomTypeID, false); err != nil {
+			logger.Warn("reservation room could not be priced", zap.Int("room", i+1), zap.Error(err))
+			return nil, err
+		}
+		if booking.PromotionID > 0 {
+			promoApplied = true
+		}
+	}
+	if code != "" && !promoApplied {
+		return nil, errs.NewValidationError("promo code does not apply to any room in this reservation")
+	}
+
+	// A group code books every room against the group's allotment.
+	needed := make(map[int]int)
+	for _, booking := range res.Bookings {
+		if strings.TrimSpace(res.GroupCode) == "" {
+			needed[booking.RoomTypeID]++
+			continue
+		}
+		if err := s.applyGroupCode(ctx, booking); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkPublicRooms(ctx, needed, res.CheckInDate, res.CheckOutDate); err != nil {
 		return nil, err
 	}
 
-	// Send Confirmation Email
+	if err := s.createWithAvailableRooms(ctx, res); err != nil {
+		return nil, err
+	}
+
+	details, err := s.bookingRepo.GetReservation(ctx, res.ReservationID)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetReservation failed")
+		return nil, errs.NewUnexpectedError("failed to get reservation")
+	}
+
 	go func() {
-		// Fetch full details (populated with joins) for the email
-		// Note: At this point, the transaction is committed, so we can read from DB.
-		details, dbErr := s.bookingRepo.GetBookingWithAddons(context.Background(), booking.BookingID)
-		if dbErr != nil {
-			logger.ErrorErr(dbErr, "failed to fetch booking for email")
-			return
+		if emailErr := s.emailRepo.SendReservationConfirmation(context.Background(), details); emailErr != nil {
+			logger.ErrorErr(emailErr, "failed to send reservation confirmation email")
+		}
+	}()
+
+	logger.Info("reservation created successfully", zap.Int("ReservationID", res.ReservationID))
+	return details, nil
+}
+
+// createWithAvailableRooms assigns a free room to every booking of the
+// reservation and inserts them together, retrying like createWithAvailableRoom
+// when a concurrent booking takes one of the rooms first.
+func (s *BookingService) createWithAvailableRooms(ctx context.Context, res *domain.Reservation) error {
+	byType := make(map[int][]*domain.Booking)
+	var typeOrder []int
+	for _, booking := range res.Bookings {
+		if _, ok := byType[booking.RoomTypeID]; !ok {
+			typeOrder = append(typeOrder, booking.RoomTypeID)
 		}
+		byType[booking.RoomTypeID] = append(byType[booking.RoomTypeID], booking)
+	}
 
-		emailCtx := context.Background()
-		if emailErr := s.emailRepo.SendBookingConfirmation(emailCtx, details, details.BookingAddon); emailErr != nil {
-			logger.ErrorErr(emailErr, "failed to send confirmation email")
+	var promotionID int
+	charges := make([][]*domain.FolioEntry, len(res.Bookings))
+	for i, booking := range res.Bookings {
+		charges[i] = bookingCharges(booking)
+		if promotionID == 0 {
+			promotionID = booking.PromotionID
 		}
-	}()
+	}
 
-	logger.Info("booking created successfully", zap.Int("BookingID", booking.BookingID))
-	return booking, err
+	// Retried until no rooms are left, as in createWithAvailableRoom.
+	for attempt := 1; ; attempt++ {
+		for _, roomTypeID := range typeOrder {
+			bookings := byType[roomTypeID]
+			roomIDs, err := s.roomRepo.GetAvailableRoomIDs(ctx, roomTypeID, res.CheckInDate, res.CheckOutDate, len(bookings))
+			if err != nil {
+				logger.ErrorErr(err, "GetAvailableRoomIDs failed")
+				return errs.NewUnexpectedError("failed to find available rooms")
+			}
+			if len(roomIDs) < len(bookings) {
+				logger.Warn("not enough rooms for type", zap.Int("roomTypeID", roomTypeID),
+					zap.Int("wanted", len(bookings)), zap.Int("free", len(roomIDs)))
+				return errs.NewNotFoundError(fmt.Sprintf("only %d rooms of type %d are available for the specified dates", len(roomIDs), roomTypeID))
+			}
+			for i, booking := range bookings {
+				booking.RoomID = roomIDs[i]
+			}
+		}
+
+		err := s.bookingRepo.CreateReservation(ctx, res, charges)
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, errs.ErrSoldOut) {
+			return soldOutError(err, strings.TrimSpace(res.GroupCode) != "")
+		}
+		if errors.Is(err, errs.ErrFullyBooked) {
+			return addonCapacityError(err)
+		}
+		if promoErr := redemptionError(err, promotionID); promoErr != nil {
+			return promoErr
+		}
+		if !errors.Is(err, errs.ErrConflict) {
+			logger.ErrorErr(err, "repo.CreateReservation failed")
+			return err
+		}
+
+		logger.Warn("room taken by a concurrent booking, retrying reservation", zap.Int("attempt", attempt))
+	}
+}
+
+func (s *BookingService) GetReservation(ctx context.Context, reservationID int) (*domain.ReservationDetail, error) {
+	logger.Info("GetReservation called", zap.Int("ReservationID", reservationID))
+
+	if reservationID <= 0 {
+		return nil, errs.NewValidationError("invalid reservation id")
+	}
+
+	res, err := s.bookingRepo.GetReservation(ctx, reservationID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.NewNotFoundError("reservation not found")
+		}
+		logger.ErrorErr(err, "repo.GetReservation failed")
+		return nil, errs.NewUnexpectedError("failed to get reservation")
+	}
+
+	return res, nil
 }
 
 func (s *BookingService) GetFullDetails(ctx context.Context, bookingID int) (*domain.BookingDetail, error) {
@@ -134,8 +615,8 @@ func (s *BookingService) GetFullDetails(ctx context.Context, bookingID int) (*do
 	return booking, nil
 }
 
-func (s *BookingService) ChangeStatus(ctx context.Context, bookingID int, status string) error {
-	logger.Info("ChangeStatus called", zap.Int("BookingID", bookingID), zap.String("Status", status))
+func (s *BookingService) ChangeStatus(ctx context.Context, bookingID, actorID int, status, reason string) error {
+	logger.Info("ChangeStatus called", zap.Int("BookingID", bookingID), zap.String("Status", status), zap.Int("ActorID", actorID))
 
 	if bookingID <= 0 || status == "" {
 		logger.Warn("validation failed: missing booking id or status")
@@ -144,86 +625,571 @@ func (s *BookingService) ChangeStatus(ctx context.Context, bookingID int, status
 
 	normalizedStatus := strings.ToLower(status)
 
-	validStatues := map[string]bool{
-		"pending":     true,
-		"confirmed":   true,
-		"cancelled":   true,
-		"checked-in":  true,
-		"checked-out": true,
-	}
-
-	if !validStatues[normalizedStatus] {
+	if !knownBookingStatuses[normalizedStatus] {
 		logger.Warn("invalid status", zap.String("status", status))
 		return errs.NewValidationError("invalid status")
 	}
 
-	// Just update status to confirmed for mock
-	if err := s.bookingRepo.UpdateBookingStatus(ctx, bookingID, normalizedStatus); err != nil {
+	if normalizedStatus == domain.BookingStatusCancelled {
+		_, err := s.CancelBooking(ctx, bookingID, actorID, reason)
+		return err
+	}
+
+	booking, err := s.bookingRepo.GetBookingWithAddons(ctx, bookingID)
+	if err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
 			return fmt.Errorf("booking id %d: %w", bookingID, errs.ErrNotFound)
 		}
-		logger.ErrorErr(err, "ChangeStatus failed")
+		logger.ErrorErr(err, "repo.GetBookingWithAddons failed")
 		return err
 	}
 
-	// If status changed to confirmed, resend email (receipt)
-	if normalizedStatus == "confirmed" {
-		go func() {
-			// Fetch full details to get email and addons
-			details, dbErr := s.bookingRepo.GetBookingWithAddons(context.Background(), bookingID)
-			if dbErr != nil {
-				logger.ErrorErr(dbErr, "failed to fetch booking for email")
-				return
-			}
+	if booking.Status == domain.BookingStatusPending && normalizedStatus == domain.BookingStatusConfirmed {
+		logger.Warn("manual confirmation of unpaid booking", zap.Int("BookingID", bookingID))
+		return errs.NewValidationError("pending bookings are confirmed by a verified payment")
+	}
 
-			details.Status = "confirmed"
+	// The balance is due at check-in; add-ons bought during the stay are settled before check-out.
+	if (normalizedStatus == domain.BookingStatusCheckedIn || normalizedStatus == domain.BookingStatusCheckedOut) && booking.BalanceDue().IsPositive() {
+		logger.Warn("status change with balance due", zap.String("to", normalizedStatus), zap.Int("BookingID", bookingID), zap.Stringer("balance", booking.BalanceDue()))
+		return errs.NewValidationError(fmt.Sprintf("booking has a balance due of %s", booking.BalanceDue().Display()))
+	}
 
-			emailCtx := context.Background()
-			if emailErr := s.emailRepo.SendBookingConfirmation(emailCtx, details, details.BookingAddon); emailErr != nil {
-				logger.ErrorErr(emailErr, "failed to send confirmation email")
-			} else {
-				logger.Info("confirmation email sent (payment success)", zap.String("email", details.Email))
-			}
-		}()
+	if !canTransitionBooking(booking.Status, normalizedStatus) {
+		logger.Warn("illegal status transition", zap.String("from", booking.Status), zap.String("to", normalizedStatus))
+		return errs.NewValidationError(fmt.Sprintf("cannot change booking status from %s to %s", booking.Status, normalizedStatus))
+	}
+
+	change := &domain.BookingStatusHistory{
+		BookingID:   bookingID,
+		ActorUserID: actorID,
+		OldStatus:   booking.Status,
+		NewStatus:   normalizedStatus,
+		Reason:      reason,
+	}
+	if err := s.bookingRepo.UpdateBookingStatus(ctx, change); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("booking status changed concurrently", zap.Int("BookingID", bookingID))
+			return errs.NewValidationError("booking status was changed by another request, please retry")
+		}
+		logger.ErrorErr(err, "ChangeStatus failed")
+		return err
 	}
 
 	logger.Info("booking status changed successfully", zap.Int("BookingID", bookingID), zap.String("Status", status))
 	return nil
 }
 
-func (s *BookingService) ModifyBookingAddons(ctx context.Context, bookingID int, newAddons []*domain.BookingAddon) error {
+func (s *BookingService) GetStatusHistory(ctx context.Context, bookingID int) ([]*domain.BookingStatusHistory, error) {
+	logger.Info("GetStatusHistory called", zap.Int("BookingID", bookingID))
+
+	if bookingID <= 0 {
+		logger.Warn("validation failed: missing booking id")
+		return nil, errs.NewValidationError("invalid booking id")
+	}
+
+	history, err := s.bookingRepo.GetBookingStatusHistory(ctx, bookingID)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetBookingStatusHistory failed")
+		return nil, errs.NewUnexpectedError("failed to get booking status history")
+	}
+
+	logger.Debug("booking status history returned", zap.Int("count", len(history)))
+	return history, nil
+}
+
+func (s *BookingService) CancelBooking(ctx context.Context, bookingID, actorID int, reason string) (*domain.BookingDetail, error) {
+	logger.Info("CancelBooking called", zap.Int("BookingID", bookingID), zap.Int("ActorID", actorID))
+
+	if bookingID <= 0 {
+		logger.Warn("validation failed: missing booking id")
+		return nil, errs.NewValidationError("invalid booking id")
+	}
+
 	booking, err := s.bookingRepo.GetBookingWithAddons(ctx, bookingID)
 	if err != nil {
-		return err
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.NewNotFoundError("booking not found")
+		}
+		logger.ErrorErr(err, "repo.GetBookingWithAddons failed")
+		return nil, errs.NewUnexpectedError("failed to get booking")
 	}
 
-	var newAddonTotal float64
-	for i := range newAddons {
-		addon, err := s.addonRepo.GetAddonByID(ctx, newAddons[i].AddonID)
-		if err != nil {
-			return err
+	if !canTransitionBooking(booking.Status, domain.BookingStatusCancelled) {
+		logger.Warn("booking is not cancellable", zap.String("status", booking.Status))
+		return nil, errs.NewValidationError(fmt.Sprintf("booking with status %s cannot be cancelled", booking.Status))
+	}
+
+	now := time.Now()
+	if !now.Before(checkInTime(booking.CheckInDate)) {
+		logger.Warn("cancellation after check-in time", zap.Int("BookingID", bookingID))
+		return nil, errs.NewValidationError("booking can no longer be cancelled after check-in time")
+	}
+
+	rp, err := s.rateplanRepo.GetRatePlanByID(ctx, booking.RatePlanID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.NewNotFoundError("rate plan not found")
+		}
+		logger.ErrorErr(err, "repo.GetRatePlanByID failed")
+		return nil, errs.NewUnexpectedError("failed to get rate plan")
+	}
+
+	fee, err := cancellationFee(booking, rp, now)
+	if err != nil {
+		return nil, moneyError(err)
+	}
+
+	change := &domain.BookingStatusHistory{
+		BookingID:   bookingID,
+		ActorUserID: actorID,
+		OldStatus:   booking.Status,
+		NewStatus:   domain.BookingStatusCancelled,
+		Reason:      reason,
+	}
+	if err := s.bookingRepo.CancelBooking(ctx, change, fee, cancellationEntries(booking, fee, actorID)); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.NewValidationError("booking is no longer cancellable")
+		}
+		logger.ErrorErr(err, "repo.CancelBooking failed")
+		return nil, errs.NewUnexpectedError("failed to cancel booking")
+	}
+
+	booking.Status = domain.BookingStatusCancelled
+	booking.CancellationReason = reason
+	booking.CancellationFee = fee
+	booking.CancelledAt = now
+
+	go func(details *domain.BookingDetail) {
+		if emailErr := s.emailRepo.SendBookingCancellation(context.Background(), details); emailErr != nil {
+			logger.ErrorErr(emailErr, "failed to send cancellation email")
+		}
+	}(booking)
+	go s.offerFreedRoom()
+
+	logger.Info("booking cancelled successfully", zap.Int("BookingID", bookingID), zap.Stringer("fee", fee))
+	return booking, nil
+}
+
+// checkInTime returns the moment the stay starts: the check-in date at the
+// hotel's configured check-in hour, in local time.
+func checkInTime(checkInDate time.Time) time.Time {
+	y, m, d := checkInDate.Date()
+	return time.Date(y, m, d, viper.GetInt("booking.check_in_hour"), 0, 0, 0, time.Local)
+}
+
+// cancellationFee works out the penalty for cancelling a booking at the given time.
+// Bookings with nothing paid on them are always free to cancel, whatever their
+// status. Paid bookings on a free-cancel plan are free until the deadline and pay
+// a number of nights afterwards; every other paid booking forfeits its total price.
+func cancellationFee(booking *domain.BookingDetail, rp *domain.RatePlan, now time.Time) (domain.Money, error) {
+	none := domain.NewMoney(0, booking.TotalPrice.Currency)
+	if !booking.AmountPaid.IsPositive() {
+		return none, nil
+	}
+
+	if !rp.AllowFreeCancel {
+		return booking.TotalPrice, nil
+	}
+
+	deadline := checkInTime(booking.CheckInDate).Add(-time.Duration(viper.GetInt("booking.cancellation_deadline_hours")) * time.Hour)
+	if now.Before(deadline) {
+		return none, nil
+	}
+
+	numNights := int(booking.CheckOutDate.Sub(booking.CheckInDate).Hours() / 24)
+	if numNights <= 0 {
+		return none, nil
+	}
+
+	penaltyNights := viper.GetInt("booking.late_cancellation_penalty_nights")
+	if penaltyNights > numNights {
+		penaltyNights = numNights
+	}
+
+	// Charge the actual rates of the first nights when the breakdown is stored.
+	if len(booking.Nights) >= penaltyNights {
+		return sumNights(booking.Nights[:penaltyNights])
+	}
+
+	return booking.RoomSubTotal.Mul(int64(penaltyNights)).DivRound(int64(numNights)), nil
+}
+
+// ModifyBookingAddons replaces a booking's add-ons. The booking's totals are
+// recalculated and the difference is posted to its folio, so money already
+// paid is never silently rewritten.
+func (s *BookingService) ModifyBookingAddons(ctx context.Context, bookingID, actorID int, newAddons []*domain.BookingAddon) error {
+	logger.Info("ModifyBookingAddons called", zap.Int("BookingID", bookingID), zap.Int("ActorID", actorID))
+
+	booking, err := s.bookingRepo.GetBookingWithAddons(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.NewNotFoundError("booking not found")
 		}
+		logger.ErrorErr(err, "repo.GetBookingWithAddons failed")
+		return errs.NewUnexpectedError("failed to get booking")
+	}
+
+	switch booking.Status {
+	case domain.BookingStatusPending, domain.BookingStatusConfirmed, domain.BookingStatusCheckedIn:
+	default:
+		return errs.NewValidationError(fmt.Sprintf("cannot change add-ons of a %s booking", booking.Status))
+	}
+
+	// The party may rely on an extra bed that is being removed.
+	room, err := s.roomRepo.GetRoomByID(ctx, booking.RoomID)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetRoomByID failed")
+		return errs.NewUnexpectedError("failed to get booked room")
+	}
+	if _, err := s.checkOccupancy(ctx, room.RoomTypeID, booking.RatePlanID, booking.NumAdults, booking.ChildAges, newAddons); err != nil {
+		return err
+	}
+
+	newAddonTotal, err := s.priceAddons(ctx, newAddons, booking.CheckInDate, booking.CheckOutDate, booking.NumAdults+len(booking.ChildAges))
+	if err != nil {
+		return err
+	}
+
+	roomCharge, err := booking.RoomSubTotal.Sub(booking.DiscountAmount)
+	if err != nil {
+		return moneyError(err)
+	}
+	taxes, taxesAmount, err := s.calculateTaxes(ctx, taxableCharges{
+		Room:   roomCharge,
+		Addon:  newAddonTotal,
+		Nights: int(booking.CheckOutDate.Sub(booking.CheckInDate).Hours() / 24),
+		Guests: booking.NumAdults + len(booking.ChildAges),
+	})
+	if err != nil {
+		return err
+	}
+	total, err := domain.Sum(roomCharge, newAddonTotal, taxesAmount)
+	if err != nil {
+		return moneyError(err)
+	}
 
-		newAddons[i].PriceAtBooking = addon.Price
-		newAddonTotal += (addon.Price * float64(newAddons[i].Quantity))
+	entries, err := addonChangeEntries(booking.BookingAddon, newAddons, booking.Taxes, taxes, actorID)
+	if err != nil {
+		return moneyError(err)
 	}
 
-	newTaxes := (booking.RoomSubTotal + newAddonTotal) * 0.07
-	newTotalPrice := booking.RoomSubTotal + newAddonTotal + newTaxes
+	booking.BookingAddon = newAddons
+	booking.AddonSubTotal = newAddonTotal
+	booking.Taxes = taxes
+	booking.TaxesAmount = taxesAmount
+	booking.TotalPrice = total
 
-	err = s.bookingRepo.SyncBookingAddons(ctx, bookingID, newAddons, newTotalPrice)
+	err = s.bookingRepo.SyncBookingAddons(ctx, booking, entries)
 	if err != nil {
+		if errors.Is(err, errs.ErrFullyBooked) {
+			return addonCapacityError(err)
+		}
 		logger.ErrorErr(err, "repo.SyncBookingAddons failed")
 		return err
 	}
 
-	booking.AddonSubTotal = newAddonTotal
-	booking.TaxesAmount = newTaxes
-	booking.TotalPrice = newTotalPrice
+	logger.Info("booking addons updated", zap.Int("BookingID", bookingID), zap.Int("entries", len(entries)))
+	return nil
+}
+
+// ModifyStay moves a booking to new dates or another room type. It prices the
+// new stay with the current rates, keeping the booking's add-ons and promo code
+// where they still apply. With preview set it only returns the quote;
+// otherwise the change and the price difference are applied in one transaction.
+func (s *BookingService) ModifyStay(ctx context.Context, bookingID, actorID int, change *domain.StayChange, preview bool) (*domain.StayQuote, error) {
+	logger.Info("ModifyStay called", zap.Int("BookingID", bookingID), zap.Int("ActorID", actorID), zap.Bool("preview", preview))
+
+	booking, err := s.bookingRepo.GetBookingWithAddons(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.NewNotFoundError("booking not found")
+		}
+		logger.ErrorErr(err, "repo.GetBookingWithAddons failed")
+		return nil, errs.NewUnexpectedError("failed to get booking")
+	}
 
+	if booking.Status != domain.BookingStatusPending && booking.Status != domain.BookingStatusConfirmed {
+		return nil, errs.NewValidationError(fmt.Sprintf("cannot change the stay of a %s booking", booking.Status))
+	}
+	if !time.Now().Before(checkInTime(booking.CheckInDate)) {
+		return nil, errs.NewValidationError("stay can no longer be changed after check-in time")
+	}
+
+	room, err := s.roomRepo.GetRoomByID(ctx, booking.RoomID)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetRoomByID failed")
+		return nil, errs.NewUnexpectedError("failed to get booked room")
+	}
+
+	quote := &domain.StayQuote{
+		BookingID:     bookingID,
+		RoomTypeID:    room.RoomTypeID,
+		CheckInDate:   booking.CheckInDate,
+		CheckOutDate:  booking.CheckOutDate,
+		OldTotalPrice: booking.TotalPrice,
+	}
+	if change.RoomTypeID > 0 {
+		quote.RoomTypeID = change.RoomTypeID
+	}
+	if !change.CheckInDate.IsZero() {
+		quote.CheckInDate = change.CheckInDate
+	}
+	if !change.CheckOutDate.IsZero() {
+		quote.CheckOutDate = change.CheckOutDate
+	}
+
+	if quote.RoomTypeID == room.RoomTypeID &&
+		utils.DateOnly(quote.CheckInDate).Equal(utils.DateOnly(booking.CheckInDate)) &&
+		utils.DateOnly(quote.CheckOutDate).Equal(utils.DateOnly(booking.CheckOutDate)) {
+		return nil, errs.NewValidationError("requested stay is the same as the current one")
+	}
+
+	numNights := int(quote.CheckOutDate.Sub(quote.CheckInDate).Hours() / 24)
+	if numNights <= 0 {
+		return nil, errs.NewValidationError("check out date must be after check in date")
+	}
+	if utils.DateOnly(quote.CheckInDate).Before(utils.DateOnly(time.Now())) {
+		return nil, errs.NewValidationError("check in date cannot be in the past")
+	}
+
+	restrictions, err := s.restrictRepo.GetRestrictionsForStay(ctx, quote.RoomTypeID, booking.RatePlanID, quote.CheckInDate, quote.CheckOutDate)
+	if err != nil {
+		logger.ErrorErr(err, "GetRestrictionsForStay failed")
+		return nil, errs.NewUnexpectedError("failed to check stay restrictions")
+	}
+	if err := checkStayRestrictions(restrictions, quote.CheckInDate, quote.CheckOutDate); err != nil {
+		logger.Warn("stay restriction violated", zap.Int("RoomTypeID", quote.RoomTypeID), zap.Error(err))
+		return nil, err
+	}
+
+	if err := s.checkStayInventory(ctx, booking, room.RoomTypeID, quote); err != nil {
+		return nil, err
+	}
+
+	occupancy, err := s.checkOccupancy(ctx, quote.RoomTypeID, booking.RatePlanID, booking.NumAdults, booking.ChildAges, booking.BookingAddon)
+	if err != nil {
+		return nil, err
+	}
+
+	quote.Nights, err = s.rateplanRepo.GetNightlyRates(ctx, quote.RoomTypeID, booking.RatePlanID, quote.CheckInDate, quote.CheckOutDate)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.NewValidationError("the booking's rate plan is not offered for the new stay")
+		}
+		logger.ErrorErr(err, "GetNightlyRates failed")
+		return nil, errs.NewUnexpectedError("failed to price the new stay")
+	}
+	if err := chargeExtraGuests(quote.Nights, occupancy, booking.NumAdults, booking.ChildAges); err != nil {
+		return nil, err
+	}
+	if quote.RoomSubTotal, err = sumNights(quote.Nights); err != nil {
+		return nil, moneyError(err)
+	}
+
+	quote.BookingAddon = make([]*domain.BookingAddon, len(booking.BookingAddon))
+	quote.AddonSubTotal = domain.THB(0)
+	for i, a := range booking.BookingAddon {
+		line := *a
+		if err := sizeAddon(&line, quote.CheckInDate, quote.CheckOutDate, booking.NumAdults+len(booking.ChildAges)); err != nil {
+			return nil, err
+		}
+		quote.BookingAddon[i] = &line
+		if quote.AddonSubTotal, err = quote.AddonSubTotal.Add(line.Total()); err != nil {
+			return nil, moneyError(err)
+		}
+	}
+
+	quote.DiscountAmount = domain.THB(0)
+	if booking.PromotionID > 0 {
+		quote.DiscountAmount, quote.PromoDropped = s.repriceDiscount(ctx, booking, quote)
+	}
+
+	roomCharge, err := quote.RoomSubTotal.Sub(quote.DiscountAmount)
+	if err != nil {
+		return nil, moneyError(err)
+	}
+	quote.Taxes, quote.TaxesAmount, err = s.calculateTaxes(ctx, taxableCharges{
+		Room:   roomCharge,
+		Addon:  quote.AddonSubTotal,
+		Nights: numNights,
+		Guests: booking.NumAdults + len(booking.ChildAges),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if quote.TotalPrice, err = domain.Sum(roomCharge, quote.AddonSubTotal, quote.TaxesAmount); err != nil {
+		return nil, moneyError(err)
+	}
+	if quote.Difference, err = quote.TotalPrice.Sub(quote.OldTotalPrice); err != nil {
+		return nil, moneyError(err)
+	}
+
+	if preview {
+		quote.RoomID, err = s.roomRepo.GetAvailableRoomIDForBooking(ctx, bookingID, quote.RoomTypeID, quote.CheckInDate, quote.CheckOutDate)
+		if err != nil {
+			return nil, roomSearchError(err)
+		}
+		logger.Info("stay change previewed", zap.Int("BookingID", bookingID), zap.Stringer("difference", quote.Difference))
+		return quote, nil
+	}
+
+	if change.ExpectedTotal != nil && *change.ExpectedTotal != quote.TotalPrice {
+		logger.Warn("stay change price moved since preview", zap.Int("BookingID", bookingID),
+			zap.Stringer("expected", *change.ExpectedTotal), zap.Stringer("actual", quote.TotalPrice))
+		return nil, errs.NewConflictError(fmt.Sprintf("the new stay now costs %s, please review the change again", quote.TotalPrice.Display()))
+	}
+
+	updated := &domain.BookingDetail{
+		Nights:         quote.Nights,
+		RoomSubTotal:   quote.RoomSubTotal,
+		BookingAddon:   quote.BookingAddon,
+		DiscountAmount: quote.DiscountAmount,
+		PromoCode:      booking.PromoCode,
+		Taxes:          quote.Taxes,
+	}
+	entries, err := stayChangeEntries(booking, updated, actorID)
+	if err != nil {
+		return nil, moneyError(err)
+	}
+
+	// Retried until no room is left, as in createWithAvailableRoom.
+	for attempt := 1; ; attempt++ {
+		quote.RoomID, err = s.roomRepo.GetAvailableRoomIDForBooking(ctx, bookingID, quote.RoomTypeID, quote.CheckInDate, quote.CheckOutDate)
+		if err != nil {
+			return nil, roomSearchError(err)
+		}
+
+		err = s.bookingRepo.ModifyStay(ctx, booking, quote, entries)
+		if err == nil {
+			quote.Applied = true
+			logger.Info("stay changed", zap.Int("BookingID", bookingID), zap.Int("RoomID", quote.RoomID), zap.Stringer("difference", quote.Difference))
+			return quote, nil
+		}
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("booking changed during stay change", zap.Int("BookingID", bookingID))
+			return nil, errs.NewConflictError("booking was changed by another request, please retry")
+		}
+		if errors.Is(err, errs.ErrSoldOut) {
+			return nil, soldOutError(err, booking.AllotmentID > 0)
+		}
+		if errors.Is(err, errs.ErrFullyBooked) {
+			return nil, addonCapacityError(err)
+		}
+		if errors.Is(err, errs.ErrPaymentPending) {
+			return nil, errs.NewConflictError("a payment for this booking is in progress, please wait for it to finish")
+		}
+		if !errors.Is(err, errs.ErrConflict) {
+			logger.ErrorErr(err, "repo.ModifyStay failed")
+			return nil, errs.NewUnexpectedError("failed to change stay")
+		}
+
+		logger.Warn("room taken by a concurrent booking, retrying", zap.Int("roomID", quote.RoomID), zap.Int("attempt", attempt))
+	}
+}
+
+// checkStayInventory makes sure the new stay has a room to draw on: a group
+// booking has to stay inside its allotment, anyone else needs a room on public
+// sale. The booking's own room counts as free on the nights it already holds.
+func (s *BookingService) checkStayInventory(ctx context.Context, booking *domain.BookingDetail, currentRoomTypeID int, quote *domain.StayQuote) error {
+	if booking.AllotmentID > 0 {
+		allotment, err := s.groupRepo.GetAllotmentByID(ctx, booking.AllotmentID)
+		if err != nil {
+			logger.ErrorErr(err, "repo.GetAllotmentByID failed")
+			return errs.NewUnexpectedError("failed to check group allotment")
+		}
+		if quote.RoomTypeID != allotment.RoomTypeID {
+			return errs.NewValidationError("group bookings must stay in the group's room type")
+		}
+		if utils.DateOnly(quote.CheckInDate).Before(utils.DateOnly(allotment.StartDate)) ||
+			utils.DateOnly(quote.CheckOutDate).After(utils.DateOnly(allotment.EndDate)) {
+			return errs.NewValidationError("stay is outside the group's dates")
+		}
+		return nil
+	}
+
+	counts, err := s.roomRepo.GetAvailableRoomCounts(ctx, quote.CheckInDate, quote.CheckOutDate)
+	if err != nil {
+		logger.ErrorErr(err, "GetAvailableRoomCounts failed")
+		return errs.NewUnexpectedError("failed to find available room")
+	}
+	free := counts[quote.RoomTypeID]
+	if quote.RoomTypeID == currentRoomTypeID &&
+		booking.CheckInDate.Before(quote.CheckOutDate) && booking.CheckOutDate.After(quote.CheckInDate) {
+		free++
+	}
+	if free < 1 {
+		return errs.NewNotFoundError("no available room found for the specified type and dates")
+	}
 	return nil
 }
 
+// repriceDiscount works out the booking's promo discount for the new stay. The
+// code was redeemed when the booking was made, so only its stay conditions are
+// checked again; when they no longer hold the discount is dropped.
+func (s *BookingService) repriceDiscount(ctx context.Context, booking *domain.BookingDetail, quote *domain.StayQuote) (domain.Money, bool) {
+	none := domain.THB(0)
+
+	promo, err := s.promoRepo.GetPromotionByID(ctx, booking.PromotionID)
+	if err != nil {
+		logger.Warn("promotion of booking not found, dropping discount", zap.Int("PromotionID", booking.PromotionID), zap.Error(err))
+		return none, true
+	}
+
+	discount, err := stayDiscount(promo, &domain.Booking{
+		RatePlanID:    booking.RatePlanID,
+		CheckInDate:   quote.CheckInDate,
+		CheckOutDate:  quote.CheckOutDate,
+		RoomSubTotal:  quote.RoomSubTotal,
+		AddonSubTotal: quote.AddonSubTotal,
+	}, quote.RoomTypeID)
+	if err != nil {
+		logger.Info("promo code no longer applies to new stay", zap.String("Code", booking.PromoCode), zap.Error(err))
+		return none, true
+	}
+
+	return discount, false
+}
+
+// moneyError reports amounts in different currencies being added up or
+// compared, which only bad data can cause.
+func moneyError(err error) error {
+	logger.ErrorErr(err, "amounts in different currencies")
+	return errs.NewUnexpectedError("failed to calculate amounts")
+}
+
+// sumNights adds up the price of every night.
+func sumNights(nights []*domain.NightlyRate) (domain.Money, error) {
+	prices := make([]domain.Money, len(nights))
+	for i, night := range nights {
+		prices[i] = night.Price
+	}
+	return domain.Sum(prices...)
+}
+
+func roomSearchError(err error) error {
+	if errors.Is(err, errs.ErrNotFound) {
+		return errs.NewNotFoundError("no available room found for the specified type and dates")
+	}
+	logger.ErrorErr(err, "GetAvailableRoomIDForBooking failed")
+	return errs.NewUnexpectedError("failed to find available room")
+}
+
+// calculateTaxes prices the active tax and fee rules against the charges.
+func (s *BookingService) calculateTaxes(ctx context.Context, charges taxableCharges) ([]*domain.BookingTax, domain.Money, error) {
+	rules, err := s.taxRepo.GetActiveTaxRules(ctx)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetActiveTaxRules failed")
+		return nil, domain.Money{}, errs.NewUnexpectedError("failed to calculate taxes")
+	}
+
+	taxes, total, err := computeTaxes(rules, charges, viper.GetString("pricing.tax_rounding"))
+	if err != nil {
+		return nil, domain.Money{}, moneyError(err)
+	}
+	return taxes, total, nil
+}
+
 func (s *BookingService) GetAddonDetails(ctx context.Context, bookingID int) ([]*domain.BookingAddon, error) {
 	logger.Info("GetAddonDetails called", zap.Int("BookingID", bookingID))
 
@@ -251,6 +1217,15 @@ func (s *BookingService) CleanupExpiredBookings(ctx context.Context) (int64, err
 		return 0, err
 	}
 	logger.Info("expired bookings cleaned up", zap.Int64("rowsAffected", rows))
+
+	// Expired holds put their rooms back on sale.
+	if rows > 0 {
+		if _, err := s.OfferWaitlist(ctx); err != nil {
+			logger.ErrorErr(err, "OfferWaitlist failed")
+		}
+	} else if _, err := s.waitlistRepo.SettleWaitlist(ctx, utils.DateOnly(time.Now())); err != nil {
+		logger.ErrorErr(err, "repo.SettleWaitlist failed")
+	}
 	return rows, nil
 }
 
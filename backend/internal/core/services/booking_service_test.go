@@ -0,0 +1,229 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/postgresql"
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/services"
+	"github.com/ingwrok/hotelBooking/internal/core/utils"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
+)
+
+// testDB connects to the migrated database in TEST_DATABASE_URL, or skips the
+// test when none is configured.
+func testDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := sqlx.Connect("pgx", dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+type noopEmail struct{}
+
+func (noopEmail) Send(context.Context, *domain.EmailMessage) error { return nil }
+func (noopEmail) SendBookingConfirmation(context.Context, *domain.BookingDetail, []*domain.BookingAddon) error {
+	return nil
+}
+func (noopEmail) SendReservationConfirmation(context.Context, *domain.ReservationDetail) error {
+	return nil
+}
+func (noopEmail) SendBookingCancellation(context.Context, *domain.BookingDetail) error { return nil }
+func (noopEmail) SendWaitlistOffer(context.Context, *domain.BookingDetail, *domain.WaitlistEntry) error {
+	return nil
+}
+
+func newBookingService(db *sqlx.DB) *services.BookingService {
+	return services.NewBookingService(
+		postgresql.NewBookingRepository(db),
+		postgresql.NewRoomRepository(db),
+		postgresql.NewRoomTypeRepository(db),
+		postgresql.NewRatePlanRepository(db),
+		postgresql.NewAddonRepository(db),
+		postgresql.NewRestrictionRepository(db),
+		postgresql.NewPromotionRepository(db),
+		postgresql.NewTaxRuleRepository(db),
+		postgresql.NewAllotmentRepository(db),
+		postgresql.NewWaitlistRepository(db),
+		noopEmail{},
+	)
+}
+
+// lastRoomFixture creates a user and a room type with a single room on a rate
+// plan of its own, and removes them again when the test ends.
+func lastRoomFixture(t *testing.T, db *sqlx.DB) (userID, roomTypeID, ratePlanID int) {
+	t.Helper()
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	must := func(q string, args ...interface{}) int {
+		t.Helper()
+		var id int
+		if err := db.QueryRowx(q, args...).Scan(&id); err != nil {
+			t.Fatalf("fixture %q: %v", q, err)
+		}
+		return id
+	}
+
+	userID = must(`INSERT INTO users (username, email, password_hash) VALUES ($1, $2, 'x') RETURNING user_id`,
+		"race_"+suffix, "race_"+suffix+"@example.com")
+	roomTypeID = must(`INSERT INTO roomtypes (name, capacity) VALUES ($1, 2) RETURNING room_type_id`, "Race "+suffix)
+	must(`INSERT INTO rooms (room_type_id, room_number) VALUES ($1, $2) RETURNING room_id`, roomTypeID, "R"+suffix)
+	ratePlanID = must(`INSERT INTO rate_plans (name) VALUES ($1) RETURNING rate_plan_id`, "Race "+suffix)
+	if _, err := db.Exec(`INSERT INTO room_type_rate_prices (room_type_id, rate_plan_id, price) VALUES ($1, $2, 1000)`,
+		roomTypeID, ratePlanID); err != nil {
+		t.Fatalf("fixture rate price: %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM bookings WHERE rate_plan_id = $1`, ratePlanID)
+		db.Exec(`DELETE FROM reservations WHERE user_id = $1`, userID)
+		db.Exec(`DELETE FROM roomtypes WHERE room_type_id = $1`, roomTypeID)
+		db.Exec(`DELETE FROM rate_plans WHERE rate_plan_id = $1`, ratePlanID)
+		db.Exec(`DELETE FROM users WHERE user_id = $1`, userID)
+	})
+	return userID, roomTypeID, ratePlanID
+}
+
+func TestAddBookingLastFreeRoom(t *testing.T) {
+	db := testDB(t)
+	svc := newBookingService(db)
+	userID, roomTypeID, ratePlanID := lastRoomFixture(t, db)
+
+	checkIn := utils.DateOnly(time.Now().AddDate(0, 6, 0))
+	checkOut := checkIn.AddDate(0, 0, 2)
+
+	const guests = 8
+	results := make([]error, guests)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := range guests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, results[i] = svc.AddBooking(context.Background(), &domain.Booking{
+				UserID:       userID,
+				RatePlanID:   ratePlanID,
+				CheckInDate:  checkIn,
+				CheckOutDate: checkOut,
+				NumAdults:    1,
+			}, roomTypeID)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	booked := 0
+	for i, err := range results {
+		switch {
+		case err == nil:
+			booked++
+		case errors.Is(err, errs.ErrNotFound), errors.Is(err, errs.ErrConflict):
+		default:
+			t.Errorf("guest %d: unexpected error: %v", i, err)
+		}
+	}
+	if booked != 1 {
+		t.Fatalf("got %d bookings for the last free room, want 1", booked)
+	}
+
+	var stored int
+	if err := db.Get(&stored, `SELECT COUNT(*) FROM bookings WHERE rate_plan_id = $1`, ratePlanID); err != nil {
+		t.Fatalf("count bookings: %v", err)
+	}
+	if stored != 1 {
+		t.Fatalf("got %d stored bookings, want 1", stored)
+	}
+}
+
+func TestPayLaterWaitlistHoldLapses(t *testing.T) {
+	db := testDB(t)
+	svc := newBookingService(db)
+	firstUserID, roomTypeID, ratePlanID := lastRoomFixture(t, db)
+
+	// A plan that confirms without payment, so only the offer window ends a hold.
+	if _, err := db.Exec(`UPDATE rate_plans SET allow_pay_later = TRUE WHERE rate_plan_id = $1`, ratePlanID); err != nil {
+		t.Fatalf("fixture pay later: %v", err)
+	}
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	var secondUserID int
+	if err := db.QueryRowx(`INSERT INTO users (username, email, password_hash) VALUES ($1, $2, 'x') RETURNING user_id`,
+		"next_"+suffix, "next_"+suffix+"@example.com").Scan(&secondUserID); err != nil {
+		t.Fatalf("fixture user: %v", err)
+	}
+	t.Cleanup(func() { db.Exec(`DELETE FROM users WHERE user_id = $1`, secondUserID) })
+
+	checkIn := utils.DateOnly(time.Now().AddDate(0, 6, 0))
+	checkOut := checkIn.AddDate(0, 0, 2)
+	join := func(userID int) int {
+		t.Helper()
+		var id int
+		err := db.QueryRowx(`INSERT INTO waitlist_entries (user_id, room_type_id, rate_plan_id, check_in_date, check_out_date, num_adults)
+				VALUES ($1, $2, $3, $4, $5, 1) RETURNING entry_id`,
+			userID, roomTypeID, ratePlanID, checkIn, checkOut).Scan(&id)
+		if err != nil {
+			t.Fatalf("fixture waitlist entry: %v", err)
+		}
+		return id
+	}
+	firstEntry := join(firstUserID)
+	secondEntry := join(secondUserID)
+
+	entry := func(id int) (status string, bookingID *int) {
+		t.Helper()
+		row := db.QueryRowx(`SELECT status, booking_id FROM waitlist_entries WHERE entry_id = $1`, id)
+		if err := row.Scan(&status, &bookingID); err != nil {
+			t.Fatalf("load waitlist entry %d: %v", id, err)
+		}
+		return status, bookingID
+	}
+
+	if _, err := svc.OfferWaitlist(context.Background()); err != nil {
+		t.Fatalf("OfferWaitlist: %v", err)
+	}
+	status, hold := entry(firstEntry)
+	if status != domain.WaitlistStatusOffered || hold == nil {
+		t.Fatalf("first entry is %s with hold %v, want an offer", status, hold)
+	}
+	if status, _ := entry(secondEntry); status != domain.WaitlistStatusWaiting {
+		t.Fatalf("second entry is %s, want waiting while the room is held", status)
+	}
+
+	if _, err := db.Exec(`UPDATE bookings SET expired_at = NOW() - INTERVAL '1 minute' WHERE booking_id = $1`, *hold); err != nil {
+		t.Fatalf("age hold: %v", err)
+	}
+	if _, err := svc.CleanupExpiredBookings(context.Background()); err != nil {
+		t.Fatalf("CleanupExpiredBookings: %v", err)
+	}
+
+	var holdStatus string
+	if err := db.Get(&holdStatus, `SELECT status FROM bookings WHERE booking_id = $1`, *hold); err != nil {
+		t.Fatalf("load hold: %v", err)
+	}
+	if holdStatus != domain.BookingStatusExpired {
+		t.Errorf("lapsed hold is %s, want %s", holdStatus, domain.BookingStatusExpired)
+	}
+	if status, _ := entry(firstEntry); status != domain.WaitlistStatusExpired {
+		t.Errorf("first entry is %s, want %s", status, domain.WaitlistStatusExpired)
+	}
+	status, next := entry(secondEntry)
+	if status != domain.WaitlistStatusOffered || next == nil || *next == *hold {
+		t.Errorf("second entry is %s with hold %v, want an offer of its own", status, next)
+	}
+}
@@ -0,0 +1,40 @@
+package services
+
+import "github.com/ingwrok/hotelBooking/internal/core/domain"
+
+// bookingTransitions lists, for every booking status, the statuses it may move to.
+// Statuses missing from the map (cancelled, expired, no-show, checked-out) are final.
+var bookingTransitions = map[string][]string{
+	domain.BookingStatusPending: {
+		domain.BookingStatusConfirmed,
+		domain.BookingStatusCancelled,
+		domain.BookingStatusExpired,
+	},
+	domain.BookingStatusConfirmed: {
+		domain.BookingStatusCheckedIn,
+		domain.BookingStatusCancelled,
+		domain.BookingStatusNoShow,
+	},
+	domain.BookingStatusCheckedIn: {
+		domain.BookingStatusCheckedOut,
+	},
+}
+
+var knownBookingStatuses = map[string]bool{
+	domain.BookingStatusPending:    true,
+	domain.BookingStatusConfirmed:  true,
+	domain.BookingStatusCancelled:  true,
+	domain.BookingStatusExpired:    true,
+	domain.BookingStatusNoShow:     true,
+	domain.BookingStatusCheckedIn:  true,
+	domain.BookingStatusCheckedOut: true,
+}
+
+func canTransitionBooking(from, to string) bool {
+	for _, next := range bookingTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
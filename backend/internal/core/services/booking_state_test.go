@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+)
+
+var allBookingStatuses = []string{
+	domain.BookingStatusPending,
+	domain.BookingStatusConfirmed,
+	domain.BookingStatusCancelled,
+	domain.BookingStatusExpired,
+	domain.BookingStatusNoShow,
+	domain.BookingStatusCheckedIn,
+	domain.BookingStatusCheckedOut,
+}
+
+// allowedTransitions is the state machine written out by hand, so the test
+// does not just read bookingTransitions back.
+var allowedTransitions = map[[2]string]bool{
+	{domain.BookingStatusPending, domain.BookingStatusConfirmed}:    true,
+	{domain.BookingStatusPending, domain.BookingStatusCancelled}:    true,
+	{domain.BookingStatusPending, domain.BookingStatusExpired}:      true,
+	{domain.BookingStatusConfirmed, domain.BookingStatusCheckedIn}:  true,
+	{domain.BookingStatusConfirmed, domain.BookingStatusCancelled}:  true,
+	{domain.BookingStatusConfirmed, domain.BookingStatusNoShow}:     true,
+	{domain.BookingStatusCheckedIn, domain.BookingStatusCheckedOut}: true,
+}
+
+func TestCanTransitionBooking(t *testing.T) {
+	for _, from := range allBookingStatuses {
+		for _, to := range allBookingStatuses {
+			want := allowedTransitions[[2]string{from, to}]
+			if got := canTransitionBooking(from, to); got != want {
+				t.Errorf("canTransitionBooking(%s, %s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
+
+// statusRepo serves one booking and records status changes; any other
+// repository call panics on the nil embedded interface.
+type statusRepo struct {
+	ports.BookingRepository
+	booking *domain.BookingDetail
+	changes []*domain.BookingStatusHistory
+}
+
+func (r *statusRepo) GetBookingWithAddons(_ context.Context, bookingID int) (*domain.BookingDetail, error) {
+	if r.booking == nil || r.booking.BookingID != bookingID {
+		return nil, errs.ErrNotFound
+	}
+	b := *r.booking
+	return &b, nil
+}
+
+func (r *statusRepo) UpdateBookingStatus(_ context.Context, change *domain.BookingStatusHistory) error {
+	r.changes = append(r.changes, change)
+	return nil
+}
+
+func TestChangeStatus(t *testing.T) {
+	for _, from := range allBookingStatuses {
+		for _, to := range allBookingStatuses {
+			// Cancelling goes through CancelBooking, which has its own rules.
+			if to == domain.BookingStatusCancelled {
+				continue
+			}
+
+			// Pending bookings are only confirmed by a payment.
+			want := allowedTransitions[[2]string{from, to}] &&
+				!(from == domain.BookingStatusPending && to == domain.BookingStatusConfirmed)
+
+			repo := &statusRepo{booking: &domain.BookingDetail{BookingID: 7, Status: from, Balance: domain.THB(0)}}
+			svc := &BookingService{bookingRepo: repo}
+			err := svc.ChangeStatus(context.Background(), 7, 1, to, "test")
+
+			if want {
+				if err != nil {
+					t.Errorf("%s -> %s: %v", from, to, err)
+					continue
+				}
+				if len(repo.changes) != 1 || repo.changes[0].OldStatus != from || repo.changes[0].NewStatus != to {
+					t.Errorf("%s -> %s: recorded %+v", from, to, repo.changes)
+				}
+				continue
+			}
+			if !errors.Is(err, errs.ErrValidation) {
+				t.Errorf("%s -> %s: got %v, want a validation error", from, to, err)
+			}
+			if len(repo.changes) != 0 {
+				t.Errorf("%s -> %s: status changed despite the error", from, to)
+			}
+		}
+	}
+}
+
+func TestChangeStatusBalanceDue(t *testing.T) {
+	tests := []struct {
+		from    string
+		to      string
+		balance int64
+		wantErr bool
+	}{
+		{domain.BookingStatusConfirmed, domain.BookingStatusCheckedIn, 1, true},
+		{domain.BookingStatusConfirmed, domain.BookingStatusCheckedIn, 0, false},
+		{domain.BookingStatusConfirmed, domain.BookingStatusCheckedIn, -500, false}, // owed a refund
+		{domain.BookingStatusCheckedIn, domain.BookingStatusCheckedOut, 25000, true},
+		{domain.BookingStatusCheckedIn, domain.BookingStatusCheckedOut, 0, false},
+		{domain.BookingStatusConfirmed, domain.BookingStatusNoShow, 25000, false},
+	}
+
+	for _, tt := range tests {
+		repo := &statusRepo{booking: &domain.BookingDetail{BookingID: 7, Status: tt.from, Balance: domain.THB(tt.balance)}}
+		svc := &BookingService{bookingRepo: repo}
+		err := svc.ChangeStatus(context.Background(), 7, 1, tt.to, "test")
+
+		if tt.wantErr {
+			if !errors.Is(err, errs.ErrValidation) || len(repo.changes) != 0 {
+				t.Errorf("%s -> %s with balance %d: got %v, want a validation error", tt.from, tt.to, tt.balance, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s -> %s with balance %d: %v", tt.from, tt.to, tt.balance, err)
+		}
+	}
+}
+
+func TestChangeStatusRejectsInput(t *testing.T) {
+	repo := &statusRepo{booking: &domain.BookingDetail{BookingID: 7, Status: domain.BookingStatusConfirmed}}
+	svc := &BookingService{bookingRepo: repo}
+
+	for _, status := range []string{"", "paid", "CONFIRMED "} {
+		if err := svc.ChangeStatus(context.Background(), 7, 1, status, "test"); !errors.Is(err, errs.ErrValidation) {
+			t.Errorf("ChangeStatus(%q): got %v, want a validation error", status, err)
+		}
+	}
+	if err := svc.ChangeStatus(context.Background(), 0, 1, domain.BookingStatusCheckedIn, "test"); !errors.Is(err, errs.ErrValidation) {
+		t.Errorf("ChangeStatus on booking 0: got %v, want a validation error", err)
+	}
+	if err := svc.ChangeStatus(context.Background(), 8, 1, domain.BookingStatusCheckedIn, "test"); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("ChangeStatus on a missing booking: got %v, want not found", err)
+	}
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/common/logger"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/utils"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// JoinWaitlist puts a guest in line for a room type that has no room left on
+// public sale for the stay.
+func (s *BookingService) JoinWaitlist(ctx context.Context, entry *domain.WaitlistEntry) (*domain.WaitlistEntry, error) {
+	logger.Info("JoinWaitlist called",
+		zap.Int("UserID", entry.UserID),
+		zap.Int("RoomTypeID", entry.RoomTypeID),
+		zap.Int("RatePlanID", entry.RatePlanID),
+	)
+
+	entry.CheckInDate, entry.CheckOutDate = utils.DateOnly(entry.CheckInDate), utils.DateOnly(entry.CheckOutDate)
+	if !entry.CheckOutDate.After(entry.CheckInDate) {
+		return nil, errs.NewValidationError("check out date must be after check in date")
+	}
+	if !entry.CheckInDate.After(utils.DateOnly(time.Now())) {
+		return nil, errs.NewValidationError("the waitlist is only open for stays starting after today")
+	}
+	if _, err := s.checkOccupancy(ctx, entry.RoomTypeID, entry.RatePlanID, entry.NumAdults, entry.ChildAges, nil); err != nil {
+		return nil, err
+	}
+
+	err := s.checkPublicRooms(ctx, map[int]int{entry.RoomTypeID: 1}, entry.CheckInDate, entry.CheckOutDate)
+	if err == nil {
+		logger.Warn("waitlist joined for available room type", zap.Int("RoomTypeID", entry.RoomTypeID))
+		return nil, errs.NewConflictError("rooms of this type are still available for these dates, please book directly")
+	}
+	if !errors.Is(err, errs.ErrNotFound) {
+		return nil, err
+	}
+
+	entry.Status = domain.WaitlistStatusWaiting
+	if err := s.waitlistRepo.CreateWaitlistEntry(ctx, entry); err != nil {
+		if errors.Is(err, errs.ErrConflict) {
+			return nil, errs.NewConflictError("you are already on the waitlist for this room type and dates")
+		}
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.NewNotFoundError("room type or rate plan not found")
+		}
+		logger.ErrorErr(err, "repo.CreateWaitlistEntry failed")
+		return nil, errs.NewUnexpectedError("failed to join waitlist")
+	}
+
+	logger.Info("waitlist joined", zap.Int("EntryID", entry.EntryID))
+	return entry, nil
+}
+
+func (s *BookingService) GetMyWaitlist(ctx context.Context, userID int) ([]*domain.WaitlistEntry, error) {
+	logger.Info("GetMyWaitlist called", zap.Int("UserID", userID))
+
+	entries, err := s.waitlistRepo.GetWaitlistByUserID(ctx, userID)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetWaitlistByUserID failed")
+		return nil, errs.NewUnexpectedError("failed to get waitlist")
+	}
+	return entries, nil
+}
+
+// ListWaitlist returns the waitlist of a room type in queue order, or of every
+// room type when roomTypeID is 0.
+func (s *BookingService) ListWaitlist(ctx context.Context, roomTypeID int) ([]*domain.WaitlistEntry, error) {
+	logger.Info("ListWaitlist called", zap.Int("RoomTypeID", roomTypeID))
+
+	entries, err := s.waitlistRepo.GetWaitlist(ctx, roomTypeID)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetWaitlist failed")
+		return nil, errs.NewUnexpectedError("failed to get waitlist")
+	}
+	return entries, nil
+}
+
+// getOwnWaitlistEntry loads an entry of the user. Other users' entries are
+// reported as not found.
+func (s *BookingService) getOwnWaitlistEntry(ctx context.Context, entryID, userID int) (*domain.WaitlistEntry, error) {
+	entry, err := s.waitlistRepo.GetWaitlistEntry(ctx, entryID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.NewNotFoundError("waitlist entry not found")
+		}
+		logger.ErrorErr(err, "repo.GetWaitlistEntry failed")
+		return nil, errs.NewUnexpectedError("failed to get waitlist entry")
+	}
+	if entry.UserID != userID {
+		logger.Warn("waitlist entry of another user", zap.Int("EntryID", entryID), zap.Int("UserID", userID))
+		return nil, errs.NewNotFoundError("waitlist entry not found")
+	}
+	return entry, nil
+}
+
+// LeaveWaitlist takes the guest off the waitlist. An open offer is declined
+// and its room passed on to the next guest in line.
+func (s *BookingService) LeaveWaitlist(ctx context.Context, entryID, userID int) error {
+	logger.Info("LeaveWaitlist called", zap.Int("EntryID", entryID), zap.Int("UserID", userID))
+
+	entry, err := s.getOwnWaitlistEntry(ctx, entryID, userID)
+	if err != nil {
+		return err
+	}
+
+	switch entry.Status {
+	case domain.WaitlistStatusWaiting:
+	case domain.WaitlistStatusOffered:
+		hold, err := s.bookingRepo.GetBookingWithAddons(ctx, entry.BookingID)
+		if err != nil && !errors.Is(err, errs.ErrNotFound) {
+			logger.ErrorErr(err, "repo.GetBookingWithAddons failed")
+			return errs.NewUnexpectedError("failed to get offered booking")
+		}
+		if hold != nil {
+			switch hold.Status {
+			case domain.BookingStatusPending:
+				// Cancelling the hold offers its room to the next guest.
+				if _, err := s.CancelBooking(ctx, hold.BookingID, userID, "waitlist offer declined"); err != nil {
+					return err
+				}
+			case domain.BookingStatusExpired, domain.BookingStatusCancelled:
+			default:
+				return errs.NewValidationError(fmt.Sprintf("the offered room is booked as #%d, cancel that booking instead", hold.BookingID))
+			}
+		}
+	default:
+		return errs.NewValidationError(fmt.Sprintf("a %s waitlist entry cannot be withdrawn", entry.Status))
+	}
+
+	if err := s.waitlistRepo.UpdateWaitlistStatus(ctx, entryID, entry.Status, domain.WaitlistStatusCancelled); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.NewConflictError("waitlist entry was changed by another request, please retry")
+		}
+		logger.ErrorErr(err, "repo.UpdateWaitlistStatus failed")
+		return errs.NewUnexpectedError("failed to leave waitlist")
+	}
+
+	logger.Info("waitlist left", zap.Int("EntryID", entryID))
+	return nil
+}
+
+// AcceptWaitlistOffer confirms the room held for an offered entry. Offers on
+// rate plans that take payment up front are accepted by paying for the held
+// booking instead.
+func (s *BookingService) AcceptWaitlistOffer(ctx context.Context, entryID, userID int) (*domain.BookingDetail, error) {
+	logger.Info("AcceptWaitlistOffer called", zap.Int("EntryID", entryID), zap.Int("UserID", userID))
+
+	entry, err := s.getOwnWaitlistEntry(ctx, entryID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status != domain.WaitlistStatusOffered {
+		return nil, errs.NewValidationError(fmt.Sprintf("waitlist entry is %s, not offered", entry.Status))
+	}
+
+	booking, err := s.bookingRepo.GetBookingWithAddons(ctx, entry.BookingID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.NewValidationError("the offer has expired")
+		}
+		logger.ErrorErr(err, "repo.GetBookingWithAddons failed")
+		return nil, errs.NewUnexpectedError("failed to get offered booking")
+	}
+	if booking.Status != domain.BookingStatusPending || !time.Now().Before(booking.ExpiredAt) {
+		return nil, errs.NewValidationError("the offer has expired")
+	}
+
+	rp, err := s.rateplanRepo.GetRatePlanByID(ctx, booking.RatePlanID)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetRatePlanByID failed")
+		return nil, errs.NewUnexpectedError("failed to get rate plan")
+	}
+	if needsPaymentToConfirm(rp) {
+		return nil, errs.NewValidationError(fmt.Sprintf("pay for booking #%d to accept the offer", booking.BookingID))
+	}
+
+	change := &domain.BookingStatusHistory{
+		BookingID:   booking.BookingID,
+		ActorUserID: userID,
+		OldStatus:   booking.Status,
+		NewStatus:   domain.BookingStatusConfirmed,
+		Reason:      "waitlist offer accepted",
+	}
+	if err := s.bookingRepo.UpdateBookingStatus(ctx, change); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.NewValidationError("the offer has expired")
+		}
+		logger.ErrorErr(err, "repo.UpdateBookingStatus failed")
+		return nil, errs.NewUnexpectedError("failed to accept offer")
+	}
+	booking.Status = domain.BookingStatusConfirmed
+
+	// The booking is confirmed either way; SettleWaitlist catches up the entry.
+	if err := s.waitlistRepo.UpdateWaitlistStatus(ctx, entryID, domain.WaitlistStatusOffered, domain.WaitlistStatusBooked); err != nil {
+		logger.ErrorErr(err, "repo.UpdateWaitlistStatus failed")
+	}
+
+	go func(details *domain.BookingDetail) {
+		if emailErr := s.emailRepo.SendBookingConfirmation(context.Background(), details, details.BookingAddon); emailErr != nil {
+			logger.ErrorErr(emailErr, "failed to send confirmation email")
+		}
+	}(booking)
+
+	logger.Info("waitlist offer accepted", zap.Int("EntryID", entryID), zap.Int("BookingID", booking.BookingID))
+	return booking, nil
+}
+
+// OfferWaitlist closes finished offers, then offers freed rooms to waiting
+// guests in the order they joined. Each offer is a pending booking held for
+// waitlist.offer_hold_minutes and emailed to the guest. It returns how many
+// offers were made.
+func (s *BookingService) OfferWaitlist(ctx context.Context) (int, error) {
+	logger.Info("OfferWaitlist called")
+
+	if _, err := s.waitlistRepo.SettleWaitlist(ctx, utils.DateOnly(time.Now())); err != nil {
+		logger.ErrorErr(err, "repo.SettleWaitlist failed")
+		return 0, err
+	}
+
+	entries, err := s.waitlistRepo.GetWaitingEntries(ctx)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetWaitingEntries failed")
+		return 0, err
+	}
+
+	offered := 0
+	for _, entry := range entries {
+		booking, err := s.holdRoomFor(ctx, entry)
+		if err != nil {
+			logger.Warn("waitlist entry could not be offered", zap.Int("EntryID", entry.EntryID), zap.Error(err))
+			continue
+		}
+		if booking == nil {
+			continue
+		}
+
+		offered++
+		go s.sendWaitlistOffer(entry, booking)
+	}
+
+	logger.Info("waitlist offers made", zap.Int("offered", offered), zap.Int("waiting", len(entries)))
+	return offered, nil
+}
+
+// holdRoomFor books a pending hold for the entry's stay at today's price. It
+// returns nil when no room of the type is free.
+func (s *BookingService) holdRoomFor(ctx context.Context, entry *domain.WaitlistEntry) (*domain.Booking, error) {
+	err := s.checkPublicRooms(ctx, map[int]int{entry.RoomTypeID: 1}, entry.CheckInDate, entry.CheckOutDate)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	booking := &domain.Booking{
+		UserID:          entry.UserID,
+		RatePlanID:      entry.RatePlanID,
+		RoomTypeID:      entry.RoomTypeID,
+		CheckInDate:     entry.CheckInDate,
+		CheckOutDate:    entry.CheckOutDate,
+		NumAdults:       entry.NumAdults,
+		ChildAges:       entry.ChildAges,
+		Email:           entry.Email,
+		WaitlistEntryID: entry.EntryID,
+	}
+	if err := s.priceBooking(ctx, booking, entry.RoomTypeID, false); err != nil {
+		return nil, err
+	}
+
+	// The guest has to take the offer up, even on plans that confirm at once.
+	booking.Status = domain.BookingStatusPending
+	booking.ExpiredAt = time.Now().Add(time.Duration(viper.GetInt("waitlist.offer_hold_minutes")) * time.Minute)
+
+	if err := s.createWithAvailableRoom(ctx, booking, entry.RoomTypeID); err != nil {
+		// Either the room went to a direct booking first or the entry stopped
+		// waiting while it was being priced.
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	entry.Status = domain.WaitlistStatusOffered
+	entry.BookingID = booking.BookingID
+	entry.OfferExpiresAt = booking.ExpiredAt
+	logger.Info("waitlist entry offered a room", zap.Int("EntryID", entry.EntryID), zap.Int("BookingID", booking.BookingID))
+	return booking, nil
+}
+
+func (s *BookingService) sendWaitlistOffer(entry *domain.WaitlistEntry, booking *domain.Booking) {
+	details, err := s.bookingRepo.GetBookingWithAddons(context.Background(), booking.BookingID)
+	if err != nil {
+		logger.ErrorErr(err, "failed to fetch booking for waitlist offer email")
+		return
+	}
+	if err := s.emailRepo.SendWaitlistOffer(context.Background(), details, entry); err != nil {
+		logger.ErrorErr(err, "failed to send waitlist offer email")
+	}
+}
+
+// offerFreedRoom passes a room freed by a cancellation on to the waitlist.
+func (s *BookingService) offerFreedRoom() {
+	if _, err := s.OfferWaitlist(context.Background()); err != nil {
+		logger.ErrorErr(err, "OfferWaitlist failed")
+	}
+}
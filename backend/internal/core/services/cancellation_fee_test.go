@@ -0,0 +1,97 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/spf13/viper"
+)
+
+func TestCancellationFee(t *testing.T) {
+	viper.Set("booking.check_in_hour", 14)
+	viper.Set("booking.cancellation_deadline_hours", 24)
+	t.Cleanup(func() {
+		viper.Set("booking.check_in_hour", nil)
+		viper.Set("booking.cancellation_deadline_hours", nil)
+		viper.Set("booking.late_cancellation_penalty_nights", nil)
+	})
+
+	checkIn := time.Date(2030, 3, 10, 0, 0, 0, 0, time.Local)
+	deadline := time.Date(2030, 3, 9, 14, 0, 0, 0, time.Local)
+	freeCancel := &domain.RatePlan{AllowFreeCancel: true}
+	nonRefundable := &domain.RatePlan{}
+
+	// Three nights at 1000, 1200 and 1500 THB.
+	booking := func(paid int64, withNights bool) *domain.BookingDetail {
+		b := &domain.BookingDetail{
+			Status:       domain.BookingStatusConfirmed,
+			CheckInDate:  checkIn,
+			CheckOutDate: checkIn.AddDate(0, 0, 3),
+			RoomSubTotal: domain.THB(370000),
+			TotalPrice:   domain.THB(395900),
+			AmountPaid:   domain.THB(paid),
+		}
+		if withNights {
+			for i, price := range []int64{100000, 120000, 150000} {
+				b.Nights = append(b.Nights, &domain.NightlyRate{StayDate: checkIn.AddDate(0, 0, i), Price: domain.THB(price)})
+			}
+		}
+		return b
+	}
+
+	tests := []struct {
+		name          string
+		booking       *domain.BookingDetail
+		rp            *domain.RatePlan
+		now           time.Time
+		penaltyNights int
+		want          int64
+	}{
+		{"free plan just before the deadline", booking(395900, true), freeCancel, deadline.Add(-time.Nanosecond), 1, 0},
+		{"free plan at the deadline", booking(395900, true), freeCancel, deadline, 1, 100000},
+		{"free plan after the deadline", booking(395900, true), freeCancel, deadline.Add(time.Hour), 1, 100000},
+		{"two penalty nights", booking(395900, true), freeCancel, deadline, 2, 220000},
+		{"penalty longer than the stay", booking(395900, true), freeCancel, deadline, 5, 370000},
+		{"no nightly breakdown", booking(395900, false), freeCancel, deadline, 1, 123333},
+		{"deposit paid on a free plan after the deadline", booking(39590, true), freeCancel, deadline, 1, 100000},
+		{"non-refundable plan paid", booking(395900, true), nonRefundable, deadline.AddDate(0, -1, 0), 1, 395900},
+		{"non-refundable plan deposit paid", booking(1, true), nonRefundable, deadline.AddDate(0, -1, 0), 1, 395900},
+		{"non-refundable plan nothing paid", booking(0, true), nonRefundable, deadline.Add(time.Hour), 1, 0},
+		{"free plan nothing paid after the deadline", booking(0, true), freeCancel, deadline.Add(time.Hour), 1, 0},
+		{"fully refunded", booking(-100, true), nonRefundable, deadline.Add(time.Hour), 1, 0},
+	}
+
+	for _, tt := range tests {
+		viper.Set("booking.late_cancellation_penalty_nights", tt.penaltyNights)
+		got, err := cancellationFee(tt.booking, tt.rp, tt.now)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != domain.THB(tt.want) {
+			t.Errorf("%s: fee = %v, want %v", tt.name, got, domain.THB(tt.want))
+		}
+	}
+}
+
+func TestCancellationFeePendingBooking(t *testing.T) {
+	// A pending booking with a deposit paid is charged like a confirmed one.
+	b := &domain.BookingDetail{
+		Status:       domain.BookingStatusPending,
+		CheckInDate:  time.Date(2030, 3, 10, 0, 0, 0, 0, time.Local),
+		CheckOutDate: time.Date(2030, 3, 11, 0, 0, 0, 0, time.Local),
+		TotalPrice:   domain.THB(100000),
+		AmountPaid:   domain.THB(30000),
+	}
+	got, err := cancellationFee(b, &domain.RatePlan{}, time.Date(2030, 1, 1, 0, 0, 0, 0, time.Local))
+	if err != nil || got != domain.THB(100000) {
+		t.Errorf("fee = %v, %v; want 1000.00 THB", got, err)
+	}
+
+	b.AmountPaid = domain.THB(0)
+	got, err = cancellationFee(b, &domain.RatePlan{}, time.Date(2030, 1, 1, 0, 0, 0, 0, time.Local))
+	if err != nil || !got.IsZero() {
+		t.Errorf("unpaid fee = %v, %v; want 0", got, err)
+	}
+}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/common/logger"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"go.uber.org/zap"
+)
+
+type FolioService struct {
+	repo        ports.FolioRepository
+	bookingRepo ports.BookingRepository
+}
+
+func NewFolioService(repo ports.FolioRepository, bookingRepo ports.BookingRepository) *FolioService {
+	return &FolioService{
+		repo:        repo,
+		bookingRepo: bookingRepo,
+	}
+}
+
+func (s *FolioService) GetFolio(ctx context.Context, bookingID int) (*domain.Folio, error) {
+	logger.Info("GetFolio called", zap.Int("BookingID", bookingID))
+
+	if _, err := s.getBooking(ctx, bookingID); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.GetFolioEntries(ctx, bookingID)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetFolioEntries failed")
+		return nil, errs.NewUnexpectedError("failed to get folio")
+	}
+
+	balance, err := domain.FolioBalance(entries)
+	if err != nil {
+		logger.ErrorErr(err, "folio entries in different currencies", zap.Int("BookingID", bookingID))
+		return nil, errs.NewUnexpectedError("failed to get folio")
+	}
+
+	return &domain.Folio{
+		BookingID: bookingID,
+		Entries:   entries,
+		Balance:   balance,
+	}, nil
+}
+
+// PostAdjustment posts a manual correction to a booking's folio, e.g. a
+// goodwill credit (negative) or a minibar charge (positive).
+func (s *FolioService) PostAdjustment(ctx context.Context, bookingID, actorID int, amount domain.Money, description string) (*domain.FolioEntry, error) {
+	logger.Info("PostAdjustment called", zap.Int("BookingID", bookingID), zap.Int("ActorID", actorID), zap.Stringer("amount", amount))
+
+	description = strings.TrimSpace(description)
+	if amount.IsZero() {
+		return nil, errs.NewValidationError("adjustment amount must not be 0")
+	}
+	if description == "" {
+		return nil, errs.NewValidationError("adjustment description is required")
+	}
+	if len(description) > 255 {
+		return nil, errs.NewValidationError("adjustment description must be at most 255 characters")
+	}
+
+	booking, err := s.getBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.Status == domain.BookingStatusExpired {
+		return nil, errs.NewValidationError("cannot adjust an expired booking")
+	}
+
+	entry := &domain.FolioEntry{
+		BookingID:   bookingID,
+		EntryType:   domain.FolioEntryAdjustment,
+		Category:    domain.FolioCategoryOther,
+		Description: description,
+		Amount:      amount,
+		ActorUserID: actorID,
+	}
+	if err := s.repo.PostEntry(ctx, entry); err != nil {
+		logger.ErrorErr(err, "repo.PostEntry failed")
+		return nil, errs.NewUnexpectedError("failed to post adjustment")
+	}
+
+	logger.Info("folio adjustment posted", zap.Int("EntryID", entry.EntryID), zap.Int("BookingID", bookingID))
+	return entry, nil
+}
+
+func (s *FolioService) getBooking(ctx context.Context, bookingID int) (*domain.BookingDetail, error) {
+	if bookingID <= 0 {
+		return nil, errs.NewValidationError("invalid booking ID")
+	}
+
+	booking, err := s.bookingRepo.GetBookingWithAddons(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.NewNotFoundError("booking not found")
+		}
+		logger.ErrorErr(err, "repo.GetBookingWithAddons failed")
+		return nil, errs.NewUnexpectedError("failed to get booking")
+	}
+
+	return booking, nil
+}
+
+// bookingCharges itemises a new booking for its folio: one charge per night,
+// add-on line and tax, and the promo discount as a credit. They sum to the
+// booking's total price.
+func bookingCharges(booking *domain.Booking) []*domain.FolioEntry {
+	var entries []*domain.FolioEntry
+	add := func(entryType, category, description string, amount domain.Money) {
+		if amount.IsZero() {
+			return
+		}
+		entries = append(entries, &domain.FolioEntry{
+			EntryType:   entryType,
+			Category:    category,
+			Description: description,
+			Amount:      amount,
+			ActorUserID: booking.UserID,
+		})
+	}
+
+	if len(booking.Nights) > 0 {
+		for _, night := range booking.Nights {
+			add(domain.FolioEntryCharge, domain.FolioCategoryRoom, "Room night "+night.StayDate.Format("2006-01-02"), night.Price)
+		}
+	} else {
+		add(domain.FolioEntryCharge, domain.FolioCategoryRoom, "Room", booking.RoomSubTotal)
+	}
+
+	add(domain.FolioEntryAdjustment, domain.FolioCategoryDiscount, "Promo code "+booking.PromoCode, booking.DiscountAmount.Neg())
+
+	for _, a := range booking.BookingAddon {
+		add(domain.FolioEntryCharge, domain.FolioCategoryAddon, fmt.Sprintf("%s (%s)", a.AddonName, a.Breakdown()), a.Total())
+	}
+
+	for _, t := range booking.Taxes {
+		add(domain.FolioEntryCharge, domain.FolioCategoryTax, t.Name, t.Amount)
+	}
+
+	return entries
+}
+
+// cancellationEntries releases a cancelled booking's charges and charges the
+// cancellation fee in their place.
+func cancellationEntries(booking *domain.BookingDetail, fee domain.Money, actorID int) []*domain.FolioEntry {
+	var entries []*domain.FolioEntry
+	if booking.TotalPrice.IsPositive() {
+		entries = append(entries, &domain.FolioEntry{
+			EntryType:   domain.FolioEntryAdjustment,
+			Category:    domain.FolioCategoryCancellation,
+			Description: "Charges released on cancellation",
+			Amount:      booking.TotalPrice.Neg(),
+			ActorUserID: actorID,
+		})
+	}
+	if fee.IsPositive() {
+		entries = append(entries, &domain.FolioEntry{
+			EntryType:   domain.FolioEntryCharge,
+			Category:    domain.FolioCategoryCancellation,
+			Description: "Cancellation fee",
+			Amount:      fee,
+			ActorUserID: actorID,
+		})
+	}
+	return entries
+}
+
+// addonChangeEntries posts the difference between a booking's old and new
+// add-ons and taxes: a charge for each line that went up, a credit for each
+// line that went down.
+func addonChangeEntries(oldAddons, newAddons []*domain.BookingAddon, oldTaxes, newTaxes []*domain.BookingTax, actorID int) ([]*domain.FolioEntry, error) {
+	type addonLine struct {
+		name     string
+		oldQty   int
+		newQty   int
+		oldTotal domain.Money
+		newTotal domain.Money
+	}
+	lines := map[int]*addonLine{}
+	line := func(a *domain.BookingAddon) *addonLine {
+		l, ok := lines[a.AddonID]
+		if !ok {
+			l = &addonLine{oldTotal: domain.THB(0), newTotal: domain.THB(0)}
+			lines[a.AddonID] = l
+		}
+		if a.AddonName != "" {
+			l.name = a.AddonName
+		}
+		return l
+	}
+	var err error
+	for _, a := range oldAddons {
+		l := line(a)
+		l.oldQty += a.Quantity
+		if l.oldTotal, err = l.oldTotal.Add(a.Total()); err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range newAddons {
+		l := line(a)
+		l.newQty += a.Quantity
+		if l.newTotal, err = l.newTotal.Add(a.Total()); err != nil {
+			return nil, err
+		}
+	}
+
+	addonIDs := make([]int, 0, len(lines))
+	for id := range lines {
+		addonIDs = append(addonIDs, id)
+	}
+	sort.Ints(addonIDs)
+
+	var entries []*domain.FolioEntry
+	for _, id := range addonIDs {
+		l := lines[id]
+		delta, err := l.newTotal.Sub(l.oldTotal)
+		if err != nil {
+			return nil, err
+		}
+		entries = appendDelta(entries, domain.FolioCategoryAddon, fmt.Sprintf("%s x%d -> x%d", l.name, l.oldQty, l.newQty), delta, actorID)
+	}
+
+	taxEntries, err := taxChangeEntries(oldTaxes, newTaxes, actorID)
+	if err != nil {
+		return nil, err
+	}
+	return append(entries, taxEntries...), nil
+}
+
+// stayChangeEntries posts the difference between a booking's old and new
+// nights, discount and taxes when its stay is moved.
+func stayChangeEntries(old, updated *domain.BookingDetail, actorID int) ([]*domain.FolioEntry, error) {
+	prices := map[string]domain.Money{}
+	var dates []string
+	var err error
+	for _, n := range old.Nights {
+		d := n.StayDate.Format("2006-01-02")
+		if _, ok := prices[d]; !ok {
+			dates = append(dates, d)
+			prices[d] = domain.THB(0)
+		}
+		if prices[d], err = prices[d].Sub(n.Price); err != nil {
+			return nil, err
+		}
+	}
+	for _, n := range updated.Nights {
+		d := n.StayDate.Format("2006-01-02")
+		if _, ok := prices[d]; !ok {
+			dates = append(dates, d)
+			prices[d] = domain.THB(0)
+		}
+		if prices[d], err = prices[d].Add(n.Price); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(dates)
+
+	var entries []*domain.FolioEntry
+	if len(old.Nights) == 0 {
+		// Bookings from before nightly rates were stored only have a room total.
+		entries = appendDelta(entries, domain.FolioCategoryRoom, "Room charges released on stay change", old.RoomSubTotal.Neg(), actorID)
+	}
+	for _, d := range dates {
+		entries = appendDelta(entries, domain.FolioCategoryRoom, "Room night "+d, prices[d], actorID)
+	}
+
+	// Per-night addons are resized to the new stay; the rest keep their price.
+	oldAddons := make(map[int]*domain.BookingAddon, len(old.BookingAddon))
+	for _, a := range old.BookingAddon {
+		oldAddons[a.BookingAddonID] = a
+	}
+	for _, a := range updated.BookingAddon {
+		if prev, ok := oldAddons[a.BookingAddonID]; ok {
+			delta, err := a.Total().Sub(prev.Total())
+			if err != nil {
+				return nil, err
+			}
+			entries = appendDelta(entries, domain.FolioCategoryAddon, fmt.Sprintf("%s (%s)", a.AddonName, a.Breakdown()), delta, actorID)
+		}
+	}
+
+	discount, err := old.DiscountAmount.Sub(updated.DiscountAmount)
+	if err != nil {
+		return nil, err
+	}
+	entries = appendDelta(entries, domain.FolioCategoryDiscount, "Promo code "+updated.PromoCode, discount, actorID)
+
+	taxEntries, err := taxChangeEntries(old.Taxes, updated.Taxes, actorID)
+	if err != nil {
+		return nil, err
+	}
+	return append(entries, taxEntries...), nil
+}
+
+// taxChangeEntries posts the change in each tax line, matched by name.
+func taxChangeEntries(oldTaxes, newTaxes []*domain.BookingTax, actorID int) ([]*domain.FolioEntry, error) {
+	deltas := map[string]domain.Money{}
+	var names []string
+	var err error
+	for _, t := range oldTaxes {
+		if _, ok := deltas[t.Name]; !ok {
+			names = append(names, t.Name)
+			deltas[t.Name] = domain.THB(0)
+		}
+		if deltas[t.Name], err = deltas[t.Name].Sub(t.Amount); err != nil {
+			return nil, err
+		}
+	}
+	for _, t := range newTaxes {
+		if _, ok := deltas[t.Name]; !ok {
+			names = append(names, t.Name)
+			deltas[t.Name] = domain.THB(0)
+		}
+		if deltas[t.Name], err = deltas[t.Name].Add(t.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	var entries []*domain.FolioEntry
+	for _, name := range names {
+		entries = appendDelta(entries, domain.FolioCategoryTax, name, deltas[name], actorID)
+	}
+	return entries, nil
+}
+
+// appendDelta posts a change in what the guest owes: a charge when it went up,
+// a credit when it went down, and nothing when it did not move.
+func appendDelta(entries []*domain.FolioEntry, category, description string, delta domain.Money, actorID int) []*domain.FolioEntry {
+	if delta.IsZero() {
+		return entries
+	}
+	entryType := domain.FolioEntryCharge
+	if delta.IsNegative() {
+		entryType = domain.FolioEntryAdjustment
+	}
+	return append(entries, &domain.FolioEntry{
+		EntryType:   entryType,
+		Category:    category,
+		Description: description,
+		Amount:      delta,
+		ActorUserID: actorID,
+	})
+}
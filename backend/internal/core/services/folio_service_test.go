@@ -0,0 +1,169 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+)
+
+// pricedBooking prices a booking the way priceBooking does: two nights, a 10%
+// promo discount, the given add-ons, and a service charge with compound VAT.
+func pricedBooking(t *testing.T, addons []*domain.BookingAddon, withNights bool) *domain.Booking {
+	t.Helper()
+	checkIn := time.Date(2030, 3, 10, 0, 0, 0, 0, time.Local)
+	b := &domain.Booking{
+		UserID:         3,
+		CheckInDate:    checkIn,
+		CheckOutDate:   checkIn.AddDate(0, 0, 2),
+		NumAdults:      2,
+		RoomSubTotal:   domain.THB(220000),
+		PromoCode:      "SPRING10",
+		DiscountAmount: domain.THB(22000),
+		BookingAddon:   addons,
+	}
+	if withNights {
+		b.Nights = []*domain.NightlyRate{
+			{StayDate: checkIn, Price: domain.THB(100000)},
+			{StayDate: checkIn.AddDate(0, 0, 1), Price: domain.THB(120000)},
+		}
+	}
+	b.AddonSubTotal, b.Taxes, b.TaxesAmount, b.TotalPrice = priceCharges(t, b.RoomSubTotal, b.DiscountAmount, addons)
+	return b
+}
+
+func priceCharges(t *testing.T, roomSubTotal, discount domain.Money, addons []*domain.BookingAddon) (domain.Money, []*domain.BookingTax, domain.Money, domain.Money) {
+	t.Helper()
+	addonTotal := domain.THB(0)
+	for _, a := range addons {
+		addonTotal = mustMoney(t)(addonTotal.Add(a.Total()))
+	}
+	room := mustMoney(t)(roomSubTotal.Sub(discount))
+	rules := []*domain.TaxRule{
+		percentRule(1, 1, 10, domain.TaxAppliesToAll, false),
+		percentRule(2, 2, 7, domain.TaxAppliesToAll, true),
+	}
+	taxes, taxesAmount, err := computeTaxes(rules, taxableCharges{Room: room, Addon: addonTotal, Nights: 2, Guests: 2}, domain.RoundPerLine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addonTotal, taxes, taxesAmount, mustMoney(t)(domain.Sum(room, addonTotal, taxesAmount))
+}
+
+func mustMoney(t *testing.T) func(domain.Money, error) domain.Money {
+	return func(m domain.Money, err error) domain.Money {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+}
+
+func breakfast(qty int) *domain.BookingAddon {
+	return &domain.BookingAddon{AddonID: 1, AddonName: "Breakfast", Quantity: qty, PriceAtBooking: domain.THB(35000),
+		ChargeUnit: domain.AddonChargePerPersonNight, Nights: 2, Guests: 2}
+}
+
+func transfer() *domain.BookingAddon {
+	return &domain.BookingAddon{AddonID: 2, AddonName: "Airport transfer", Quantity: 1, PriceAtBooking: domain.THB(80000),
+		ChargeUnit: domain.AddonChargePerStay}
+}
+
+func folioBalance(t *testing.T, entries []*domain.FolioEntry) domain.Money {
+	t.Helper()
+	balance, err := domain.FolioBalance(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return balance
+}
+
+func TestBookingChargesSumToTotal(t *testing.T) {
+	tests := []struct {
+		name       string
+		addons     []*domain.BookingAddon
+		withNights bool
+		want       int64
+	}{
+		// 1980 room + 2200 add-ons + 418 service + 321.86 VAT.
+		{"nightly rates with add-ons", []*domain.BookingAddon{breakfast(1), transfer()}, true, 491986},
+		{"room total only", []*domain.BookingAddon{breakfast(1), transfer()}, false, 491986},
+		// 1980 room + 198 service + 152.46 VAT.
+		{"no add-ons", nil, true, 233046},
+	}
+
+	for _, tt := range tests {
+		b := pricedBooking(t, tt.addons, tt.withNights)
+		if b.TotalPrice != domain.THB(tt.want) {
+			t.Fatalf("%s: priced at %v, want %v", tt.name, b.TotalPrice, domain.THB(tt.want))
+		}
+		if got := folioBalance(t, bookingCharges(b)); got != b.TotalPrice {
+			t.Errorf("%s: folio balance = %v, want the total %v", tt.name, got, b.TotalPrice)
+		}
+	}
+}
+
+func TestAddonChangeKeepsFolioAtTotal(t *testing.T) {
+	b := pricedBooking(t, []*domain.BookingAddon{breakfast(1), transfer()}, true)
+	entries := bookingCharges(b)
+
+	tests := []struct {
+		name   string
+		addons []*domain.BookingAddon
+	}{
+		{"more breakfasts", []*domain.BookingAddon{breakfast(2), transfer()}},
+		{"transfer dropped", []*domain.BookingAddon{breakfast(2)}},
+		{"everything dropped", nil},
+		{"back to the original", []*domain.BookingAddon{breakfast(1), transfer()}},
+	}
+
+	oldAddons, oldTaxes := b.BookingAddon, b.Taxes
+	for _, tt := range tests {
+		_, taxes, _, total := priceCharges(t, b.RoomSubTotal, b.DiscountAmount, tt.addons)
+		change, err := addonChangeEntries(oldAddons, tt.addons, oldTaxes, taxes, 9)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		entries = append(entries, change...)
+		if got := folioBalance(t, entries); got != total {
+			t.Errorf("%s: folio balance = %v, want the new total %v", tt.name, got, total)
+		}
+		oldAddons, oldTaxes = tt.addons, taxes
+	}
+
+	if got := folioBalance(t, entries); got != b.TotalPrice {
+		t.Errorf("after changing back: folio balance = %v, want %v", got, b.TotalPrice)
+	}
+}
+
+func TestCancellationNetsToFee(t *testing.T) {
+	b := pricedBooking(t, []*domain.BookingAddon{breakfast(1), transfer()}, true)
+	detail := &domain.BookingDetail{BookingID: 7, TotalPrice: b.TotalPrice}
+	payment := &domain.FolioEntry{EntryType: domain.FolioEntryPayment, Category: domain.FolioCategoryPayment, Amount: b.TotalPrice.Neg()}
+
+	tests := []struct {
+		name string
+		paid bool
+		fee  int64
+		want int64
+	}{
+		{"free cancellation", false, 0, 0},
+		{"one night's fee", false, 100000, 100000},
+		{"full fee", false, 491986, 491986},
+		// Negative: the hotel owes the guest the rest of the payment.
+		{"paid, one night's fee", true, 100000, 100000 - 491986},
+		{"paid, full fee", true, 491986, 0},
+	}
+
+	for _, tt := range tests {
+		entries := bookingCharges(b)
+		if tt.paid {
+			entries = append(entries, payment)
+		}
+		entries = append(entries, cancellationEntries(detail, domain.THB(tt.fee), 9)...)
+		if got := folioBalance(t, entries); got != domain.THB(tt.want) {
+			t.Errorf("%s: folio balance = %v, want %v", tt.name, got, domain.THB(tt.want))
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/common/logger"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const maxIdempotencyKeyLength = 255
+
+type IdempotencyService struct {
+	repo ports.IdempotencyRepository
+}
+
+func NewIdempotencyService(repo ports.IdempotencyRepository) *IdempotencyService {
+	return &IdempotencyService{repo: repo}
+}
+
+// BeginRequest claims key for the user's request. It returns nil when the
+// request should run, or the stored response when it is a retry of a request
+// that already finished. Reusing a key for a different request, or while the
+// first one is still running, is a conflict. A request that has held its key
+// for longer than idempotency.processing_lease_seconds is taken to have died,
+// and a retry runs in its place.
+func (s *IdempotencyService) BeginRequest(ctx context.Context, userID int, key, requestHash string) (*domain.IdempotencyKey, error) {
+	logger.Info("BeginRequest called", zap.Int("UserID", userID), zap.String("Key", key))
+
+	key = strings.TrimSpace(key)
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return nil, errs.NewValidationError("idempotency key must be 1 to 255 characters")
+	}
+
+	ttl := time.Duration(viper.GetInt("idempotency.ttl_hours")) * time.Hour
+	lease := time.Duration(viper.GetInt("idempotency.processing_lease_seconds")) * time.Second
+	stored, created, err := s.repo.ReserveIdempotencyKey(ctx, &domain.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(ttl),
+		LockedUntil: time.Now().Add(lease),
+	})
+	if err != nil {
+		if errors.Is(err, errs.ErrConflict) {
+			return nil, errs.NewConflictError("a request with this idempotency key is still being processed")
+		}
+		logger.ErrorErr(err, "repo.ReserveIdempotencyKey failed", zap.Int("UserID", userID))
+		return nil, errs.NewUnexpectedError("failed to check idempotency key")
+	}
+	if created {
+		return nil, nil
+	}
+
+	if stored.RequestHash != requestHash {
+		logger.Warn("idempotency key reused with a different request", zap.Int("UserID", userID), zap.String("Key", key))
+		return nil, errs.NewConflictError("idempotency key was already used for a different request")
+	}
+	if stored.Status != domain.IdempotencyStatusCompleted {
+		return nil, errs.NewConflictError("a request with this idempotency key is still being processed")
+	}
+
+	logger.Info("replaying stored response", zap.Int("UserID", userID), zap.String("Key", key))
+	return stored, nil
+}
+
+// CompleteRequest stores the response of a request claimed by BeginRequest.
+func (s *IdempotencyService) CompleteRequest(ctx context.Context, userID int, key string, status int, contentType string, body []byte) error {
+	err := s.repo.CompleteIdempotencyKey(ctx, &domain.IdempotencyKey{
+		UserID:         userID,
+		Key:            strings.TrimSpace(key),
+		ResponseStatus: status,
+		ContentType:    contentType,
+		ResponseBody:   body,
+	})
+	if err != nil {
+		logger.ErrorErr(err, "repo.CompleteIdempotencyKey failed", zap.Int("UserID", userID))
+		return errs.NewUnexpectedError("failed to store idempotent response")
+	}
+	return nil
+}
+
+// ReleaseRequest frees a claimed key whose request failed unexpectedly, so a
+// retry runs the request again.
+func (s *IdempotencyService) ReleaseRequest(ctx context.Context, userID int, key string) error {
+	if err := s.repo.ReleaseIdempotencyKey(ctx, userID, strings.TrimSpace(key)); err != nil {
+		logger.ErrorErr(err, "repo.ReleaseIdempotencyKey failed", zap.Int("UserID", userID))
+		return errs.NewUnexpectedError("failed to release idempotency key")
+	}
+	return nil
+}
+
+func (s *IdempotencyService) CleanupExpiredKeys(ctx context.Context) (int64, error) {
+	logger.Info("CleanupExpiredKeys called")
+
+	rows, err := s.repo.DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		logger.ErrorErr(err, "CleanupExpiredKeys failed")
+		return 0, err
+	}
+	logger.Info("expired idempotency keys cleaned up", zap.Int64("rowsAffected", rows))
+	return rows, nil
+}
@@ -0,0 +1,593 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/common/logger"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"go.uber.org/zap"
+)
+
+type PaymentService struct {
+	repo         ports.PaymentRepository
+	bookingRepo  ports.BookingRepository
+	rateplanRepo ports.RatePlanRepository
+	gateway      ports.PaymentGateway
+	emailRepo    ports.EmailRepository
+}
+
+func NewPaymentService(repo ports.PaymentRepository, bookingRepo ports.BookingRepository, rateplanRepo ports.RatePlanRepository, gateway ports.PaymentGateway, emailRepo ports.EmailRepository) *PaymentService {
+	return &PaymentService{
+		repo:         repo,
+		bookingRepo:  bookingRepo,
+		rateplanRepo: rateplanRepo,
+		gateway:      gateway,
+		emailRepo:    emailRepo,
+	}
+}
+
+// manualPaymentMethods are the ways staff can take a payment at the front desk.
+var manualPaymentMethods = map[string]bool{
+	"cash":          true,
+	"card":          true,
+	"bank_transfer": true,
+}
+
+// PayBooking starts the payment that confirms a pending booking: the full total,
+// or on pay-later plans the deposit or a zero-amount card guarantee. The booking
+// stays pending until the provider's webhook reports the result.
+func (s *PaymentService) PayBooking(ctx context.Context, bookingID int, paymentMethod string) (*domain.Payment, *domain.PaymentIntent, error) {
+	logger.Info("PayBooking called", zap.Int("BookingID", bookingID), zap.String("provider", s.gateway.Name()))
+
+	booking, err := s.getBooking(ctx, bookingID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if booking.Status != domain.BookingStatusPending {
+		return nil, nil, errs.NewValidationError("booking is not awaiting payment")
+	}
+	if !booking.ExpiredAt.IsZero() && time.Now().After(booking.ExpiredAt) {
+		return nil, nil, errs.NewValidationError("payment window for this booking has expired")
+	}
+
+	rp, err := s.rateplanRepo.GetRatePlanByID(ctx, booking.RatePlanID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, nil, errs.NewNotFoundError("rate plan not found")
+		}
+		logger.ErrorErr(err, "repo.GetRatePlanByID failed")
+		return nil, nil, errs.NewUnexpectedError("failed to get rate plan")
+	}
+
+	payment := &domain.Payment{BookingID: booking.BookingID, Amount: rp.AmountDueNow(booking.TotalPrice)}
+	return s.startPayment(ctx, payment, booking.Email, paymentMethod)
+}
+
+// PayBalance starts a payment of part or all of a confirmed booking's balance.
+func (s *PaymentService) PayBalance(ctx context.Context, bookingID int, amount domain.Money, paymentMethod string) (*domain.Payment, *domain.PaymentIntent, error) {
+	logger.Info("PayBalance called", zap.Int("BookingID", bookingID), zap.Stringer("amount", amount))
+
+	booking, err := s.getBooking(ctx, bookingID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if booking.Status != domain.BookingStatusConfirmed && booking.Status != domain.BookingStatusCheckedIn {
+		return nil, nil, errs.NewValidationError("only confirmed bookings have a balance to pay")
+	}
+	if err := validatePartialAmount(amount, booking); err != nil {
+		return nil, nil, err
+	}
+
+	payment := &domain.Payment{BookingID: booking.BookingID, Amount: amount}
+	return s.startPayment(ctx, payment, booking.Email, paymentMethod)
+}
+
+// PayReservation starts one payment that confirms every pending booking of a
+// reservation, covering what each of them needs to be confirmed.
+func (s *PaymentService) PayReservation(ctx context.Context, reservationID int, paymentMethod string) (*domain.Payment, *domain.PaymentIntent, error) {
+	logger.Info("PayReservation called", zap.Int("ReservationID", reservationID), zap.String("provider", s.gateway.Name()))
+
+	if reservationID <= 0 {
+		return nil, nil, errs.NewValidationError("invalid reservation ID")
+	}
+
+	res, err := s.bookingRepo.GetReservation(ctx, reservationID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, nil, errs.NewNotFoundError("reservation not found")
+		}
+		logger.ErrorErr(err, "repo.GetReservation failed")
+		return nil, nil, errs.NewUnexpectedError("failed to get reservation")
+	}
+
+	amount := domain.THB(0)
+	pending := 0
+	for _, booking := range res.Bookings {
+		if booking.Status != domain.BookingStatusPending {
+			continue
+		}
+		if !booking.ExpiredAt.IsZero() && time.Now().After(booking.ExpiredAt) {
+			return nil, nil, errs.NewValidationError("payment window for this reservation has expired")
+		}
+
+		rp, err := s.rateplanRepo.GetRatePlanByID(ctx, booking.RatePlanID)
+		if err != nil {
+			logger.ErrorErr(err, "repo.GetRatePlanByID failed")
+			return nil, nil, errs.NewUnexpectedError("failed to get rate plan")
+		}
+		if amount, err = amount.Add(rp.AmountDueNow(booking.TotalPrice)); err != nil {
+			return nil, nil, moneyError(err)
+		}
+		pending++
+	}
+	if pending == 0 {
+		return nil, nil, errs.NewValidationError("reservation is not awaiting payment")
+	}
+
+	payment := &domain.Payment{ReservationID: reservationID, Amount: amount}
+	return s.startPayment(ctx, payment, res.Email, paymentMethod)
+}
+
+// RecordPayment records a payment taken by staff at the front desk. It is
+// credited right away and confirms a pending booking once it covers the amount
+// due at booking.
+func (s *PaymentService) RecordPayment(ctx context.Context, bookingID, actorID int, amount domain.Money, method, reference string) (*domain.Payment, error) {
+	logger.Info("RecordPayment called",
+		zap.Int("BookingID", bookingID),
+		zap.Int("ActorID", actorID),
+		zap.Stringer("amount", amount),
+		zap.String("method", method),
+	)
+
+	if !manualPaymentMethods[method] {
+		return nil, errs.NewValidationError("payment method must be cash, card or bank_transfer")
+	}
+
+	booking, err := s.getBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch booking.Status {
+	case domain.BookingStatusPending:
+		rp, err := s.rateplanRepo.GetRatePlanByID(ctx, booking.RatePlanID)
+		if err != nil {
+			logger.ErrorErr(err, "repo.GetRatePlanByID failed")
+			return nil, errs.NewUnexpectedError("failed to get rate plan")
+		}
+		short, err := amount.LessThan(rp.AmountDueNow(booking.TotalPrice))
+		if err != nil {
+			return nil, moneyError(err)
+		}
+		if short {
+			return nil, errs.NewValidationError("payment does not cover the amount due to confirm the booking")
+		}
+	case domain.BookingStatusConfirmed, domain.BookingStatusCheckedIn:
+	default:
+		return nil, errs.NewValidationError(fmt.Sprintf("cannot take payments for a %s booking", booking.Status))
+	}
+	if err := validatePartialAmount(amount, booking); err != nil {
+		return nil, err
+	}
+
+	if reference == "" {
+		reference, err = manualReference()
+		if err != nil {
+			logger.ErrorErr(err, "manualReference failed")
+			return nil, errs.NewUnexpectedError("failed to record payment")
+		}
+	}
+
+	payment := &domain.Payment{
+		BookingID:   bookingID,
+		Provider:    method,
+		ProviderRef: reference,
+		Amount:      amount,
+		Status:      domain.PaymentStatusSucceeded,
+		RecordedBy:  actorID,
+	}
+	_, confirmed, err := s.repo.RecordPayment(ctx, payment, "payment recorded at front desk")
+	if err != nil {
+		if errors.Is(err, errs.ErrConflict) {
+			return nil, errs.NewConflictError("a payment with this reference is already recorded")
+		}
+		logger.ErrorErr(err, "repo.RecordPayment failed")
+		return nil, errs.NewUnexpectedError("failed to record payment")
+	}
+
+	if confirmed {
+		s.sendConfirmation(bookingID)
+	}
+
+	logger.Info("payment recorded", zap.Int("PaymentID", payment.PaymentID), zap.Int("BookingID", bookingID))
+	return payment, nil
+}
+
+func (s *PaymentService) getBooking(ctx context.Context, bookingID int) (*domain.BookingDetail, error) {
+	if bookingID <= 0 {
+		return nil, errs.NewValidationError("invalid booking ID")
+	}
+
+	booking, err := s.bookingRepo.GetBookingWithAddons(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("booking not found", zap.Int("BookingID", bookingID))
+			return nil, errs.NewNotFoundError("booking not found")
+		}
+		logger.ErrorErr(err, "repo.GetBookingWithAddons failed")
+		return nil, errs.NewUnexpectedError("failed to get booking")
+	}
+
+	return booking, nil
+}
+
+// startPayment creates and captures a provider payment for a booking, or for a
+// whole reservation when payment.ReservationID is set. Only one provider
+// payment per booking may be in flight at a time; the early check saves a
+// gateway call and CreatePayment settles races.
+func (s *PaymentService) startPayment(ctx context.Context, payment *domain.Payment, email, paymentMethod string) (*domain.Payment, *domain.PaymentIntent, error) {
+	var payments []*domain.Payment
+	var err error
+	if payment.ReservationID > 0 {
+		payments, err = s.repo.GetPaymentsByReservationID(ctx, payment.ReservationID)
+	} else {
+		payments, err = s.repo.GetPaymentsByBookingID(ctx, payment.BookingID)
+	}
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetPayments failed")
+		return nil, nil, errs.NewUnexpectedError("failed to check payments")
+	}
+	for _, p := range payments {
+		if p.Status == domain.PaymentStatusPending {
+			return nil, nil, errs.NewConflictError("a payment for this booking is already in progress")
+		}
+	}
+
+	intent, err := s.gateway.CreateIntent(ctx, &domain.PaymentIntentRequest{
+		BookingID:     payment.BookingID,
+		ReservationID: payment.ReservationID,
+		Amount:        payment.Amount,
+		Email:         email,
+		PaymentMethod: paymentMethod,
+	})
+	if err != nil {
+		logger.ErrorErr(err, "gateway.CreateIntent failed")
+		return nil, nil, errs.NewUnexpectedError("failed to start payment")
+	}
+
+	payment.Provider = s.gateway.Name()
+	payment.ProviderRef = intent.ProviderRef
+	payment.Status = domain.PaymentStatusPending
+	if err := s.repo.CreatePayment(ctx, payment); err != nil {
+		// The intent is never captured, so losing the race charges nothing.
+		if errors.Is(err, errs.ErrConflict) {
+			return nil, nil, errs.NewConflictError("a payment for this booking is already in progress")
+		}
+		logger.ErrorErr(err, "repo.CreatePayment failed")
+		return nil, nil, errs.NewUnexpectedError("failed to record payment")
+	}
+
+	if err := s.gateway.Capture(ctx, intent.ProviderRef); err != nil {
+		logger.ErrorErr(err, "gateway.Capture failed", zap.String("ref", intent.ProviderRef))
+		if updErr := s.repo.UpdatePaymentStatus(ctx, payment.PaymentID, domain.PaymentStatusPending, domain.PaymentStatusFailed, "capture failed"); updErr != nil {
+			logger.ErrorErr(updErr, "repo.UpdatePaymentStatus failed")
+		}
+		return nil, nil, errs.NewUnexpectedError("failed to capture payment")
+	}
+
+	logger.Info("payment started", zap.Int("PaymentID", payment.PaymentID), zap.String("ref", payment.ProviderRef))
+	return payment, intent, nil
+}
+
+// HandleWebhook applies a provider notification. Events are matched to payments
+// by provider reference and only move a payment forward, so redelivered events
+// are harmless.
+func (s *PaymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	event, err := s.gateway.VerifyWebhook(payload, signature)
+	if err != nil {
+		logger.Warn("webhook verification failed", zap.Error(err))
+		return errs.NewUnauthorizedError("invalid webhook signature")
+	}
+
+	logger.Info("HandleWebhook called", zap.String("type", event.Type), zap.String("ref", event.ProviderRef))
+
+	payment, err := s.repo.GetPaymentByProviderRef(ctx, s.gateway.Name(), event.ProviderRef)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("webhook for unknown payment", zap.String("ref", event.ProviderRef))
+			return errs.NewNotFoundError("payment not found")
+		}
+		logger.ErrorErr(err, "repo.GetPaymentByProviderRef failed")
+		return errs.NewUnexpectedError("failed to get payment")
+	}
+
+	switch event.Type {
+	case domain.PaymentEventSucceeded:
+		return s.paymentSucceeded(ctx, payment, event)
+	case domain.PaymentEventFailed:
+		return s.moveStatus(ctx, payment, domain.PaymentStatusPending, domain.PaymentStatusFailed, event.FailureReason)
+	case domain.PaymentEventRefunded:
+		// Refunds are posted to the folio when they are issued, so the
+		// provider's notice only confirms what is already recorded.
+		logger.Info("refund confirmed by provider", zap.Int("PaymentID", payment.PaymentID), zap.Stringer("amount", event.Amount))
+		return nil
+	default:
+		logger.Warn("ignoring unknown webhook event", zap.String("type", event.Type))
+		return nil
+	}
+}
+
+func (s *PaymentService) paymentSucceeded(ctx context.Context, payment *domain.Payment, event *domain.PaymentEvent) error {
+	if payment.Status != domain.PaymentStatusPending {
+		logger.Info("payment already settled", zap.Int("PaymentID", payment.PaymentID), zap.String("status", payment.Status))
+		return nil
+	}
+	if event.Amount != payment.Amount {
+		logger.Warn("webhook amount does not match payment",
+			zap.Int("PaymentID", payment.PaymentID),
+			zap.Stringer("expected", payment.Amount),
+			zap.Stringer("received", event.Amount),
+		)
+		return errs.NewValidationError("payment amount mismatch")
+	}
+
+	previous, confirmed, err := s.repo.ConfirmPayment(ctx, payment.PaymentID, "payment received")
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Info("payment settled concurrently", zap.Int("PaymentID", payment.PaymentID))
+			return nil
+		}
+		logger.ErrorErr(err, "repo.ConfirmPayment failed")
+		return errs.NewUnexpectedError("failed to confirm payment")
+	}
+
+	switch {
+	case confirmed:
+		logger.Info("booking confirmed by payment",
+			zap.Int("BookingID", payment.BookingID), zap.Int("ReservationID", payment.ReservationID), zap.Int("PaymentID", payment.PaymentID))
+		s.sendPaymentConfirmation(payment)
+	case previous == domain.BookingStatusPending:
+		// The booking was repriced while the payment was in flight.
+		logger.Warn("payment does not cover the amount due, booking left pending",
+			zap.Int("BookingID", payment.BookingID), zap.Int("ReservationID", payment.ReservationID), zap.Int("PaymentID", payment.PaymentID))
+	case previous == domain.BookingStatusConfirmed, previous == domain.BookingStatusCheckedIn:
+		logger.Info("payment credited to booking",
+			zap.Int("BookingID", payment.BookingID), zap.Int("ReservationID", payment.ReservationID), zap.Int("PaymentID", payment.PaymentID))
+	default:
+		// The booking expired or was cancelled while the payment was in flight.
+		logger.Warn("payment received for a booking that no longer takes payments, refunding",
+			zap.Int("BookingID", payment.BookingID), zap.Int("ReservationID", payment.ReservationID), zap.Int("PaymentID", payment.PaymentID))
+		if _, err := s.refund(ctx, payment, payment.BookingID, 0, payment.Amount, "Refund: booking no longer takes payments"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RefundPayment gives part or all of a succeeded payment back to the guest.
+// Only money the folio shows the guest is owed can be refunded; anything else
+// needs an adjustment first.
+func (s *PaymentService) RefundPayment(ctx context.Context, bookingID, actorID, paymentID int, amount domain.Money, reason string) (*domain.FolioEntry, error) {
+	logger.Info("RefundPayment called",
+		zap.Int("BookingID", bookingID),
+		zap.Int("ActorID", actorID),
+		zap.Int("PaymentID", paymentID),
+		zap.Stringer("amount", amount),
+	)
+
+	if !amount.IsPositive() {
+		return nil, errs.NewValidationError("refund amount must be greater than 0")
+	}
+
+	booking, err := s.getBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := s.repo.GetPaymentsByBookingID(ctx, bookingID)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetPaymentsByBookingID failed")
+		return nil, errs.NewUnexpectedError("failed to get payments")
+	}
+	var payment *domain.Payment
+	for _, p := range payments {
+		if p.PaymentID == paymentID {
+			payment = p
+		}
+	}
+	if payment == nil {
+		return nil, errs.NewNotFoundError("payment not found")
+	}
+	if payment.Status != domain.PaymentStatusSucceeded {
+		return nil, errs.NewValidationError(fmt.Sprintf("cannot refund a %s payment", payment.Status))
+	}
+
+	refunded, err := s.repo.GetRefundedAmount(ctx, paymentID)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetRefundedAmount failed")
+		return nil, errs.NewUnexpectedError("failed to check refunds")
+	}
+	left, err := payment.Amount.Sub(refunded)
+	if err != nil {
+		return nil, moneyError(err)
+	}
+	over, err := amount.GreaterThan(left)
+	if err != nil {
+		return nil, moneyError(err)
+	}
+	if over {
+		return nil, errs.NewValidationError(fmt.Sprintf("only %s of this payment is left to refund", left.Display()))
+	}
+
+	credit := booking.Balance.Neg()
+	if over, err = amount.GreaterThan(credit); err != nil {
+		return nil, moneyError(err)
+	}
+	if over {
+		return nil, errs.NewValidationError(fmt.Sprintf("refund exceeds the guest's credit of %s; post an adjustment first", credit.Display()))
+	}
+
+	if strings.TrimSpace(reason) == "" {
+		reason = "Refund via " + payment.Provider
+	}
+	return s.refund(ctx, payment, bookingID, actorID, amount, strings.TrimSpace(reason))
+}
+
+// refund returns money through the provider that took it and posts the refund
+// to bookingID's folio, or for a reservation payment with no bookingID to the
+// bookings it was credited to. Front desk payments were taken by hand, so they
+// are refunded by hand and only need posting.
+//
+// The amount is reserved against the payment before the provider is called,
+// so two refunds racing each other cannot both be sent.
+func (s *PaymentService) refund(ctx context.Context, payment *domain.Payment, bookingID, actorID int, amount domain.Money, description string) (*domain.FolioEntry, error) {
+	refundID, err := s.repo.ReserveRefund(ctx, payment.PaymentID, amount)
+	if err != nil {
+		if errors.Is(err, errs.ErrConflict) {
+			return nil, errs.NewConflictError("payment was refunded concurrently")
+		}
+		logger.ErrorErr(err, "repo.ReserveRefund failed", zap.Int("PaymentID", payment.PaymentID))
+		return nil, errs.NewUnexpectedError("failed to refund payment")
+	}
+
+	if payment.Provider == s.gateway.Name() {
+		if err := s.gateway.Refund(ctx, payment.ProviderRef, amount); err != nil {
+			logger.ErrorErr(err, "gateway.Refund failed", zap.String("ref", payment.ProviderRef))
+			if relErr := s.repo.ReleaseRefund(ctx, refundID, err.Error()); relErr != nil {
+				logger.ErrorErr(relErr, "repo.ReleaseRefund failed", zap.Int("RefundID", refundID))
+			}
+			return nil, errs.NewUnexpectedError("failed to refund payment")
+		}
+	}
+
+	entry := &domain.FolioEntry{
+		BookingID:   bookingID,
+		EntryType:   domain.FolioEntryRefund,
+		Category:    domain.FolioCategoryPayment,
+		Description: description,
+		Amount:      amount,
+		PaymentID:   payment.PaymentID,
+		ActorUserID: actorID,
+	}
+	if err := s.repo.RecordRefund(ctx, refundID, entry); err != nil {
+		// The provider has already sent the money back and the refund stays
+		// pending, so this needs reconciling by hand.
+		logger.ErrorErr(err, "repo.RecordRefund failed after refund was issued",
+			zap.Int("RefundID", refundID), zap.Int("PaymentID", payment.PaymentID),
+			zap.String("ref", payment.ProviderRef), zap.Stringer("amount", amount))
+		return nil, errs.NewUnexpectedError("refund issued but could not be recorded")
+	}
+
+	logger.Info("refund recorded", zap.Int("PaymentID", payment.PaymentID), zap.Stringer("amount", amount))
+	return entry, nil
+}
+
+func (s *PaymentService) sendPaymentConfirmation(payment *domain.Payment) {
+	if payment.ReservationID == 0 {
+		s.sendConfirmation(payment.BookingID)
+		return
+	}
+
+	go func() {
+		res, dbErr := s.bookingRepo.GetReservation(context.Background(), payment.ReservationID)
+		if dbErr != nil {
+			logger.ErrorErr(dbErr, "failed to fetch reservation for email")
+			return
+		}
+		if emailErr := s.emailRepo.SendReservationConfirmation(context.Background(), res); emailErr != nil {
+			logger.ErrorErr(emailErr, "failed to send reservation confirmation email")
+		} else {
+			logger.Info("reservation confirmation email sent (payment success)", zap.String("email", res.Email))
+		}
+	}()
+}
+
+func (s *PaymentService) sendConfirmation(bookingID int) {
+	go func() {
+		details, dbErr := s.bookingRepo.GetBookingWithAddons(context.Background(), bookingID)
+		if dbErr != nil {
+			logger.ErrorErr(dbErr, "failed to fetch booking for email")
+			return
+		}
+		if emailErr := s.emailRepo.SendBookingConfirmation(context.Background(), details, details.BookingAddon); emailErr != nil {
+			logger.ErrorErr(emailErr, "failed to send confirmation email")
+		} else {
+			logger.Info("confirmation email sent (payment success)", zap.String("email", details.Email))
+		}
+	}()
+}
+
+func (s *PaymentService) moveStatus(ctx context.Context, payment *domain.Payment, from, to, reason string) error {
+	if payment.Status != from {
+		logger.Info("ignoring payment event", zap.Int("PaymentID", payment.PaymentID), zap.String("status", payment.Status), zap.String("to", to))
+		return nil
+	}
+
+	err := s.repo.UpdatePaymentStatus(ctx, payment.PaymentID, from, to, reason)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Info("payment settled concurrently", zap.Int("PaymentID", payment.PaymentID))
+			return nil
+		}
+		logger.ErrorErr(err, "repo.UpdatePaymentStatus failed")
+		return errs.NewUnexpectedError("failed to update payment")
+	}
+
+	logger.Info("payment status updated", zap.Int("PaymentID", payment.PaymentID), zap.String("status", to))
+	return nil
+}
+
+func (s *PaymentService) GetBookingPayments(ctx context.Context, bookingID int) ([]*domain.Payment, error) {
+	logger.Info("GetBookingPayments called", zap.Int("BookingID", bookingID))
+
+	if bookingID <= 0 {
+		return nil, errs.NewValidationError("invalid booking ID")
+	}
+
+	payments, err := s.repo.GetPaymentsByBookingID(ctx, bookingID)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetPaymentsByBookingID failed")
+		return nil, errs.NewUnexpectedError("failed to retrieve payments")
+	}
+
+	return payments, nil
+}
+
+// needsPaymentToConfirm reports whether bookings on the plan wait for a payment
+// or card guarantee before they are confirmed.
+func needsPaymentToConfirm(rp *domain.RatePlan) bool {
+	return !rp.AllowPayLater || rp.DepositPercent > 0 || rp.RequiresGuarantee
+}
+
+func validatePartialAmount(amount domain.Money, booking *domain.BookingDetail) error {
+	if !amount.IsPositive() {
+		return errs.NewValidationError("payment amount must be greater than 0")
+	}
+	over, err := amount.GreaterThan(booking.BalanceDue())
+	if err != nil {
+		return moneyError(err)
+	}
+	if over {
+		return errs.NewValidationError(fmt.Sprintf("payment exceeds the balance due of %s", booking.BalanceDue().Display()))
+	}
+	return nil
+}
+
+func manualReference() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "manual_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/common/logger"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/ingwrok/hotelBooking/internal/core/utils"
+	"go.uber.org/zap"
+)
+
+type PromotionService struct {
+	repo ports.PromotionRepository
+}
+
+func NewPromotionService(repo ports.PromotionRepository) *PromotionService {
+	return &PromotionService{repo: repo}
+}
+
+func (s *PromotionService) AddPromotion(ctx context.Context, promo *domain.Promotion) (*domain.Promotion, error) {
+	logger.Info("AddPromotion called", zap.String("Code", promo.Code))
+
+	promo.Code = normalizePromoCode(promo.Code)
+	if err := validatePromotion(promo); err != nil {
+		logger.Warn("validation failed", zap.Error(err))
+		return nil, err
+	}
+
+	err := s.repo.CreatePromotion(ctx, promo)
+	if err != nil {
+		if errors.Is(err, errs.ErrConflict) {
+			logger.Warn("duplicate promo code", zap.String("Code", promo.Code))
+			return nil, errs.NewConflictError("promo code already exists")
+		}
+		logger.ErrorErr(err, "repo.CreatePromotion failed")
+		return nil, errs.NewUnexpectedError("failed to create promotion")
+	}
+
+	logger.Info("promotion created successfully", zap.Int("PromotionID", promo.PromotionID))
+	return promo, nil
+}
+
+func (s *PromotionService) ChangePromotion(ctx context.Context, promo *domain.Promotion) error {
+	logger.Info("ChangePromotion called", zap.Int("PromotionID", promo.PromotionID))
+
+	if promo.PromotionID <= 0 {
+		return errs.NewValidationError("invalid promotion ID")
+	}
+	promo.Code = normalizePromoCode(promo.Code)
+	if err := validatePromotion(promo); err != nil {
+		logger.Warn("validation failed", zap.Error(err))
+		return err
+	}
+
+	err := s.repo.UpdatePromotion(ctx, promo)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("promotion not found", zap.Int("PromotionID", promo.PromotionID))
+			return errs.NewNotFoundError("promotion not found")
+		}
+		if errors.Is(err, errs.ErrConflict) {
+			logger.Warn("duplicate promo code", zap.String("Code", promo.Code))
+			return errs.NewConflictError("promo code already exists")
+		}
+		logger.ErrorErr(err, "repo.UpdatePromotion failed")
+		return errs.NewUnexpectedError("failed to update promotion")
+	}
+
+	logger.Info("promotion updated successfully", zap.Int("PromotionID", promo.PromotionID))
+	return nil
+}
+
+func (s *PromotionService) RemovePromotion(ctx context.Context, promotionID int) error {
+	logger.Info("RemovePromotion called", zap.Int("PromotionID", promotionID))
+
+	err := s.repo.DeletePromotion(ctx, promotionID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("promotion not found", zap.Int("PromotionID", promotionID))
+			return errs.NewNotFoundError("promotion not found")
+		}
+		logger.ErrorErr(err, "repo.DeletePromotion failed")
+		return errs.NewUnexpectedError("failed to delete promotion")
+	}
+
+	logger.Info("promotion deleted successfully", zap.Int("PromotionID", promotionID))
+	return nil
+}
+
+func (s *PromotionService) GetPromotion(ctx context.Context, promotionID int) (*domain.Promotion, error) {
+	logger.Info("GetPromotion called", zap.Int("PromotionID", promotionID))
+
+	if promotionID <= 0 {
+		return nil, errs.NewValidationError("invalid promotion ID")
+	}
+
+	promo, err := s.repo.GetPromotionByID(ctx, promotionID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("promotion not found", zap.Int("PromotionID", promotionID))
+			return nil, errs.NewNotFoundError("promotion not found")
+		}
+		logger.ErrorErr(err, "repo.GetPromotionByID failed")
+		return nil, errs.NewUnexpectedError("failed to get promotion")
+	}
+
+	return promo, nil
+}
+
+func (s *PromotionService) ListPromotions(ctx context.Context) ([]*domain.Promotion, error) {
+	logger.Info("ListPromotions called")
+
+	promos, err := s.repo.GetAllPromotions(ctx)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetAllPromotions failed")
+		return nil, errs.NewUnexpectedError("failed to retrieve promotions")
+	}
+
+	logger.Debug("promotion list returned", zap.Int("count", len(promos)))
+	return promos, nil
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func validatePromotion(promo *domain.Promotion) error {
+	if promo.Code == "" {
+		return errs.NewValidationError("promo code is required")
+	}
+	switch promo.DiscountType {
+	case domain.DiscountTypePercent:
+		if promo.DiscountRate <= 0 || promo.DiscountRate > 100 {
+			return errs.NewValidationError("percentage discount must be between 0 and 100")
+		}
+		promo.DiscountAmount = domain.THB(0)
+	case domain.DiscountTypeFixed:
+		if !promo.DiscountAmount.IsPositive() {
+			return errs.NewValidationError("fixed discount must be greater than 0")
+		}
+		promo.DiscountRate = 0
+	default:
+		return errs.NewValidationError("discount type must be percent or fixed")
+	}
+	if promo.MinSpend.IsNegative() {
+		return errs.NewValidationError("minimum spend cannot be negative")
+	}
+	if promo.MaxRedemptions < 0 || promo.MaxPerUser < 0 {
+		return errs.NewValidationError("redemption limits cannot be negative")
+	}
+	if !promo.BookingStart.IsZero() && !promo.BookingEnd.IsZero() && promo.BookingStart.After(promo.BookingEnd) {
+		return errs.NewValidationError("booking window start must be before its end")
+	}
+	if !promo.StayStart.IsZero() && !promo.StayEnd.IsZero() && promo.StayStart.After(promo.StayEnd) {
+		return errs.NewValidationError("stay window start must be before its end")
+	}
+	return nil
+}
+
+// promotionDiscount checks the promotion against the booking and returns the discount
+// on the room charge. Addons count towards the minimum spend but are never discounted.
+// Caps are only checked here for a friendly message; the booking transaction enforces them.
+func promotionDiscount(promo *domain.Promotion, booking *domain.Booking, roomTypeID int, now time.Time) (domain.Money, error) {
+	if !promo.IsActive {
+		return domain.Money{}, errs.NewValidationError("promo code is not active")
+	}
+	if !promo.BookingStart.IsZero() && now.Before(promo.BookingStart) {
+		return domain.Money{}, errs.NewValidationError("promo code is not valid yet")
+	}
+	if !promo.BookingEnd.IsZero() && now.After(promo.BookingEnd) {
+		return domain.Money{}, errs.NewValidationError("promo code has expired")
+	}
+	if promo.MaxRedemptions > 0 && promo.Redemptions >= promo.MaxRedemptions {
+		return domain.Money{}, errs.NewValidationError("promo code has been fully redeemed")
+	}
+
+	return stayDiscount(promo, booking, roomTypeID)
+}
+
+// stayDiscount checks the promotion's stay conditions and returns the discount.
+// A booking that already redeemed the promotion only has to keep meeting these
+// when its stay changes.
+func stayDiscount(promo *domain.Promotion, booking *domain.Booking, roomTypeID int) (domain.Money, error) {
+	firstNight := utils.DateOnly(booking.CheckInDate)
+	lastNight := utils.DateOnly(booking.CheckOutDate).AddDate(0, 0, -1)
+	if !promo.StayStart.IsZero() && firstNight.Before(utils.DateOnly(promo.StayStart)) {
+		return domain.Money{}, errs.NewValidationError("promo code is not valid for these stay dates")
+	}
+	if !promo.StayEnd.IsZero() && lastNight.After(utils.DateOnly(promo.StayEnd)) {
+		return domain.Money{}, errs.NewValidationError("promo code is not valid for these stay dates")
+	}
+
+	if len(promo.RoomTypeIDs) > 0 && !slices.Contains(promo.RoomTypeIDs, roomTypeID) {
+		return domain.Money{}, errs.NewValidationError("promo code is not valid for this room type")
+	}
+	if len(promo.RatePlanIDs) > 0 && !slices.Contains(promo.RatePlanIDs, booking.RatePlanID) {
+		return domain.Money{}, errs.NewValidationError("promo code is not valid for this rate plan")
+	}
+
+	spend, err := booking.RoomSubTotal.Add(booking.AddonSubTotal)
+	if err != nil {
+		return domain.Money{}, moneyError(err)
+	}
+	short, err := spend.LessThan(promo.MinSpend)
+	if err != nil {
+		return domain.Money{}, moneyError(err)
+	}
+	if short {
+		return domain.Money{}, errs.NewValidationError("booking does not reach the promo code minimum spend")
+	}
+
+	discount := promo.DiscountAmount
+	if promo.DiscountType == domain.DiscountTypePercent {
+		discount = booking.RoomSubTotal.MulPercent(promo.DiscountRate)
+	}
+
+	discount, err = discount.Min(booking.RoomSubTotal)
+	if err != nil {
+		return domain.Money{}, moneyError(err)
+	}
+	return discount, nil
+}
@@ -3,6 +3,9 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"time"
 
 	"github.com/ingwrok/hotelBooking/internal/common/errs"
 	"github.com/ingwrok/hotelBooking/internal/common/logger"
@@ -28,6 +31,10 @@ func (s *RatePlanService) AddRatePlan(ctx context.Context, rp *domain.RatePlan)
 		logger.Warn("validation failed: missing rate plan name or description")
 		return nil, errs.NewValidationError("rate plan name and description is required")
 	}
+	if err := validatePaymentTerms(rp); err != nil {
+		logger.Warn("validation failed", zap.Error(err))
+		return nil, err
+	}
 
 	err := s.repo.CreateRatePlan(ctx, rp)
 	if err != nil {
@@ -51,6 +58,10 @@ func (s *RatePlanService) ChangeRatePlan(ctx context.Context, rp *domain.RatePla
 	if rp.Name == "" {
 		return errs.NewValidationError("rate plan name is required")
 	}
+	if err := validatePaymentTerms(rp); err != nil {
+		logger.Warn("validation failed", zap.Error(err))
+		return err
+	}
 
 	err := s.repo.UpdateRatePlan(ctx, rp)
 	if err != nil {
@@ -110,7 +121,7 @@ func (s *RatePlanService) ListRatePlans(ctx context.Context) ([]*domain.RatePlan
 	return rps, nil
 }
 
-func (s *RatePlanService) ChangeRoomTypePrice(ctx context.Context, roomTypeID, ratePlanID int, price float64) error {
+func (s *RatePlanService) ChangeRoomTypePrice(ctx context.Context, roomTypeID, ratePlanID int, price domain.Money) error {
 	logger.Info("ChangeRoomTypePrice called",
 		zap.Int("RoomTypeID", roomTypeID),
 		zap.Int("RatePlanID", ratePlanID),
@@ -121,7 +132,7 @@ func (s *RatePlanService) ChangeRoomTypePrice(ctx context.Context, roomTypeID, r
 		return errs.NewValidationError("room type ID or rate plan ID is required")
 	}
 
-	if price <= 0 {
+	if !price.IsPositive() {
 		logger.Warn("validation failed: missing price")
 		return errs.NewValidationError("price is required")
 	}
@@ -143,7 +154,7 @@ func (s *RatePlanService) ChangeRoomTypePrice(ctx context.Context, roomTypeID, r
 	return nil
 }
 
-func (s *RatePlanService) GetPrice(ctx context.Context, roomTypeID, ratePlanID int) (float64, error) {
+func (s *RatePlanService) GetPrice(ctx context.Context, roomTypeID, ratePlanID int) (domain.Money, error) {
 	logger.Info("GetPrice called",
 		zap.Int("RoomTypeID", roomTypeID),
 		zap.Int("RatePlanID", ratePlanID),
@@ -151,17 +162,17 @@ func (s *RatePlanService) GetPrice(ctx context.Context, roomTypeID, ratePlanID i
 
 	if roomTypeID <= 0 || ratePlanID <= 0 {
 		logger.Warn("validation failed: missing room type ID or rate plan ID")
-		return 0, errs.NewValidationError("room type ID or rate plan ID is required")
+		return domain.Money{}, errs.NewValidationError("room type ID or rate plan ID is required")
 	}
 
 	price, err := s.repo.GetPriceByRoomType(ctx, roomTypeID, ratePlanID)
 	if err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
 			logger.Warn("room type not found", zap.Int("RoomTypeID", roomTypeID))
-			return 0, errs.NewNotFoundError("room type not found")
+			return domain.Money{}, errs.NewNotFoundError("room type not found")
 		}
 		logger.ErrorErr(err, "repo.GetPriceByRoomType failed")
-		return 0, errs.NewUnexpectedError("failed to get room type price")
+		return domain.Money{}, errs.NewUnexpectedError("failed to get room type price")
 	}
 
 	logger.Info("room type price fetched",
@@ -217,3 +228,256 @@ func (s *RatePlanService) ListRatePlansByRoomType(ctx context.Context, roomTypeI
 
 	return rps, nil
 }
+
+// maxCalendarRangeDays caps how many dates a single calendar update may touch.
+const maxCalendarRangeDays = 731
+
+// SetCalendarPrice sets the price for every date between startDate and endDate (inclusive).
+// When weekdays is not empty only the dates falling on those weekdays are changed.
+func (s *RatePlanService) SetCalendarPrice(ctx context.Context, roomTypeID, ratePlanID int, startDate, endDate time.Time, weekdays []time.Weekday, price domain.Money) (int, error) {
+	logger.Info("SetCalendarPrice called",
+		zap.Int("RoomTypeID", roomTypeID),
+		zap.Int("RatePlanID", ratePlanID),
+		zap.Time("start", startDate),
+		zap.Time("end", endDate),
+	)
+
+	if roomTypeID <= 0 || ratePlanID <= 0 {
+		logger.Warn("validation failed: missing room type ID or rate plan ID")
+		return 0, errs.NewValidationError("room type ID or rate plan ID is required")
+	}
+
+	if !price.IsPositive() {
+		logger.Warn("validation failed: missing price")
+		return 0, errs.NewValidationError("price is required")
+	}
+
+	if err := validateCalendarRange(startDate, endDate); err != nil {
+		return 0, err
+	}
+
+	onDay := make(map[time.Weekday]bool, len(weekdays))
+	for _, wd := range weekdays {
+		if wd < time.Sunday || wd > time.Saturday {
+			logger.Warn("validation failed: invalid weekday", zap.Int("weekday", int(wd)))
+			return 0, errs.NewValidationError("weekdays must be between 0 (Sunday) and 6 (Saturday)")
+		}
+		onDay[wd] = true
+	}
+
+	var entries []*domain.RateCalendarEntry
+	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
+		if len(onDay) > 0 && !onDay[d.Weekday()] {
+			continue
+		}
+		entries = append(entries, &domain.RateCalendarEntry{
+			RoomTypeID: roomTypeID,
+			RatePlanID: ratePlanID,
+			StayDate:   d,
+			Price:      price,
+		})
+	}
+
+	if len(entries) == 0 {
+		logger.Warn("validation failed: no dates match the weekdays")
+		return 0, errs.NewValidationError("no dates in the range match the selected weekdays")
+	}
+
+	err := s.repo.SetCalendarPrices(ctx, entries)
+	if err != nil {
+		logger.ErrorErr(err, "repo.SetCalendarPrices failed")
+		return 0, errs.NewUnexpectedError("failed to update rate calendar")
+	}
+
+	logger.Info("rate calendar updated", zap.Int("dates", len(entries)))
+	return len(entries), nil
+}
+
+func (s *RatePlanService) ClearCalendarPrice(ctx context.Context, roomTypeID, ratePlanID int, startDate, endDate time.Time) (int64, error) {
+	logger.Info("ClearCalendarPrice called",
+		zap.Int("RoomTypeID", roomTypeID),
+		zap.Int("RatePlanID", ratePlanID),
+		zap.Time("start", startDate),
+		zap.Time("end", endDate),
+	)
+
+	if roomTypeID <= 0 || ratePlanID <= 0 {
+		logger.Warn("validation failed: missing room type ID or rate plan ID")
+		return 0, errs.NewValidationError("room type ID or rate plan ID is required")
+	}
+
+	if err := validateCalendarRange(startDate, endDate); err != nil {
+		return 0, err
+	}
+
+	rows, err := s.repo.DeleteCalendarPrices(ctx, roomTypeID, ratePlanID, startDate, endDate)
+	if err != nil {
+		logger.ErrorErr(err, "repo.DeleteCalendarPrices failed")
+		return 0, errs.NewUnexpectedError("failed to clear rate calendar")
+	}
+
+	logger.Info("rate calendar cleared", zap.Int64("dates", rows))
+	return rows, nil
+}
+
+// GetCalendar returns the effective price for every date between startDate and endDate (inclusive).
+func (s *RatePlanService) GetCalendar(ctx context.Context, roomTypeID, ratePlanID int, startDate, endDate time.Time) ([]*domain.NightlyRate, error) {
+	logger.Info("GetCalendar called",
+		zap.Int("RoomTypeID", roomTypeID),
+		zap.Int("RatePlanID", ratePlanID),
+	)
+
+	if roomTypeID <= 0 || ratePlanID <= 0 {
+		logger.Warn("validation failed: missing room type ID or rate plan ID")
+		return nil, errs.NewValidationError("room type ID or rate plan ID is required")
+	}
+
+	if err := validateCalendarRange(startDate, endDate); err != nil {
+		return nil, err
+	}
+
+	rates, err := s.repo.GetNightlyRates(ctx, roomTypeID, ratePlanID, startDate, endDate.AddDate(0, 0, 1))
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("rate calendar incomplete", zap.Error(err))
+			return nil, errs.NewNotFoundError("no price set for this room type and rate plan")
+		}
+		logger.ErrorErr(err, "repo.GetNightlyRates failed")
+		return nil, errs.NewUnexpectedError("failed to get rate calendar")
+	}
+
+	return rates, nil
+}
+
+// ChangeOccupancyPricing sets how a room type's rate on a rate plan changes
+// with the size of the party.
+func (s *RatePlanService) ChangeOccupancyPricing(ctx context.Context, p *domain.OccupancyPricing) error {
+	logger.Info("ChangeOccupancyPricing called",
+		zap.Int("RoomTypeID", p.RoomTypeID),
+		zap.Int("RatePlanID", p.RatePlanID),
+		zap.Int("BaseOccupancy", p.BaseOccupancy),
+	)
+
+	if err := validateOccupancyPricing(p); err != nil {
+		logger.Warn("validation failed", zap.Error(err))
+		return err
+	}
+
+	err := s.repo.SetOccupancyPricing(ctx, p)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("room type or rate plan not found", zap.Int("RoomTypeID", p.RoomTypeID), zap.Int("RatePlanID", p.RatePlanID))
+			return errs.NewNotFoundError("room type or rate plan not found")
+		}
+		logger.ErrorErr(err, "repo.SetOccupancyPricing failed")
+		return errs.NewUnexpectedError("failed to update occupancy pricing")
+	}
+
+	logger.Info("occupancy pricing updated",
+		zap.Int("RoomTypeID", p.RoomTypeID),
+		zap.Int("RatePlanID", p.RatePlanID),
+	)
+	return nil
+}
+
+func (s *RatePlanService) GetOccupancyPricing(ctx context.Context, roomTypeID, ratePlanID int) (*domain.OccupancyPricing, error) {
+	logger.Info("GetOccupancyPricing called",
+		zap.Int("RoomTypeID", roomTypeID),
+		zap.Int("RatePlanID", ratePlanID),
+	)
+
+	if roomTypeID <= 0 || ratePlanID <= 0 {
+		logger.Warn("validation failed: missing room type ID or rate plan ID")
+		return nil, errs.NewValidationError("room type ID or rate plan ID is required")
+	}
+
+	p, err := s.repo.GetOccupancyPricing(ctx, roomTypeID, ratePlanID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.NewNotFoundError("no occupancy pricing set for this room type and rate plan")
+		}
+		logger.ErrorErr(err, "repo.GetOccupancyPricing failed")
+		return nil, errs.NewUnexpectedError("failed to get occupancy pricing")
+	}
+
+	return p, nil
+}
+
+func (s *RatePlanService) RemoveOccupancyPricing(ctx context.Context, roomTypeID, ratePlanID int) error {
+	logger.Info("RemoveOccupancyPricing called",
+		zap.Int("RoomTypeID", roomTypeID),
+		zap.Int("RatePlanID", ratePlanID),
+	)
+
+	if roomTypeID <= 0 || ratePlanID <= 0 {
+		logger.Warn("validation failed: missing room type ID or rate plan ID")
+		return errs.NewValidationError("room type ID or rate plan ID is required")
+	}
+
+	err := s.repo.DeleteOccupancyPricing(ctx, roomTypeID, ratePlanID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.NewNotFoundError("no occupancy pricing set for this room type and rate plan")
+		}
+		logger.ErrorErr(err, "repo.DeleteOccupancyPricing failed")
+		return errs.NewUnexpectedError("failed to delete occupancy pricing")
+	}
+
+	logger.Info("occupancy pricing deleted",
+		zap.Int("RoomTypeID", roomTypeID),
+		zap.Int("RatePlanID", ratePlanID),
+	)
+	return nil
+}
+
+func validatePaymentTerms(rp *domain.RatePlan) error {
+	if rp.DepositPercent < 0 || rp.DepositPercent > 100 {
+		return errs.NewValidationError("deposit percent must be between 0 and 100")
+	}
+	if !rp.AllowPayLater && (rp.DepositPercent > 0 || rp.RequiresGuarantee) {
+		return errs.NewValidationError("deposits and card guarantees only apply to pay-later rate plans")
+	}
+	return nil
+}
+
+func validateCalendarRange(startDate, endDate time.Time) error {
+	if startDate.After(endDate) {
+		logger.Warn("calendar range invalid", zap.Time("start", startDate), zap.Time("end", endDate))
+		return errs.NewValidationError("start date must be before or equal to end date")
+	}
+	if endDate.Sub(startDate) > maxCalendarRangeDays*24*time.Hour {
+		logger.Warn("calendar range too long", zap.Time("start", startDate), zap.Time("end", endDate))
+		return errs.NewValidationError("date range must not exceed two years")
+	}
+	return nil
+}
+
+// maxChildAge is the oldest a guest can be and still be booked as a child.
+const maxChildAge = 17
+
+func validateOccupancyPricing(p *domain.OccupancyPricing) error {
+	if p.RoomTypeID <= 0 || p.RatePlanID <= 0 {
+		return errs.NewValidationError("room type ID or rate plan ID is required")
+	}
+	if p.BaseOccupancy <= 0 {
+		return errs.NewValidationError("base occupancy must be at least 1")
+	}
+	if p.ExtraAdultFee.IsNegative() {
+		return errs.NewValidationError("extra adult fee cannot be negative")
+	}
+
+	bands := append([]*domain.ChildAgeBand(nil), p.ChildFees...)
+	sort.Slice(bands, func(i, j int) bool { return bands[i].MinAge < bands[j].MinAge })
+	for i, band := range bands {
+		if band.MinAge < 0 || band.MaxAge > maxChildAge || band.MinAge > band.MaxAge {
+			return errs.NewValidationError(fmt.Sprintf("child age bands must run from 0 to %d with min age not above max age", maxChildAge))
+		}
+		if band.Fee.IsNegative() {
+			return errs.NewValidationError("child fees cannot be negative")
+		}
+		if i > 0 && band.MinAge <= bands[i-1].MaxAge {
+			return errs.NewValidationError("child age bands must not overlap")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/common/logger"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/ingwrok/hotelBooking/internal/core/utils"
+	"go.uber.org/zap"
+)
+
+type RestrictionService struct {
+	repo ports.RestrictionRepository
+}
+
+func NewRestrictionService(repo ports.RestrictionRepository) *RestrictionService {
+	return &RestrictionService{repo: repo}
+}
+
+func (s *RestrictionService) AddRestriction(ctx context.Context, r *domain.StayRestriction) (*domain.StayRestriction, error) {
+	logger.Info("AddRestriction called",
+		zap.Int("RoomTypeID", r.RoomTypeID),
+		zap.Int("RatePlanID", r.RatePlanID),
+		zap.Time("start", r.StartDate),
+		zap.Time("end", r.EndDate),
+	)
+
+	if err := validateRestriction(r); err != nil {
+		logger.Warn("validation failed", zap.Error(err))
+		return nil, err
+	}
+
+	err := s.repo.CreateRestriction(ctx, r)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("room type or rate plan not found", zap.Error(err))
+			return nil, errs.NewNotFoundError("room type or rate plan not found")
+		}
+		logger.ErrorErr(err, "repo.CreateRestriction failed")
+		return nil, errs.NewUnexpectedError("failed to create restriction")
+	}
+
+	logger.Info("restriction created successfully", zap.Int("RestrictionID", r.RestrictionID))
+	return r, nil
+}
+
+func (s *RestrictionService) ChangeRestriction(ctx context.Context, r *domain.StayRestriction) error {
+	logger.Info("ChangeRestriction called", zap.Int("RestrictionID", r.RestrictionID))
+
+	if r.RestrictionID <= 0 {
+		return errs.NewValidationError("invalid restriction ID")
+	}
+	if err := validateRestriction(r); err != nil {
+		logger.Warn("validation failed", zap.Error(err))
+		return err
+	}
+
+	err := s.repo.UpdateRestriction(ctx, r)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("restriction not found", zap.Error(err))
+			return errs.NewNotFoundError("restriction, room type or rate plan not found")
+		}
+		logger.ErrorErr(err, "repo.UpdateRestriction failed")
+		return errs.NewUnexpectedError("failed to update restriction")
+	}
+
+	logger.Info("restriction updated successfully", zap.Int("RestrictionID", r.RestrictionID))
+	return nil
+}
+
+func (s *RestrictionService) RemoveRestriction(ctx context.Context, restrictionID int) error {
+	logger.Info("RemoveRestriction called", zap.Int("RestrictionID", restrictionID))
+
+	if restrictionID <= 0 {
+		return errs.NewValidationError("invalid restriction ID")
+	}
+
+	err := s.repo.DeleteRestriction(ctx, restrictionID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("restriction not found", zap.Int("RestrictionID", restrictionID))
+			return errs.NewNotFoundError("restriction not found")
+		}
+		logger.ErrorErr(err, "repo.DeleteRestriction failed")
+		return errs.NewUnexpectedError("failed to delete restriction")
+	}
+
+	logger.Info("restriction deleted successfully", zap.Int("RestrictionID", restrictionID))
+	return nil
+}
+
+func (s *RestrictionService) GetRestriction(ctx context.Context, restrictionID int) (*domain.StayRestriction, error) {
+	logger.Info("GetRestriction called", zap.Int("RestrictionID", restrictionID))
+
+	if restrictionID <= 0 {
+		return nil, errs.NewValidationError("invalid restriction ID")
+	}
+
+	r, err := s.repo.GetRestrictionByID(ctx, restrictionID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("restriction not found", zap.Int("RestrictionID", restrictionID))
+			return nil, errs.NewNotFoundError("restriction not found")
+		}
+		logger.ErrorErr(err, "repo.GetRestrictionByID failed")
+		return nil, errs.NewUnexpectedError("failed to get restriction")
+	}
+
+	return r, nil
+}
+
+func (s *RestrictionService) ListRestrictionsByRoomType(ctx context.Context, roomTypeID int) ([]*domain.StayRestriction, error) {
+	logger.Info("ListRestrictionsByRoomType called", zap.Int("RoomTypeID", roomTypeID))
+
+	if roomTypeID <= 0 {
+		return nil, errs.NewValidationError("room type ID is required")
+	}
+
+	restrictions, err := s.repo.GetRestrictionsByRoomType(ctx, roomTypeID)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetRestrictionsByRoomType failed")
+		return nil, errs.NewUnexpectedError("failed to retrieve restrictions")
+	}
+
+	logger.Debug("restrictions fetched", zap.Int("count", len(restrictions)))
+	return restrictions, nil
+}
+
+func validateRestriction(r *domain.StayRestriction) error {
+	if r.RoomTypeID <= 0 {
+		return errs.NewValidationError("room type ID is required")
+	}
+	if r.RatePlanID < 0 {
+		return errs.NewValidationError("invalid rate plan ID")
+	}
+	if r.StartDate.After(r.EndDate) {
+		return errs.NewValidationError("start date must be before or equal to end date")
+	}
+	if r.MinLOS < 0 || r.MaxLOS < 0 {
+		return errs.NewValidationError("length of stay limits cannot be negative")
+	}
+	if r.MinLOS > 0 && r.MaxLOS > 0 && r.MinLOS > r.MaxLOS {
+		return errs.NewValidationError("minimum stay cannot be longer than maximum stay")
+	}
+	if r.MinLOS == 0 && r.MaxLOS == 0 && !r.ClosedToArrival && !r.ClosedToDeparture && !r.StopSell {
+		return errs.NewValidationError("restriction must set at least one rule")
+	}
+	return nil
+}
+
+// checkStayRestrictions returns a validation error naming the first restriction
+// the stay breaks. Stop-sell applies to every night, CTA and length of stay to
+// the arrival date and CTD to the departure date.
+func checkStayRestrictions(restrictions []*domain.StayRestriction, checkIn, checkOut time.Time) error {
+	checkIn = utils.DateOnly(checkIn)
+	checkOut = utils.DateOnly(checkOut)
+	numNights := int(checkOut.Sub(checkIn).Hours() / 24)
+
+	for _, r := range restrictions {
+		if r.StopSell {
+			for night := checkIn; night.Before(checkOut); night = night.AddDate(0, 0, 1) {
+				if restrictionCovers(r, night) {
+					return errs.NewValidationError(fmt.Sprintf("room type is closed for sale on %s", night.Format(utils.DateFormat)))
+				}
+			}
+		}
+
+		if restrictionCovers(r, checkIn) {
+			arrival := checkIn.Format(utils.DateFormat)
+			if r.ClosedToArrival {
+				return errs.NewValidationError(fmt.Sprintf("arrival is not allowed on %s", arrival))
+			}
+			if r.MinLOS > 0 && numNights < r.MinLOS {
+				return errs.NewValidationError(fmt.Sprintf("stays arriving on %s require at least %d nights", arrival, r.MinLOS))
+			}
+			if r.MaxLOS > 0 && numNights > r.MaxLOS {
+				return errs.NewValidationError(fmt.Sprintf("stays arriving on %s allow at most %d nights", arrival, r.MaxLOS))
+			}
+		}
+
+		if r.ClosedToDeparture && restrictionCovers(r, checkOut) {
+			return errs.NewValidationError(fmt.Sprintf("departure is not allowed on %s", checkOut.Format(utils.DateFormat)))
+		}
+	}
+
+	return nil
+}
+
+func restrictionCovers(r *domain.StayRestriction, date time.Time) bool {
+	return !date.Before(utils.DateOnly(r.StartDate)) && !date.After(utils.DateOnly(r.EndDate))
+}
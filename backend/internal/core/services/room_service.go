@@ -15,11 +15,12 @@ import (
 )
 
 type RoomService struct {
-	repo ports.RoomRepository
+	repo         ports.RoomRepository
+	restrictRepo ports.RestrictionRepository
 }
 
-func NewRoomService(repo ports.RoomRepository) *RoomService {
-	return &RoomService{repo: repo}
+func NewRoomService(repo ports.RoomRepository, restrictRepo ports.RestrictionRepository) *RoomService {
+	return &RoomService{repo: repo, restrictRepo: restrictRepo}
 }
 
 func (s *RoomService)	AddRoom(ctx context.Context, room *domain.Room) (*domain.Room,error){
@@ -220,7 +221,10 @@ func (s *RoomService)	UnblockRoom(ctx context.Context, blockID int) error{
 	return nil
 }
 
-func (s *RoomService)CountAvailableRooms(ctx context.Context, checkInStr, checkOutStr string) (map[int]int, error){
+// CountAvailableRooms returns free rooms per room type. Room types whose stay
+// restrictions reject the dates for ratePlanID (0 = restrictions shared by all
+// plans) are reported with zero rooms.
+func (s *RoomService)CountAvailableRooms(ctx context.Context, checkInStr, checkOutStr string, ratePlanID int) (map[int]int, error){
 
 	checkIn, err := utils.ParseDate(checkInStr,"check-in")
 	if err != nil {
@@ -253,11 +257,31 @@ func (s *RoomService)CountAvailableRooms(ctx context.Context, checkInStr, checkO
 		return nil, errs.NewUnexpectedError("failed to get available room counts")
 	}
 
+	restrictions, err := s.restrictRepo.GetRestrictionsForStay(ctx, 0, ratePlanID, checkIn, checkOut)
+	if err != nil {
+		logger.ErrorErr(err, "GetRestrictionsForStay failed")
+		return nil, errs.NewUnexpectedError("failed to check stay restrictions")
+	}
+
+	byRoomType := make(map[int][]*domain.StayRestriction)
+	for _, r := range restrictions {
+		byRoomType[r.RoomTypeID] = append(byRoomType[r.RoomTypeID], r)
+	}
+	for roomTypeID, rs := range byRoomType {
+		if _, ok := counts[roomTypeID]; !ok {
+			continue
+		}
+		if err := checkStayRestrictions(rs, checkIn, checkOut); err != nil {
+			logger.Debug("room type restricted", zap.Int("roomTypeID", roomTypeID), zap.Error(err))
+			counts[roomTypeID] = 0
+		}
+	}
+
 	logger.Debug("Available room counts calculated", zap.Int("total_types", len(counts)))
 	return counts, nil
 }
 
-func (s *RoomService)FindAvailableRoom(ctx context.Context, roomTypeID int, checkInStr, checkOutStr string) (int, error){
+func (s *RoomService)FindAvailableRoom(ctx context.Context, roomTypeID int, checkInStr, checkOutStr string, ratePlanID int) (int, error){
 
 	if roomTypeID <= 0 {
 		logger.Warn("validation failed: missing roomTypeID")
@@ -287,6 +311,17 @@ func (s *RoomService)FindAvailableRoom(ctx context.Context, roomTypeID int, chec
 		logger.Warn("Validation failed: check-in date is after check-out date")
 		return 0, errs.NewValidationError("check-in date must be before check-out date")
 	}
+
+	restrictions, err := s.restrictRepo.GetRestrictionsForStay(ctx, roomTypeID, ratePlanID, checkIn, checkOut)
+	if err != nil {
+		logger.ErrorErr(err, "GetRestrictionsForStay failed")
+		return 0, errs.NewUnexpectedError("failed to check stay restrictions")
+	}
+	if err := checkStayRestrictions(restrictions, checkIn, checkOut); err != nil {
+		logger.Warn("stay restriction violated", zap.Int("roomTypeID", roomTypeID), zap.Error(err))
+		return 0, err
+	}
+
 	roomID,err := s.repo.GetAnyAvailableRoomID(ctx, roomTypeID, checkIn, checkOut)
 	if err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
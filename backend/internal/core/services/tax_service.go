@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/common/logger"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"go.uber.org/zap"
+)
+
+type TaxRuleService struct {
+	repo ports.TaxRuleRepository
+}
+
+func NewTaxRuleService(repo ports.TaxRuleRepository) *TaxRuleService {
+	return &TaxRuleService{repo: repo}
+}
+
+func (s *TaxRuleService) AddTaxRule(ctx context.Context, rule *domain.TaxRule) (*domain.TaxRule, error) {
+	logger.Info("AddTaxRule called", zap.String("Name", rule.Name))
+
+	if err := validateTaxRule(rule); err != nil {
+		logger.Warn("validation failed", zap.Error(err))
+		return nil, err
+	}
+
+	err := s.repo.CreateTaxRule(ctx, rule)
+	if err != nil {
+		logger.ErrorErr(err, "repo.CreateTaxRule failed")
+		return nil, errs.NewUnexpectedError("failed to create tax rule")
+	}
+
+	logger.Info("tax rule created successfully", zap.Int("TaxRuleID", rule.TaxRuleID))
+	return rule, nil
+}
+
+func (s *TaxRuleService) ChangeTaxRule(ctx context.Context, rule *domain.TaxRule) error {
+	logger.Info("ChangeTaxRule called", zap.Int("TaxRuleID", rule.TaxRuleID))
+
+	if rule.TaxRuleID <= 0 {
+		return errs.NewValidationError("invalid tax rule ID")
+	}
+	if err := validateTaxRule(rule); err != nil {
+		logger.Warn("validation failed", zap.Error(err))
+		return err
+	}
+
+	err := s.repo.UpdateTaxRule(ctx, rule)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("tax rule not found", zap.Int("TaxRuleID", rule.TaxRuleID))
+			return errs.NewNotFoundError("tax rule not found")
+		}
+		logger.ErrorErr(err, "repo.UpdateTaxRule failed")
+		return errs.NewUnexpectedError("failed to update tax rule")
+	}
+
+	logger.Info("tax rule updated successfully", zap.Int("TaxRuleID", rule.TaxRuleID))
+	return nil
+}
+
+func (s *TaxRuleService) RemoveTaxRule(ctx context.Context, taxRuleID int) error {
+	logger.Info("RemoveTaxRule called", zap.Int("TaxRuleID", taxRuleID))
+
+	err := s.repo.DeleteTaxRule(ctx, taxRuleID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("tax rule not found", zap.Int("TaxRuleID", taxRuleID))
+			return errs.NewNotFoundError("tax rule not found")
+		}
+		logger.ErrorErr(err, "repo.DeleteTaxRule failed")
+		return errs.NewUnexpectedError("failed to delete tax rule")
+	}
+
+	logger.Info("tax rule deleted successfully", zap.Int("TaxRuleID", taxRuleID))
+	return nil
+}
+
+func (s *TaxRuleService) GetTaxRule(ctx context.Context, taxRuleID int) (*domain.TaxRule, error) {
+	logger.Info("GetTaxRule called", zap.Int("TaxRuleID", taxRuleID))
+
+	if taxRuleID <= 0 {
+		return nil, errs.NewValidationError("invalid tax rule ID")
+	}
+
+	rule, err := s.repo.GetTaxRuleByID(ctx, taxRuleID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			logger.Warn("tax rule not found", zap.Int("TaxRuleID", taxRuleID))
+			return nil, errs.NewNotFoundError("tax rule not found")
+		}
+		logger.ErrorErr(err, "repo.GetTaxRuleByID failed")
+		return nil, errs.NewUnexpectedError("failed to get tax rule")
+	}
+
+	return rule, nil
+}
+
+func (s *TaxRuleService) ListTaxRules(ctx context.Context) ([]*domain.TaxRule, error) {
+	logger.Info("ListTaxRules called")
+
+	rules, err := s.repo.GetAllTaxRules(ctx)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetAllTaxRules failed")
+		return nil, errs.NewUnexpectedError("failed to retrieve tax rules")
+	}
+
+	logger.Debug("tax rule list returned", zap.Int("count", len(rules)))
+	return rules, nil
+}
+
+func validateTaxRule(rule *domain.TaxRule) error {
+	if rule.Name == "" {
+		return errs.NewValidationError("tax rule name is required")
+	}
+	if rule.Basis == "" {
+		rule.Basis = domain.TaxBasisStay
+	}
+	if rule.AppliesTo == "" {
+		rule.AppliesTo = domain.TaxAppliesToAll
+	}
+
+	switch rule.CalcType {
+	case domain.TaxCalcPercent:
+		if rule.Rate < 0 || rule.Rate > 100 {
+			return errs.NewValidationError("percentage rate must be between 0 and 100")
+		}
+		if rule.Basis != domain.TaxBasisStay {
+			return errs.NewValidationError("percentage rules are charged per stay")
+		}
+		rule.Amount = domain.THB(0)
+	case domain.TaxCalcFixed:
+		if rule.Amount.IsNegative() {
+			return errs.NewValidationError("fixed amount cannot be negative")
+		}
+		rule.Rate = 0
+		if rule.IsCompound {
+			return errs.NewValidationError("only percentage rules can compound")
+		}
+	default:
+		return errs.NewValidationError("calculation type must be percent or fixed")
+	}
+
+	switch rule.Basis {
+	case domain.TaxBasisStay, domain.TaxBasisNight, domain.TaxBasisPerson, domain.TaxBasisPersonNight:
+	default:
+		return errs.NewValidationError("basis must be stay, night, person or person_night")
+	}
+
+	switch rule.AppliesTo {
+	case domain.TaxAppliesToRoom, domain.TaxAppliesToAddon, domain.TaxAppliesToAll:
+	default:
+		return errs.NewValidationError("applies to must be room, addon or all")
+	}
+
+	return nil
+}
+
+// taxableCharges is what the tax engine needs to know about a stay. Room is
+// the room charge after discounts.
+type taxableCharges struct {
+	Room   domain.Money
+	Addon  domain.Money
+	Nights int
+	Guests int
+}
+
+// computeTaxes applies the rules in priority order and returns one line per
+// rule that charged something, and their sum. With domain.RoundPerLine each
+// line is rounded half-up to the minor unit before summing; with
+// domain.RoundAtTotal the exact lines are summed and rounded once and the
+// difference is put on the largest line. Compound rules also tax the lines
+// charged before them.
+func computeTaxes(rules []*domain.TaxRule, charges taxableCharges, rounding string) ([]*domain.BookingTax, domain.Money, error) {
+	subtotal, err := charges.Room.Add(charges.Addon)
+	if err != nil {
+		return nil, domain.Money{}, err
+	}
+	currency := subtotal.Currency
+
+	sorted := make([]*domain.TaxRule, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Priority < sorted[j].Priority })
+
+	var lines []*domain.BookingTax
+	var exactTotal int64
+	charged := domain.NewMoney(0, currency)
+	for _, rule := range sorted {
+		var base domain.Money
+		switch rule.AppliesTo {
+		case domain.TaxAppliesToRoom:
+			base = charges.Room
+		case domain.TaxAppliesToAddon:
+			base = charges.Addon
+		default:
+			base = subtotal
+		}
+		if !base.IsPositive() {
+			continue
+		}
+
+		var micros int64
+		if rule.CalcType == domain.TaxCalcPercent {
+			if rule.IsCompound {
+				if base, err = base.Add(charged); err != nil {
+					return nil, domain.Money{}, err
+				}
+			}
+			micros = base.PercentMicros(rule.Rate)
+		} else {
+			fee := rule.Amount.Mul(int64(taxUnits(rule.Basis, charges)))
+			micros = fee.Amount * domain.MicrosPerMinor
+		}
+		if micros <= 0 {
+			continue
+		}
+
+		amount := domain.MoneyFromMicros(micros, currency)
+		lines = append(lines, &domain.BookingTax{
+			TaxRuleID: rule.TaxRuleID,
+			Name:      rule.Name,
+			Amount:    amount,
+		})
+		exactTotal += micros
+		if charged, err = charged.Add(amount); err != nil {
+			return nil, domain.Money{}, err
+		}
+	}
+
+	if rounding == domain.RoundAtTotal && len(lines) > 0 {
+		rounded := domain.MoneyFromMicros(exactTotal, currency)
+		largest := lines[0]
+		for _, line := range lines[1:] {
+			if line.Amount.Amount > largest.Amount.Amount {
+				largest = line
+			}
+		}
+		// Every line is in currency, so only the amounts need adjusting.
+		largest.Amount.Amount += rounded.Amount - charged.Amount
+		charged = rounded
+	}
+
+	kept := lines[:0]
+	for _, line := range lines {
+		if line.Amount.IsPositive() {
+			kept = append(kept, line)
+		}
+	}
+
+	return kept, charged, nil
+}
+
+func taxUnits(basis string, charges taxableCharges) int {
+	switch basis {
+	case domain.TaxBasisNight:
+		return charges.Nights
+	case domain.TaxBasisPerson:
+		return charges.Guests
+	case domain.TaxBasisPersonNight:
+		return charges.Guests * charges.Nights
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+)
+
+func percentRule(id, priority int, rate float64, appliesTo string, compound bool) *domain.TaxRule {
+	return &domain.TaxRule{
+		TaxRuleID:  id,
+		Name:       "percent",
+		CalcType:   domain.TaxCalcPercent,
+		Rate:       rate,
+		Basis:      domain.TaxBasisStay,
+		AppliesTo:  appliesTo,
+		IsCompound: compound,
+		Priority:   priority,
+	}
+}
+
+func fixedRule(id int, amount int64, basis string) *domain.TaxRule {
+	return &domain.TaxRule{
+		TaxRuleID: id,
+		Name:      "fixed",
+		CalcType:  domain.TaxCalcFixed,
+		Amount:    domain.THB(amount),
+		Basis:     basis,
+		AppliesTo: domain.TaxAppliesToAll,
+	}
+}
+
+func TestComputeTaxes(t *testing.T) {
+	room := func(amount int64) taxableCharges {
+		return taxableCharges{Room: domain.THB(amount), Addon: domain.THB(0), Nights: 2, Guests: 3}
+	}
+	all := domain.TaxAppliesToAll
+
+	tests := []struct {
+		name      string
+		rules     []*domain.TaxRule
+		charges   taxableCharges
+		rounding  string
+		wantLines map[int]int64 // TaxRuleID -> minor units
+		wantTotal int64
+	}{
+		{
+			name:      "service charge then compound VAT",
+			rules:     []*domain.TaxRule{percentRule(2, 2, 7, all, true), percentRule(1, 1, 10, all, false)},
+			charges:   room(100000),
+			wantLines: map[int]int64{1: 10000, 2: 7700},
+			wantTotal: 17700,
+		},
+		{
+			name:      "VAT without compounding",
+			rules:     []*domain.TaxRule{percentRule(1, 1, 10, all, false), percentRule(2, 2, 7, all, false)},
+			charges:   room(100000),
+			wantLines: map[int]int64{1: 10000, 2: 7000},
+			wantTotal: 17000,
+		},
+		{
+			name:      "compound rule first has nothing to compound",
+			rules:     []*domain.TaxRule{percentRule(1, 1, 7, all, true), percentRule(2, 2, 10, all, false)},
+			charges:   room(100000),
+			wantLines: map[int]int64{1: 7000, 2: 10000},
+			wantTotal: 17000,
+		},
+		{
+			name:      "round per line",
+			rules:     []*domain.TaxRule{percentRule(1, 1, 7, all, false), percentRule(2, 2, 2, all, false)},
+			charges:   room(1020),
+			rounding:  domain.RoundPerLine,
+			wantLines: map[int]int64{1: 71, 2: 20},
+			wantTotal: 91,
+		},
+		{
+			name:      "round at total puts the difference on the largest line",
+			rules:     []*domain.TaxRule{percentRule(1, 1, 7, all, false), percentRule(2, 2, 2, all, false)},
+			charges:   room(1020),
+			rounding:  domain.RoundAtTotal,
+			wantLines: map[int]int64{1: 72, 2: 20},
+			wantTotal: 92,
+		},
+		{
+			name:      "half a minor unit rounds up",
+			rules:     []*domain.TaxRule{percentRule(1, 1, 7, all, false)},
+			charges:   room(1050),
+			wantLines: map[int]int64{1: 74},
+			wantTotal: 74,
+		},
+		{
+			name:      "lines under a minor unit are dropped per line",
+			rules:     []*domain.TaxRule{percentRule(1, 1, 4, all, false), percentRule(2, 2, 4, all, false), percentRule(3, 3, 4, all, false)},
+			charges:   room(10),
+			rounding:  domain.RoundPerLine,
+			wantLines: map[int]int64{},
+			wantTotal: 0,
+		},
+		{
+			name:      "lines under a minor unit add up at total",
+			rules:     []*domain.TaxRule{percentRule(1, 1, 4, all, false), percentRule(2, 2, 4, all, false), percentRule(3, 3, 4, all, false)},
+			charges:   room(10),
+			rounding:  domain.RoundAtTotal,
+			wantLines: map[int]int64{1: 1},
+			wantTotal: 1,
+		},
+		{
+			name:      "fee per stay",
+			rules:     []*domain.TaxRule{fixedRule(1, 5000, domain.TaxBasisStay)},
+			charges:   room(100000),
+			wantLines: map[int]int64{1: 5000},
+			wantTotal: 5000,
+		},
+		{
+			name:      "fee per night",
+			rules:     []*domain.TaxRule{fixedRule(1, 5000, domain.TaxBasisNight)},
+			charges:   room(100000),
+			wantLines: map[int]int64{1: 10000},
+			wantTotal: 10000,
+		},
+		{
+			name:      "fee per person",
+			rules:     []*domain.TaxRule{fixedRule(1, 5000, domain.TaxBasisPerson)},
+			charges:   room(100000),
+			wantLines: map[int]int64{1: 15000},
+			wantTotal: 15000,
+		},
+		{
+			name:      "fee per person per night",
+			rules:     []*domain.TaxRule{fixedRule(1, 5000, domain.TaxBasisPersonNight)},
+			charges:   room(100000),
+			wantLines: map[int]int64{1: 30000},
+			wantTotal: 30000,
+		},
+		{
+			name:      "compound VAT on a fee",
+			rules:     []*domain.TaxRule{fixedRule(1, 5000, domain.TaxBasisPersonNight), percentRule(2, 1, 7, all, true)},
+			charges:   room(100000),
+			wantLines: map[int]int64{1: 30000, 2: 9100},
+			wantTotal: 39100,
+		},
+		{
+			name:      "room and addon rules",
+			rules:     []*domain.TaxRule{percentRule(1, 1, 10, domain.TaxAppliesToRoom, false), percentRule(2, 2, 5, domain.TaxAppliesToAddon, false)},
+			charges:   taxableCharges{Room: domain.THB(100000), Addon: domain.THB(20000), Nights: 2, Guests: 3},
+			wantLines: map[int]int64{1: 10000, 2: 1000},
+			wantTotal: 11000,
+		},
+		{
+			name:      "addon rule without addons",
+			rules:     []*domain.TaxRule{percentRule(1, 1, 5, domain.TaxAppliesToAddon, false), fixedRule(2, 0, domain.TaxBasisStay)},
+			charges:   room(100000),
+			wantLines: map[int]int64{},
+			wantTotal: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		lines, total, err := computeTaxes(tt.rules, tt.charges, tt.rounding)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if total != domain.THB(tt.wantTotal) {
+			t.Errorf("%s: total = %v, want %v", tt.name, total, domain.THB(tt.wantTotal))
+		}
+		if len(lines) != len(tt.wantLines) {
+			t.Errorf("%s: got %d lines, want %d", tt.name, len(lines), len(tt.wantLines))
+		}
+		for _, line := range lines {
+			want, ok := tt.wantLines[line.TaxRuleID]
+			if !ok || line.Amount != domain.THB(want) {
+				t.Errorf("%s: rule %d charged %v, want %v", tt.name, line.TaxRuleID, line.Amount, domain.THB(want))
+			}
+		}
+	}
+}
+
+// pricingRepos serves what priceBooking reads for a two-night stay at 1000 THB
+// a night with no restrictions, occupancy pricing or promotions.
+type pricingRepos struct {
+	ports.RoomTypeRepository
+	ports.RatePlanRepository
+	ports.RestrictionRepository
+	ports.TaxRuleRepository
+	rules []*domain.TaxRule
+}
+
+func (r *pricingRepos) GetRoomTypeByID(_ context.Context, id int) (*domain.RoomType, error) {
+	return &domain.RoomType{RoomTypeID: id, Name: "Deluxe", Capacity: 4}, nil
+}
+
+func (r *pricingRepos) GetOccupancyPricing(context.Context, int, int) (*domain.OccupancyPricing, error) {
+	return nil, errs.ErrNotFound
+}
+
+func (r *pricingRepos) GetRestrictionsForStay(context.Context, int, int, time.Time, time.Time) ([]*domain.StayRestriction, error) {
+	return nil, nil
+}
+
+func (r *pricingRepos) GetNightlyRates(_ context.Context, _, _ int, checkIn, checkOut time.Time) ([]*domain.NightlyRate, error) {
+	var nights []*domain.NightlyRate
+	for d := checkIn; d.Before(checkOut); d = d.AddDate(0, 0, 1) {
+		nights = append(nights, &domain.NightlyRate{StayDate: d, Price: domain.THB(100000)})
+	}
+	return nights, nil
+}
+
+func (r *pricingRepos) GetActiveTaxRules(context.Context) ([]*domain.TaxRule, error) {
+	return r.rules, nil
+}
+
+func (r *pricingRepos) GetRatePlanByID(_ context.Context, id int) (*domain.RatePlan, error) {
+	return &domain.RatePlan{RatePlanID: id}, nil
+}
+
+func TestPriceBookingChargesChildrenPerPerson(t *testing.T) {
+	repos := &pricingRepos{rules: []*domain.TaxRule{fixedRule(1, 5000, domain.TaxBasisPersonNight)}}
+	svc := &BookingService{roomTypeRepo: repos, rateplanRepo: repos, restrictRepo: repos, taxRepo: repos}
+
+	checkIn := time.Date(2030, 3, 10, 0, 0, 0, 0, time.Local)
+	booking := &domain.Booking{
+		RatePlanID:   1,
+		CheckInDate:  checkIn,
+		CheckOutDate: checkIn.AddDate(0, 0, 2),
+		NumAdults:    2,
+		ChildAges:    []int{5},
+	}
+	if err := svc.priceBooking(context.Background(), booking, 1, false); err != nil {
+		t.Fatalf("priceBooking: %v", err)
+	}
+
+	// Three guests for two nights at 50 THB each.
+	if booking.TaxesAmount != domain.THB(30000) {
+		t.Errorf("taxes = %v, want 300.00 THB", booking.TaxesAmount)
+	}
+	if booking.TotalPrice != domain.THB(230000) {
+		t.Errorf("total = %v, want 2300.00 THB", booking.TotalPrice)
+	}
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/common/logger"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// RequestEmailVerification mails a fresh verification link to the user's
+// current address. Earlier links stop working.
+func (s *UserService) RequestEmailVerification(ctx context.Context, userID int) error {
+	logger.Info("RequestEmailVerification called", zap.Int("UserID", userID))
+
+	u, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.EmailVerified() {
+		return errs.NewConflictError("email is already verified")
+	}
+
+	if err := s.sendVerification(ctx, u); err != nil {
+		logger.ErrorErr(err, "failed to send verification email", zap.Int("UserID", userID))
+		return errs.NewUnexpectedError("failed to send verification email")
+	}
+	return nil
+}
+
+// VerifyEmail spends a verification token. It only verifies the address the
+// link was sent to, so a link for an email the user has since changed is void.
+func (s *UserService) VerifyEmail(ctx context.Context, token string) error {
+	if token == "" {
+		return errs.NewValidationError("token is required")
+	}
+
+	t, err := s.accountTokenRepo.ConsumeAccountToken(ctx, domain.AccountTokenVerifyEmail, hashToken(token))
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.NewValidationError("invalid or expired verification link")
+		}
+		logger.ErrorErr(err, "repo.ConsumeAccountToken failed in VerifyEmail")
+		return errs.NewUnexpectedError("internal server error")
+	}
+	logger.Info("VerifyEmail called", zap.Int("UserID", t.UserID))
+
+	if err := s.repo.MarkEmailVerified(ctx, t.UserID, t.Email); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.NewValidationError("invalid or expired verification link")
+		}
+		logger.ErrorErr(err, "repo.MarkEmailVerified failed")
+		return errs.NewUnexpectedError("internal server error")
+	}
+
+	logger.Info("email verified", zap.Int("UserID", t.UserID))
+	return nil
+}
+
+// RequestPasswordReset mails a reset link if an account uses email. It
+// reports success either way so the endpoint cannot be used to find accounts.
+func (s *UserService) RequestPasswordReset(ctx context.Context, email string) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return errs.NewValidationError("email is required")
+	}
+
+	u, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if !errors.Is(err, errs.ErrNotFound) {
+			logger.ErrorErr(err, "repo.GetByEmail failed in RequestPasswordReset")
+		}
+		return nil
+	}
+	logger.Info("RequestPasswordReset called", zap.Int("UserID", u.UserID))
+
+	// Sent in the background so a known address answers as fast as an
+	// unknown one.
+	go func(u domain.User) {
+		if err := s.sendPasswordReset(context.Background(), &u); err != nil {
+			logger.ErrorErr(err, "failed to send password reset email", zap.Int("UserID", u.UserID))
+		}
+	}(*u)
+	return nil
+}
+
+func (s *UserService) sendPasswordReset(ctx context.Context, u *domain.User) error {
+	ttl := time.Duration(viper.GetInt("auth.reset_password_minutes")) * time.Minute
+	raw, err := s.createAccountToken(ctx, u, domain.AccountTokenResetPassword, ttl)
+	if err != nil {
+		return err
+	}
+
+	msg := &domain.EmailMessage{
+		To:      u.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %d minutes.\n\n%s\n\n"+
+			"If you did not ask for this, you can ignore this email.",
+			u.Username, int(ttl.Minutes()), accountLink("/reset-password", raw)),
+	}
+	return s.emailRepo.Send(ctx, msg)
+}
+
+// ResetPassword spends a reset token and sets the new password, which also
+// ends every session of the account and lifts any login lockout. The password
+// is checked and hashed first, so a password that cannot be used does not
+// use up the link.
+func (s *UserService) ResetPassword(ctx context.Context, token, password string, device domain.Device) error {
+	if token == "" || password == "" {
+		return errs.NewValidationError("token and password are required")
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	t, err := s.accountTokenRepo.ConsumeAccountToken(ctx, domain.AccountTokenResetPassword, hashToken(token))
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.NewValidationError("invalid or expired reset link")
+		}
+		logger.ErrorErr(err, "repo.ConsumeAccountToken failed in ResetPassword")
+		return errs.NewUnexpectedError("internal server error")
+	}
+	logger.Info("ResetPassword called", zap.Int("UserID", t.UserID))
+
+	if err := s.repo.Update(ctx, t.UserID, map[string]interface{}{"password_hash": hash}); err != nil {
+		logger.ErrorErr(err, "repo.Update failed in ResetPassword", zap.Int("UserID", t.UserID))
+		return errs.NewUnexpectedError("internal server error")
+	}
+	if err := s.LogoutAll(ctx, t.UserID); err != nil {
+		return err
+	}
+	return s.unlock(ctx, t.UserID, "unlocked by password reset", device)
+}
+
+func (s *UserService) sendVerification(ctx context.Context, u *domain.User) error {
+	if u.Email == "" {
+		return nil
+	}
+
+	ttl := time.Duration(viper.GetInt("auth.verify_email_hours")) * time.Hour
+	raw, err := s.createAccountToken(ctx, u, domain.AccountTokenVerifyEmail, ttl)
+	if err != nil {
+		return err
+	}
+
+	msg := &domain.EmailMessage{
+		To:      u.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %d hours.\n\n%s",
+			u.Username, int(ttl.Hours()), accountLink("/verify-email", raw)),
+	}
+	return s.emailRepo.Send(ctx, msg)
+}
+
+// createAccountToken stores a new token for u and returns the raw value to
+// put in the link.
+func (s *UserService) createAccountToken(ctx context.Context, u *domain.User, purpose string, ttl time.Duration) (string, error) {
+	raw, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	t := &domain.AccountToken{
+		UserID:    u.UserID,
+		Purpose:   purpose,
+		TokenHash: hashToken(raw),
+		Email:     u.Email,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.accountTokenRepo.CreateAccountToken(ctx, t); err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+func accountLink(path, token string) string {
+	return strings.TrimRight(viper.GetString("app.frontend_url"), "/") + path + "?token=" + token
+}
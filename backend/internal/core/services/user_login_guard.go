@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/common/logger"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Failed logins are counted per username and per client IP within
+// auth.lockout_window_minutes:
+//   - after auth.login_free_attempts failures on a username, each further try
+//     must wait a delay that doubles per failure, up to auth.login_max_delay_seconds;
+//   - at auth.lockout_threshold failures the username is locked for
+//     auth.lockout_minutes and the owner is mailed an unlock link;
+//   - at auth.ip_block_threshold failures the IP is blocked for as long.
+//
+// Unknown usernames are counted, locked and timed like real ones, so the
+// answers do not reveal which accounts exist. A broken attempt store is logged
+// and ignored rather than locking everybody out. The IP is the client's own
+// address as the web layer resolved it behind the trusted proxy.
+
+// dummyPasswordHash is compared against for unknown usernames, at the same
+// cost as real hashes.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+func userAttemptKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// checkLoginAllowed rejects the attempt before the password is looked at.
+func (s *UserService) checkLoginAllowed(ctx context.Context, username string, device domain.Device) error {
+	now := time.Now()
+
+	if ip, ok := s.loginAttempt(ctx, ipAttemptKey(device.IPAddress)); ok && ip.Locked(now) {
+		return errs.NewTooManyRequestsError("too many failed logins from this address, please try again later")
+	}
+
+	a, ok := s.loginAttempt(ctx, userAttemptKey(username))
+	if !ok {
+		return nil
+	}
+	if a.Locked(now) {
+		s.audit(ctx, &domain.AuditEntry{
+			Event:   domain.AuditLoginWhileLocked,
+			Details: fmt.Sprintf("locked until %s", a.LockedUntil.Format(time.RFC3339)),
+		}, nil, username, device)
+		return errs.NewTooManyRequestsError("this account is temporarily locked, check your email to unlock it or try again later")
+	}
+	if wait := time.Until(a.LastFailedAt.Add(loginDelay(a.Failures))); wait > 0 {
+		return errs.NewTooManyRequestsError(fmt.Sprintf("too many failed logins, please wait %d seconds", int(wait.Seconds())+1))
+	}
+	return nil
+}
+
+// recordLoginFailure counts a wrong username or password. u is nil when the
+// username does not exist.
+func (s *UserService) recordLoginFailure(ctx context.Context, u *domain.User, username string, device domain.Device) {
+	window := time.Duration(viper.GetInt("auth.lockout_window_minutes")) * time.Minute
+	lockFor := time.Duration(viper.GetInt("auth.lockout_minutes")) * time.Minute
+
+	a, err := s.loginAttempts.RecordLoginFailure(ctx, userAttemptKey(username), window)
+	if err != nil {
+		logger.ErrorErr(err, "failed to record login failure", zap.String("Username", username))
+	} else {
+		switch {
+		case a.Failures >= viper.GetInt("auth.lockout_threshold") && !a.Locked(time.Now()):
+			s.lockAccount(ctx, a.Key, u, username, device, time.Now().Add(lockFor))
+		case a.Failures == viper.GetInt("auth.login_free_attempts"):
+			s.audit(ctx, &domain.AuditEntry{
+				Event:   domain.AuditLoginThrottled,
+				Details: fmt.Sprintf("%d failed logins", a.Failures),
+			}, u, username, device)
+		}
+	}
+
+	ip, err := s.loginAttempts.RecordLoginFailure(ctx, ipAttemptKey(device.IPAddress), window)
+	if err != nil {
+		logger.ErrorErr(err, "failed to record login failure", zap.String("IP", device.IPAddress))
+		return
+	}
+	if ip.Failures >= viper.GetInt("auth.ip_block_threshold") && !ip.Locked(time.Now()) {
+		until := time.Now().Add(lockFor)
+		if err := s.loginAttempts.LockLogin(ctx, ip.Key, until); err != nil {
+			logger.ErrorErr(err, "failed to block IP", zap.String("IP", device.IPAddress))
+			return
+		}
+		logger.Warn("IP blocked after failed logins", zap.String("IP", device.IPAddress), zap.Int("Failures", ip.Failures))
+		s.audit(ctx, &domain.AuditEntry{
+			Event:   domain.AuditIPBlocked,
+			Details: fmt.Sprintf("%d failed logins, blocked until %s", ip.Failures, until.Format(time.RFC3339)),
+		}, nil, username, device)
+	}
+}
+
+func (s *UserService) lockAccount(ctx context.Context, key string, u *domain.User, username string, device domain.Device, until time.Time) {
+	if err := s.loginAttempts.LockLogin(ctx, key, until); err != nil {
+		logger.ErrorErr(err, "failed to lock account", zap.String("Username", username))
+		return
+	}
+	logger.Warn("account locked after failed logins", zap.String("Username", username))
+	s.audit(ctx, &domain.AuditEntry{
+		Event:   domain.AuditAccountLocked,
+		Details: fmt.Sprintf("locked until %s", until.Format(time.RFC3339)),
+	}, u, username, device)
+
+	if u == nil {
+		return
+	}
+	// Sent in the background so a locked real account answers as fast as a
+	// locked unknown one.
+	go func(u domain.User) {
+		if err := s.sendUnlockEmail(context.Background(), &u, until); err != nil {
+			logger.ErrorErr(err, "failed to send unlock email", zap.Int("UserID", u.UserID))
+		}
+	}(*u)
+}
+
+func (s *UserService) clearLoginFailures(ctx context.Context, username string) {
+	if err := s.loginAttempts.ResetLoginAttempts(ctx, userAttemptKey(username)); err != nil {
+		logger.ErrorErr(err, "failed to reset login attempts", zap.String("Username", username))
+	}
+}
+
+// UnlockAccount spends an unlock token from the lockout email.
+func (s *UserService) UnlockAccount(ctx context.Context, token string, device domain.Device) error {
+	if token == "" {
+		return errs.NewValidationError("token is required")
+	}
+
+	t, err := s.accountTokenRepo.ConsumeAccountToken(ctx, domain.AccountTokenUnlockAccount, hashToken(token))
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.NewValidationError("invalid or expired unlock link")
+		}
+		logger.ErrorErr(err, "repo.ConsumeAccountToken failed in UnlockAccount")
+		return errs.NewUnexpectedError("internal server error")
+	}
+	logger.Info("UnlockAccount called", zap.Int("UserID", t.UserID))
+
+	return s.unlock(ctx, t.UserID, "unlocked from email link", device)
+}
+
+// UnlockUser lets staff lift a lockout without waiting for it to expire.
+func (s *UserService) UnlockUser(ctx context.Context, userID, actorID int, device domain.Device) error {
+	logger.Info("UnlockUser called", zap.Int("UserID", userID), zap.Int("ActorID", actorID))
+
+	return s.unlock(ctx, userID, fmt.Sprintf("unlocked by user %d", actorID), device)
+}
+
+func (s *UserService) unlock(ctx context.Context, userID int, details string, device domain.Device) error {
+	u, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	key := userAttemptKey(u.Username)
+	a, tracked := s.loginAttempt(ctx, key)
+	if err := s.loginAttempts.ResetLoginAttempts(ctx, key); err != nil {
+		logger.ErrorErr(err, "failed to reset login attempts", zap.Int("UserID", userID))
+		return errs.NewUnexpectedError("failed to unlock account")
+	}
+	if tracked && a.Locked(time.Now()) {
+		s.audit(ctx, &domain.AuditEntry{Event: domain.AuditAccountUnlocked, Details: details}, u, u.Username, device)
+	}
+	return nil
+}
+
+func (s *UserService) GetAuditLog(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	if filter.Limit <= 0 || filter.Limit > 500 {
+		filter.Limit = 100
+	}
+
+	entries, err := s.auditRepo.ListAudit(ctx, filter)
+	if err != nil {
+		logger.ErrorErr(err, "repo.ListAudit failed")
+		return nil, errs.NewUnexpectedError("internal server error")
+	}
+	return entries, nil
+}
+
+func (s *UserService) CleanupLoginAttempts(ctx context.Context) (int64, error) {
+	logger.Info("CleanupLoginAttempts called")
+
+	window := time.Duration(viper.GetInt("auth.lockout_window_minutes")) * time.Minute
+	rows, err := s.loginAttempts.DeleteStaleLoginAttempts(ctx, time.Now().Add(-window))
+	if err != nil {
+		logger.ErrorErr(err, "CleanupLoginAttempts failed")
+		return 0, err
+	}
+	logger.Info("stale login attempts cleaned up", zap.Int64("rowsAffected", rows))
+	return rows, nil
+}
+
+func (s *UserService) sendUnlockEmail(ctx context.Context, u *domain.User, until time.Time) error {
+	ttl := time.Duration(viper.GetInt("auth.unlock_account_hours")) * time.Hour
+	raw, err := s.createAccountToken(ctx, u, domain.AccountTokenUnlockAccount, ttl)
+	if err != nil {
+		return err
+	}
+
+	msg := &domain.EmailMessage{
+		To:      u.Email,
+		Subject: "Your account has been locked",
+		Body: fmt.Sprintf("Hi %s,\n\nWe locked your account after several failed login attempts. "+
+			"It unlocks by itself at %s.\n\nIf this was you, you can unlock it now:\n\n%s\n\n"+
+			"If it was not you, someone may be guessing your password. Consider resetting it.",
+			u.Username, until.Format("02 Jan 2006 15:04"), accountLink("/unlock-account", raw)),
+	}
+	return s.emailRepo.Send(ctx, msg)
+}
+
+// loginAttempt reads key from the store; ok is false when there is nothing
+// recorded or the store failed.
+func (s *UserService) loginAttempt(ctx context.Context, key string) (*domain.LoginAttempt, bool) {
+	a, err := s.loginAttempts.GetLoginAttempt(ctx, key)
+	if err != nil {
+		if !errors.Is(err, errs.ErrNotFound) {
+			logger.ErrorErr(err, "failed to read login attempts", zap.String("Key", key))
+		}
+		return nil, false
+	}
+	return a, true
+}
+
+// loginDelay is how long to wait after the last failure before another try
+// is allowed.
+func loginDelay(failures int) time.Duration {
+	extra := failures - viper.GetInt("auth.login_free_attempts")
+	if extra < 0 {
+		return 0
+	}
+	maxDelay := time.Duration(viper.GetInt("auth.login_max_delay_seconds")) * time.Second
+	delay := time.Duration(viper.GetInt("auth.login_delay_seconds")) * time.Second
+	for ; extra > 0 && delay < maxDelay; extra-- {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+func (s *UserService) audit(ctx context.Context, entry *domain.AuditEntry, u *domain.User, username string, device domain.Device) {
+	if u != nil {
+		entry.UserID = u.UserID
+	}
+	entry.Username = username
+	entry.IPAddress = device.IPAddress
+	entry.UserAgent = device.UserAgent
+
+	if err := s.auditRepo.RecordAudit(ctx, entry); err != nil {
+		logger.ErrorErr(err, "failed to write audit entry", zap.String("Event", entry.Event))
+	}
+}
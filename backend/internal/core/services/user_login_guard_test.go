@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/adapters/secondary/memory"
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"github.com/spf13/viper"
+)
+
+func setAuthConfig(t *testing.T, values map[string]int) {
+	t.Helper()
+	for key, value := range values {
+		viper.Set("auth."+key, value)
+	}
+	t.Cleanup(func() {
+		for key := range values {
+			viper.Set("auth."+key, nil)
+		}
+	})
+}
+
+func TestLoginDelay(t *testing.T) {
+	setAuthConfig(t, map[string]int{"login_free_attempts": 3, "login_delay_seconds": 1, "login_max_delay_seconds": 30})
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{2, 0},
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{5, 4 * time.Second},
+		{7, 16 * time.Second},
+		{8, 30 * time.Second},
+		{1000, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := loginDelay(tt.failures); got != tt.want {
+			t.Errorf("loginDelay(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+
+	viper.Set("auth.login_max_delay_seconds", 5)
+	if got := loginDelay(6); got != 5*time.Second {
+		t.Errorf("loginDelay(6) with a 5s cap = %v, want 5s", got)
+	}
+}
+
+// auditLog records audit entries in memory.
+type auditLog struct {
+	ports.AuditRepository
+	entries []*domain.AuditEntry
+}
+
+func (l *auditLog) RecordAudit(_ context.Context, entry *domain.AuditEntry) error {
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func (l *auditLog) count(event string) int {
+	n := 0
+	for _, e := range l.entries {
+		if e.Event == event {
+			n++
+		}
+	}
+	return n
+}
+
+func newGuardedUserService() (*UserService, ports.LoginAttemptStore, *auditLog) {
+	store := memory.NewLoginAttemptStore()
+	audit := &auditLog{}
+	return &UserService{loginAttempts: store, auditRepo: audit}, store, audit
+}
+
+func TestRecordLoginFailureLocksAccount(t *testing.T) {
+	setAuthConfig(t, map[string]int{
+		"login_free_attempts":     3,
+		"login_delay_seconds":     1,
+		"login_max_delay_seconds": 30,
+		"lockout_threshold":       5,
+		"lockout_window_minutes":  15,
+		"lockout_minutes":         30,
+		"ip_block_threshold":      100,
+	})
+	ctx := context.Background()
+	svc, store, audit := newGuardedUserService()
+	device := domain.Device{IPAddress: "203.0.113.7"}
+
+	// Unknown usernames are locked like real ones; u stays nil so no unlock
+	// email is sent.
+	for i := 1; i <= 2; i++ {
+		svc.recordLoginFailure(ctx, nil, "ghost", device)
+	}
+	if err := svc.checkLoginAllowed(ctx, "ghost", device); err != nil {
+		t.Fatalf("after 2 failures: %v", err)
+	}
+
+	svc.recordLoginFailure(ctx, nil, " Ghost", device)
+	if n := audit.count(domain.AuditLoginThrottled); n != 1 {
+		t.Errorf("throttled audited %d times, want 1", n)
+	}
+	if err := svc.checkLoginAllowed(ctx, "ghost", device); !errors.Is(err, errs.ErrTooManyRequests) {
+		t.Errorf("right after the 3rd failure: got %v, want too many requests", err)
+	}
+
+	svc.recordLoginFailure(ctx, nil, "ghost", device)
+	if a, _ := store.GetLoginAttempt(ctx, userAttemptKey("ghost")); a.Locked(time.Now()) {
+		t.Fatal("locked after 4 failures, threshold is 5")
+	}
+
+	svc.recordLoginFailure(ctx, nil, "ghost", device)
+	a, err := store.GetLoginAttempt(ctx, userAttemptKey("ghost"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.Locked(time.Now().Add(29*time.Minute)) || a.Locked(time.Now().Add(31*time.Minute)) {
+		t.Errorf("locked until %v, want 30 minutes from now", a.LockedUntil)
+	}
+
+	// Further failures while locked do not extend or re-announce the lock.
+	svc.recordLoginFailure(ctx, nil, "ghost", device)
+	if n := audit.count(domain.AuditAccountLocked); n != 1 {
+		t.Errorf("lock audited %d times, want 1", n)
+	}
+	if err := svc.checkLoginAllowed(ctx, "GHOST", device); !errors.Is(err, errs.ErrTooManyRequests) {
+		t.Errorf("while locked: got %v, want too many requests", err)
+	}
+	if err := svc.checkLoginAllowed(ctx, "someone", device); err != nil {
+		t.Errorf("another username from the same IP: %v", err)
+	}
+}
+
+func TestRecordLoginFailureBlocksIP(t *testing.T) {
+	setAuthConfig(t, map[string]int{
+		"login_free_attempts":    3,
+		"lockout_threshold":      10,
+		"lockout_window_minutes": 15,
+		"lockout_minutes":        30,
+		"ip_block_threshold":     3,
+	})
+	ctx := context.Background()
+	svc, store, audit := newGuardedUserService()
+	device := domain.Device{IPAddress: "203.0.113.7"}
+
+	// One failure each on different usernames, so only the IP adds up.
+	for _, username := range []string{"alice", "bob"} {
+		svc.recordLoginFailure(ctx, nil, username, device)
+	}
+	if err := svc.checkLoginAllowed(ctx, "carol", device); err != nil {
+		t.Fatalf("after 2 failures: %v", err)
+	}
+
+	svc.recordLoginFailure(ctx, nil, "carol", device)
+	ip, err := store.GetLoginAttempt(ctx, ipAttemptKey(device.IPAddress))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ip.Locked(time.Now()) {
+		t.Fatal("IP not blocked at the threshold")
+	}
+	if n := audit.count(domain.AuditIPBlocked); n != 1 {
+		t.Errorf("IP block audited %d times, want 1", n)
+	}
+	if n := audit.count(domain.AuditAccountLocked); n != 0 {
+		t.Errorf("account lock audited %d times, want 0", n)
+	}
+
+	if err := svc.checkLoginAllowed(ctx, "dave", device); !errors.Is(err, errs.ErrTooManyRequests) {
+		t.Errorf("from the blocked IP: got %v, want too many requests", err)
+	}
+	if err := svc.checkLoginAllowed(ctx, "dave", domain.Device{IPAddress: "198.51.100.1"}); err != nil {
+		t.Errorf("from another IP: %v", err)
+	}
+}
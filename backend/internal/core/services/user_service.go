@@ -4,26 +4,40 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"fmt"
-	"time"
+	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
 	"github.com/ingwrok/hotelBooking/internal/common/errs"
 	"github.com/ingwrok/hotelBooking/internal/common/logger"
 	"github.com/ingwrok/hotelBooking/internal/core/domain"
 	"github.com/ingwrok/hotelBooking/internal/core/ports"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
-
-	"github.com/spf13/viper"
 )
 
 type UserService struct {
-	repo ports.UserRepoPort
+	repo             ports.UserRepoPort
+	roleRepo         ports.RoleRepository
+	tokenRepo        ports.RefreshTokenRepository
+	accountTokenRepo ports.AccountTokenRepository
+	emailRepo        ports.EmailRepository
+	loginAttempts    ports.LoginAttemptStore
+	auditRepo        ports.AuditRepository
+	twoFactorRepo    ports.TwoFactorRepository
 }
 
-func NewUserService(repo ports.UserRepoPort) *UserService {
-	return &UserService{repo: repo}
+func NewUserService(repo ports.UserRepoPort, roleRepo ports.RoleRepository, tokenRepo ports.RefreshTokenRepository,
+	accountTokenRepo ports.AccountTokenRepository, emailRepo ports.EmailRepository,
+	loginAttempts ports.LoginAttemptStore, auditRepo ports.AuditRepository, twoFactorRepo ports.TwoFactorRepository) *UserService {
+	return &UserService{
+		repo:             repo,
+		roleRepo:         roleRepo,
+		tokenRepo:        tokenRepo,
+		accountTokenRepo: accountTokenRepo,
+		emailRepo:        emailRepo,
+		loginAttempts:    loginAttempts,
+		auditRepo:        auditRepo,
+		twoFactorRepo:    twoFactorRepo,
+	}
 }
 
 func (s *UserService) Register(ctx context.Context, username, email, password string) (*domain.User, error) {
@@ -36,17 +50,16 @@ func (s *UserService) Register(ctx context.Context, username, email, password st
 		return nil, errs.NewValidationError("username already exists")
 	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hash, err := hashPassword(password)
 	if err != nil {
-		logger.ErrorErr(err, "failed to hash password")
-		return nil, errs.NewUnexpectedError("internal server error")
+		return nil, err
 	}
 
 	newUser := &domain.User{
 		Username:     username,
 		Email:        email,
 		PasswordHash: string(hash),
-		IsAdmin:      false,
+		Roles:        []string{domain.RoleGuest},
 	}
 
 	if err := s.repo.Create(ctx, newUser); err != nil {
@@ -54,46 +67,57 @@ func (s *UserService) Register(ctx context.Context, username, email, password st
 		return nil, errs.NewUnexpectedError("failed to create user")
 	}
 	newUser.PasswordHash = ""
+
+	go func(u domain.User) {
+		if err := s.sendVerification(context.Background(), &u); err != nil {
+			logger.ErrorErr(err, "failed to send verification email", zap.Int("UserID", u.UserID))
+		}
+	}(*newUser)
+
 	return newUser, nil
 }
 
-func (s *UserService) Login(ctx context.Context, username string, password string) (string, *domain.User, error) {
+// Login checks the password and starts a new session on device: a
+// short-lived access token plus the first refresh token of a new family.
+// Users with two-factor enabled get a challenge instead, to answer through
+// CompleteLogin. Repeated failures are throttled and lock the account; see
+// checkLoginAllowed.
+func (s *UserService) Login(ctx context.Context, username string, password string, device domain.Device) (*domain.LoginResult, error) {
+	if err := s.checkLoginAllowed(ctx, username, device); err != nil {
+		return nil, err
+	}
+
 	u, err := s.repo.GetByUsername(ctx, username)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return "", nil, errs.NewUnauthorizedError("invalid username or password")
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, errs.ErrNotFound) {
+			// Spend as long as a wrong password would, so the response time
+			// does not tell which usernames exist.
+			bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+			s.recordLoginFailure(ctx, nil, username, device)
+			return nil, errs.NewUnauthorizedError("invalid username or password")
 		}
 		logger.ErrorErr(err, "repo.GetByUsername failed in Login")
-		return "", nil, errs.NewUnexpectedError("internal server error")
+		return nil, errs.NewUnexpectedError("internal server error")
 	}
 	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
-		return "", nil, errs.NewUnauthorizedError("invalid username or password")
+		s.recordLoginFailure(ctx, u, username, device)
+		return nil, errs.NewUnauthorizedError("invalid username or password")
 	}
 
-	sec := viper.GetString("secret")
-	if sec == "" {
-		logger.Error("jwt secret missing")
-		return "", nil, errs.NewUnexpectedError("internal server error")
+	// Failures are only cleared once the second factor is in too, so wrong
+	// codes keep counting towards the lockout.
+	if u.TwoFactorEnabled {
+		return s.startLoginChallenge(ctx, u)
 	}
+	s.clearLoginFailures(ctx, username)
 
-	claims := &middleware.MyCustomClaims{
-		UserID:  u.UserID,
-		IsAdmin: u.IsAdmin,
-		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   fmt.Sprintf("%d", u.UserID),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			Issuer:    "hotel-booking",
-		},
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(sec))
+	pair, err := s.issueTokens(ctx, u, device, nil)
 	if err != nil {
-		return "", nil, errs.NewUnexpectedError("failed to generate token")
+		return nil, err
 	}
 
 	u.PasswordHash = ""
-	return tokenString, u, nil
+	return &domain.LoginResult{Tokens: pair, User: u}, nil
 }
 
 func (s *UserService) UpdateUser(ctx context.Context, userID int, fields map[string]interface{}) error {
@@ -106,19 +130,27 @@ func (s *UserService) UpdateUser(ctx context.Context, userID int, fields map[str
 
 	if v, ok := fields["password"]; ok {
 		if raw, ok2 := v.(string); ok2 && raw != "" {
-			hash, err := bcrypt.GenerateFromPassword([]byte(raw), bcrypt.DefaultCost)
+			hash, err := hashPassword(raw)
 			if err != nil {
-				logger.ErrorErr(err, "failed to hash password during update")
-				return errs.NewUnexpectedError("internal server error")
+				return err
 			}
-			fields["password_hash"] = string(hash)
+			fields["password_hash"] = hash
 			delete(fields, "password")
 		} else {
 			delete(fields, "password")
 		}
 	}
 
+	// Roles change only through AssignRole and RevokeRole.
 	delete(fields, "is_admin")
+	delete(fields, "roles")
+
+	// Verification only comes from VerifyEmail, and a new address needs it again.
+	delete(fields, "email_verified_at")
+	_, emailChanged := fields["email"]
+	if emailChanged {
+		fields["email_verified_at"] = nil
+	}
 
 	if err := s.repo.Update(ctx, userID, fields); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -127,14 +159,52 @@ func (s *UserService) UpdateUser(ctx context.Context, userID int, fields map[str
 		logger.ErrorErr(err, "repo.Update failed")
 		return errs.NewUnexpectedError("internal server error")
 	}
+
+	if emailChanged {
+		go func() {
+			u, err := s.repo.GetByID(context.Background(), userID)
+			if err != nil {
+				logger.ErrorErr(err, "failed to load user for verification email", zap.Int("UserID", userID))
+				return
+			}
+			if err := s.sendVerification(context.Background(), u); err != nil {
+				logger.ErrorErr(err, "failed to send verification email", zap.Int("UserID", userID))
+			}
+		}()
+	}
+
+	// A new password ends every session, including any opened with a stolen token.
+	if _, changed := fields["password_hash"]; changed {
+		return s.LogoutAll(ctx, userID)
+	}
 	return nil
 }
 
+// hashPassword checks a new password and returns its bcrypt hash.
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			return "", errs.NewValidationError("password must be at most 72 bytes")
+		}
+		logger.ErrorErr(err, "failed to hash password")
+		return "", errs.NewUnexpectedError("internal server error")
+	}
+	return string(hash), nil
+}
+
+// DeleteUser removes a user and their bookings. The last admin cannot be
+// deleted, just as the admin role cannot be revoked from them.
 func (s *UserService) DeleteUser(ctx context.Context, userID int) error {
+	logger.Info("DeleteUser called", zap.Int("UserID", userID))
+
 	if err := s.repo.Delete(ctx, userID); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, errs.ErrNotFound) {
 			return errs.NewNotFoundError("user not found")
 		}
+		if errors.Is(err, errs.ErrConflict) {
+			return errs.NewConflictError("cannot delete the last admin")
+		}
 		logger.ErrorErr(err, "repo.Delete failed")
 		return errs.NewUnexpectedError("internal server error")
 	}
@@ -167,3 +237,58 @@ func (s *UserService) GetUsers(ctx context.Context) ([]*domain.User, error) {
 	}
 	return users, nil
 }
+
+func (s *UserService) GetRoles(ctx context.Context) ([]*domain.Role, error) {
+	roles, err := s.roleRepo.GetRoles(ctx)
+	if err != nil {
+		logger.ErrorErr(err, "repo.GetRoles failed")
+		return nil, errs.NewUnexpectedError("internal server error")
+	}
+	return roles, nil
+}
+
+func (s *UserService) AssignRole(ctx context.Context, userID int, role string) (*domain.User, error) {
+	logger.Info("AssignRole called", zap.Int("UserID", userID), zap.String("Role", role))
+
+	role = strings.TrimSpace(role)
+	if userID <= 0 || role == "" {
+		return nil, errs.NewValidationError("user id and role are required")
+	}
+
+	if err := s.roleRepo.AssignRole(ctx, userID, role); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.NewNotFoundError("user or role not found")
+		}
+		if errors.Is(err, errs.ErrConflict) {
+			return nil, errs.NewConflictError("user already has this role")
+		}
+		logger.ErrorErr(err, "repo.AssignRole failed")
+		return nil, errs.NewUnexpectedError("failed to assign role")
+	}
+
+	logger.Info("role assigned", zap.Int("UserID", userID), zap.String("Role", role))
+	return s.GetUser(ctx, userID)
+}
+
+func (s *UserService) RevokeRole(ctx context.Context, userID int, role string) (*domain.User, error) {
+	logger.Info("RevokeRole called", zap.Int("UserID", userID), zap.String("Role", role))
+
+	role = strings.TrimSpace(role)
+	if userID <= 0 || role == "" {
+		return nil, errs.NewValidationError("user id and role are required")
+	}
+
+	if err := s.roleRepo.RevokeRole(ctx, userID, role); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.NewNotFoundError("user does not have this role")
+		}
+		if errors.Is(err, errs.ErrConflict) {
+			return nil, errs.NewConflictError("cannot revoke the admin role from the last admin")
+		}
+		logger.ErrorErr(err, "repo.RevokeRole failed")
+		return nil, errs.NewUnexpectedError("failed to revoke role")
+	}
+
+	logger.Info("role revoked", zap.Int("UserID", userID), zap.String("Role", role))
+	return s.GetUser(ctx, userID)
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/ingwrok/hotelBooking/internal/adapters/primary/web/middleware"
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/common/logger"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Refresh exchanges a refresh token for a new pair. The presented token is
+// spent; presenting it again means it leaked, so its whole family is revoked
+// and the device has to log in again.
+func (s *UserService) Refresh(ctx context.Context, refreshToken string, device domain.Device) (*domain.TokenPair, *domain.User, error) {
+	if refreshToken == "" {
+		return nil, nil, errs.NewValidationError("refresh token is required")
+	}
+
+	stored, err := s.tokenRepo.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, nil, errs.NewUnauthorizedError("invalid refresh token")
+		}
+		logger.ErrorErr(err, "repo.GetRefreshTokenByHash failed")
+		return nil, nil, errs.NewUnexpectedError("internal server error")
+	}
+	logger.Info("Refresh called", zap.Int("UserID", stored.UserID), zap.String("FamilyID", stored.FamilyID))
+
+	if stored.Spent() {
+		return nil, nil, s.revokeReusedFamily(ctx, stored)
+	}
+	if !time.Now().Before(stored.ExpiresAt) {
+		return nil, nil, errs.NewUnauthorizedError("refresh token expired, please log in again")
+	}
+
+	u, err := s.repo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, nil, errs.NewUnauthorizedError("invalid refresh token")
+		}
+		logger.ErrorErr(err, "repo.GetByID failed in Refresh")
+		return nil, nil, errs.NewUnexpectedError("internal server error")
+	}
+
+	pair, err := s.issueTokens(ctx, u, device, stored)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u.PasswordHash = ""
+	return pair, u, nil
+}
+
+// Logout ends the session the refresh token belongs to. Unknown tokens are
+// ignored so logging out twice is harmless.
+func (s *UserService) Logout(ctx context.Context, refreshToken string) error {
+	if refreshToken == "" {
+		return nil
+	}
+
+	stored, err := s.tokenRepo.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil
+		}
+		logger.ErrorErr(err, "repo.GetRefreshTokenByHash failed in Logout")
+		return errs.NewUnexpectedError("internal server error")
+	}
+	logger.Info("Logout called", zap.Int("UserID", stored.UserID), zap.String("FamilyID", stored.FamilyID))
+
+	if err := s.tokenRepo.RevokeTokenFamily(ctx, stored.FamilyID); err != nil {
+		logger.ErrorErr(err, "repo.RevokeTokenFamily failed")
+		return errs.NewUnexpectedError("failed to log out")
+	}
+	return nil
+}
+
+// LogoutAll revokes every refresh token of the user and, by raising the token
+// version, every access token already issued.
+func (s *UserService) LogoutAll(ctx context.Context, userID int) error {
+	logger.Info("LogoutAll called", zap.Int("UserID", userID))
+
+	if err := s.repo.BumpTokenVersion(ctx, userID); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.NewNotFoundError("user not found")
+		}
+		logger.ErrorErr(err, "repo.BumpTokenVersion failed")
+		return errs.NewUnexpectedError("failed to log out")
+	}
+	if err := s.tokenRepo.RevokeUserTokens(ctx, userID); err != nil {
+		logger.ErrorErr(err, "repo.RevokeUserTokens failed")
+		return errs.NewUnexpectedError("failed to log out")
+	}
+	return nil
+}
+
+func (s *UserService) CleanupExpiredTokens(ctx context.Context) (int64, error) {
+	logger.Info("CleanupExpiredTokens called")
+
+	rows, err := s.tokenRepo.DeleteExpiredRefreshTokens(ctx)
+	if err != nil {
+		logger.ErrorErr(err, "CleanupExpiredTokens failed")
+		return 0, err
+	}
+	logger.Info("expired refresh tokens cleaned up", zap.Int64("rowsAffected", rows))
+	return rows, nil
+}
+
+func (s *UserService) revokeReusedFamily(ctx context.Context, stored *domain.RefreshToken) error {
+	logger.Warn("refresh token reuse detected, revoking family",
+		zap.Int("UserID", stored.UserID),
+		zap.String("FamilyID", stored.FamilyID),
+	)
+	if err := s.tokenRepo.RevokeTokenFamily(ctx, stored.FamilyID); err != nil {
+		logger.ErrorErr(err, "repo.RevokeTokenFamily failed")
+		return errs.NewUnexpectedError("internal server error")
+	}
+	return errs.NewUnauthorizedError("refresh token was already used, please log in again")
+}
+
+// issueTokens signs an access token for u and stores a new refresh token:
+// the successor of prev when rotating, else the first of a new family.
+func (s *UserService) issueTokens(ctx context.Context, u *domain.User, device domain.Device, prev *domain.RefreshToken) (*domain.TokenPair, error) {
+	sec := viper.GetString("secret")
+	if sec == "" {
+		logger.Error("jwt secret missing")
+		return nil, errs.NewUnexpectedError("internal server error")
+	}
+
+	now := time.Now()
+	accessExpiresAt := now.Add(time.Duration(viper.GetInt("auth.access_token_minutes")) * time.Minute)
+	claims := &middleware.MyCustomClaims{
+		UserID:       u.UserID,
+		Roles:        u.Roles,
+		Permissions:  u.Permissions,
+		TokenVersion: u.TokenVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   fmt.Sprintf("%d", u.UserID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(accessExpiresAt),
+			Issuer:    "hotel-booking",
+		},
+	}
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(sec))
+	if err != nil {
+		return nil, errs.NewUnexpectedError("failed to generate token")
+	}
+
+	raw, err := newOpaqueToken()
+	if err != nil {
+		logger.ErrorErr(err, "failed to generate refresh token")
+		return nil, errs.NewUnexpectedError("failed to generate token")
+	}
+	next := &domain.RefreshToken{
+		UserID:    u.UserID,
+		TokenHash: hashToken(raw),
+		UserAgent: device.UserAgent,
+		IPAddress: device.IPAddress,
+		ExpiresAt: now.Add(time.Duration(viper.GetInt("auth.refresh_token_days")) * 24 * time.Hour),
+	}
+
+	if prev == nil {
+		next.FamilyID = uuid.NewString()
+		err = s.tokenRepo.CreateRefreshToken(ctx, next)
+	} else {
+		next.FamilyID = prev.FamilyID
+		err = s.tokenRepo.RotateRefreshToken(ctx, prev.TokenID, next)
+		if errors.Is(err, errs.ErrConflict) {
+			// Another request spent the token first: the same reuse as above.
+			return nil, s.revokeReusedFamily(ctx, prev)
+		}
+	}
+	if err != nil {
+		logger.ErrorErr(err, "failed to store refresh token", zap.Int("UserID", u.UserID))
+		return nil, errs.NewUnexpectedError("failed to generate token")
+	}
+
+	return &domain.TokenPair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     raw,
+		RefreshExpiresAt: next.ExpiresAt,
+	}, nil
+}
+
+func newOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,413 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ingwrok/hotelBooking/internal/common/errs"
+	"github.com/ingwrok/hotelBooking/internal/common/logger"
+	"github.com/ingwrok/hotelBooking/internal/core/domain"
+	"github.com/ingwrok/hotelBooking/internal/core/utils"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// CompleteLogin is the second step of Login for users with two-factor
+// enabled: the challenge token plus an authenticator or recovery code buys
+// the session tokens.
+func (s *UserService) CompleteLogin(ctx context.Context, challengeToken, code string, device domain.Device) (*domain.TokenPair, *domain.User, error) {
+	if challengeToken == "" || code == "" {
+		return nil, nil, errs.NewValidationError("challenge token and code are required")
+	}
+
+	ch, err := s.twoFactorRepo.GetLoginChallengeByHash(ctx, hashToken(challengeToken))
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, nil, errs.NewUnauthorizedError("invalid or expired login, please log in again")
+		}
+		logger.ErrorErr(err, "repo.GetLoginChallengeByHash failed")
+		return nil, nil, errs.NewUnexpectedError("internal server error")
+	}
+	logger.Info("CompleteLogin called", zap.Int("UserID", ch.UserID))
+
+	if !ch.UsedAt.IsZero() || !time.Now().Before(ch.ExpiresAt) ||
+		ch.FailedAttempts >= viper.GetInt("auth.login_challenge_max_attempts") {
+		return nil, nil, errs.NewUnauthorizedError("invalid or expired login, please log in again")
+	}
+
+	u, err := s.repo.GetByID(ctx, ch.UserID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, nil, errs.NewUnauthorizedError("invalid or expired login, please log in again")
+		}
+		logger.ErrorErr(err, "repo.GetByID failed in CompleteLogin")
+		return nil, nil, errs.NewUnexpectedError("internal server error")
+	}
+	if err := s.checkLoginAllowed(ctx, u.Username, device); err != nil {
+		return nil, nil, err
+	}
+
+	ok, err := s.verifySecondFactor(ctx, u, code, device)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		if err := s.twoFactorRepo.FailLoginChallenge(ctx, ch.ChallengeID); err != nil {
+			logger.ErrorErr(err, "repo.FailLoginChallenge failed")
+		}
+		s.audit(ctx, &domain.AuditEntry{Event: domain.AuditTwoFactorFailed}, u, u.Username, device)
+		s.recordLoginFailure(ctx, u, u.Username, device)
+		return nil, nil, errs.NewUnauthorizedError("invalid code")
+	}
+
+	if err := s.twoFactorRepo.ConsumeLoginChallenge(ctx, ch.ChallengeID); err != nil {
+		if errors.Is(err, errs.ErrConflict) {
+			return nil, nil, errs.NewUnauthorizedError("invalid or expired login, please log in again")
+		}
+		logger.ErrorErr(err, "repo.ConsumeLoginChallenge failed")
+		return nil, nil, errs.NewUnexpectedError("internal server error")
+	}
+	s.clearLoginFailures(ctx, u.Username)
+
+	pair, err := s.issueTokens(ctx, u, device, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u.PasswordHash = ""
+	return pair, u, nil
+}
+
+// SetupTwoFactor starts enrolment with a new secret. It replaces any earlier
+// enrolment that was never confirmed.
+func (s *UserService) SetupTwoFactor(ctx context.Context, userID int) (*domain.TwoFactorSetup, error) {
+	logger.Info("SetupTwoFactor called", zap.Int("UserID", userID))
+
+	u, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.TwoFactorEnabled {
+		return nil, errs.NewConflictError("two-factor authentication is already enabled")
+	}
+
+	secret, err := utils.NewTOTPSecret()
+	if err != nil {
+		logger.ErrorErr(err, "failed to generate totp secret")
+		return nil, errs.NewUnexpectedError("internal server error")
+	}
+	sealed, err := utils.SealSecret(totpEncryptionKey(), secret)
+	if err != nil {
+		logger.ErrorErr(err, "failed to seal totp secret")
+		return nil, errs.NewUnexpectedError("internal server error")
+	}
+
+	if err := s.twoFactorRepo.SaveTOTP(ctx, userID, sealed); err != nil {
+		if errors.Is(err, errs.ErrConflict) {
+			return nil, errs.NewConflictError("two-factor authentication is already enabled")
+		}
+		logger.ErrorErr(err, "repo.SaveTOTP failed")
+		return nil, errs.NewUnexpectedError("internal server error")
+	}
+
+	account := u.Email
+	if account == "" {
+		account = u.Username
+	}
+	return &domain.TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(viper.GetString("auth.totp_issuer"), account, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor enables two-factor once the user shows a code from the new
+// secret, and returns the recovery codes. They are shown only this once.
+// Sessions opened with just a password are ended.
+func (s *UserService) ConfirmTwoFactor(ctx context.Context, userID int, code string, device domain.Device) ([]string, error) {
+	logger.Info("ConfirmTwoFactor called", zap.Int("UserID", userID))
+
+	t, err := s.twoFactorRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.NewValidationError("start two-factor setup first")
+		}
+		logger.ErrorErr(err, "repo.GetTOTP failed")
+		return nil, errs.NewUnexpectedError("internal server error")
+	}
+	if t.Confirmed() {
+		return nil, errs.NewConflictError("two-factor authentication is already enabled")
+	}
+
+	secret, err := utils.OpenSecret(totpEncryptionKey(), t.Secret)
+	if err != nil {
+		logger.ErrorErr(err, "failed to open totp secret", zap.Int("UserID", userID))
+		return nil, errs.NewUnexpectedError("internal server error")
+	}
+	step, ok := utils.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return nil, errs.NewValidationError("invalid code")
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		logger.ErrorErr(err, "failed to generate recovery codes")
+		return nil, errs.NewUnexpectedError("internal server error")
+	}
+	if err := s.twoFactorRepo.ConfirmTOTP(ctx, userID, step, hashes); err != nil {
+		if errors.Is(err, errs.ErrConflict) {
+			return nil, errs.NewConflictError("two-factor authentication is already enabled")
+		}
+		logger.ErrorErr(err, "repo.ConfirmTOTP failed")
+		return nil, errs.NewUnexpectedError("internal server error")
+	}
+
+	u, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit(ctx, &domain.AuditEntry{Event: domain.AuditTwoFactorEnabled}, u, u.Username, device)
+
+	if err := s.LogoutAll(ctx, userID); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor turns two-factor off after checking a current code.
+func (s *UserService) DisableTwoFactor(ctx context.Context, userID int, code string, device domain.Device) error {
+	logger.Info("DisableTwoFactor called", zap.Int("UserID", userID))
+
+	u, err := s.requireSecondFactor(ctx, userID, code, device)
+	if err != nil {
+		return err
+	}
+
+	if err := s.twoFactorRepo.DeleteTOTP(ctx, userID); err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.ErrorErr(err, "repo.DeleteTOTP failed")
+		return errs.NewUnexpectedError("internal server error")
+	}
+	s.audit(ctx, &domain.AuditEntry{Event: domain.AuditTwoFactorDisabled}, u, u.Username, device)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a
+// current code.
+func (s *UserService) RegenerateRecoveryCodes(ctx context.Context, userID int, code string, device domain.Device) ([]string, error) {
+	logger.Info("RegenerateRecoveryCodes called", zap.Int("UserID", userID))
+
+	if _, err := s.requireSecondFactor(ctx, userID, code, device); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		logger.ErrorErr(err, "failed to generate recovery codes")
+		return nil, errs.NewUnexpectedError("internal server error")
+	}
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		logger.ErrorErr(err, "repo.ReplaceRecoveryCodes failed")
+		return nil, errs.NewUnexpectedError("internal server error")
+	}
+	return codes, nil
+}
+
+// ResetTwoFactor lets staff remove the enrolment of a user who lost both
+// their authenticator and recovery codes. Their sessions are ended.
+func (s *UserService) ResetTwoFactor(ctx context.Context, userID, actorID int, device domain.Device) error {
+	logger.Info("ResetTwoFactor called", zap.Int("UserID", userID), zap.Int("ActorID", actorID))
+
+	u, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.twoFactorRepo.DeleteTOTP(ctx, userID); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.NewNotFoundError("user has no two-factor enrolment")
+		}
+		logger.ErrorErr(err, "repo.DeleteTOTP failed")
+		return errs.NewUnexpectedError("internal server error")
+	}
+	s.audit(ctx, &domain.AuditEntry{
+		Event:   domain.AuditTwoFactorDisabled,
+		Details: fmt.Sprintf("reset by user %d", actorID),
+	}, u, u.Username, device)
+
+	return s.LogoutAll(ctx, userID)
+}
+
+func (s *UserService) GetTwoFactorStatus(ctx context.Context, userID int) (*domain.TwoFactorStatus, error) {
+	u, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &domain.TwoFactorStatus{Enabled: u.TwoFactorEnabled, Required: u.TwoFactorRequired}
+	if u.TwoFactorEnabled {
+		status.RecoveryCodesLeft, err = s.twoFactorRepo.CountRecoveryCodes(ctx, userID)
+		if err != nil {
+			logger.ErrorErr(err, "repo.CountRecoveryCodes failed")
+			return nil, errs.NewUnexpectedError("internal server error")
+		}
+	}
+	return status, nil
+}
+
+// SetRoleTwoFactor makes two-factor mandatory, or optional again, for
+// everyone holding role.
+func (s *UserService) SetRoleTwoFactor(ctx context.Context, role string, required bool) error {
+	logger.Info("SetRoleTwoFactor called", zap.String("Role", role), zap.Bool("Required", required))
+
+	if err := s.roleRepo.SetRoleTwoFactor(ctx, strings.TrimSpace(role), required); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.NewNotFoundError("role not found")
+		}
+		logger.ErrorErr(err, "repo.SetRoleTwoFactor failed")
+		return errs.NewUnexpectedError("internal server error")
+	}
+	return nil
+}
+
+func (s *UserService) CleanupLoginChallenges(ctx context.Context) (int64, error) {
+	logger.Info("CleanupLoginChallenges called")
+
+	rows, err := s.twoFactorRepo.DeleteExpiredLoginChallenges(ctx)
+	if err != nil {
+		logger.ErrorErr(err, "CleanupLoginChallenges failed")
+		return 0, err
+	}
+	logger.Info("expired login challenges cleaned up", zap.Int64("rowsAffected", rows))
+	return rows, nil
+}
+
+// startLoginChallenge is issued by Login in place of tokens once the password
+// is right for a user with two-factor enabled.
+func (s *UserService) startLoginChallenge(ctx context.Context, u *domain.User) (*domain.LoginResult, error) {
+	raw, err := newOpaqueToken()
+	if err != nil {
+		logger.ErrorErr(err, "failed to generate login challenge")
+		return nil, errs.NewUnexpectedError("internal server error")
+	}
+
+	ch := &domain.LoginChallenge{
+		UserID:    u.UserID,
+		TokenHash: hashToken(raw),
+		ExpiresAt: time.Now().Add(time.Duration(viper.GetInt("auth.login_challenge_minutes")) * time.Minute),
+	}
+	if err := s.twoFactorRepo.CreateLoginChallenge(ctx, ch); err != nil {
+		logger.ErrorErr(err, "repo.CreateLoginChallenge failed", zap.Int("UserID", u.UserID))
+		return nil, errs.NewUnexpectedError("internal server error")
+	}
+
+	return &domain.LoginResult{ChallengeToken: raw, ChallengeExpiresAt: ch.ExpiresAt}, nil
+}
+
+// requireSecondFactor loads a user with two-factor enabled and checks code.
+// Wrong codes count as failed logins.
+func (s *UserService) requireSecondFactor(ctx context.Context, userID int, code string, device domain.Device) (*domain.User, error) {
+	u, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.TwoFactorEnabled {
+		return nil, errs.NewConflictError("two-factor authentication is not enabled")
+	}
+	if err := s.checkLoginAllowed(ctx, u.Username, device); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.verifySecondFactor(ctx, u, code, device)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		s.audit(ctx, &domain.AuditEntry{Event: domain.AuditTwoFactorFailed}, u, u.Username, device)
+		s.recordLoginFailure(ctx, u, u.Username, device)
+		return nil, errs.NewValidationError("invalid code")
+	}
+	return u, nil
+}
+
+// verifySecondFactor accepts a current authenticator code, once, or an
+// unused recovery code.
+func (s *UserService) verifySecondFactor(ctx context.Context, u *domain.User, code string, device domain.Device) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) != utils.TOTPDigits {
+		return s.useRecoveryCode(ctx, u, code, device)
+	}
+
+	t, err := s.twoFactorRepo.GetTOTP(ctx, u.UserID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return false, nil
+		}
+		logger.ErrorErr(err, "repo.GetTOTP failed")
+		return false, errs.NewUnexpectedError("internal server error")
+	}
+	if !t.Confirmed() {
+		return false, nil
+	}
+
+	secret, err := utils.OpenSecret(totpEncryptionKey(), t.Secret)
+	if err != nil {
+		logger.ErrorErr(err, "failed to open totp secret", zap.Int("UserID", u.UserID))
+		return false, errs.NewUnexpectedError("internal server error")
+	}
+	step, ok := utils.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	// A code seen once, e.g. over someone's shoulder, is not accepted again.
+	if err := s.twoFactorRepo.UseTOTPStep(ctx, u.UserID, step); err != nil {
+		if errors.Is(err, errs.ErrConflict) {
+			return false, nil
+		}
+		logger.ErrorErr(err, "repo.UseTOTPStep failed")
+		return false, errs.NewUnexpectedError("internal server error")
+	}
+	return true, nil
+}
+
+func (s *UserService) useRecoveryCode(ctx context.Context, u *domain.User, code string, device domain.Device) (bool, error) {
+	normalized := utils.NormalizeConfirmationCode(code)
+	if !utils.ValidConfirmationCode(normalized) {
+		return false, nil
+	}
+
+	if err := s.twoFactorRepo.UseRecoveryCode(ctx, u.UserID, hashToken(normalized)); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return false, nil
+		}
+		logger.ErrorErr(err, "repo.UseRecoveryCode failed")
+		return false, errs.NewUnexpectedError("internal server error")
+	}
+	s.audit(ctx, &domain.AuditEntry{Event: domain.AuditRecoveryCodeUsed}, u, u.Username, device)
+	return true, nil
+}
+
+// newRecoveryCodes returns codes to show the user, formatted "XXXX-XXXX",
+// and the hashes to store.
+func newRecoveryCodes() ([]string, []string, error) {
+	n := viper.GetInt("auth.recovery_code_count")
+	codes := make([]string, n)
+	hashes := make([]string, n)
+	for i := range n {
+		c, err := utils.NewConfirmationCode()
+		if err != nil {
+			return nil, nil, err
+		}
+		codes[i] = c[:4] + "-" + c[4:]
+		hashes[i] = hashToken(c)
+	}
+	return codes, hashes, nil
+}
+
+// totpEncryptionKey is kept apart from the JWT secret so a leaked signing
+// key does not also expose every TOTP secret. main checks it is set.
+func totpEncryptionKey() string {
+	return viper.GetString("auth.totp_encryption_key")
+}
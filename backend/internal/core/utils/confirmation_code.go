@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"crypto/rand"
+	"strings"
+)
+
+// ConfirmationCodeLength gives 32^8 (about 10^12) possible codes, far more
+// than can be guessed through the rate-limited lookup.
+const ConfirmationCodeLength = 8
+
+// Crockford's base32 alphabet leaves out I, L, O and U so codes read back
+// over the phone without mix-ups.
+const confirmationAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewConfirmationCode returns a random code such as "7K3QX9MD".
+func NewConfirmationCode() (string, error) {
+	buf := make([]byte, ConfirmationCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = confirmationAlphabet[b%32]
+	}
+	return string(buf), nil
+}
+
+// NormalizeConfirmationCode upper-cases a code typed by a guest, drops spaces
+// and dashes and maps the letters Crockford's alphabet leaves out to the
+// digits they are mistaken for.
+func NormalizeConfirmationCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.NewReplacer(" ", "", "-", "", "I", "1", "L", "1", "O", "0").Replace(code)
+	return code
+}
+
+// ValidConfirmationCode reports whether a normalized code could have been issued.
+func ValidConfirmationCode(code string) bool {
+	if len(code) != ConfirmationCodeLength {
+		return false
+	}
+	for _, c := range code {
+		if !strings.ContainsRune(confirmationAlphabet, c) {
+			return false
+		}
+	}
+	return true
+}
@@ -22,4 +22,11 @@ func ParseDate(dateStr string,fieldName string) (time.Time, error) {
 
 	return t.Truncate(24*time.Hour), nil
 
-}
\ No newline at end of file
+}
+
+// DateOnly drops the clock and zone so dates from requests and the database
+// compare by calendar day.
+func DateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
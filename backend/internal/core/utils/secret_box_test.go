@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestSealOpenSecret(t *testing.T) {
+	tests := []struct {
+		name      string
+		plaintext string
+	}{
+		{"totp secret", rfc6238Secret},
+		{"empty", ""},
+		{"unicode", "ความลับ"},
+	}
+
+	for _, tt := range tests {
+		sealed, err := SealSecret("key one", tt.plaintext)
+		if err != nil {
+			t.Fatalf("%s: SealSecret: %v", tt.name, err)
+		}
+		if tt.plaintext != "" && sealed == tt.plaintext {
+			t.Errorf("%s: sealed secret is the plaintext", tt.name)
+		}
+
+		got, err := OpenSecret("key one", sealed)
+		if err != nil {
+			t.Fatalf("%s: OpenSecret: %v", tt.name, err)
+		}
+		if got != tt.plaintext {
+			t.Errorf("%s: OpenSecret = %q, want %q", tt.name, got, tt.plaintext)
+		}
+	}
+}
+
+func TestSealSecretFreshNonce(t *testing.T) {
+	a, err := SealSecret("key one", "same secret")
+	if err != nil {
+		t.Fatalf("SealSecret: %v", err)
+	}
+	b, err := SealSecret("key one", "same secret")
+	if err != nil {
+		t.Fatalf("SealSecret: %v", err)
+	}
+	if a == b {
+		t.Error("sealing the same secret twice gave the same output")
+	}
+}
+
+func TestOpenSecretRejects(t *testing.T) {
+	sealed, err := SealSecret("key one", "secret")
+	if err != nil {
+		t.Fatalf("SealSecret: %v", err)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(sealed)
+	raw[len(raw)-1] ^= 0x01
+	tampered := base64.StdEncoding.EncodeToString(raw)
+
+	tests := []struct {
+		name       string
+		passphrase string
+		sealed     string
+	}{
+		{"wrong key", "key two", sealed},
+		{"missing key", "", sealed},
+		{"tampered", "key one", tampered},
+		{"too short", "key one", base64.StdEncoding.EncodeToString([]byte("short"))},
+		{"not base64", "key one", "%%%"},
+	}
+
+	for _, tt := range tests {
+		if got, err := OpenSecret(tt.passphrase, tt.sealed); err == nil {
+			t.Errorf("%s: OpenSecret = %q, want an error", tt.name, got)
+		}
+	}
+}
+
+func TestSealSecretMissingKey(t *testing.T) {
+	if _, err := SealSecret("", "secret"); err == nil {
+		t.Error("SealSecret accepted an empty key")
+	}
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 test key of RFC 6238 Appendix B, "12345678901234567890",
+// in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The Appendix B codes are eight digits; six-digit codes are their last six.
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	for _, tt := range rfc6238Vectors {
+		got, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode at %d: %v", tt.unix, err)
+		}
+		if got != tt.code {
+			t.Errorf("TOTPCode at %d = %s, want %s", tt.unix, got, tt.code)
+		}
+	}
+}
+
+func TestTOTPCodeLowerCaseSecret(t *testing.T) {
+	got, err := TOTPCode("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", TOTPStep(time.Unix(59, 0)))
+	if err != nil || got != "287082" {
+		t.Errorf("TOTPCode with lower-case secret = %q, %v; want 287082", got, err)
+	}
+}
+
+func TestTOTPCodeBadSecret(t *testing.T) {
+	if _, err := TOTPCode("not base32!", 1); err == nil {
+		t.Error("TOTPCode accepted a secret that is not base32")
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := TOTPStep(now)
+	codeAt := func(s int64) string {
+		code, err := TOTPCode(rfc6238Secret, s)
+		if err != nil {
+			t.Fatalf("TOTPCode: %v", err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		wantOK   bool
+		wantStep int64
+	}{
+		{"current step", codeAt(step), true, step},
+		{"one step behind", codeAt(step - 1), true, step - 1},
+		{"one step ahead", codeAt(step + 1), true, step + 1},
+		{"two steps behind", codeAt(step - 2), false, 0},
+		{"two steps ahead", codeAt(step + 2), false, 0},
+		{"spaces are ignored", " 005 924 ", true, step},
+		{"too short", "00592", false, 0},
+		{"too long", "0059245", false, 0},
+		{"empty", "", false, 0},
+		{"wrong code", "123456", false, 0},
+	}
+
+	for _, tt := range tests {
+		gotStep, ok := ValidateTOTP(rfc6238Secret, tt.code, now)
+		if ok != tt.wantOK || gotStep != tt.wantStep {
+			t.Errorf("%s: ValidateTOTP(%q) = %d, %v; want %d, %v", tt.name, tt.code, gotStep, ok, tt.wantStep, tt.wantOK)
+		}
+	}
+}
+
+func TestNewTOTPSecret(t *testing.T) {
+	a, err := NewTOTPSecret()
+	if err != nil {
+		t.Fatalf("NewTOTPSecret: %v", err)
+	}
+	b, err := NewTOTPSecret()
+	if err != nil {
+		t.Fatalf("NewTOTPSecret: %v", err)
+	}
+	if len(a) != 32 {
+		t.Errorf("secret %q has %d characters, want 32 (160 bits)", a, len(a))
+	}
+	if a == b {
+		t.Error("two secrets were the same")
+	}
+	if _, err := TOTPCode(a, 1); err != nil {
+		t.Errorf("new secret does not decode: %v", err)
+	}
+}
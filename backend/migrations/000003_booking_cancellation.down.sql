@@ -0,0 +1,3 @@
+ALTER TABLE bookings DROP COLUMN IF EXISTS cancelled_at;
+ALTER TABLE bookings DROP COLUMN IF EXISTS cancellation_fee;
+ALTER TABLE bookings DROP COLUMN IF EXISTS cancellation_reason;
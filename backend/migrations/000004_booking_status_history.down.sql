@@ -0,0 +1,3 @@
+UPDATE bookings SET status = 'cancelled' WHERE status IN ('expired', 'no-show');
+
+DROP TABLE IF EXISTS booking_status_history;
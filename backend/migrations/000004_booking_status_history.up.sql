@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS booking_status_history (
+    history_id SERIAL PRIMARY KEY,
+    booking_id INT REFERENCES bookings(booking_id) ON DELETE CASCADE,
+    actor_user_id INT REFERENCES users(user_id) ON DELETE SET NULL, -- NULL when changed by the system
+    old_status VARCHAR(20),
+    new_status VARCHAR(20) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_status_history_booking_id ON booking_status_history(booking_id);
+
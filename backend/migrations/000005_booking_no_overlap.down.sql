@@ -0,0 +1 @@
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_room_no_overlap;
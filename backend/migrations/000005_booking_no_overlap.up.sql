@@ -0,0 +1,10 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- A room can only hold one live booking per night. Released bookings
+-- (cancelled, expired, no-show) no longer occupy the room.
+ALTER TABLE bookings ADD CONSTRAINT bookings_room_no_overlap
+    EXCLUDE USING gist (
+        room_id WITH =,
+        daterange(check_in_date, check_out_date, '[)') WITH &&
+    )
+    WHERE (status NOT IN ('cancelled', 'expired', 'no-show'));
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS booking_nights;
+DROP TABLE IF EXISTS room_type_rate_calendar;
@@ -0,0 +1,16 @@
+-- Per-date price overrides; room_type_rate_prices stays the fallback for dates without one
+CREATE TABLE IF NOT EXISTS room_type_rate_calendar (
+    room_type_id INT REFERENCES roomtypes(room_type_id) ON DELETE CASCADE,
+    rate_plan_id INT REFERENCES rate_plans(rate_plan_id) ON DELETE CASCADE,
+    stay_date DATE NOT NULL,
+    price DECIMAL(10, 2) NOT NULL,
+    PRIMARY KEY (room_type_id, rate_plan_id, stay_date)
+);
+
+-- Price charged for each night of a booking
+CREATE TABLE IF NOT EXISTS booking_nights (
+    booking_id INT REFERENCES bookings(booking_id) ON DELETE CASCADE,
+    stay_date DATE NOT NULL,
+    price DECIMAL(10, 2) NOT NULL,
+    PRIMARY KEY (booking_id, stay_date)
+);
@@ -0,0 +1,21 @@
+-- Length-of-stay and arrival/departure restrictions; a NULL rate_plan_id applies to every rate plan
+CREATE TABLE IF NOT EXISTS stay_restrictions (
+    restriction_id SERIAL PRIMARY KEY,
+    room_type_id INT NOT NULL REFERENCES roomtypes(room_type_id) ON DELETE CASCADE,
+    rate_plan_id INT REFERENCES rate_plans(rate_plan_id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    min_los INT,
+    max_los INT,
+    closed_to_arrival BOOLEAN NOT NULL DEFAULT FALSE,
+    closed_to_departure BOOLEAN NOT NULL DEFAULT FALSE,
+    stop_sell BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (start_date <= end_date),
+    CHECK (min_los IS NULL OR min_los > 0),
+    CHECK (max_los IS NULL OR max_los > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stay_restrictions_room_type_dates
+    ON stay_restrictions (room_type_id, start_date, end_date);
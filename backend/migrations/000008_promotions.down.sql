@@ -0,0 +1,7 @@
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS discount_amount,
+    DROP COLUMN IF EXISTS promo_code,
+    DROP COLUMN IF EXISTS promotion_id;
+
+DROP TABLE IF EXISTS promotion_redemptions;
+DROP TABLE IF EXISTS promotions;
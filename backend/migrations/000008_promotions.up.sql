@@ -0,0 +1,37 @@
+-- Promo codes; NULL windows, caps and empty room type / rate plan lists mean unrestricted
+CREATE TABLE IF NOT EXISTS promotions (
+    promotion_id SERIAL PRIMARY KEY,
+    code VARCHAR(50) UNIQUE NOT NULL,
+    description TEXT,
+    discount_type VARCHAR(20) NOT NULL CHECK (discount_type IN ('percent', 'fixed')),
+    discount_value DECIMAL(10, 2) NOT NULL CHECK (discount_value > 0),
+    min_spend DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    booking_start TIMESTAMP,
+    booking_end TIMESTAMP,
+    stay_start DATE,
+    stay_end DATE,
+    room_type_ids INT[] NOT NULL DEFAULT '{}',
+    rate_plan_ids INT[] NOT NULL DEFAULT '{}',
+    max_redemptions INT,
+    max_per_user INT,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One row per booking that used a promotion; rows of cancelled or expired bookings no longer count towards the caps
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    redemption_id SERIAL PRIMARY KEY,
+    promotion_id INT NOT NULL REFERENCES promotions(promotion_id) ON DELETE CASCADE,
+    booking_id INT NOT NULL UNIQUE REFERENCES bookings(booking_id) ON DELETE CASCADE,
+    user_id INT REFERENCES users(user_id) ON DELETE SET NULL,
+    discount_amount DECIMAL(10, 2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_promotion ON promotion_redemptions (promotion_id, user_id);
+
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS promotion_id INT REFERENCES promotions(promotion_id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS promo_code VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS discount_amount DECIMAL(10, 2) NOT NULL DEFAULT 0;
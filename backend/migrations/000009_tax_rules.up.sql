@@ -0,0 +1,37 @@
+-- Tax and fee rules applied in priority order. Fixed amounts are multiplied by the basis;
+-- compound percentages are charged on the base plus the taxes applied before them.
+CREATE TABLE IF NOT EXISTS tax_rules (
+    tax_rule_id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    calc_type VARCHAR(20) NOT NULL CHECK (calc_type IN ('percent', 'fixed')),
+    rate DECIMAL(10, 4) NOT NULL CHECK (rate >= 0),
+    basis VARCHAR(20) NOT NULL DEFAULT 'stay' CHECK (basis IN ('stay', 'night', 'person', 'person_night')),
+    applies_to VARCHAR(20) NOT NULL DEFAULT 'all' CHECK (applies_to IN ('room', 'addon', 'all')),
+    is_compound BOOLEAN NOT NULL DEFAULT FALSE,
+    priority INT NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Itemized taxes and fees charged on a booking; bookings.taxes_amount keeps their sum
+CREATE TABLE IF NOT EXISTS booking_taxes (
+    booking_tax_id SERIAL PRIMARY KEY,
+    booking_id INT NOT NULL REFERENCES bookings(booking_id) ON DELETE CASCADE,
+    tax_rule_id INT REFERENCES tax_rules(tax_rule_id) ON DELETE SET NULL,
+    name VARCHAR(100) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_taxes_booking ON booking_taxes (booking_id);
+
+-- The service charge is seeded inactive so existing prices keep the old 7% VAT total
+INSERT INTO tax_rules (name, calc_type, rate, basis, applies_to, is_compound, priority, is_active) VALUES
+    ('Service Charge', 'percent', 10, 'stay', 'all', FALSE, 10, FALSE),
+    ('VAT', 'percent', 7, 'stay', 'all', TRUE, 20, TRUE);
+
+INSERT INTO booking_taxes (booking_id, tax_rule_id, name, amount)
+SELECT b.booking_id, t.tax_rule_id, t.name, b.taxes_amount
+FROM bookings b
+JOIN tax_rules t ON t.name = 'VAT'
+WHERE b.taxes_amount > 0;
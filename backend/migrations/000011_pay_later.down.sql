@@ -0,0 +1,5 @@
+ALTER TABLE payments DROP COLUMN IF EXISTS recorded_by;
+ALTER TABLE bookings DROP COLUMN IF EXISTS amount_paid;
+ALTER TABLE rate_plans
+    DROP COLUMN IF EXISTS requires_guarantee,
+    DROP COLUMN IF EXISTS deposit_percent;
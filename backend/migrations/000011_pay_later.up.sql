@@ -0,0 +1,24 @@
+-- Pay-later plans confirm bookings without payment, or after a deposit or card guarantee.
+ALTER TABLE rate_plans
+    ADD COLUMN IF NOT EXISTS deposit_percent DECIMAL(5, 2) NOT NULL DEFAULT 0 CHECK (deposit_percent >= 0 AND deposit_percent <= 100),
+    ADD COLUMN IF NOT EXISTS requires_guarantee BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- amount_paid is the sum of succeeded payments; the balance is due at check-in.
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS amount_paid DECIMAL(10, 2) NOT NULL DEFAULT 0;
+
+UPDATE bookings b
+SET amount_paid = b.total_price
+WHERE b.status IN ('confirmed', 'checked-in', 'checked-out', 'no-show');
+
+UPDATE bookings b
+SET amount_paid = b.total_price
+WHERE b.status = 'cancelled'
+  AND EXISTS (
+      SELECT 1 FROM booking_status_history h
+      WHERE h.booking_id = b.booking_id AND h.new_status = 'confirmed'
+  );
+
+-- Front desk payments are recorded by staff instead of arriving through a provider webhook.
+ALTER TABLE payments
+    ADD COLUMN IF NOT EXISTS recorded_by INT REFERENCES users(user_id) ON DELETE SET NULL;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS folio_entries;
@@ -0,0 +1,75 @@
+-- The folio is a booking's ledger. Amounts are signed from the guest's side:
+-- charges and refunds are positive, payments and credits negative, and the
+-- balance is the sum of the entries.
+CREATE TABLE IF NOT EXISTS folio_entries (
+    entry_id SERIAL PRIMARY KEY,
+    booking_id INT NOT NULL REFERENCES bookings(booking_id) ON DELETE CASCADE,
+    entry_type VARCHAR(20) NOT NULL CHECK (entry_type IN ('charge', 'payment', 'refund', 'adjustment')),
+    category VARCHAR(20) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    amount DECIMAL(10, 2) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'THB',
+    payment_id INT REFERENCES payments(payment_id) ON DELETE SET NULL,
+    actor_user_id INT REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (entry_type <> 'charge' OR amount >= 0),
+    CHECK (entry_type <> 'payment' OR amount <= 0),
+    CHECK (entry_type <> 'refund' OR amount >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_folio_entries_booking ON folio_entries (booking_id, created_at);
+
+-- Backfill existing bookings from their stored totals.
+INSERT INTO folio_entries (booking_id, entry_type, category, description, amount, actor_user_id, created_at)
+SELECT b.booking_id, 'charge', 'room', 'Room', b.room_subtotal, b.user_id, b.created_at
+FROM bookings b;
+
+INSERT INTO folio_entries (booking_id, entry_type, category, description, amount, actor_user_id, created_at)
+SELECT b.booking_id, 'adjustment', 'discount', 'Promo code ' || COALESCE(b.promo_code, ''), -b.discount_amount, b.user_id, b.created_at
+FROM bookings b
+WHERE b.discount_amount > 0;
+
+INSERT INTO folio_entries (booking_id, entry_type, category, description, amount, actor_user_id, created_at)
+SELECT b.booking_id, 'charge', 'addon', a.name || ' x' || ba.quantity, ba.price_at_time_of_booking * ba.quantity, b.user_id, b.created_at
+FROM booking_addons ba
+JOIN bookings b ON b.booking_id = ba.booking_id
+JOIN addons a ON a.addon_id = ba.addon_id;
+
+INSERT INTO folio_entries (booking_id, entry_type, category, description, amount, actor_user_id, created_at)
+SELECT b.booking_id, 'charge', 'tax', bt.name, bt.amount, b.user_id, b.created_at
+FROM booking_taxes bt
+JOIN bookings b ON b.booking_id = bt.booking_id;
+
+INSERT INTO folio_entries (booking_id, entry_type, category, description, amount, created_at)
+SELECT b.booking_id, 'adjustment', 'cancellation', 'Charges released on ' || b.status, -(b.total_price - b.cancellation_fee), COALESCE(b.cancelled_at, b.updated_at)
+FROM bookings b
+WHERE b.status IN ('cancelled', 'expired');
+
+INSERT INTO folio_entries (booking_id, entry_type, category, description, amount, payment_id, actor_user_id, created_at)
+SELECT p.booking_id, 'payment', 'payment', 'Payment via ' || p.provider, -p.amount, p.payment_id, p.recorded_by, p.created_at
+FROM payments p
+WHERE p.status IN ('succeeded', 'refunded');
+
+INSERT INTO folio_entries (booking_id, entry_type, category, description, amount, payment_id, created_at)
+SELECT p.booking_id, 'refund', 'payment', 'Refund via ' || p.provider, p.amount, p.payment_id, p.updated_at
+FROM payments p
+WHERE p.status = 'refunded';
+
+-- Bookings confirmed before payments were recorded have no payment rows.
+INSERT INTO folio_entries (booking_id, entry_type, category, description, amount, created_at)
+SELECT b.booking_id, 'payment', 'payment', 'Payment before ledger', -(b.amount_paid - COALESCE(p.paid, 0)), b.created_at
+FROM bookings b
+LEFT JOIN (
+    SELECT booking_id, SUM(amount) AS paid
+    FROM payments
+    WHERE status = 'succeeded'
+    GROUP BY booking_id
+) p ON p.booking_id = b.booking_id
+WHERE b.amount_paid > COALESCE(p.paid, 0);
+
+-- amount_paid is now the net of payment and refund entries.
+UPDATE bookings b
+SET amount_paid = COALESCE((
+    SELECT -SUM(f.amount) FROM folio_entries f
+    WHERE f.booking_id = b.booking_id AND f.entry_type IN ('payment', 'refund')
+), 0);
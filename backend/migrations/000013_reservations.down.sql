@@ -0,0 +1,10 @@
+DELETE FROM payments WHERE booking_id IS NULL;
+
+ALTER TABLE payments
+    DROP CONSTRAINT IF EXISTS payments_booking_or_reservation,
+    ALTER COLUMN booking_id SET NOT NULL,
+    DROP COLUMN IF EXISTS reservation_id;
+
+ALTER TABLE bookings DROP COLUMN IF EXISTS reservation_id;
+
+DROP TABLE IF EXISTS reservations;
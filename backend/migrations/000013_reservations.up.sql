@@ -0,0 +1,38 @@
+-- A reservation groups the room stays booked together in one checkout under
+-- one confirmation number. Every booking belongs to exactly one reservation.
+CREATE TABLE IF NOT EXISTS reservations (
+    reservation_id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS reservation_id INT REFERENCES reservations(reservation_id) ON DELETE CASCADE;
+
+-- Existing bookings become single-room reservations that keep their number.
+INSERT INTO reservations (reservation_id, user_id, created_at, updated_at)
+SELECT booking_id, user_id, created_at, updated_at
+FROM bookings
+WHERE reservation_id IS NULL;
+
+UPDATE bookings SET reservation_id = booking_id WHERE reservation_id IS NULL;
+
+SELECT setval(
+    pg_get_serial_sequence('reservations', 'reservation_id'),
+    COALESCE((SELECT MAX(reservation_id) FROM reservations), 0) + 1,
+    false
+);
+
+ALTER TABLE bookings ALTER COLUMN reservation_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_reservation ON bookings (reservation_id);
+
+-- One payment can cover every room of a reservation; it is then recorded
+-- against the reservation instead of a single booking.
+ALTER TABLE payments
+    ADD COLUMN IF NOT EXISTS reservation_id INT REFERENCES reservations(reservation_id) ON DELETE CASCADE,
+    ALTER COLUMN booking_id DROP NOT NULL,
+    ADD CONSTRAINT payments_booking_or_reservation CHECK (booking_id IS NOT NULL OR reservation_id IS NOT NULL);
+
+CREATE INDEX IF NOT EXISTS idx_payments_reservation ON payments (reservation_id);
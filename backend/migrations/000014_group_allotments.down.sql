@@ -0,0 +1,3 @@
+ALTER TABLE bookings DROP COLUMN IF EXISTS allotment_id;
+
+DROP TABLE IF EXISTS group_allotments;
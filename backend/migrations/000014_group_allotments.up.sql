@@ -0,0 +1,25 @@
+-- Rooms of a type held for a group (wedding, conference...) over a date range.
+-- Until the cutoff date the unclaimed rooms are kept out of public inventory;
+-- from the cutoff date on they return to it.
+CREATE TABLE IF NOT EXISTS group_allotments (
+    allotment_id SERIAL PRIMARY KEY,
+    group_code VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    room_type_id INT NOT NULL REFERENCES roomtypes(room_type_id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    rooms_allotted INT NOT NULL CHECK (rooms_allotted > 0),
+    cutoff_date DATE NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date > start_date),
+    CHECK (cutoff_date <= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_allotments_dates ON group_allotments (room_type_id, start_date, end_date);
+
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS allotment_id INT REFERENCES group_allotments(allotment_id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_allotment ON bookings (allotment_id);
@@ -0,0 +1,8 @@
+ALTER TABLE addons DROP COLUMN IF EXISTS is_extra_bed;
+
+ALTER TABLE booking_nights DROP COLUMN IF EXISTS extra_guest_charge;
+
+ALTER TABLE bookings DROP COLUMN IF EXISTS child_ages;
+
+DROP TABLE IF EXISTS occupancy_child_fees;
+DROP TABLE IF EXISTS occupancy_pricing;
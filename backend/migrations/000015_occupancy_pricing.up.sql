@@ -0,0 +1,31 @@
+-- Guest-count pricing per room type and rate plan. The nightly rate covers
+-- base_occupancy guests; every guest beyond that pays a fee per night.
+CREATE TABLE IF NOT EXISTS occupancy_pricing (
+    room_type_id INT REFERENCES roomtypes(room_type_id) ON DELETE CASCADE,
+    rate_plan_id INT REFERENCES rate_plans(rate_plan_id) ON DELETE CASCADE,
+    base_occupancy INT NOT NULL CHECK (base_occupancy > 0),
+    extra_adult_fee DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (extra_adult_fee >= 0),
+    PRIMARY KEY (room_type_id, rate_plan_id)
+);
+
+-- Per-night fee for an extra child, by age band (ages inclusive)
+CREATE TABLE IF NOT EXISTS occupancy_child_fees (
+    child_fee_id SERIAL PRIMARY KEY,
+    room_type_id INT NOT NULL,
+    rate_plan_id INT NOT NULL,
+    min_age INT NOT NULL CHECK (min_age >= 0),
+    max_age INT NOT NULL,
+    fee DECIMAL(10, 2) NOT NULL CHECK (fee >= 0),
+    FOREIGN KEY (room_type_id, rate_plan_id) REFERENCES occupancy_pricing(room_type_id, rate_plan_id) ON DELETE CASCADE,
+    CHECK (max_age >= min_age)
+);
+
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS child_ages INT[] NOT NULL DEFAULT '{}';
+
+-- Part of the night's price charged for guests beyond the base occupancy
+ALTER TABLE booking_nights
+    ADD COLUMN IF NOT EXISTS extra_guest_charge DECIMAL(10, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE addons
+    ADD COLUMN IF NOT EXISTS is_extra_bed BOOLEAN NOT NULL DEFAULT FALSE;
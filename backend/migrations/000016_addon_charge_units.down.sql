@@ -0,0 +1,9 @@
+ALTER TABLE booking_addons
+    DROP COLUMN IF EXISTS service_date,
+    DROP COLUMN IF EXISTS guests,
+    DROP COLUMN IF EXISTS nights,
+    DROP COLUMN IF EXISTS charge_unit;
+
+ALTER TABLE addons
+    DROP COLUMN IF EXISTS requires_service_date,
+    DROP COLUMN IF EXISTS charge_unit;
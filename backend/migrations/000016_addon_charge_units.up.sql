@@ -0,0 +1,13 @@
+-- How an addon's price is multiplied: once per stay, per night, per person per
+-- night, or per item ordered. per_item keeps the old price x quantity behaviour.
+ALTER TABLE addons
+    ADD COLUMN IF NOT EXISTS charge_unit VARCHAR(30) NOT NULL DEFAULT 'per_item'
+        CHECK (charge_unit IN ('per_stay', 'per_night', 'per_person_per_night', 'per_item')),
+    ADD COLUMN IF NOT EXISTS requires_service_date BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- nights and guests are the multipliers the line was charged for (0 when not used)
+ALTER TABLE booking_addons
+    ADD COLUMN IF NOT EXISTS charge_unit VARCHAR(30) NOT NULL DEFAULT 'per_item',
+    ADD COLUMN IF NOT EXISTS nights INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS guests INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS service_date DATE;
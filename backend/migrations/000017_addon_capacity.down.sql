@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_booking_addons_addon;
+
+ALTER TABLE booking_addons
+    DROP COLUMN IF EXISTS slot_id;
+
+DROP TABLE IF EXISTS addon_slots;
+
+ALTER TABLE addons
+    DROP COLUMN IF EXISTS daily_capacity;
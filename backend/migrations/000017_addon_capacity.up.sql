@@ -0,0 +1,19 @@
+-- Optional limits on how much of an addon can be sold per day. An addon has a
+-- daily stock, named time slots each with their own capacity, or both; with
+-- neither it is unlimited.
+ALTER TABLE addons
+    ADD COLUMN IF NOT EXISTS daily_capacity INT CHECK (daily_capacity > 0);
+
+CREATE TABLE IF NOT EXISTS addon_slots (
+    slot_id SERIAL PRIMARY KEY,
+    addon_id INT NOT NULL REFERENCES addons(addon_id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    capacity INT NOT NULL CHECK (capacity > 0),
+    UNIQUE (addon_id, name)
+);
+
+-- A slot cannot be deleted while bookings hold it.
+ALTER TABLE booking_addons
+    ADD COLUMN IF NOT EXISTS slot_id INT REFERENCES addon_slots(slot_id);
+
+CREATE INDEX IF NOT EXISTS idx_booking_addons_addon ON booking_addons (addon_id, service_date);
@@ -0,0 +1,29 @@
+-- Guests waiting for a sold-out room type. When a room frees up the oldest
+-- matching entry is offered it as a pending booking that lapses at
+-- offer_expires_at.
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+    entry_id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    room_type_id INT NOT NULL REFERENCES roomtypes(room_type_id) ON DELETE CASCADE,
+    rate_plan_id INT NOT NULL REFERENCES rate_plans(rate_plan_id) ON DELETE CASCADE,
+    check_in_date DATE NOT NULL,
+    check_out_date DATE NOT NULL,
+    num_adults INT NOT NULL CHECK (num_adults > 0),
+    child_ages INT[] NOT NULL DEFAULT '{}',
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting'
+        CHECK (status IN ('waiting', 'offered', 'booked', 'expired', 'cancelled')),
+    booking_id INT REFERENCES bookings(booking_id) ON DELETE SET NULL,
+    offered_at TIMESTAMP,
+    offer_expires_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (check_out_date > check_in_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_waitlist_queue ON waitlist_entries (status, created_at, entry_id);
+
+-- A guest waits once per room type and stay.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_waitlist_active
+    ON waitlist_entries (user_id, room_type_id, check_in_date, check_out_date)
+    WHERE status IN ('waiting', 'offered');
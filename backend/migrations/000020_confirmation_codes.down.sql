@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_bookings_confirmation_code;
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS confirmation_code;
@@ -0,0 +1,38 @@
+-- Non-sequential code a guest quotes to find a booking, in Crockford base32.
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS confirmation_code CHAR(8);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookings_confirmation_code ON bookings (confirmation_code);
+
+-- Existing bookings draw their codes from pgcrypto's secure generator, like
+-- the ones the app issues, and draw again on the rare clash. 256 is a multiple
+-- of 32, so taking each byte mod 32 keeps every symbol equally likely.
+DO $$
+DECLARE
+    alphabet CONSTANT TEXT := '0123456789ABCDEFGHJKMNPQRSTVWXYZ';
+    b RECORD;
+    raw BYTEA;
+    code TEXT;
+BEGIN
+    FOR b IN SELECT booking_id FROM bookings WHERE confirmation_code IS NULL LOOP
+        LOOP
+            raw := gen_random_bytes(8);
+            code := '';
+            FOR i IN 0..7 LOOP
+                code := code || substr(alphabet, get_byte(raw, i) % 32 + 1, 1);
+            END LOOP;
+
+            BEGIN
+                UPDATE bookings SET confirmation_code = code WHERE booking_id = b.booking_id;
+                EXIT;
+            EXCEPTION WHEN unique_violation THEN
+                -- Already taken; draw another.
+            END;
+        END LOOP;
+    END LOOP;
+END $$;
+
+ALTER TABLE bookings
+    ALTER COLUMN confirmation_code SET NOT NULL;
@@ -0,0 +1,83 @@
+-- Roles replace the all-or-nothing users.is_admin flag. A role grants a set of
+-- permissions and a user holds any number of roles.
+CREATE TABLE IF NOT EXISTS roles (
+    role_id SERIAL PRIMARY KEY,
+    name VARCHAR(50) UNIQUE NOT NULL,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    permission_id SERIAL PRIMARY KEY,
+    name VARCHAR(100) UNIQUE NOT NULL,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id INT NOT NULL REFERENCES roles(role_id) ON DELETE CASCADE,
+    permission_id INT NOT NULL REFERENCES permissions(permission_id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id INT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    role_id INT NOT NULL REFERENCES roles(role_id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, role_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_roles_role ON user_roles (role_id);
+
+INSERT INTO roles (name, description) VALUES
+    ('guest', 'Books and manages their own stays'),
+    ('front-desk', 'Handles bookings, payments and check-in for any guest'),
+    ('housekeeping', 'Updates room status and blocks rooms for maintenance'),
+    ('revenue-manager', 'Manages rates, restrictions, promotions, taxes and group allotments'),
+    ('admin', 'Full access')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+    ('bookings:read_all', 'List every booking'),
+    ('bookings:manage_any', 'View and change bookings of any guest'),
+    ('bookings:update_status', 'Move bookings through their statuses'),
+    ('payments:record', 'Record offline payments'),
+    ('payments:refund', 'Refund payments'),
+    ('folio:adjust', 'Post folio adjustments'),
+    ('waitlist:read', 'View the waitlist'),
+    ('rooms:manage', 'Create and remove rooms'),
+    ('rooms:update_status', 'Change room status'),
+    ('rooms:block', 'Block and unblock rooms'),
+    ('room_types:manage', 'Create, edit and remove room types'),
+    ('amenities:manage', 'Create, edit and remove amenities'),
+    ('addons:manage', 'Create, edit and remove addons and their categories'),
+    ('rate_plans:manage', 'Create, edit and remove rate plans and prices'),
+    ('restrictions:manage', 'Manage stay restrictions'),
+    ('promotions:manage', 'Manage promotions'),
+    ('tax_rules:manage', 'Manage tax rules'),
+    ('allotments:manage', 'Manage group allotments'),
+    ('users:read', 'List users'),
+    ('users:manage', 'View and edit any user'),
+    ('users:delete', 'Delete users'),
+    ('roles:manage', 'Assign and revoke roles')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.role_id, p.permission_id
+FROM roles r
+JOIN permissions p ON
+    (r.name = 'admin')
+    OR (r.name = 'front-desk' AND p.name IN (
+        'bookings:read_all', 'bookings:manage_any', 'bookings:update_status', 'payments:record',
+        'folio:adjust', 'waitlist:read', 'rooms:update_status', 'users:read'))
+    OR (r.name = 'housekeeping' AND p.name IN ('rooms:update_status', 'rooms:block'))
+    OR (r.name = 'revenue-manager' AND p.name IN (
+        'bookings:read_all', 'rate_plans:manage', 'restrictions:manage', 'promotions:manage',
+        'tax_rules:manage', 'allotments:manage', 'addons:manage'))
+ON CONFLICT DO NOTHING;
+
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.user_id, r.role_id
+FROM users u
+JOIN roles r ON r.name = CASE WHEN u.is_admin THEN 'admin' ELSE 'guest' END
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE users DROP COLUMN IF EXISTS is_admin;
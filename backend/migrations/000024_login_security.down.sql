@@ -0,0 +1,9 @@
+DELETE FROM permissions WHERE name = 'audit:read';
+
+DELETE FROM account_tokens WHERE purpose = 'unlock_account';
+ALTER TABLE account_tokens DROP CONSTRAINT IF EXISTS account_tokens_purpose_check;
+ALTER TABLE account_tokens ADD CONSTRAINT account_tokens_purpose_check
+    CHECK (purpose IN ('verify_email', 'reset_password'));
+
+DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS login_attempts;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS login_challenges;
+DROP TABLE IF EXISTS recovery_codes;
+DROP TABLE IF EXISTS user_totp;
+
+ALTER TABLE roles
+    DROP COLUMN IF EXISTS require_two_factor;
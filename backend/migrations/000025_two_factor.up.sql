@@ -0,0 +1,41 @@
+-- Roles whose holders must use two-factor authentication. Until they enrol,
+-- such users keep their session but lose the role's permissions.
+ALTER TABLE roles
+    ADD COLUMN IF NOT EXISTS require_two_factor BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE roles SET require_two_factor = TRUE WHERE name = 'admin';
+
+-- One TOTP secret per user, encrypted with auth.totp_encryption_key.
+-- confirmed_at is NULL while enrolment waits for the first code;
+-- last_used_step stops a code from being used twice.
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id INT PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    confirmed_at TIMESTAMP,
+    last_used_step BIGINT NOT NULL DEFAULT 0
+);
+
+-- Single-use recovery codes, stored as SHA-256 hashes.
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    code_id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    used_at TIMESTAMP,
+    UNIQUE (user_id, code_hash)
+);
+
+-- Issued after a correct password for users with two-factor enabled, and
+-- exchanged for tokens together with a code.
+CREATE TABLE IF NOT EXISTS login_challenges (
+    challenge_id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    failed_attempts INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_challenges_expires ON login_challenges (expires_at);
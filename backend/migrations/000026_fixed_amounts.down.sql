@@ -0,0 +1,13 @@
+UPDATE tax_rules SET rate = amount WHERE calc_type = 'fixed';
+
+ALTER TABLE tax_rules
+    DROP COLUMN IF EXISTS amount;
+
+ALTER TABLE promotions DROP CONSTRAINT IF EXISTS promotions_discount_check;
+
+UPDATE promotions SET discount_rate = discount_amount WHERE discount_type = 'fixed';
+
+ALTER TABLE promotions
+    DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE promotions RENAME COLUMN discount_rate TO discount_value;
+ALTER TABLE promotions ADD CONSTRAINT promotions_discount_value_check CHECK (discount_value > 0);
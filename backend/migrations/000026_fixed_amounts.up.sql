@@ -0,0 +1,18 @@
+-- Fixed discounts and fees get money columns of their own, so discount_rate
+-- and rate only ever hold percentages.
+ALTER TABLE promotions DROP CONSTRAINT IF EXISTS promotions_discount_value_check;
+ALTER TABLE promotions RENAME COLUMN discount_value TO discount_rate;
+ALTER TABLE promotions
+    ADD COLUMN IF NOT EXISTS discount_amount DECIMAL(10, 2) NOT NULL DEFAULT 0;
+
+UPDATE promotions SET discount_amount = discount_rate, discount_rate = 0 WHERE discount_type = 'fixed';
+
+ALTER TABLE promotions ADD CONSTRAINT promotions_discount_check CHECK (
+    (discount_type = 'percent' AND discount_rate > 0 AND discount_rate <= 100 AND discount_amount = 0)
+    OR (discount_type = 'fixed' AND discount_amount > 0 AND discount_rate = 0)
+);
+
+ALTER TABLE tax_rules
+    ADD COLUMN IF NOT EXISTS amount DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (amount >= 0);
+
+UPDATE tax_rules SET amount = ROUND(rate, 2), rate = 0 WHERE calc_type = 'fixed';
@@ -0,0 +1,15 @@
+-- A refund is reserved here under the payment's lock before the provider is
+-- asked to send the money back, so concurrent refunds cannot together return
+-- more than was paid. Pending rows count against the payment until the
+-- provider answers; one left pending means the outcome needs checking by hand.
+CREATE TABLE IF NOT EXISTS refunds (
+    refund_id SERIAL PRIMARY KEY,
+    payment_id INT NOT NULL REFERENCES payments(payment_id) ON DELETE CASCADE,
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    failure_reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_pending ON refunds (payment_id) WHERE status = 'pending';
@@ -0,0 +1,5 @@
+-- A processing key is leased to the request running it. If the process dies
+-- before the request finishes or is released, a retry takes the key over once
+-- the lease runs out instead of waiting for the key to expire.
+ALTER TABLE idempotency_keys
+    ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;